@@ -0,0 +1,56 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "sync"
+
+// Accumulator folds a stream of updates into a current state, by merging each update into the
+// state, e.g. to apply configuration watch events or CRDT deltas. Options are processed only once,
+// when the accumulator is created, and are shared by all merges. Accumulators are safe for
+// concurrent use.
+type Accumulator[T any] struct {
+	mu        sync.Mutex
+	coalescer *coalescer
+	value     T
+}
+
+// NewAccumulator creates a new Accumulator with the given initial state, that merges updates with
+// the given options. The initial state is never modified.
+func NewAccumulator[T any](initial T, opts ...Option) *Accumulator[T] {
+	return &Accumulator[T]{coalescer: newCoalescer(opts...), value: initial}
+}
+
+// Add merges the given update into the current state, as DeepMerge(state, update) would. If the
+// merge fails, the error is returned and the current state is left unchanged. The update is never
+// modified, and the new state shares no references with it.
+func (a *Accumulator[T]) Add(update T) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.coalescer.reset()
+	merged, err := deepMerge(a.coalescer, a.value, update)
+	if err != nil {
+		return err
+	}
+	a.value = merged
+	return nil
+}
+
+// Value returns the current state. The returned value is shared with the accumulator, and must not
+// be modified; use DeepCopy to obtain a modifiable copy.
+func (a *Accumulator[T]) Value() T {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.value
+}
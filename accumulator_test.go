@@ -0,0 +1,71 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccumulator(t *testing.T) {
+	type config struct {
+		Replicas *int
+		Labels   map[string]string
+		Hosts    []string
+	}
+	t.Run("fold", func(t *testing.T) {
+		initial := config{Replicas: intPtr(1), Labels: map[string]string{"a": "1"}}
+		acc := NewAccumulator(initial, WithSliceSetUnionMerge(reflect.TypeOf([]string{})))
+		assert.Equal(t, initial, acc.Value())
+		update1 := config{Labels: map[string]string{"b": "2"}, Hosts: []string{"h1"}}
+		require.NoError(t, acc.Add(update1))
+		update2 := config{Replicas: intPtr(3), Hosts: []string{"h1", "h2"}}
+		require.NoError(t, acc.Add(update2))
+		got := acc.Value()
+		assert.Equal(t, config{Replicas: intPtr(3), Labels: map[string]string{"a": "1", "b": "2"}, Hosts: []string{"h1", "h2"}}, got)
+		assert.Equal(t, config{Replicas: intPtr(1), Labels: map[string]string{"a": "1"}}, initial)
+		assert.NotSame(t, update2.Replicas, got.Replicas)
+	})
+	t.Run("error", func(t *testing.T) {
+		acc := NewAccumulator(config{Replicas: intPtr(1)}, WithValidator(func(v interface{}) error {
+			if *v.(config).Replicas > 5 {
+				return errors.New("too many replicas")
+			}
+			return nil
+		}))
+		assert.EqualError(t, acc.Add(config{Replicas: intPtr(10)}), "validation failed: too many replicas")
+		assert.Equal(t, config{Replicas: intPtr(1)}, acc.Value())
+		require.NoError(t, acc.Add(config{Replicas: intPtr(2)}))
+		assert.Equal(t, config{Replicas: intPtr(2)}, acc.Value())
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		acc := NewAccumulator(map[int]bool{})
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				assert.NoError(t, acc.Add(map[int]bool{i: true}))
+			}(i)
+		}
+		wg.Wait()
+		assert.Len(t, acc.Value(), 20)
+	})
+}
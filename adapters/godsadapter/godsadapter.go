@@ -0,0 +1,117 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package godsadapter provides goalesce container adapters for the maps, sets and lists of
+// github.com/emirpasic/gods, so that they can be deep-copied and deep-merged.
+package godsadapter
+
+import (
+	"reflect"
+
+	"github.com/adutra/goalesce"
+	"github.com/emirpasic/gods/lists"
+	"github.com/emirpasic/gods/maps"
+	"github.com/emirpasic/gods/sets"
+)
+
+// WithMap registers an adapter for the map type returned by the given constructor, e.g.:
+//
+//	godsadapter.WithMap(func() maps.Map { return treemap.NewWithStringComparator() })
+//
+// Maps are merged key by key.
+func WithMap(newMap func() maps.Map) goalesce.Option {
+	return goalesce.WithContainerAdapter(reflect.TypeOf(newMap()), &mapAdapter{newMap: newMap})
+}
+
+// WithSet registers an adapter for the set type returned by the given constructor, e.g.:
+//
+//	godsadapter.WithSet(func() sets.Set { return hashset.New() })
+//
+// Sets are merged with set-union semantics.
+func WithSet(newSet func() sets.Set) goalesce.Option {
+	return goalesce.WithContainerAdapter(reflect.TypeOf(newSet()), &setAdapter{newSet: newSet})
+}
+
+// WithList registers an adapter for the list type returned by the given constructor, e.g.:
+//
+//	godsadapter.WithList(func() lists.List { return arraylist.New() })
+//
+// Lists are merged index by index.
+func WithList(newList func() lists.List) goalesce.Option {
+	return goalesce.WithContainerAdapter(reflect.TypeOf(newList()), &listAdapter{newList: newList})
+}
+
+type mapAdapter struct {
+	newMap func() maps.Map
+}
+
+func (a *mapAdapter) Entries(container interface{}) ([]goalesce.ContainerEntry, error) {
+	m := container.(maps.Map)
+	entries := make([]goalesce.ContainerEntry, 0, m.Size())
+	for _, k := range m.Keys() {
+		v, _ := m.Get(k)
+		entries = append(entries, goalesce.ContainerEntry{Key: k, Value: v})
+	}
+	return entries, nil
+}
+
+func (a *mapAdapter) New(entries []goalesce.ContainerEntry) (interface{}, error) {
+	m := a.newMap()
+	for _, entry := range entries {
+		m.Put(entry.Key, entry.Value)
+	}
+	return m, nil
+}
+
+type setAdapter struct {
+	newSet func() sets.Set
+}
+
+func (a *setAdapter) Entries(container interface{}) ([]goalesce.ContainerEntry, error) {
+	values := container.(sets.Set).Values()
+	entries := make([]goalesce.ContainerEntry, len(values))
+	for i, v := range values {
+		entries[i] = goalesce.ContainerEntry{Key: v, Value: v}
+	}
+	return entries, nil
+}
+
+func (a *setAdapter) New(entries []goalesce.ContainerEntry) (interface{}, error) {
+	s := a.newSet()
+	for _, entry := range entries {
+		s.Add(entry.Value)
+	}
+	return s, nil
+}
+
+type listAdapter struct {
+	newList func() lists.List
+}
+
+func (a *listAdapter) Entries(container interface{}) ([]goalesce.ContainerEntry, error) {
+	values := container.(lists.List).Values()
+	entries := make([]goalesce.ContainerEntry, len(values))
+	for i, v := range values {
+		entries[i] = goalesce.ContainerEntry{Key: i, Value: v}
+	}
+	return entries, nil
+}
+
+func (a *listAdapter) New(entries []goalesce.ContainerEntry) (interface{}, error) {
+	l := a.newList()
+	for _, entry := range entries {
+		l.Add(entry.Value)
+	}
+	return l, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package godsadapter
+
+import (
+	"testing"
+
+	"github.com/adutra/goalesce"
+	"github.com/emirpasic/gods/lists"
+	"github.com/emirpasic/gods/lists/arraylist"
+	"github.com/emirpasic/gods/maps"
+	"github.com/emirpasic/gods/maps/linkedhashmap"
+	"github.com/emirpasic/gods/maps/treemap"
+	"github.com/emirpasic/gods/sets"
+	"github.com/emirpasic/gods/sets/hashset"
+	"github.com/emirpasic/gods/sets/treeset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestWithMap(t *testing.T) {
+	opt := WithMap(func() maps.Map { return linkedhashmap.New() })
+	m1 := linkedhashmap.New()
+	m1.Put("b", &user{Name: "Bob"})
+	m1.Put("a", &user{Name: "Alice"})
+	m2 := linkedhashmap.New()
+	m2.Put("c", &user{Name: "Carol"})
+	m2.Put("a", &user{Age: 30})
+	t.Run("copy", func(t *testing.T) {
+		copied, err := goalesce.DeepCopy(m1, opt)
+		require.NoError(t, err)
+		assert.Equal(t, m1.Keys(), copied.Keys())
+		assert.Equal(t, m1.Values(), copied.Values())
+		original, _ := m1.Get("a")
+		value, _ := copied.Get("a")
+		assert.NotSame(t, original, value)
+	})
+	t.Run("merge", func(t *testing.T) {
+		merged, err := goalesce.DeepMerge(m1, m2, opt)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"b", "a", "c"}, merged.Keys())
+		assert.Equal(t, []interface{}{&user{Name: "Bob"}, &user{Name: "Alice", Age: 30}, &user{Name: "Carol"}}, merged.Values())
+	})
+	t.Run("tree map", func(t *testing.T) {
+		m := treemap.NewWithStringComparator()
+		m.Put("b", 2)
+		m.Put("a", 1)
+		copied, err := goalesce.DeepCopy(m, WithMap(func() maps.Map { return treemap.NewWithStringComparator() }))
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"a", "b"}, copied.Keys())
+		assert.NotSame(t, m, copied)
+	})
+}
+
+func TestWithSet(t *testing.T) {
+	t.Run("hash set", func(t *testing.T) {
+		merged, err := goalesce.DeepMerge(hashset.New(1, 2), hashset.New(2, 3), WithSet(func() sets.Set { return hashset.New() }))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []interface{}{1, 2, 3}, merged.Values())
+	})
+	t.Run("tree set", func(t *testing.T) {
+		merged, err := goalesce.DeepMerge(treeset.NewWithStringComparator("c", "a"), treeset.NewWithStringComparator("b"), WithSet(func() sets.Set { return treeset.NewWithStringComparator() }))
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"a", "b", "c"}, merged.Values())
+	})
+}
+
+func TestWithList(t *testing.T) {
+	opt := WithList(func() lists.List { return arraylist.New() })
+	l1 := arraylist.New(&user{Name: "Alice"}, &user{Name: "Bob"})
+	l2 := arraylist.New(&user{Age: 30})
+	t.Run("copy", func(t *testing.T) {
+		copied, err := goalesce.DeepCopy(l1, opt)
+		require.NoError(t, err)
+		assert.Equal(t, l1.Values(), copied.Values())
+		original, _ := l1.Get(0)
+		value, _ := copied.Get(0)
+		assert.NotSame(t, original, value)
+	})
+	t.Run("merge", func(t *testing.T) {
+		merged, err := goalesce.DeepMerge(l1, l2, opt)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{&user{Name: "Alice", Age: 30}, &user{Name: "Bob"}}, merged.Values())
+	})
+}
@@ -0,0 +1,18 @@
+module github.com/adutra/goalesce/adapters/immutableadapter
+
+go 1.22
+
+require (
+	github.com/adutra/goalesce v0.0.0
+	github.com/benbjohnson/immutable v0.4.3
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/exp v0.0.0-20220609121020-a51bd0440498 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/adutra/goalesce => ../..
@@ -0,0 +1,145 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package immutableadapter provides goalesce container adapters for the immutable collections of
+// github.com/benbjohnson/immutable, so that they can be deep-copied and deep-merged.
+package immutableadapter
+
+import (
+	"reflect"
+
+	"github.com/adutra/goalesce"
+	"github.com/benbjohnson/immutable"
+)
+
+// WithList registers an adapter for *immutable.List[T]. Lists are merged index by index.
+func WithList[T any]() goalesce.Option {
+	return goalesce.WithContainerAdapter(reflect.TypeOf((*immutable.List[T])(nil)), listAdapter[T]{})
+}
+
+// WithMap registers an adapter for *immutable.Map[K, V]. The given hasher is used to build new
+// maps; it can be nil for the key types supported by immutable.NewHasher. Maps are merged key by
+// key. Keys must be comparable.
+func WithMap[K, V any](hasher immutable.Hasher[K]) goalesce.Option {
+	return goalesce.WithContainerAdapter(reflect.TypeOf((*immutable.Map[K, V])(nil)), mapAdapter[K, V]{hasher: hasher})
+}
+
+// WithSortedMap registers an adapter for *immutable.SortedMap[K, V]. The given comparer is used to
+// build new maps; it can be nil for the key types supported by immutable.NewComparer. Maps are
+// merged key by key. Keys must be comparable.
+func WithSortedMap[K, V any](comparer immutable.Comparer[K]) goalesce.Option {
+	return goalesce.WithContainerAdapter(reflect.TypeOf((*immutable.SortedMap[K, V])(nil)), sortedMapAdapter[K, V]{comparer: comparer})
+}
+
+// WithSet registers an adapter for immutable.Set[T]. The given hasher is used to build new sets;
+// it can be nil for the element types supported by immutable.NewHasher. Sets are merged with
+// set-union semantics. Elements must be comparable.
+func WithSet[T any](hasher immutable.Hasher[T]) goalesce.Option {
+	return goalesce.WithContainerAdapter(reflect.TypeOf(immutable.Set[T]{}), setAdapter[T]{hasher: hasher})
+}
+
+type listAdapter[T any] struct{}
+
+func (listAdapter[T]) Entries(container interface{}) ([]goalesce.ContainerEntry, error) {
+	l := container.(*immutable.List[T])
+	entries := make([]goalesce.ContainerEntry, 0, l.Len())
+	for itr := l.Iterator(); !itr.Done(); {
+		i, v := itr.Next()
+		entries = append(entries, goalesce.ContainerEntry{Key: i, Value: v})
+	}
+	return entries, nil
+}
+
+func (listAdapter[T]) New(entries []goalesce.ContainerEntry) (interface{}, error) {
+	b := immutable.NewListBuilder[T]()
+	for _, entry := range entries {
+		b.Append(valueOf[T](entry.Value))
+	}
+	return b.List(), nil
+}
+
+type mapAdapter[K, V any] struct {
+	hasher immutable.Hasher[K]
+}
+
+func (a mapAdapter[K, V]) Entries(container interface{}) ([]goalesce.ContainerEntry, error) {
+	m := container.(*immutable.Map[K, V])
+	entries := make([]goalesce.ContainerEntry, 0, m.Len())
+	for itr := m.Iterator(); !itr.Done(); {
+		k, v, _ := itr.Next()
+		entries = append(entries, goalesce.ContainerEntry{Key: k, Value: v})
+	}
+	return entries, nil
+}
+
+func (a mapAdapter[K, V]) New(entries []goalesce.ContainerEntry) (interface{}, error) {
+	b := immutable.NewMapBuilder[K, V](a.hasher)
+	for _, entry := range entries {
+		b.Set(valueOf[K](entry.Key), valueOf[V](entry.Value))
+	}
+	return b.Map(), nil
+}
+
+type sortedMapAdapter[K, V any] struct {
+	comparer immutable.Comparer[K]
+}
+
+func (a sortedMapAdapter[K, V]) Entries(container interface{}) ([]goalesce.ContainerEntry, error) {
+	m := container.(*immutable.SortedMap[K, V])
+	entries := make([]goalesce.ContainerEntry, 0, m.Len())
+	for itr := m.Iterator(); !itr.Done(); {
+		k, v, _ := itr.Next()
+		entries = append(entries, goalesce.ContainerEntry{Key: k, Value: v})
+	}
+	return entries, nil
+}
+
+func (a sortedMapAdapter[K, V]) New(entries []goalesce.ContainerEntry) (interface{}, error) {
+	b := immutable.NewSortedMapBuilder[K, V](a.comparer)
+	for _, entry := range entries {
+		b.Set(valueOf[K](entry.Key), valueOf[V](entry.Value))
+	}
+	return b.Map(), nil
+}
+
+type setAdapter[T any] struct {
+	hasher immutable.Hasher[T]
+}
+
+func (a setAdapter[T]) Entries(container interface{}) ([]goalesce.ContainerEntry, error) {
+	items := container.(immutable.Set[T]).Items()
+	entries := make([]goalesce.ContainerEntry, len(items))
+	for i, item := range items {
+		entries[i] = goalesce.ContainerEntry{Key: item, Value: item}
+	}
+	return entries, nil
+}
+
+func (a setAdapter[T]) New(entries []goalesce.ContainerEntry) (interface{}, error) {
+	values := make([]T, len(entries))
+	for i, entry := range entries {
+		values[i] = valueOf[T](entry.Value)
+	}
+	return immutable.NewSet[T](a.hasher, values...), nil
+}
+
+// valueOf converts the given entry value back to T; nil is converted to the zero-value of T, which
+// is required when T is an interface type.
+func valueOf[T any](i interface{}) T {
+	if i == nil {
+		var zero T
+		return zero
+	}
+	return i.(T)
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package immutableadapter
+
+import (
+	"testing"
+
+	"github.com/adutra/goalesce"
+	"github.com/benbjohnson/immutable"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestWithList(t *testing.T) {
+	l1 := immutable.NewList(&user{Name: "Alice"}, &user{Name: "Bob"})
+	l2 := immutable.NewList(&user{Age: 30})
+	t.Run("copy", func(t *testing.T) {
+		copied, err := goalesce.DeepCopy(l1, WithList[*user]())
+		require.NoError(t, err)
+		require.Equal(t, 2, copied.Len())
+		assert.Equal(t, &user{Name: "Alice"}, copied.Get(0))
+		assert.NotSame(t, l1.Get(0), copied.Get(0))
+	})
+	t.Run("merge", func(t *testing.T) {
+		merged, err := goalesce.DeepMerge(l1, l2, WithList[*user]())
+		require.NoError(t, err)
+		require.Equal(t, 2, merged.Len())
+		assert.Equal(t, &user{Name: "Alice", Age: 30}, merged.Get(0))
+		assert.Equal(t, &user{Name: "Bob"}, merged.Get(1))
+	})
+}
+
+func TestWithMap(t *testing.T) {
+	m1 := immutable.NewMapOf[string, *user](nil, map[string]*user{"a": {Name: "Alice"}, "b": {Name: "Bob"}})
+	m2 := immutable.NewMapOf[string, *user](nil, map[string]*user{"a": {Age: 30}, "c": {Name: "Carol"}})
+	t.Run("copy", func(t *testing.T) {
+		copied, err := goalesce.DeepCopy(m1, WithMap[string, *user](nil))
+		require.NoError(t, err)
+		assert.Equal(t, 2, copied.Len())
+		original, _ := m1.Get("a")
+		value, _ := copied.Get("a")
+		assert.Equal(t, original, value)
+		assert.NotSame(t, original, value)
+	})
+	t.Run("merge", func(t *testing.T) {
+		merged, err := goalesce.DeepMerge(m1, m2, WithMap[string, *user](nil))
+		require.NoError(t, err)
+		assert.Equal(t, 3, merged.Len())
+		value, _ := merged.Get("a")
+		assert.Equal(t, &user{Name: "Alice", Age: 30}, value)
+	})
+}
+
+func TestWithSortedMap(t *testing.T) {
+	m1 := immutable.NewSortedMap[string, int](nil).Set("b", 2).Set("a", 1)
+	m2 := immutable.NewSortedMap[string, int](nil).Set("c", 3).Set("a", 10)
+	merged, err := goalesce.DeepMerge(m1, m2, WithSortedMap[string, int](nil))
+	require.NoError(t, err)
+	var keys []string
+	var values []int
+	for itr := merged.Iterator(); !itr.Done(); {
+		k, v, _ := itr.Next()
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+	assert.Equal(t, []int{10, 2, 3}, values)
+}
+
+func TestWithSet(t *testing.T) {
+	s1 := immutable.NewSet[string](nil, "a", "b")
+	s2 := immutable.NewSet[string](nil, "b", "c")
+	merged, err := goalesce.DeepMerge(s1, s2, WithSet[string](nil))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, merged.Items())
+}
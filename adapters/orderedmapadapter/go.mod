@@ -0,0 +1,17 @@
+module github.com/adutra/goalesce/adapters/orderedmapadapter
+
+go 1.22
+
+require (
+	github.com/adutra/goalesce v0.0.0
+	github.com/elliotchance/orderedmap/v2 v2.7.0
+	github.com/stretchr/testify v1.9.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/adutra/goalesce => ../..
@@ -0,0 +1,60 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package orderedmapadapter provides a goalesce container adapter for the ordered maps of
+// github.com/elliotchance/orderedmap/v2, so that they can be deep-copied and deep-merged.
+package orderedmapadapter
+
+import (
+	"reflect"
+
+	"github.com/adutra/goalesce"
+	"github.com/elliotchance/orderedmap/v2"
+)
+
+// WithOrderedMap registers an adapter for *orderedmap.OrderedMap[K, V]. Ordered maps are merged
+// key by key; the keys of the first map come first, in their original order, followed by the keys
+// that only exist in the second map.
+func WithOrderedMap[K comparable, V any]() goalesce.Option {
+	return goalesce.WithContainerAdapter(reflect.TypeOf((*orderedmap.OrderedMap[K, V])(nil)), adapter[K, V]{})
+}
+
+type adapter[K comparable, V any] struct{}
+
+func (adapter[K, V]) Entries(container interface{}) ([]goalesce.ContainerEntry, error) {
+	m := container.(*orderedmap.OrderedMap[K, V])
+	entries := make([]goalesce.ContainerEntry, 0, m.Len())
+	for el := m.Front(); el != nil; el = el.Next() {
+		entries = append(entries, goalesce.ContainerEntry{Key: el.Key, Value: el.Value})
+	}
+	return entries, nil
+}
+
+func (adapter[K, V]) New(entries []goalesce.ContainerEntry) (interface{}, error) {
+	m := orderedmap.NewOrderedMapWithCapacity[K, V](len(entries))
+	for _, entry := range entries {
+		m.Set(entry.Key.(K), valueOf[V](entry.Value))
+	}
+	return m, nil
+}
+
+// valueOf converts the given entry value back to V; nil is converted to the zero-value of V, which
+// is required when V is an interface type.
+func valueOf[V any](i interface{}) V {
+	if i == nil {
+		var zero V
+		return zero
+	}
+	return i.(V)
+}
@@ -0,0 +1,60 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orderedmapadapter
+
+import (
+	"testing"
+
+	"github.com/adutra/goalesce"
+	"github.com/elliotchance/orderedmap/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	Name string
+	Age  int
+}
+
+func TestWithOrderedMap(t *testing.T) {
+	m1 := orderedmap.NewOrderedMap[string, *user]()
+	m1.Set("b", &user{Name: "Bob"})
+	m1.Set("a", &user{Name: "Alice"})
+	m2 := orderedmap.NewOrderedMap[string, *user]()
+	m2.Set("c", &user{Name: "Carol"})
+	m2.Set("a", &user{Age: 30})
+	t.Run("copy", func(t *testing.T) {
+		copied, err := goalesce.DeepCopy(m1, WithOrderedMap[string, *user]())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "a"}, copied.Keys())
+		assert.Equal(t, &user{Name: "Alice"}, copied.GetOrDefault("a", nil))
+		assert.NotSame(t, m1.GetOrDefault("a", nil), copied.GetOrDefault("a", nil))
+	})
+	t.Run("merge", func(t *testing.T) {
+		merged, err := goalesce.DeepMerge(m1, m2, WithOrderedMap[string, *user]())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "a", "c"}, merged.Keys())
+		assert.Equal(t, &user{Name: "Alice", Age: 30}, merged.GetOrDefault("a", nil))
+	})
+	t.Run("interface values", func(t *testing.T) {
+		m := orderedmap.NewOrderedMap[string, interface{}]()
+		m.Set("a", nil)
+		m.Set("b", 1)
+		copied, err := goalesce.DeepCopy(m, WithOrderedMap[string, interface{}]())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, copied.Keys())
+		assert.Nil(t, copied.GetOrDefault("a", 42))
+	})
+}
@@ -20,15 +20,21 @@ import "reflect"
 // registered for the array type. If there is, it uses it. Otherwise, it uses the default array
 // merge strategy, which is atomic.
 func (c *coalescer) deepMergeArray(v1, v2 reflect.Value) (reflect.Value, error) {
-	if value, done := checkZero(v1, v2); done {
+	if value, done := checkZero(v1, v2); done && !c.mustCheckPermissions(v2) {
+		c.traceZeroShortcut(v1)
 		return c.deepCopy(value)
 	}
 	if arrayMerger, found := c.arrayMergers[v1.Type()]; found {
+		c.trace("using array merger for %s", v1.Type().String())
 		return arrayMerger(v1, v2)
 	}
 	if c.arrayMerger != nil {
+		c.trace("using default array merger")
 		return c.arrayMerger(v1, v2)
 	}
+	if c.mustCheckPermissions(v2) && mayHoldStructs(v1.Type().Elem()) {
+		return c.deepMergeCheckedElements(v1, v2)
+	}
 	return c.deepMergeAtomic(v1, v2)
 }
 
@@ -40,8 +46,11 @@ func (c *coalescer) deepMergeArrayByIndex(v1, v2 reflect.Value) (reflect.Value,
 		return c.deepCopy(value)
 	}
 	merged := reflect.New(v1.Type())
+	parent := c.path
+	defer func() { c.path = parent }()
 	for i := 0; i < v1.Len(); i++ {
-		elem, err := c.deepMerge(v1.Index(i), v2.Index(i))
+		c.path = indexPath(parent, i)
+		elem, err := c.deepMergeAt(v1.Index(i), v2.Index(i))
 		if err != nil {
 			return reflect.Value{}, err
 		}
@@ -21,14 +21,30 @@ import "reflect"
 // general contract of DeepMergeFunc, it returns a deep copy of the first value if the second value
 // is the zero-value; otherwise, it returns a deep copy of the second value. By default, this
 // function is used to "merge" all immutable value types (int, string, etc.), and also to merge
-// slices and arrays.
+// slices and arrays. With WithFirstValuePriority, the first value is returned if it is non-zero
+// instead.
 func (c *coalescer) deepMergeAtomic(v1, v2 reflect.Value) (reflect.Value, error) {
-	if v2.IsZero() {
+	if v2.IsZero() || c.firstValuePriority && !v1.IsZero() {
+		c.trace("atomic merge, keeping first value")
 		return c.deepCopy(v1)
 	}
+	c.trace("atomic merge, using second value")
+	if err := c.reportConflict(v1, v2); err != nil {
+		return reflect.Value{}, err
+	}
 	return c.deepCopy(v2)
 }
 
+// deepMergeKeepFirst merges two values with "keep-first" semantics: it is the mirror of
+// deepMergeAtomic, and returns a deep copy of the second value if the first value is the
+// zero-value; otherwise, it returns a deep copy of the first value.
+func (c *coalescer) deepMergeKeepFirst(v1, v2 reflect.Value) (reflect.Value, error) {
+	if v1.IsZero() {
+		return c.deepCopy(v2)
+	}
+	return c.deepCopy(v1)
+}
+
 // deepCopyAtomic copies the value with atomic semantics, that is, it assumes the value is immutable
 // and indivisible, and that the value is a copy of itself. Therefore, it simply returns the value
 // as is. By default, this function is used to "copy" all immutable value types (int, string, etc.).
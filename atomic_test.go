@@ -225,6 +225,20 @@ func Test_coalescer_deepMergeAtomic(t *testing.T) {
 			v2:   &foo{},
 			want: &foo{},
 		},
+		{
+			name: "int none zero first value priority",
+			v1:   1,
+			v2:   2,
+			want: 1,
+			opts: []Option{WithFirstValuePriority()},
+		},
+		{
+			name: "int v1 zero first value priority",
+			v1:   0,
+			v2:   2,
+			want: 2,
+			opts: []Option{WithFirstValuePriority()},
+		},
 		{
 			name:    "generic error",
 			v1:      123,
@@ -253,6 +267,32 @@ func Test_coalescer_deepMergeAtomic(t *testing.T) {
 	}
 }
 
+func Test_coalescer_deepMergeKeepFirst(t *testing.T) {
+	tests := []struct {
+		name string
+		v1   interface{}
+		v2   interface{}
+		want interface{}
+	}{
+		{"int both zero", 0, 0, 0},
+		{"int v1 zero", 0, 1, 1},
+		{"int v2 zero", 1, 0, 1},
+		{"int none zero", 1, 2, 1},
+		{"*int v1 zero", (*int)(nil), intPtr(0), intPtr(0)},
+		{"*int none zero", intPtr(1), intPtr(0), intPtr(1)},
+		{"slice none zero", []int{1}, []int{2}, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newCoalescer().deepMergeKeepFirst(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Interface())
+			assertNotSame(t, tt.v1, got.Interface())
+			assertNotSame(t, tt.v2, got.Interface())
+		})
+	}
+}
+
 func Test_coalescer_deepCopyAtomic(t *testing.T) {
 	t.Run("nil", func(t *testing.T) {
 		input := (*int)(nil)
@@ -0,0 +1,59 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// deepMergeBitwiseOr merges 2 integers, or pointers thereto, by combining them with a bitwise OR.
+// Nil pointers are ignored.
+func (c *coalescer) deepMergeBitwiseOr(v1, v2 reflect.Value) (reflect.Value, error) {
+	if !isInteger(indirect(v1.Type())) {
+		return reflect.Value{}, fmt.Errorf("expecting integer or pointer thereto, got: %s", v1.Type().String())
+	}
+	if value, done := checkZero(v1, v2); done {
+		return c.deepCopy(value)
+	}
+	e1, e2 := reflect.Indirect(v1), reflect.Indirect(v2)
+	merged := reflect.New(e1.Type())
+	switch e1.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		merged.Elem().SetInt(e1.Int() | e2.Int())
+	default:
+		merged.Elem().SetUint(e1.Uint() | e2.Uint())
+	}
+	if v1.Kind() == reflect.Ptr {
+		return merged, nil
+	}
+	return merged.Elem(), nil
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func (c *coalescer) bitwiseOrFieldMerger(field reflect.StructField) (DeepMergeFunc, error) {
+	if !isInteger(indirect(field.Type)) {
+		return nil, fmt.Errorf("%s strategy is only supported for integers and pointers thereto", MergeStrategyBitwiseOr)
+	}
+	return c.deepMergeBitwiseOr, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_deepMergeBitwiseOr(t *testing.T) {
+	type permissions uint8
+	type role struct {
+		Permissions permissions `goalesce:"bitor"`
+		Flags       int         `goalesce:"bitor"`
+		Mask        *uint32     `goalesce:"bitor"`
+	}
+	uint32Ptr := func(u uint32) *uint32 { return &u }
+	tests := []struct {
+		name string
+		v1   role
+		v2   role
+		want role
+	}{
+		{
+			name: "zero",
+			v1:   role{},
+			v2:   role{},
+			want: role{},
+		},
+		{
+			name: "v1 only",
+			v1:   role{Permissions: 0b01, Flags: 0b01, Mask: uint32Ptr(0b01)},
+			v2:   role{},
+			want: role{Permissions: 0b01, Flags: 0b01, Mask: uint32Ptr(0b01)},
+		},
+		{
+			name: "v2 only",
+			v1:   role{},
+			v2:   role{Permissions: 0b10, Flags: 0b10, Mask: uint32Ptr(0b10)},
+			want: role{Permissions: 0b10, Flags: 0b10, Mask: uint32Ptr(0b10)},
+		},
+		{
+			name: "both",
+			v1:   role{Permissions: 0b011, Flags: 0b011, Mask: uint32Ptr(0b011)},
+			v2:   role{Permissions: 0b110, Flags: -8, Mask: uint32Ptr(0b110)},
+			want: role{Permissions: 0b111, Flags: -5, Mask: uint32Ptr(0b111)},
+		},
+		{
+			name: "pointer to zero",
+			v1:   role{Mask: uint32Ptr(0b01)},
+			v2:   role{Mask: uint32Ptr(0)},
+			want: role{Mask: uint32Ptr(0b01)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			if tt.v1.Mask != nil {
+				assert.NotSame(t, tt.v1.Mask, got.Mask)
+			}
+			if tt.v2.Mask != nil {
+				assert.NotSame(t, tt.v2.Mask, got.Mask)
+			}
+		})
+	}
+	t.Run("wrong type", func(t *testing.T) {
+		c := newCoalescer()
+		_, err := c.deepMergeBitwiseOr(reflect.ValueOf("a"), reflect.ValueOf("b"))
+		assert.EqualError(t, err, "expecting integer or pointer thereto, got: string")
+	})
+}
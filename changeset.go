@@ -0,0 +1,170 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// DeepChangeSet computes the set of columns that differ between the 2 struct values and returns
+// them as a map of column names to new values, ready to be fed into an UPDATE statement builder.
+//
+// The values must be structs, or pointers thereto; a nil pointer is treated as the zero-value of
+// the struct. Each exported field is considered a column. Column names are resolved from the
+// following struct tags, in this order: `db:"name"` (sqlx and the like), `bun:"name"` and
+// `gorm:"column:name"`. If no name is declared, the default naming convention of the tag family is
+// followed: lower case for db tags, as with sqlx, e.g. "updatedat", and snake case for bun and gorm
+// tags, e.g. "updated_at". The tag family is determined by the tags of the field itself, or else by
+// the tags of the other fields of its struct; if no field has any of these tags, snake case is used.
+// Fields tagged with "-" in any of these tags are ignored, and so are bun's `bun:"table:..."` model
+// fields. Embedded structs are flattened, i.e. their fields are treated as columns of the enclosing
+// struct, unless they carry a column name.
+//
+// Columns are compared with the same semantics as DeepDiff. The values in the returned map are
+// deep copies of the columns of the second value. The returned map is never nil.
+func DeepChangeSet[T any](o1, o2 T, opts ...Option) (map[string]interface{}, error) {
+	v1 := reflect.ValueOf(o1)
+	v2 := reflect.ValueOf(o2)
+	if !v1.IsValid() || !v2.IsValid() {
+		return nil, fmt.Errorf("expecting struct or pointer thereto, got: nil")
+	}
+	if err := checkTypesMatch(v1.Type(), v2.Type()); err != nil {
+		return nil, err
+	}
+	if indirect(v1.Type()).Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expecting struct or pointer thereto, got: %s", v1.Type().String())
+	}
+	d := &differ{coalescer: newCoalescer(opts...), seen: make(map[[2]uintptr]bool)}
+	changes := make(map[string]interface{})
+	if err := d.changeSet(safeIndirect(v1), safeIndirect(v2), changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (c *differ) changeSet(v1, v2 reflect.Value, changes map[string]interface{}) error {
+	for i := 0; i < v1.NumField(); i++ {
+		field := v1.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		column, skip := columnName(field)
+		if skip {
+			continue
+		}
+		if column == "" {
+			if fieldType := indirect(field.Type); field.Anonymous && fieldType.Kind() == reflect.Struct && hasExportedFields(fieldType) {
+				if err := c.changeSet(safeIndirect(v1.Field(i)), safeIndirect(v2.Field(i)), changes); err != nil {
+					return err
+				}
+				continue
+			}
+			column = defaultColumnName(v1.Type(), field)
+		}
+		var diff Diff
+		if err := c.deepDiff(field.Name, v1.Field(i), v2.Field(i), &diff); err != nil {
+			return err
+		}
+		if len(diff) > 0 {
+			copied, err := c.deepCopy(v2.Field(i))
+			if err != nil {
+				return err
+			}
+			changes[column] = copied.Interface()
+		}
+	}
+	return nil
+}
+
+// columnTagFamilies are the struct tags declaring column names, in order of precedence.
+var columnTagFamilies = []string{"db", "bun", "gorm"}
+
+// defaultColumnName returns the column name of the given field of the given struct type, when
+// none is declared in its tags, following the naming convention of its tag family.
+func defaultColumnName(structType reflect.Type, field reflect.StructField) string {
+	family := columnTagFamily(field)
+	for i := 0; family == "" && i < structType.NumField(); i++ {
+		family = columnTagFamily(structType.Field(i))
+	}
+	if family == "db" {
+		return strings.ToLower(field.Name)
+	}
+	return snakeCase(field.Name)
+}
+
+// columnTagFamily returns the first tag family of the given field, or an empty string if none.
+func columnTagFamily(field reflect.StructField) string {
+	for _, family := range columnTagFamilies {
+		if _, found := field.Tag.Lookup(family); found {
+			return family
+		}
+	}
+	return ""
+}
+
+// snakeCase converts the given field name to snake case, keeping initialisms together, e.g.
+// "UpdatedAt" to "updated_at" and "UserID" to "user_id", as bun and gorm do.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+// columnName returns the column name declared for the given field in its db, bun or gorm struct
+// tags. It returns an empty name if no name is declared, and true if the field must be skipped.
+func columnName(field reflect.StructField) (string, bool) {
+	if tag, found := field.Tag.Lookup("db"); found {
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	if tag, found := field.Tag.Lookup("bun"); found {
+		name := strings.Split(tag, ",")[0]
+		if name == "-" || strings.HasPrefix(name, "table:") {
+			return "", true
+		}
+		if name != "" && !strings.Contains(name, ":") {
+			return name, false
+		}
+	}
+	if tag, found := field.Tag.Lookup("gorm"); found {
+		for _, setting := range strings.Split(tag, ";") {
+			setting = strings.TrimSpace(setting)
+			if setting == "-" || strings.HasPrefix(setting, "-:") {
+				return "", true
+			}
+			if strings.HasPrefix(strings.ToLower(setting), "column:") {
+				return setting[len("column:"):], false
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,222 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeepChangeSet(t *testing.T) {
+	type Timestamps struct {
+		CreatedAt int `db:"created_at"`
+		UpdatedAt int `db:"updated_at"`
+	}
+	type BaseModel struct{}
+	type user struct {
+		BaseModel `bun:"table:users"`
+		Timestamps
+		ID         int               `db:"id"`
+		Name       string            `bun:"user_name,notnull"`
+		Email      string            `gorm:"type:varchar(100);column:email_address"`
+		Age        int               `db:",omitempty"`
+		Labels     map[string]string `db:"labels"`
+		Cache      string            `db:"-"`
+		Secret     string            `bun:"-"`
+		Ignored    string            `gorm:"-:all"`
+		unexported int
+	}
+	tests := []struct {
+		name    string
+		v1      interface{}
+		v2      interface{}
+		want    map[string]interface{}
+		wantErr string
+	}{
+		{
+			name: "no changes",
+			v1:   user{ID: 1, Name: "Alice"},
+			v2:   user{ID: 1, Name: "Alice"},
+			want: map[string]interface{}{},
+		},
+		{
+			name: "tagged columns",
+			v1:   user{ID: 1, Name: "Alice", Email: "alice@example.com"},
+			v2:   user{ID: 2, Name: "Bob", Email: "bob@example.com", Age: 20},
+			want: map[string]interface{}{
+				"id":            2,
+				"user_name":     "Bob",
+				"email_address": "bob@example.com",
+				"age":           20,
+			},
+		},
+		{
+			name: "embedded",
+			v1:   user{Timestamps: Timestamps{CreatedAt: 1, UpdatedAt: 1}},
+			v2:   user{Timestamps: Timestamps{CreatedAt: 1, UpdatedAt: 2}},
+			want: map[string]interface{}{"updated_at": 2},
+		},
+		{
+			name: "nested values",
+			v1:   user{Labels: map[string]string{"a": "1", "b": "2"}},
+			v2:   user{Labels: map[string]string{"a": "1", "b": "3"}},
+			want: map[string]interface{}{"labels": map[string]string{"a": "1", "b": "3"}},
+		},
+		{
+			name: "skipped",
+			v1:   user{Cache: "a", Secret: "a", Ignored: "a", unexported: 1},
+			v2:   user{Cache: "b", Secret: "b", Ignored: "b", unexported: 2},
+			want: map[string]interface{}{},
+		},
+		{
+			name: "pointers",
+			v1:   (*user)(nil),
+			v2:   &user{ID: 1},
+			want: map[string]interface{}{"id": 1},
+		},
+		{
+			name:    "nil",
+			v1:      nil,
+			v2:      nil,
+			wantErr: "expecting struct or pointer thereto, got: nil",
+		},
+		{
+			name:    "not a struct",
+			v1:      1,
+			v2:      2,
+			wantErr: "expecting struct or pointer thereto, got: int",
+		},
+		{
+			name:    "types mismatch",
+			v1:      1,
+			v2:      "a",
+			wantErr: "types do not match: int != string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepChangeSet(tt.v1, tt.v2)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+	t.Run("time columns", func(t *testing.T) {
+		type record struct {
+			ID        int `gorm:"primaryKey"`
+			UpdatedAt time.Time
+			DeletedAt *time.Time
+		}
+		t1, t2 := time.Unix(1, 0), time.Unix(2, 0)
+		got, err := DeepChangeSet(record{1, t1, &t1}, record{1, t2, &t2})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"updated_at": t2, "deleted_at": &t2}, got)
+	})
+	t.Run("untagged struct", func(t *testing.T) {
+		type record struct {
+			UserID   int
+			FullName string
+		}
+		got, err := DeepChangeSet(record{1, "a"}, record{2, "b"})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"user_id": 2, "full_name": "b"}, got)
+	})
+	t.Run("copy error", func(t *testing.T) {
+		_, err := DeepChangeSet(user{ID: 1}, user{ID: 2}, withMockDeepCopyError)
+		assert.EqualError(t, err, "mock DeepCopy error")
+	})
+}
+
+func Test_columnName(t *testing.T) {
+	tests := []struct {
+		tag      reflect.StructTag
+		wantName string
+		wantSkip bool
+	}{
+		{``, "", false},
+		{`db:"id"`, "id", false},
+		{`db:"id,omitempty"`, "id", false},
+		{`db:"-"`, "", true},
+		{`db:""`, "", false},
+		{`bun:"id,pk"`, "id", false},
+		{`bun:",pk"`, "", false},
+		{`bun:"-"`, "", true},
+		{`bun:"table:users"`, "", true},
+		{`bun:"embed:prefix_"`, "", false},
+		{`gorm:"primaryKey;column:id"`, "id", false},
+		{`gorm:"primaryKey"`, "", false},
+		{`gorm:"-"`, "", true},
+		{`gorm:"-:migration"`, "", true},
+		{`db:"a" bun:"b" gorm:"column:c"`, "a", false},
+		{`bun:"b" gorm:"column:c"`, "b", false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.tag), func(t *testing.T) {
+			name, skip := columnName(reflect.StructField{Name: "Field", Tag: tt.tag})
+			assert.Equal(t, tt.wantName, name)
+			assert.Equal(t, tt.wantSkip, skip)
+		})
+	}
+}
+
+func Test_defaultColumnName(t *testing.T) {
+	type sqlxModel struct {
+		ID        int `db:"id"`
+		UpdatedAt int
+	}
+	type bunModel struct {
+		ID        int `bun:",pk"`
+		UpdatedAt int
+	}
+	type mixedModel struct {
+		UpdatedAt int `gorm:"not null"`
+		DeletedAt int `db:",omitempty"`
+	}
+	for _, tt := range []struct {
+		structType reflect.Type
+		field      string
+		want       string
+	}{
+		{reflect.TypeOf(sqlxModel{}), "UpdatedAt", "updatedat"},
+		{reflect.TypeOf(bunModel{}), "UpdatedAt", "updated_at"},
+		{reflect.TypeOf(bunModel{}), "ID", "id"},
+		{reflect.TypeOf(mixedModel{}), "UpdatedAt", "updated_at"},
+		{reflect.TypeOf(mixedModel{}), "DeletedAt", "deletedat"},
+	} {
+		field, _ := tt.structType.FieldByName(tt.field)
+		assert.Equal(t, tt.want, defaultColumnName(tt.structType, field), tt.structType.String()+"."+tt.field)
+	}
+}
+
+func Test_snakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"ID":         "id",
+		"Name":       "name",
+		"UpdatedAt":  "updated_at",
+		"UserID":     "user_id",
+		"HTTPServer": "http_server",
+		"Address2":   "address2",
+		"V2Name":     "v2_name",
+	} {
+		assert.Equal(t, want, snakeCase(name), name)
+	}
+}
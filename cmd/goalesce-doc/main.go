@@ -0,0 +1,236 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command goalesce-doc renders a reference of the merge strategies of the fields of some struct
+// types, as declared in their goalesce struct tags. It reads the Go source files of a single
+// package, without compiling them:
+//
+//	goalesce-doc [-format markdown|html] -types Type1,Type2 [dir]
+//
+// The struct types reachable from the given root types within the same package are documented
+// too. Since options cannot be taken into account, and since types declared in other packages
+// cannot be inspected, the strategy of some fields may be left blank; use
+// goalesce.DescribeMergeStrategies for a precise reference.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/adutra/goalesce"
+)
+
+func main() {
+	format := flag.String("format", goalesce.StrategyFormatMarkdown, "output format: markdown or html")
+	roots := flag.String("types", "", "comma-separated list of root struct types")
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	if *roots == "" {
+		fmt.Fprintln(os.Stderr, "goalesce-doc: -types is required")
+		os.Exit(2)
+	}
+	strategies, err := describePackage(dir, strings.Split(*roots, ","))
+	if err == nil {
+		err = goalesce.RenderMergeStrategies(os.Stdout, strategies, *format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goalesce-doc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// describePackage parses the package in the given directory and describes the fields of the given
+// root struct types, and of the struct types of the same package reachable from them.
+func describePackage(dir string, roots []string) ([]goalesce.FieldStrategy, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	var pkgName string
+	typeSpecs := make(map[string]*ast.TypeSpec)
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+					for _, spec := range gen.Specs {
+						typeSpec := spec.(*ast.TypeSpec)
+						typeSpecs[typeSpec.Name.Name] = typeSpec
+					}
+				}
+			}
+		}
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expecting exactly one package in %s, found %d", dir, len(pkgs))
+	}
+	d := &describer{pkgName: pkgName, typeSpecs: typeSpecs, visited: make(map[string]bool)}
+	for _, root := range roots {
+		root = strings.TrimSpace(root)
+		if _, ok := d.structType(root); !ok {
+			return nil, fmt.Errorf("struct type %s not found in %s", root, dir)
+		}
+		if err := d.visit(root); err != nil {
+			return nil, err
+		}
+	}
+	return d.strategies, nil
+}
+
+type describer struct {
+	pkgName    string
+	typeSpecs  map[string]*ast.TypeSpec
+	visited    map[string]bool
+	strategies []goalesce.FieldStrategy
+}
+
+func (d *describer) structType(name string) (*ast.StructType, bool) {
+	if spec, ok := d.typeSpecs[name]; ok {
+		structType, ok := spec.Type.(*ast.StructType)
+		return structType, ok
+	}
+	return nil, false
+}
+
+func (d *describer) visit(name string) error {
+	structType, ok := d.structType(name)
+	if !ok || d.visited[name] {
+		return nil
+	}
+	d.visited[name] = true
+	var nested []ast.Expr
+	for _, field := range structType.Fields.List {
+		names := field.Names
+		if len(names) == 0 {
+			// embedded field
+			names = []*ast.Ident{ast.NewIdent(embeddedName(field.Type))}
+		}
+		for _, fieldName := range names {
+			if !fieldName.IsExported() {
+				continue
+			}
+			strategy, err := d.describeField(name, fieldName.Name, field)
+			if err != nil {
+				return err
+			}
+			d.strategies = append(d.strategies, strategy)
+			nested = append(nested, field.Type)
+		}
+	}
+	for _, expr := range nested {
+		if err := d.visitExpr(expr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *describer) visitExpr(expr ast.Expr) error {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return d.visit(e.Name)
+	case *ast.StarExpr:
+		return d.visitExpr(e.X)
+	case *ast.ArrayType:
+		return d.visitExpr(e.Elt)
+	case *ast.MapType:
+		return d.visitExpr(e.Value)
+	}
+	return nil
+}
+
+func (d *describer) describeField(structName, fieldName string, field *ast.Field) (goalesce.FieldStrategy, error) {
+	strategy := goalesce.FieldStrategy{
+		Struct: d.pkgName + "." + structName,
+		Field:  fieldName,
+		Type:   types.ExprString(field.Type),
+	}
+	if field.Tag != nil {
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			return goalesce.FieldStrategy{}, fmt.Errorf("field %s.%s: invalid tag: %w", structName, fieldName, err)
+		}
+		strategy.Tag = reflect.StructTag(tag).Get(goalesce.MergeStrategyTag)
+	}
+	name, argument := goalesce.ParseMergeStrategyTag(strategy.Tag)
+	switch {
+	case strategy.Tag == "":
+		strategy.Strategy = d.exprStrategy(field.Type)
+	case name == goalesce.MergeStrategyDefault:
+		strategy.Strategy = d.exprStrategy(field.Type)
+		strategy.Default = argument
+	case name == goalesce.MergeStrategyKey:
+		strategy.Strategy = d.exprStrategy(field.Type)
+	case name == goalesce.MergeStrategyID:
+		strategy.Strategy = name
+		strategy.MergeKey = argument
+	default:
+		strategy.Strategy = name
+	}
+	return strategy, nil
+}
+
+// exprStrategy returns the default strategy of the given type expression, or an empty string if it
+// cannot be determined from the source.
+func (d *describer) exprStrategy(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.InterfaceType, *ast.StructType:
+		return goalesce.MergeStrategyDeep
+	case *ast.ArrayType:
+		return goalesce.MergeStrategyAtomic
+	case *ast.SelectorExpr:
+		if types.ExprString(e) == "time.Time" {
+			return goalesce.MergeStrategyAtomic
+		}
+	case *ast.Ident:
+		if spec, ok := d.typeSpecs[e.Name]; ok {
+			return d.exprStrategy(spec.Type)
+		} else if predeclared := types.Universe.Lookup(e.Name); predeclared != nil {
+			if types.IsInterface(predeclared.Type()) {
+				return goalesce.MergeStrategyDeep
+			}
+			return goalesce.MergeStrategyAtomic
+		}
+	}
+	return ""
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	}
+	return types.ExprString(expr)
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/adutra/goalesce"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const source = `package api
+
+import "time"
+
+type Port int
+
+type Deployment struct {
+	Name       string            ` + "`goalesce:\"immutable\"`" + `
+	Replicas   *int              ` + "`goalesce:\"default:1\"`" + `
+	Containers []Container       ` + "`goalesce:\"id:Name\"`" + `
+	Labels     map[string]string
+	Created    time.Time         ` + "`goalesce:\"earliest\"`" + `
+	Any        interface{}
+	Err        error
+	internal   string
+	Metadata
+}
+
+type Container struct {
+	Name  string
+	Ports []Port ` + "`goalesce:\"union\"`" + `
+	Port  Port
+}
+
+type Metadata struct {
+	Owner string
+}
+`
+
+func Test_describePackage(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api.go"), []byte(source), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api_test.go"), []byte("package api_test"), 0o600))
+	got, err := describePackage(dir, []string{"Deployment"})
+	require.NoError(t, err)
+	assert.Equal(t, []goalesce.FieldStrategy{
+		{Struct: "api.Deployment", Field: "Name", Type: "string", Strategy: "immutable", Tag: "immutable"},
+		{Struct: "api.Deployment", Field: "Replicas", Type: "*int", Strategy: "deep", Default: "1", Tag: "default:1"},
+		{Struct: "api.Deployment", Field: "Containers", Type: "[]Container", Strategy: "id", MergeKey: "Name", Tag: "id:Name"},
+		{Struct: "api.Deployment", Field: "Labels", Type: "map[string]string", Strategy: "deep"},
+		{Struct: "api.Deployment", Field: "Created", Type: "time.Time", Strategy: "earliest", Tag: "earliest"},
+		{Struct: "api.Deployment", Field: "Any", Type: "interface{}", Strategy: "deep"},
+		{Struct: "api.Deployment", Field: "Err", Type: "error", Strategy: "deep"},
+		{Struct: "api.Deployment", Field: "Metadata", Type: "Metadata", Strategy: "deep"},
+		{Struct: "api.Container", Field: "Name", Type: "string", Strategy: "atomic"},
+		{Struct: "api.Container", Field: "Ports", Type: "[]Port", Strategy: "union", Tag: "union"},
+		{Struct: "api.Container", Field: "Port", Type: "Port", Strategy: "atomic"},
+		{Struct: "api.Metadata", Field: "Owner", Type: "string", Strategy: "atomic"},
+	}, got)
+	_, err = describePackage(dir, []string{"Missing"})
+	assert.EqualError(t, err, "struct type Missing not found in "+dir)
+}
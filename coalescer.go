@@ -14,60 +14,244 @@
 
 package goalesce
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+)
 
 // coalescer is the engine for merging and copying objets. It has two methods that satisfy
 // DeepMergeFunc and DeepCopyFunc: deepMerge and deepCopy respectively.
 type coalescer struct {
-	deepCopy       DeepCopyFunc
-	deepMerge      DeepMergeFunc
-	typeCopiers    map[reflect.Type]DeepCopyFunc
-	typeMergers    map[reflect.Type]DeepMergeFunc
-	sliceMerger    DeepMergeFunc
-	sliceMergers   map[ /* slice type */ reflect.Type]DeepMergeFunc
-	arrayMerger    DeepMergeFunc
-	arrayMergers   map[ /* slice type */ reflect.Type]DeepMergeFunc
-	fieldMergers   map[ /* struct type */ reflect.Type]map[ /* field name */ string]DeepMergeFunc
-	zeroEmptySlice bool
-	errorOnCycle   bool
-	seen           map[uintptr]bool
+	deepCopy               DeepCopyFunc
+	deepMerge              DeepMergeFunc
+	typeCopiers            map[reflect.Type]DeepCopyFunc
+	typeMergers            map[reflect.Type]DeepMergeFunc
+	genericTypeCopiers     map[ /* generic origin */ string]DeepCopyFunc
+	genericTypeMergers     map[ /* generic origin */ string]DeepMergeFunc
+	interfaceCopiers       []interfaceCopier // in registration order
+	interfaceMergers       []interfaceMerger // in registration order
+	kindMergers            map[reflect.Kind]DeepMergeFunc
+	sliceMerger            DeepMergeFunc
+	sliceMergers           map[ /* slice type */ reflect.Type]DeepMergeFunc
+	sliceKeyOrders         map[ /* slice type */ reflect.Type]SliceKeyOrder
+	sliceMergerOverrides   map[ /* slice type */ reflect.Type]DeepMergeFunc
+	listAppendDedup        bool
+	listAppendDedupKeys    map[ /* slice type */ reflect.Type]SliceMergeKeyFunc
+	sliceSorts             map[ /* slice type */ reflect.Type]func(e1, e2 reflect.Value) bool
+	sliceDifferences       map[ /* slice type */ reflect.Type]SliceMergeKeyFunc
+	sliceDeleteMarkers     map[ /* slice type */ reflect.Type]SliceDeleteMarker
+	sliceElemMergers       map[ /* slice type */ reflect.Type]DeepMergeFunc
+	defaultSliceKeyOrder   SliceKeyOrder
+	strictIndexMerge       bool
+	mapNoNewKeys           func(path string)
+	mapAddOnly             bool
+	mapNullDeletion        bool
+	mapNullDeletions       map[ /* map type */ reflect.Type]bool
+	mapAtomicValues        map[ /* map type */ reflect.Type]bool
+	mapKeyMergers          map[ /* map type */ reflect.Type]map[ /* map key */ interface{}]DeepMergeFunc
+	firstValuePriority     bool
+	arrayMerger            DeepMergeFunc
+	arrayMergers           map[ /* slice type */ reflect.Type]DeepMergeFunc
+	fieldMergers           map[ /* struct type */ reflect.Type]map[ /* field name */ string]DeepMergeFunc
+	fieldDefaults          map[ /* struct type */ reflect.Type]map[ /* field name */ string]reflect.Value
+	fieldAllowlists        map[ /* struct type */ reflect.Type]map[ /* field name */ string]bool
+	fieldPathMergers       map[ /* root struct type */ reflect.Type]map[ /* field path */ string]DeepMergeFunc
+	fieldNameMatching      FieldNameMatching
+	fieldNameErrors        map[ /* struct type */ reflect.Type]error
+	pathMergers            []pathMerger
+	pathMergerErr          error
+	zeroEmptySlice         bool
+	identityShortCircuit   bool
+	subtreeHasher          *subtreeHasher
+	memoizer               *memoizer
+	errorOnCycle           bool
+	lenientTags            bool
+	unexportedFieldPolicy  UnexportedFieldPolicy
+	deepCopyMethods        bool
+	copyMethods            map[reflect.Type]DeepCopyFunc
+	lenientTagsWarn        func(err error)
+	warnings               *warnings
+	patchDirectives        bool
+	validator              ValidateFunc
+	fieldPermission        FieldPermissionFunc
+	errorOnFieldPermission bool
+	errorAccumulation      bool
+	dryRun                 *dryRun
+	fieldHooks             []FieldHookFunc
+	tracer                 *tracer
+	instrumentation        Instrumentation
+	maxElements            int
+	fastCopies             bool
+	fastMerges             bool
+	parallelism            int
+	recorder               *recorder
+	ctx                    context.Context
+	structPlans            map[reflect.Type]*structPlan // compiled struct merge plans, see structPlan
+	plainTypes             map[reflect.Type]bool        // types that can be copied by assignment, see isPlainType
+	seen                   map[cycleKey]bool            // pointer values being visited
+	fieldPathScopes        []fieldPathScope             // struct values with field path mergers being merged
+	elemMerger             DeepMergeFunc                // the merger of the elements of the slice being merged, see ElemStrategyPrefix
+	path                   string                       // the path of the value being merged, relative to the root value
+	errs                   []error                      // errors collected with WithErrorAccumulation
+	conflicts              *[]Conflict                  // conflicts collected by DeepConflicts
+	traceDepth             int                          // the indentation of traces, see WithTrace
+	stats                  OperationStats               // the statistics of the operation, see WithInstrumentation
+	elements               int                          // slice elements and map entries visited, see WithMaxElements
 }
 
 func newCoalescer(opts ...Option) *coalescer {
 	c := &coalescer{
-		typeCopiers:  make(map[reflect.Type]DeepCopyFunc),
-		typeMergers:  make(map[reflect.Type]DeepMergeFunc),
-		sliceMergers: make(map[reflect.Type]DeepMergeFunc),
-		arrayMergers: make(map[reflect.Type]DeepMergeFunc),
-		fieldMergers: make(map[reflect.Type]map[string]DeepMergeFunc),
-		seen:         make(map[uintptr]bool),
+		typeCopiers:          make(map[reflect.Type]DeepCopyFunc),
+		typeMergers:          make(map[reflect.Type]DeepMergeFunc),
+		genericTypeCopiers:   make(map[string]DeepCopyFunc),
+		genericTypeMergers:   make(map[string]DeepMergeFunc),
+		kindMergers:          make(map[reflect.Kind]DeepMergeFunc),
+		sliceMergers:         make(map[reflect.Type]DeepMergeFunc),
+		sliceKeyOrders:       make(map[reflect.Type]SliceKeyOrder),
+		sliceMergerOverrides: make(map[reflect.Type]DeepMergeFunc),
+		listAppendDedupKeys:  make(map[reflect.Type]SliceMergeKeyFunc),
+		sliceSorts:           make(map[reflect.Type]func(e1, e2 reflect.Value) bool),
+		sliceDifferences:     make(map[reflect.Type]SliceMergeKeyFunc),
+		sliceDeleteMarkers:   make(map[reflect.Type]SliceDeleteMarker),
+		sliceElemMergers:     make(map[reflect.Type]DeepMergeFunc),
+		arrayMergers:         make(map[reflect.Type]DeepMergeFunc),
+		mapNullDeletions:     make(map[reflect.Type]bool),
+		mapAtomicValues:      make(map[reflect.Type]bool),
+		mapKeyMergers:        make(map[reflect.Type]map[interface{}]DeepMergeFunc),
+		fieldMergers:         make(map[reflect.Type]map[string]DeepMergeFunc),
+		fieldDefaults:        make(map[reflect.Type]map[string]reflect.Value),
+		fieldAllowlists:      make(map[reflect.Type]map[string]bool),
+		fieldPathMergers:     make(map[reflect.Type]map[string]DeepMergeFunc),
+		fieldNameErrors:      make(map[reflect.Type]error),
+		structPlans:          make(map[reflect.Type]*structPlan),
+		plainTypes:           make(map[reflect.Type]bool),
+		copyMethods:          make(map[reflect.Type]DeepCopyFunc),
+		seen:                 make(map[cycleKey]bool),
 	}
 	c.deepCopy = c.defaultDeepCopy
 	c.deepMerge = c.defaultDeepMerge
+	c.registerStdlibTypes()
+	c.registerImmutableTypes()
 	for _, opt := range opts {
 		opt(c)
 	}
+	c.resolveFieldNames()
+	c.initFastPaths()
 	return c
 }
 
+// visitElements counts the given number of slice elements or map entries, about to be copied or
+// merged, and returns a *LimitError if the limit set with WithMaxElements is exceeded.
+func (c *coalescer) visitElements(n int) error {
+	c.elements += n
+	if c.maxElements > 0 && c.elements > c.maxElements {
+		return &LimitError{Path: c.path, Limit: c.maxElements}
+	}
+	return nil
+}
+
+// contextCheckInterval is the number of values merged or copied between two checks of the
+// context's cancellation, see WithContext.
+const contextCheckInterval = 64
+
+// checkContext returns the error of the context of the operation if it is done. To keep the
+// overhead low, the context is only checked once every contextCheckInterval values.
+func (c *coalescer) checkContext() error {
+	if c.ctx == nil || (c.stats.ValuesMerged+c.stats.ValuesCopied)%contextCheckInterval != 0 {
+		return nil
+	}
+	return c.ctx.Err()
+}
+
+// context returns the context of the operation, see WithContext.
+func (c *coalescer) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// reset clears the state accumulated by the coalescer during an operation, so that it can be
+// reused for another operation.
+func (c *coalescer) reset() {
+	clear(c.seen)
+	c.path = ""
+	c.errs = nil
+	c.traceDepth = 0
+	c.stats = OperationStats{}
+	c.elements = 0
+	if c.subtreeHasher != nil {
+		c.subtreeHasher.reset()
+	}
+	if c.memoizer != nil {
+		c.memoizer.reset()
+	}
+}
+
+// deepMergeAt merges the 2 values, located at the current path, wraps errors with the path, and
+// runs field hooks.
+func (c *coalescer) deepMergeAt(v1, v2 reflect.Value) (reflect.Value, error) {
+	return c.mergeAt(c.deepMerge, v1, v2)
+}
+
+// mergeAt is like deepMergeAt, but merges the 2 values with the given merger.
+func (c *coalescer) mergeAt(merger DeepMergeFunc, v1, v2 reflect.Value) (reflect.Value, error) {
+	merged, err := merger(v1, v2)
+	if err != nil {
+		return reflect.Value{}, wrapPath(c.path, err)
+	}
+	c.runFieldHooks(v1, v2, merged)
+	return merged, nil
+}
+
 // defaultDeepMerge is the default implementation of DeepMergeFunc. It is used when the coalescer is
 // created with default options. In the absence of a specific type merger, it merely delegates to
 // the appropriate specialized merge methods, depending on the type of the values to merge.
 func (c *coalescer) defaultDeepMerge(v1, v2 reflect.Value) (reflect.Value, error) {
 	if !v1.IsValid() {
+		c.trace("first value is nil, copying second value")
 		return c.deepCopy(v2)
 	} else if !v2.IsValid() {
+		c.trace("second value is nil, copying first value")
 		return c.deepCopy(v1)
 	}
 	if err := checkTypesMatch(v1.Type(), v2.Type()); err != nil {
 		return reflect.Value{}, err
 	}
-	if merger, found := c.typeMergers[v1.Type()]; found {
+	if err := c.checkContext(); err != nil {
+		return reflect.Value{}, err
+	}
+	c.stats.ValuesMerged++
+	c.trace("merging %s", v1.Type().String())
+	c.traceDepth++
+	defer func() { c.traceDepth-- }()
+	if merger, found, err := c.pathMerger(); err != nil {
+		return reflect.Value{}, err
+	} else if found {
+		c.trace("using path merger")
+		merged, err := merger(v1, v2)
+		if done, merged, err := checkCustomResult(merged, err, v1.Type()); done {
+			return merged, err
+		}
+	}
+	if merger, found := c.typeMerger(v1.Type()); found {
+		c.trace("using type merger")
 		merged, err := merger(v1, v2)
 		if done, merged, err := checkCustomResult(merged, err, v1.Type()); done {
 			return merged, err
 		}
 	}
+	if merger, found := c.kindMergers[v1.Kind()]; found {
+		c.trace("using kind merger")
+		merged, err := merger(v1, v2)
+		if done, merged, err := checkCustomResult(merged, err, v1.Type()); done {
+			return merged, err
+		}
+	}
+	if c.identityShortCircuit && identical(v1, v2) || c.subtreeHasher != nil && c.subtreeHasher.sameSubtrees(v1, v2) {
+		c.trace("identical values, copying first value")
+		return c.deepCopy(v1)
+	}
 	switch v1.Type().Kind() {
 	case reflect.Interface:
 		return c.deepMergeInterface(v1, v2)
@@ -93,12 +277,22 @@ func (c *coalescer) defaultDeepCopy(v reflect.Value) (reflect.Value, error) {
 	if !v.IsValid() {
 		return v, nil
 	}
-	if copier, found := c.typeCopiers[v.Type()]; found {
+	if err := c.checkContext(); err != nil {
+		return reflect.Value{}, err
+	}
+	c.stats.ValuesCopied++
+	if copier, found := c.typeCopier(v.Type()); found {
 		copied, err := copier(v)
 		if done, copied, err := checkCustomResult(copied, err, v.Type()); done {
 			return copied, err
 		}
 	}
+	if kind := v.Kind(); (kind == reflect.Struct || kind == reflect.Array) && c.isPlainType(v.Type()) {
+		return copyPlain(v), nil
+	}
+	if copied, done, err := c.fastCopy(v); done {
+		return copied, err
+	}
 	switch v.Type().Kind() {
 	case reflect.Interface:
 		return c.deepCopyInterface(v)
@@ -201,7 +201,7 @@ func Test_coalescer_defaultDeepCopy(t *testing.T) {
 		},
 		{
 			name:    "generic error",
-			v:       reflect.ValueOf(Foo{1}),
+			v:       reflect.ValueOf([]*int{intPtr(1)}),
 			wantErr: assert.Error,
 			opts:    []Option{withMockDeepCopyError},
 		},
@@ -0,0 +1,68 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "reflect"
+
+// Conflict is a location where both values of a merge are non-zero and differ, so that the second
+// value overrides the first one, as reported by DeepConflicts.
+type Conflict struct {
+	// Path is the location of the conflict, e.g. "Spec.Containers[2].Image".
+	Path string
+	// First is a deep copy of the first value, which would be overridden.
+	First interface{}
+	// Second is a deep copy of the second value, which would override the first one.
+	Second interface{}
+}
+
+// DeepConflicts reports every location where DeepMerge, called with the same values and options,
+// would override a non-zero value of the first value with a different, non-zero value of the
+// second value. This allows warning users about overrides before merging.
+//
+// Conflicts are reported where the values are merged with atomic semantics: for scalars, for
+// slices merged atomically (the default), and for interfaces wrapping values of different types.
+// Values merged recursively, e.g. maps and structs, or slices merged with other semantics, are not
+// conflicting by themselves; instead, their elements are inspected. Values merged by custom
+// mergers are never reported.
+//
+// This function returns an error if the values are not of the same type, or if the merge
+// encounters an error.
+func DeepConflicts[T any](o1, o2 T, opts ...Option) ([]Conflict, error) {
+	c := newCoalescer(opts...)
+	var conflicts []Conflict
+	c.conflicts = &conflicts
+	if _, err := c.deepMerge(reflect.ValueOf(o1), reflect.ValueOf(o2)); err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+// reportConflict records a conflict at the current path if the 2 values, about to be merged with
+// atomic semantics, are both non-zero and differ. It is a no-op outside of DeepConflicts.
+func (c *coalescer) reportConflict(v1, v2 reflect.Value) error {
+	if c.conflicts == nil || v1.IsZero() || v2.IsZero() || reflect.DeepEqual(v1.Interface(), v2.Interface()) {
+		return nil
+	}
+	first, err := c.deepCopy(v1)
+	if err != nil {
+		return err
+	}
+	second, err := c.deepCopy(v2)
+	if err != nil {
+		return err
+	}
+	*c.conflicts = append(*c.conflicts, Conflict{Path: c.path, First: first.Interface(), Second: second.Interface()})
+	return nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeepConflicts(t *testing.T) {
+	type container struct {
+		Name  string
+		Image string
+		Ports []int
+	}
+	type foo struct {
+		FieldInt        int
+		FieldPtr        *int
+		FieldMap        map[string]int
+		FieldSlice      []string
+		FieldItf        interface{}
+		FieldContainers []container
+	}
+	tests := []struct {
+		name    string
+		v1      interface{}
+		v2      interface{}
+		opts    []Option
+		want    []Conflict
+		wantErr string
+	}{
+		{
+			name: "nils",
+			v1:   nil,
+			v2:   nil,
+			want: nil,
+		},
+		{
+			name: "scalars",
+			v1:   1,
+			v2:   2,
+			want: []Conflict{{Path: "", First: 1, Second: 2}},
+		},
+		{
+			name: "zero values",
+			v1:   foo{FieldInt: 1, FieldMap: map[string]int{"a": 1}},
+			v2:   foo{FieldPtr: intPtr(1), FieldMap: map[string]int{"b": 2}},
+			want: nil,
+		},
+		{
+			name: "equal",
+			v1:   foo{FieldInt: 1, FieldPtr: intPtr(1), FieldSlice: []string{"a"}},
+			v2:   foo{FieldInt: 1, FieldPtr: intPtr(1), FieldSlice: []string{"a"}},
+			want: nil,
+		},
+		{
+			name: "different",
+			v1:   foo{FieldInt: 1, FieldPtr: intPtr(1), FieldMap: map[string]int{"a": 1, "b": 1}, FieldSlice: []string{"a"}, FieldItf: 1},
+			v2:   foo{FieldInt: 2, FieldPtr: intPtr(2), FieldMap: map[string]int{"a": 2, "c": 2}, FieldSlice: []string{"b"}, FieldItf: "a"},
+			want: []Conflict{
+				{Path: "FieldInt", First: 1, Second: 2},
+				{Path: "FieldPtr", First: 1, Second: 2},
+				{Path: `FieldMap["a"]`, First: 1, Second: 2},
+				{Path: "FieldSlice", First: []string{"a"}, Second: []string{"b"}},
+				{Path: "FieldItf", First: 1, Second: "a"},
+			},
+		},
+		{
+			name: "slices merged by id",
+			v1:   foo{FieldContainers: []container{{Name: "web", Image: "nginx:1", Ports: []int{80}}, {Name: "db", Image: "postgres"}}},
+			v2:   foo{FieldContainers: []container{{Name: "web", Image: "nginx:2", Ports: []int{80}}, {Name: "cache", Image: "redis"}}},
+			opts: []Option{WithSliceMergeByID(reflect.TypeOf([]container{}), "Name")},
+			want: []Conflict{{Path: "FieldContainers[0].Image", First: "nginx:1", Second: "nginx:2"}},
+		},
+		{
+			name: "slices merged with union",
+			v1:   foo{FieldSlice: []string{"a"}},
+			v2:   foo{FieldSlice: []string{"b"}},
+			opts: []Option{WithDefaultSliceSetUnionMerge()},
+			want: nil,
+		},
+		{
+			name:    "types do not match",
+			v1:      1,
+			v2:      "a",
+			wantErr: "types do not match: int != string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepConflicts(tt.v1, tt.v2, tt.opts...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ContainerEntry is an entry of a container. See ContainerAdapter.
+type ContainerEntry struct {
+	// Key identifies the entry inside its container. It must be comparable. For list-like
+	// containers, the key is typically the entry index; for set-like containers, the element
+	// itself.
+	Key interface{}
+	// Value is the value of the entry.
+	Value interface{}
+}
+
+// ContainerAdapter allows third-party container types, e.g. ordered maps, immutable lists or
+// sets, to participate in deep copy and deep merge operations, without having to write
+// reflection-based copiers and mergers. An adapter only needs to enumerate the entries of a
+// container, and to create a new container from a list of entries. See WithContainerAdapter.
+type ContainerAdapter interface {
+	// Entries returns the entries of the given container, in iteration order. The container is
+	// never nil nor a zero-value.
+	Entries(container interface{}) ([]ContainerEntry, error)
+	// New creates a new container holding the given entries, in the given order. The returned
+	// container must be of the same type as the containers passed to Entries.
+	New(entries []ContainerEntry) (interface{}, error)
+}
+
+// deepCopyContainer copies the given container entry by entry.
+func (c *coalescer) deepCopyContainer(adapter ContainerAdapter, v reflect.Value) (reflect.Value, error) {
+	if v.IsZero() {
+		return reflect.Zero(v.Type()), nil
+	}
+	entries, err := adapter.Entries(v.Interface())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	copiedEntries := make([]ContainerEntry, len(entries))
+	for i, entry := range entries {
+		if copiedEntries[i], err = c.deepCopyContainerEntry(entry); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	return newContainer(adapter, v.Type(), copiedEntries)
+}
+
+// deepMergeContainer merges the given containers key by key, with the same semantics as regular
+// maps. The entries of v1 come first, in their original order, followed by the entries that only
+// exist in v2.
+func (c *coalescer) deepMergeContainer(adapter ContainerAdapter, v1, v2 reflect.Value) (reflect.Value, error) {
+	if value, done := checkZero(v1, v2); done {
+		return c.deepCopyContainer(adapter, value)
+	}
+	entries1, err := adapter.Entries(v1.Interface())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	entries2, err := adapter.Entries(v2.Interface())
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	values2 := make(map[interface{}]interface{}, len(entries2))
+	for _, entry := range entries2 {
+		values2[entry.Key] = entry.Value
+	}
+	parent := c.path
+	defer func() { c.path = parent }()
+	merged := make([]ContainerEntry, 0, len(entries1)+len(entries2))
+	seen := make(map[interface{}]bool, len(entries1))
+	for _, entry := range entries1 {
+		seen[entry.Key] = true
+		value2, found := values2[entry.Key]
+		if !found {
+			copied, err := c.deepCopyContainerEntry(entry)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			merged = append(merged, copied)
+			continue
+		}
+		copiedKey, err := c.deepCopy(interfaceValue(entry.Key))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		c.path = keyPath(parent, reflect.ValueOf(entry.Key))
+		mergedValue, err := c.deepMergeAt(interfaceValue(entry.Value), interfaceValue(value2))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		merged = append(merged, ContainerEntry{Key: copiedKey.Interface(), Value: mergedValue.Interface()})
+	}
+	for _, entry := range entries2 {
+		if !seen[entry.Key] {
+			copied, err := c.deepCopyContainerEntry(entry)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			merged = append(merged, copied)
+		}
+	}
+	return newContainer(adapter, v1.Type(), merged)
+}
+
+func (c *coalescer) deepCopyContainerEntry(entry ContainerEntry) (ContainerEntry, error) {
+	copiedKey, err := c.deepCopy(interfaceValue(entry.Key))
+	if err != nil {
+		return ContainerEntry{}, err
+	}
+	copiedValue, err := c.deepCopy(interfaceValue(entry.Value))
+	if err != nil {
+		return ContainerEntry{}, err
+	}
+	return ContainerEntry{Key: copiedKey.Interface(), Value: copiedValue.Interface()}, nil
+}
+
+func newContainer(adapter ContainerAdapter, containerType reflect.Type, entries []ContainerEntry) (reflect.Value, error) {
+	container, err := adapter.New(entries)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if container == nil {
+		return reflect.Value{}, fmt.Errorf("%s: container adapter returned nil", containerType.String())
+	}
+	return reflect.ValueOf(container), nil
+}
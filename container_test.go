@@ -0,0 +1,177 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderedMap is a container with unexported fields only, that cannot be copied nor merged without
+// an adapter.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap(kv ...interface{}) *orderedMap {
+	m := &orderedMap{values: make(map[string]interface{})}
+	for i := 0; i < len(kv); i += 2 {
+		m.keys = append(m.keys, kv[i].(string))
+		m.values[kv[i].(string)] = kv[i+1]
+	}
+	return m
+}
+
+type orderedMapAdapter struct {
+	entriesErr error
+	newErr     error
+	newNil     bool
+}
+
+func (a orderedMapAdapter) Entries(container interface{}) ([]ContainerEntry, error) {
+	if a.entriesErr != nil {
+		return nil, a.entriesErr
+	}
+	m := container.(*orderedMap)
+	entries := make([]ContainerEntry, len(m.keys))
+	for i, k := range m.keys {
+		entries[i] = ContainerEntry{Key: k, Value: m.values[k]}
+	}
+	return entries, nil
+}
+
+func (a orderedMapAdapter) New(entries []ContainerEntry) (interface{}, error) {
+	if a.newErr != nil {
+		return nil, a.newErr
+	} else if a.newNil {
+		return nil, nil
+	}
+	kv := make([]interface{}, 0, len(entries)*2)
+	for _, entry := range entries {
+		kv = append(kv, entry.Key, entry.Value)
+	}
+	return newOrderedMap(kv...), nil
+}
+
+func Test_coalescer_deepCopyContainer(t *testing.T) {
+	tests := []struct {
+		name    string
+		v       *orderedMap
+		adapter orderedMapAdapter
+		want    *orderedMap
+		wantErr string
+	}{
+		{"nil", nil, orderedMapAdapter{}, nil, ""},
+		{"empty", newOrderedMap(), orderedMapAdapter{}, newOrderedMap(), ""},
+		{"non empty", newOrderedMap("b", 1, "a", []int{1}), orderedMapAdapter{}, newOrderedMap("b", 1, "a", []int{1}), ""},
+		{"entries error", newOrderedMap("a", 1), orderedMapAdapter{entriesErr: errors.New("fake")}, nil, "fake"},
+		{"new error", newOrderedMap("a", 1), orderedMapAdapter{newErr: errors.New("fake")}, nil, "fake"},
+		{"new nil", newOrderedMap("a", 1), orderedMapAdapter{newNil: true}, nil, "*goalesce.orderedMap: container adapter returned nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepCopy(tt.v, WithContainerAdapter(reflect.TypeOf(&orderedMap{}), tt.adapter))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assertNotSame(t, tt.v, got)
+			}
+		})
+	}
+	t.Run("values deep-copied", func(t *testing.T) {
+		v := newOrderedMap("a", []int{1})
+		got, err := DeepCopy(v, WithContainerAdapter(reflect.TypeOf(&orderedMap{}), orderedMapAdapter{}))
+		require.NoError(t, err)
+		got.values["a"].([]int)[0] = 2
+		assert.Equal(t, []int{1}, v.values["a"])
+	})
+}
+
+func Test_coalescer_deepMergeContainer(t *testing.T) {
+	tests := []struct {
+		name    string
+		v1      *orderedMap
+		v2      *orderedMap
+		adapter orderedMapAdapter
+		opts    []Option
+		want    *orderedMap
+		wantErr string
+	}{
+		{
+			name: "nil",
+			v1:   nil,
+			v2:   newOrderedMap("a", 1),
+			want: newOrderedMap("a", 1),
+		},
+		{
+			name: "disjoint",
+			v1:   newOrderedMap("b", 1),
+			v2:   newOrderedMap("a", 2),
+			want: newOrderedMap("b", 1, "a", 2),
+		},
+		{
+			name: "overlapping",
+			v1:   newOrderedMap("b", 1, "a", map[string]int{"x": 1}),
+			v2:   newOrderedMap("c", 3, "a", map[string]int{"y": 2}, "b", 2),
+			want: newOrderedMap("b", 2, "a", map[string]int{"x": 1, "y": 2}, "c", 3),
+		},
+		{
+			name:    "entries error",
+			v1:      newOrderedMap("a", 1),
+			v2:      newOrderedMap("a", 2),
+			adapter: orderedMapAdapter{entriesErr: errors.New("fake")},
+			wantErr: "fake",
+		},
+		{
+			name: "merge error",
+			v1:   newOrderedMap("a", 1),
+			v2:   newOrderedMap("a", 2),
+			opts: []Option{WithTypeMerger(reflect.TypeOf(0), func(v1, v2 reflect.Value) (reflect.Value, error) {
+				return reflect.Value{}, errors.New("fake")
+			})},
+			wantErr: `at ["a"]: fake`,
+		},
+		{
+			name: "copy error",
+			v1:   newOrderedMap("a", 1),
+			v2:   newOrderedMap("b", 2),
+			opts: []Option{WithTypeCopier(reflect.TypeOf(0), func(v reflect.Value) (reflect.Value, error) {
+				return reflect.Value{}, errors.New("fake")
+			})},
+			wantErr: "fake",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]Option{WithContainerAdapter(reflect.TypeOf(&orderedMap{}), tt.adapter)}, tt.opts...)
+			got, err := DeepMerge(tt.v1, tt.v2, opts...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+				assertNotSame(t, tt.v1, got)
+				assertNotSame(t, tt.v2, got)
+			}
+		})
+	}
+}
@@ -14,22 +14,38 @@
 
 package goalesce
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+	"time"
+)
 
 // DeepCopy deep-copies the value and returns the copied value.
 //
 // This function never modifies its inputs. It always returns an entirely newly-allocated value that
 // shares no references with the inputs.
 func DeepCopy[T any](o T, opts ...Option) (T, error) {
-	coalescer := newCoalescer(opts...)
-	v := reflect.ValueOf(o)
-	result, err := coalescer.deepCopy(v)
+	return deepCopy(newCoalescer(opts...), o)
+}
+
+func deepCopy[T any](coalescer *coalescer, o T) (_ T, err error) {
+	if coalescer.instrumentation != nil {
+		start := time.Now()
+		defer func() { coalescer.completeOperation(OperationCopy, start, err) }()
+	}
+	result, err := coalescer.deepCopy(reflect.ValueOf(o))
 	if !result.IsValid() || err != nil {
 		return zero[T](), err
 	}
 	return cast[T](result)
 }
 
+// DeepCopyContext is like DeepCopy, but attaches the given context to the copy, see
+// DeepMergeContext.
+func DeepCopyContext[T any](ctx context.Context, o T, opts ...Option) (T, error) {
+	return deepCopy(newCoalescer(append(opts[:len(opts):len(opts)], WithContext(ctx))...), o)
+}
+
 // MustDeepCopy is like DeepCopy, but panics if the copy returns an error.
 func MustDeepCopy[T any](o T, opts ...Option) T {
 	copied, err := DeepCopy(o, opts...)
@@ -15,6 +15,7 @@
 package goalesce
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
@@ -197,3 +198,17 @@ func TestMustDeepCopy(t *testing.T) {
 		MustDeepCopy("abc", withMockDeepCopyError)
 	})
 }
+
+func TestDeepCopyContext(t *testing.T) {
+	large := make([]map[string]int, 1000)
+	for i := range large {
+		large[i] = map[string]int{"a": i}
+	}
+	got, err := DeepCopyContext(context.Background(), large)
+	assert.NoError(t, err)
+	assert.Equal(t, large, got)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = DeepCopyContext(ctx, large)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "reflect"
+
+// deepCopyMethod returns a DeepCopyFunc delegating to the DeepCopy or DeepCopyInto method of the
+// given type, if WithDeepCopyMethods is enabled and the type has such a method. Results are cached
+// per coalescer.
+func (c *coalescer) deepCopyMethod(t reflect.Type) (DeepCopyFunc, bool) {
+	if !c.deepCopyMethods || t.Kind() == reflect.Interface {
+		return nil, false
+	}
+	copier, found := c.copyMethods[t]
+	if !found {
+		copier = lookupDeepCopyMethod(t)
+		c.copyMethods[t] = copier
+	}
+	return copier, copier != nil
+}
+
+// lookupDeepCopyMethod returns a DeepCopyFunc calling the methods generated by controller-gen, or
+// written by hand following the same convention, for the given type T or *T:
+//
+//	func (in *T) DeepCopy() *T
+//	func (in *T) DeepCopyInto(out *T)
+//
+// Value receivers, e.g. func (in T) DeepCopy() T, are also supported. DeepCopy is preferred over
+// DeepCopyInto. It returns nil if the type has none of these methods.
+func lookupDeepCopyMethod(t reflect.Type) DeepCopyFunc {
+	ptrType := t
+	if t.Kind() != reflect.Ptr {
+		ptrType = reflect.PointerTo(t)
+	}
+	if method, found := t.MethodByName("DeepCopy"); found && isDeepCopyMethod(method.Type, nil, t) {
+		return func(v reflect.Value) (reflect.Value, error) {
+			if !v.CanInterface() {
+				return reflect.Value{}, nil
+			} else if v.Kind() == reflect.Ptr && v.IsNil() {
+				return reflect.Zero(t), nil
+			}
+			return v.Method(method.Index).Call(nil)[0], nil
+		}
+	}
+	if t.Kind() != reflect.Ptr {
+		if method, found := ptrType.MethodByName("DeepCopy"); found && isDeepCopyMethod(method.Type, nil, ptrType) {
+			return func(v reflect.Value) (reflect.Value, error) {
+				if !v.CanInterface() {
+					return reflect.Value{}, nil
+				}
+				in := reflect.New(t)
+				in.Elem().Set(v)
+				return in.Method(method.Index).Call(nil)[0].Elem(), nil
+			}
+		}
+	}
+	if method, found := ptrType.MethodByName("DeepCopyInto"); found && isDeepCopyMethod(method.Type, ptrType, nil) {
+		return func(v reflect.Value) (reflect.Value, error) {
+			if !v.CanInterface() {
+				return reflect.Value{}, nil
+			}
+			in := v
+			if t.Kind() != reflect.Ptr {
+				in = reflect.New(t)
+				in.Elem().Set(v)
+			} else if v.IsNil() {
+				return reflect.Zero(t), nil
+			}
+			out := reflect.New(ptrType.Elem())
+			in.Method(method.Index).Call([]reflect.Value{out})
+			if t.Kind() != reflect.Ptr {
+				return out.Elem(), nil
+			}
+			return out, nil
+		}
+	}
+	return nil
+}
+
+// isDeepCopyMethod returns true if the given method type, including its receiver, takes the given
+// argument type, or no argument if nil, and returns the given result type, or nothing if nil.
+func isDeepCopyMethod(methodType, in, out reflect.Type) bool {
+	if in == nil && methodType.NumIn() != 1 || in != nil && (methodType.NumIn() != 2 || methodType.In(1) != in) {
+		return false
+	}
+	return out == nil && methodType.NumOut() == 0 || out != nil && methodType.NumOut() == 1 && methodType.Out(0) == out
+}
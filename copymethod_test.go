@@ -0,0 +1,132 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// podSpec follows the controller-gen convention; its copy methods record the copy in Copies.
+type podSpec struct {
+	Image  string
+	Copies int
+}
+
+func (in *podSpec) DeepCopyInto(out *podSpec) {
+	*out = *in
+	out.Copies++
+}
+
+func (in *podSpec) DeepCopy() *podSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(podSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// copyIntoOnly only has a DeepCopyInto method.
+type copyIntoOnly struct {
+	Copies int
+}
+
+func (in *copyIntoOnly) DeepCopyInto(out *copyIntoOnly) {
+	out.Copies = in.Copies + 1
+}
+
+// selector has a DeepCopy method with a value receiver.
+type selector map[string]string
+
+func (in selector) DeepCopy() selector {
+	if in == nil {
+		return nil
+	}
+	out := make(selector, len(in)+1)
+	for k, v := range in {
+		out[k] = v
+	}
+	out["copied"] = "true"
+	return out
+}
+
+func Test_lookupDeepCopyMethod(t *testing.T) {
+	for _, tt := range []struct {
+		t     reflect.Type
+		found bool
+	}{
+		{reflect.TypeOf(podSpec{}), true},
+		{reflect.TypeOf(&podSpec{}), true},
+		{reflect.TypeOf(copyIntoOnly{}), true},
+		{reflect.TypeOf(&copyIntoOnly{}), true},
+		{reflect.TypeOf(selector{}), true},
+		{reflect.TypeOf(&selector{}), false},
+		{reflect.TypeOf(configMap{}), false},
+		{reflect.TypeOf(0), false},
+	} {
+		assert.Equal(t, tt.found, lookupDeepCopyMethod(tt.t) != nil, tt.t.String())
+	}
+}
+
+func TestDeepCopy_deepCopyMethods(t *testing.T) {
+	type pod struct {
+		Spec     podSpec
+		SpecPtr  *podSpec
+		Specs    []podSpec
+		Into     copyIntoOnly
+		IntoPtr  *copyIntoOnly
+		Selector selector
+		Nil      *podSpec
+	}
+	v := pod{
+		Spec:     podSpec{Image: "nginx"},
+		SpecPtr:  &podSpec{Image: "redis"},
+		Specs:    []podSpec{{Image: "envoy"}},
+		IntoPtr:  &copyIntoOnly{},
+		Selector: selector{"app": "web"},
+	}
+	got, err := DeepCopy(v, WithDeepCopyMethods())
+	require.NoError(t, err)
+	assert.Equal(t, pod{
+		Spec:     podSpec{Image: "nginx", Copies: 1},
+		SpecPtr:  &podSpec{Image: "redis", Copies: 1},
+		Specs:    []podSpec{{Image: "envoy", Copies: 1}},
+		Into:     copyIntoOnly{Copies: 1},
+		IntoPtr:  &copyIntoOnly{Copies: 1},
+		Selector: selector{"app": "web", "copied": "true"},
+	}, got)
+	assert.NotSame(t, v.SpecPtr, got.SpecPtr)
+	t.Run("disabled by default", func(t *testing.T) {
+		got, err := DeepCopy(v)
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	})
+	t.Run("type copier takes precedence", func(t *testing.T) {
+		got, err := DeepCopy(v.Spec, WithDeepCopyMethods(), WithTypeCopier(reflect.TypeOf(podSpec{}), func(v reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf(podSpec{Image: "custom"}), nil
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, podSpec{Image: "custom"}, got)
+	})
+	t.Run("merge with zero", func(t *testing.T) {
+		got, err := DeepMerge(pod{}, pod{SpecPtr: &podSpec{Image: "redis"}}, WithDeepCopyMethods())
+		require.NoError(t, err)
+		assert.Equal(t, &podSpec{Image: "redis", Copies: 1}, got.SpecPtr)
+	})
+}
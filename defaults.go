@@ -0,0 +1,157 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// applyFieldDefault returns a copy of the default value declared for the given field if the merged
+// value is zero, and the merged value otherwise. Default values declared with WithFieldDefault take
+// precedence over default values declared in struct tags.
+func (c *coalescer) applyFieldDefault(structType reflect.Type, field reflect.StructField, merged reflect.Value) (reflect.Value, error) {
+	if !merged.IsZero() {
+		return merged, nil
+	}
+	defaultValue, found := c.fieldDefaults[structType][field.Name]
+	if !found {
+		tagValue, foundTag := defaultFromTag(structType, field)
+		if !foundTag {
+			return merged, nil
+		}
+		parsed, err := parseDefaultValue(field.Type, tagValue)
+		if err != nil {
+			return reflect.Value{}, &TagError{
+				Struct:   structType,
+				Field:    field.Name,
+				Strategy: MergeStrategyDefault + ":" + tagValue,
+				Err:      fmt.Errorf("invalid default value %q: %w", tagValue, err),
+			}
+		}
+		return parsed, nil
+	}
+	if !defaultValue.IsValid() || !defaultValue.Type().AssignableTo(field.Type) {
+		return reflect.Value{}, fmt.Errorf("field %s.%s: default value of type %s is not assignable to %s", structType.String(), field.Name, typeName(defaultValue), field.Type.String())
+	}
+	copied, err := c.deepCopy(defaultValue)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	converted := reflect.New(field.Type).Elem()
+	converted.Set(copied)
+	return converted, nil
+}
+
+// defaultFromTag returns the default value declared in the given field's merge strategy, if any,
+// e.g. "8080" for `goalesce:"default:8080"`.
+func defaultFromTag(structType reflect.Type, field reflect.StructField) (string, bool) {
+	if mergeStrategy, found := fieldStrategy(structType, field); found {
+		return strings.CutPrefix(mergeStrategy, MergeStrategyDefault+":")
+	}
+	return "", false
+}
+
+// parseDefaultValue parses the given default value according to the given type. Supported types
+// are strings, booleans, numbers, time.Duration, types implementing encoding.TextUnmarshaler, and
+// pointers to any of these.
+func parseDefaultValue(t reflect.Type, s string) (reflect.Value, error) {
+	parsed := reflect.New(t).Elem()
+	if t.Kind() == reflect.Ptr {
+		target, err := parseDefaultValue(t.Elem(), s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		parsed.Set(reflect.New(t.Elem()))
+		parsed.Elem().Set(target)
+		return parsed, nil
+	}
+	if unmarshaler, ok := parsed.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(s)); err != nil {
+			return reflect.Value{}, err
+		}
+		return parsed, nil
+	}
+	if t == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		parsed.SetInt(int64(d))
+		return parsed, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		parsed.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		parsed.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 0, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		parsed.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(s, 0, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		parsed.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		parsed.SetFloat(f)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type: %s", t.String())
+	}
+	return parsed, nil
+}
+
+// hasFieldDefaults returns true if a default value is declared for any field of the given struct
+// type, or of its nested (non-pointer) struct fields.
+func (c *coalescer) hasFieldDefaults(structType reflect.Type) bool {
+	if len(c.fieldDefaults[structType]) > 0 {
+		return true
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if _, found := defaultFromTag(structType, field); found {
+			return true
+		} else if field.Type.Kind() == reflect.Struct && c.hasFieldDefaults(field.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+	return v.Type().String()
+}
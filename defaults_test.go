@@ -0,0 +1,202 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_applyFieldDefault(t *testing.T) {
+	type server struct {
+		Host  string
+		Port  int
+		Tags  []string
+		Level *int
+	}
+	type config struct {
+		Name   string
+		Server server
+	}
+	serverType := reflect.TypeOf(server{})
+	tests := []struct {
+		name    string
+		v1      config
+		v2      config
+		opts    []Option
+		want    config
+		wantErr string
+	}{
+		{
+			name: "both zero",
+			v1:   config{},
+			v2:   config{},
+			opts: []Option{WithFieldDefault(serverType, "Port", 8080), WithFieldDefault(serverType, "Tags", []string{"default"})},
+			want: config{Server: server{Port: 8080, Tags: []string{"default"}}},
+		},
+		{
+			name: "v1 non zero",
+			v1:   config{Server: server{Port: 80}},
+			v2:   config{},
+			opts: []Option{WithFieldDefault(serverType, "Port", 8080)},
+			want: config{Server: server{Port: 80}},
+		},
+		{
+			name: "v2 non zero",
+			v1:   config{},
+			v2:   config{Server: server{Host: "localhost", Port: 80}},
+			opts: []Option{WithFieldDefault(serverType, "Port", 8080), WithFieldDefault(serverType, "Host", "example.com")},
+			want: config{Server: server{Host: "localhost", Port: 80}},
+		},
+		{
+			name: "other fields merged",
+			v1:   config{Name: "foo"},
+			v2:   config{Server: server{Host: "localhost"}},
+			opts: []Option{WithFieldDefault(serverType, "Port", 8080)},
+			want: config{Name: "foo", Server: server{Host: "localhost", Port: 8080}},
+		},
+		{
+			name: "pointer",
+			v1:   config{},
+			v2:   config{},
+			opts: []Option{WithFieldDefault(serverType, "Level", intPtr(3))},
+			want: config{Server: server{Level: intPtr(3)}},
+		},
+		{
+			name:    "wrong type",
+			v1:      config{},
+			v2:      config{},
+			opts:    []Option{WithFieldDefault(serverType, "Port", "8080")},
+			wantErr: "at Server.Port: field goalesce.server.Port: default value of type string is not assignable to int",
+		},
+		{
+			name:    "nil",
+			v1:      config{},
+			v2:      config{},
+			opts:    []Option{WithFieldDefault(serverType, "Level", nil)},
+			wantErr: "at Server.Level: field goalesce.server.Level: default value of type nil is not assignable to *int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2, tt.opts...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+	t.Run("default is copied", func(t *testing.T) {
+		tags := []string{"default"}
+		got, err := DeepMerge(config{}, config{}, WithFieldDefault(serverType, "Tags", tags))
+		require.NoError(t, err)
+		assert.Equal(t, tags, got.Server.Tags)
+		assertNotSame(t, tags, got.Server.Tags)
+	})
+}
+
+func Test_coalescer_hasFieldDefaults(t *testing.T) {
+	type inner struct {
+		Port int
+	}
+	type outer struct {
+		Inner    inner
+		InnerPtr *inner
+	}
+	c := newCoalescer(WithFieldDefault(reflect.TypeOf(inner{}), "Port", 8080))
+	assert.True(t, c.hasFieldDefaults(reflect.TypeOf(inner{})))
+	assert.True(t, c.hasFieldDefaults(reflect.TypeOf(outer{})))
+	assert.False(t, c.hasFieldDefaults(reflect.TypeOf(struct{ InnerPtr *inner }{})))
+}
+
+func Test_coalescer_applyFieldDefault_tags(t *testing.T) {
+	type server struct {
+		Host    string        `goalesce:"default:localhost"`
+		Port    int           `goalesce:"default:8080"`
+		Mask    uint8         `goalesce:"default:0xff"`
+		Ratio   float64       `goalesce:"default:0.5"`
+		TLS     *bool         `goalesce:"default:true"`
+		Timeout time.Duration `goalesce:"default:30s"`
+		Since   time.Time     `goalesce:"default:2022-01-01T00:00:00Z"`
+	}
+	type config struct {
+		Server server
+	}
+	t.Run("both zero", func(t *testing.T) {
+		got, err := DeepMerge(config{}, config{})
+		require.NoError(t, err)
+		assert.Equal(t, config{Server: server{
+			Host:    "localhost",
+			Port:    8080,
+			Mask:    255,
+			Ratio:   0.5,
+			TLS:     boolPtr(true),
+			Timeout: 30 * time.Second,
+			Since:   time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		}}, got)
+	})
+	t.Run("non zero", func(t *testing.T) {
+		v := server{
+			Host:    "example.com",
+			Port:    80,
+			Mask:    1,
+			Ratio:   1,
+			TLS:     boolPtr(false),
+			Timeout: time.Second,
+			Since:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		got, err := DeepMerge(server{}, v, WithAtomicCopy(reflect.TypeOf(time.Time{})))
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	})
+	t.Run("option precedence", func(t *testing.T) {
+		got, err := DeepMerge(server{}, server{}, WithFieldDefault(reflect.TypeOf(server{}), "Port", 9090))
+		require.NoError(t, err)
+		assert.Equal(t, 9090, got.Port)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		type invalidInt struct {
+			Port int `goalesce:"default:abc"`
+		}
+		type invalidBool struct {
+			TLS bool `goalesce:"default:maybe"`
+		}
+		type invalidDuration struct {
+			Timeout time.Duration `goalesce:"default:forever"`
+		}
+		type invalidTime struct {
+			Since *time.Time `goalesce:"default:yesterday"`
+		}
+		type unsupported struct {
+			Tags []string `goalesce:"default:a,b"`
+		}
+		_, err := DeepMerge(invalidInt{}, invalidInt{})
+		assert.EqualError(t, err, `at Port: field goalesce.invalidInt.Port: invalid default value "abc": strconv.ParseInt: parsing "abc": invalid syntax`)
+		_, err = DeepMerge(invalidBool{}, invalidBool{})
+		assert.EqualError(t, err, `at TLS: field goalesce.invalidBool.TLS: invalid default value "maybe": strconv.ParseBool: parsing "maybe": invalid syntax`)
+		_, err = DeepMerge(invalidDuration{}, invalidDuration{})
+		assert.EqualError(t, err, `at Timeout: field goalesce.invalidDuration.Timeout: invalid default value "forever": time: invalid duration "forever"`)
+		_, err = DeepMerge(invalidTime{}, invalidTime{})
+		assert.ErrorContains(t, err, `field goalesce.invalidTime.Since: invalid default value "yesterday": `)
+		_, err = DeepMerge(unsupported{}, unsupported{})
+		assert.EqualError(t, err, `at Tags: field goalesce.unsupported.Tags: invalid default value "a,b": unsupported type: []string`)
+	})
+}
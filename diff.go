@@ -0,0 +1,253 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ChangeKind is the kind of change reported by DeepDiff.
+type ChangeKind int
+
+const (
+	// ChangeModified means that the value exists on both sides, but differs.
+	ChangeModified ChangeKind = iota
+	// ChangeAdded means that the value does not exist in the first value, but exists in the second.
+	ChangeAdded
+	// ChangeRemoved means that the value exists in the first value, but not in the second.
+	ChangeRemoved
+)
+
+// String returns a human-readable name for the change kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeModified:
+		return "modified"
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// Change is a single difference reported by DeepDiff.
+type Change struct {
+	// Path is the location of the change, e.g. "Spec.Containers[2].Ports".
+	Path string
+	// Kind is the kind of change.
+	Kind ChangeKind
+	// From is a deep copy of the old value; it is nil if Kind is ChangeAdded.
+	From interface{}
+	// To is a deep copy of the new value; it is nil if Kind is ChangeRemoved.
+	To interface{}
+}
+
+// Diff is a list of changes, as returned by DeepDiff.
+type Diff []Change
+
+// DeepDiff computes the differences between the 2 values and returns them as a Diff.
+//
+// The values are compared recursively: structs field by field, maps key by key, and slices and
+// arrays index by index. Pointers and interfaces are compared by the values they point to or wrap.
+// Other values are compared with reflect.DeepEqual. Map entries are visited in a deterministic
+// order, so the returned Diff is stable across runs. Only exported fields are compared, except that
+// structs with an Equal method, e.g. time.Time, with unexported fields only, e.g. netip.Addr, or
+// with a custom copier are compared as a whole, with their Equal method if any, and with
+// reflect.DeepEqual otherwise.
+//
+// The values reported in each Change are deep copies of the original values. Options that
+// customize copy behavior are honored when creating those copies.
+//
+// This function returns an error if the values are not of the same type.
+func DeepDiff[T any](o1, o2 T, opts ...Option) (Diff, error) {
+	v1 := reflect.ValueOf(o1)
+	v2 := reflect.ValueOf(o2)
+	d := &differ{coalescer: newCoalescer(opts...), seen: make(map[[2]uintptr]bool)}
+	var diff Diff
+	if err := d.deepDiff("", v1, v2, &diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// differ computes the differences between two values. It uses the coalescer to copy the values
+// reported in each change.
+type differ struct {
+	*coalescer
+	seen map[[2]uintptr]bool
+}
+
+func (c *differ) deepDiff(path string, v1, v2 reflect.Value, diff *Diff) error {
+	if !v1.IsValid() && !v2.IsValid() {
+		return nil
+	} else if !v1.IsValid() {
+		return c.addChange(path, ChangeAdded, v1, v2, diff)
+	} else if !v2.IsValid() {
+		return c.addChange(path, ChangeRemoved, v1, v2, diff)
+	}
+	if err := checkTypesMatch(v1.Type(), v2.Type()); err != nil {
+		return err
+	}
+	switch v1.Kind() {
+	case reflect.Interface:
+		if v1.IsNil() || v2.IsNil() || v1.Elem().Type() != v2.Elem().Type() {
+			return c.diffNilable(path, v1, v2, diff)
+		}
+		return c.deepDiff(path, v1.Elem(), v2.Elem(), diff)
+	case reflect.Ptr:
+		if v1.IsNil() || v2.IsNil() {
+			return c.diffNilable(path, v1, v2, diff)
+		}
+		pair := [2]uintptr{v1.Pointer(), v2.Pointer()}
+		if pair[0] == pair[1] || c.seen[pair] {
+			return nil
+		}
+		c.seen[pair] = true
+		defer delete(c.seen, pair)
+		return c.deepDiff(path, v1.Elem(), v2.Elem(), diff)
+	case reflect.Map:
+		if v1.IsNil() || v2.IsNil() {
+			return c.diffNilable(path, v1, v2, diff)
+		}
+		return c.diffMap(path, v1, v2, diff)
+	case reflect.Struct:
+		if equal, opaque := c.structsEqual(v1, v2); opaque {
+			if !equal {
+				return c.addChange(path, ChangeModified, v1, v2, diff)
+			}
+			return nil
+		}
+		for i := 0; i < v1.NumField(); i++ {
+			field := v1.Type().Field(i)
+			if field.IsExported() {
+				if err := c.deepDiff(fieldPath(path, field.Name), v1.Field(i), v2.Field(i), diff); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if v1.IsNil() || v2.IsNil() {
+			return c.diffNilable(path, v1, v2, diff)
+		}
+		return c.diffIndexed(path, v1, v2, diff)
+	case reflect.Array:
+		return c.diffIndexed(path, v1, v2, diff)
+	default:
+		if !reflect.DeepEqual(v1.Interface(), v2.Interface()) {
+			return c.addChange(path, ChangeModified, v1, v2, diff)
+		}
+		return nil
+	}
+}
+
+// structsEqual compares 2 structs as a whole, if they cannot be compared field by field: it returns
+// true as its second value if the struct type has an Equal method, e.g. time.Time, only has
+// unexported fields, e.g. netip.Addr, or has a custom copier. Otherwise, the structs must be
+// compared field by field, and their unexported fields are ignored.
+func (c *differ) structsEqual(v1, v2 reflect.Value) (equal bool, opaque bool) {
+	t := v1.Type()
+	if method, found := t.MethodByName("Equal"); found && method.Type.NumIn() == 2 && method.Type.In(1) == t &&
+		method.Type.NumOut() == 1 && method.Type.Out(0).Kind() == reflect.Bool {
+		return v1.Method(method.Index).Call([]reflect.Value{v2})[0].Bool(), true
+	}
+	if _, found := c.typeCopier(t); found || !hasExportedFields(t) {
+		return reflect.DeepEqual(v1.Interface(), v2.Interface()), true
+	}
+	return false, false
+}
+
+func hasExportedFields(structType reflect.Type) bool {
+	for i := 0; i < structType.NumField(); i++ {
+		if structType.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// diffNilable compares values of a nilable kind when at least one of them is nil, or when they
+// are interfaces wrapping values of different types.
+func (c *differ) diffNilable(path string, v1, v2 reflect.Value, diff *Diff) error {
+	switch {
+	case v1.IsNil() && v2.IsNil():
+		return nil
+	case v1.IsNil():
+		return c.addChange(path, ChangeAdded, v1, v2, diff)
+	case v2.IsNil():
+		return c.addChange(path, ChangeRemoved, v1, v2, diff)
+	}
+	return c.addChange(path, ChangeModified, v1, v2, diff)
+}
+
+func (c *differ) diffMap(path string, v1, v2 reflect.Value, diff *Diff) error {
+	for _, k := range sortedMapKeys(v1) {
+		if !v2.MapIndex(k).IsValid() {
+			if err := c.addChange(keyPath(path, k), ChangeRemoved, v1.MapIndex(k), reflect.Value{}, diff); err != nil {
+				return err
+			}
+		} else if err := c.deepDiff(keyPath(path, k), v1.MapIndex(k), v2.MapIndex(k), diff); err != nil {
+			return err
+		}
+	}
+	for _, k := range sortedMapKeys(v2) {
+		if !v1.MapIndex(k).IsValid() {
+			if err := c.addChange(keyPath(path, k), ChangeAdded, reflect.Value{}, v2.MapIndex(k), diff); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *differ) diffIndexed(path string, v1, v2 reflect.Value, diff *Diff) error {
+	for i := 0; i < v1.Len() || i < v2.Len(); i++ {
+		var err error
+		switch {
+		case i >= v1.Len():
+			err = c.addChange(indexPath(path, i), ChangeAdded, reflect.Value{}, v2.Index(i), diff)
+		case i >= v2.Len():
+			err = c.addChange(indexPath(path, i), ChangeRemoved, v1.Index(i), reflect.Value{}, diff)
+		default:
+			err = c.deepDiff(indexPath(path, i), v1.Index(i), v2.Index(i), diff)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *differ) addChange(path string, kind ChangeKind, v1, v2 reflect.Value, diff *Diff) error {
+	change := Change{Path: path, Kind: kind}
+	if kind != ChangeAdded {
+		from, err := c.deepCopy(v1)
+		if err != nil {
+			return err
+		}
+		change.From = from.Interface()
+	}
+	if kind != ChangeRemoved {
+		to, err := c.deepCopy(v2)
+		if err != nil {
+			return err
+		}
+		change.To = to.Interface()
+	}
+	*diff = append(*diff, change)
+	return nil
+}
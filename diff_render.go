@@ -0,0 +1,100 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+// Render writes a human-readable, unified-diff-like representation of the diff to the given
+// writer. Each change is introduced by a hunk header containing its path, followed by the old value
+// prefixed with "-" (unless the change is an addition) and the new value prefixed with "+" (unless
+// the change is a removal):
+//
+//	@@ Spec.Replicas @@
+//	- 1
+//	+ 3
+//	@@ Labels["tier"] @@
+//	+ "backend"
+//
+// When colorize is true, the output is decorated with ANSI color codes, suitable for terminals.
+func (d Diff) Render(w io.Writer, colorize bool) error {
+	for _, change := range d {
+		path := change.Path
+		if path == "" {
+			path = "<root>"
+		}
+		if err := renderLine(w, colorize, ansiCyan, "@@ "+path+" @@"); err != nil {
+			return err
+		}
+		if change.Kind != ChangeAdded {
+			if err := renderLine(w, colorize, ansiRed, "- "+formatDiffValue(change.From)); err != nil {
+				return err
+			}
+		}
+		if change.Kind != ChangeRemoved {
+			if err := renderLine(w, colorize, ansiGreen, "+ "+formatDiffValue(change.To)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// String returns the uncolored rendering of the diff. See Render.
+func (d Diff) String() string {
+	var sb strings.Builder
+	_ = d.Render(&sb, false)
+	return sb.String()
+}
+
+func renderLine(w io.Writer, colorize bool, color, line string) error {
+	var err error
+	if colorize {
+		_, err = fmt.Fprintf(w, "%s%s%s\n", color, line, ansiReset)
+	} else {
+		_, err = fmt.Fprintln(w, line)
+	}
+	return err
+}
+
+// formatDiffValue formats a value reported in a change. Pointers are dereferenced and strings are
+// quoted, so that empty strings and nil values can be told apart.
+func formatDiffValue(i interface{}) string {
+	v := reflect.ValueOf(i)
+	for v.IsValid() && v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	switch {
+	case !v.IsValid():
+		return "<nil>"
+	case v.Kind() == reflect.Ptr:
+		return "<nil>"
+	case v.Kind() == reflect.String:
+		return strconv.Quote(v.String())
+	}
+	return fmt.Sprintf("%+v", v.Interface())
+}
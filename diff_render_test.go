@@ -0,0 +1,75 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write error")
+}
+
+func TestDiff_Render(t *testing.T) {
+	diff := Diff{
+		{Path: "", Kind: ChangeModified, From: 1, To: 2},
+		{Path: "Name", Kind: ChangeModified, From: "", To: "Alice"},
+		{Path: `Labels["tier"]`, Kind: ChangeAdded, To: "backend"},
+		{Path: "Age", Kind: ChangeRemoved, From: intPtr(20)},
+		{Path: "Next", Kind: ChangeRemoved, From: (*int)(nil)},
+		{Path: "Tags", Kind: ChangeAdded, To: []string{"a"}},
+	}
+	t.Run("plain", func(t *testing.T) {
+		var sb strings.Builder
+		err := diff.Render(&sb, false)
+		assert.NoError(t, err)
+		assert.Equal(t, `@@ <root> @@
+- 1
++ 2
+@@ Name @@
+- ""
++ "Alice"
+@@ Labels["tier"] @@
++ "backend"
+@@ Age @@
+- 20
+@@ Next @@
+- <nil>
+@@ Tags @@
++ [a]
+`, sb.String())
+		assert.Equal(t, sb.String(), diff.String())
+	})
+	t.Run("colorized", func(t *testing.T) {
+		var sb strings.Builder
+		err := diff[2:3].Render(&sb, true)
+		assert.NoError(t, err)
+		assert.Equal(t, "\x1b[36m@@ Labels[\"tier\"] @@\x1b[0m\n\x1b[32m+ \"backend\"\x1b[0m\n", sb.String())
+	})
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "", Diff(nil).String())
+	})
+	t.Run("write error", func(t *testing.T) {
+		assert.EqualError(t, diff[0:1].Render(failingWriter{}, false), "write error")
+		assert.EqualError(t, diff[2:3].Render(failingWriter{}, false), "write error")
+		assert.EqualError(t, diff[3:4].Render(failingWriter{}, false), "write error")
+	})
+}
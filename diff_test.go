@@ -0,0 +1,228 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeepDiff(t *testing.T) {
+	type foo struct {
+		FieldInt   int
+		FieldPtr   *int
+		FieldMap   map[string]int
+		FieldSlice []string
+		FieldItf   interface{}
+		unexported int
+	}
+	type node struct {
+		Value int
+		Next  *node
+	}
+	cyclic1 := &node{Value: 1}
+	cyclic1.Next = cyclic1
+	cyclic2 := &node{Value: 2}
+	cyclic2.Next = cyclic2
+	tests := []struct {
+		name    string
+		v1      interface{}
+		v2      interface{}
+		want    Diff
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "nils",
+			v1:   nil,
+			v2:   nil,
+			want: nil,
+		},
+		{
+			name: "untyped nil added",
+			v1:   nil,
+			v2:   1,
+			want: Diff{{Path: "", Kind: ChangeAdded, To: 1}},
+		},
+		{
+			name: "untyped nil removed",
+			v1:   1,
+			v2:   nil,
+			want: Diff{{Path: "", Kind: ChangeRemoved, From: 1}},
+		},
+		{
+			name: "equal",
+			v1:   foo{FieldInt: 1, FieldPtr: intPtr(1), FieldMap: map[string]int{"a": 1}},
+			v2:   foo{FieldInt: 1, FieldPtr: intPtr(1), FieldMap: map[string]int{"a": 1}},
+			want: nil,
+		},
+		{
+			name: "atomic modified",
+			v1:   1,
+			v2:   2,
+			want: Diff{{Path: "", Kind: ChangeModified, From: 1, To: 2}},
+		},
+		{
+			name: "struct fields",
+			v1:   foo{FieldInt: 1, unexported: 1},
+			v2:   foo{FieldInt: 2, FieldPtr: intPtr(1), unexported: 2},
+			want: Diff{
+				{Path: "FieldInt", Kind: ChangeModified, From: 1, To: 2},
+				{Path: "FieldPtr", Kind: ChangeAdded, To: intPtr(1)},
+			},
+		},
+		{
+			name: "pointers",
+			v1:   &foo{FieldPtr: intPtr(1)},
+			v2:   &foo{FieldPtr: intPtr(2)},
+			want: Diff{{Path: "FieldPtr", Kind: ChangeModified, From: 1, To: 2}},
+		},
+		{
+			name: "maps",
+			v1:   foo{FieldMap: map[string]int{"a": 1, "b": 2, "c": 3}},
+			v2:   foo{FieldMap: map[string]int{"a": 1, "b": 3, "d": 4}},
+			want: Diff{
+				{Path: `FieldMap["b"]`, Kind: ChangeModified, From: 2, To: 3},
+				{Path: `FieldMap["c"]`, Kind: ChangeRemoved, From: 3},
+				{Path: `FieldMap["d"]`, Kind: ChangeAdded, To: 4},
+			},
+		},
+		{
+			name: "nil map",
+			v1:   foo{FieldMap: map[string]int{"a": 1}},
+			v2:   foo{},
+			want: Diff{{Path: "FieldMap", Kind: ChangeRemoved, From: map[string]int{"a": 1}}},
+		},
+		{
+			name: "slices",
+			v1:   foo{FieldSlice: []string{"a", "b", "c"}},
+			v2:   foo{FieldSlice: []string{"a", "x"}},
+			want: Diff{
+				{Path: "FieldSlice[1]", Kind: ChangeModified, From: "b", To: "x"},
+				{Path: "FieldSlice[2]", Kind: ChangeRemoved, From: "c"},
+			},
+		},
+		{
+			name: "arrays",
+			v1:   [2]int{1, 2},
+			v2:   [2]int{1, 3},
+			want: Diff{{Path: "[1]", Kind: ChangeModified, From: 2, To: 3}},
+		},
+		{
+			name: "interfaces same type",
+			v1:   foo{FieldItf: foo{FieldInt: 1}},
+			v2:   foo{FieldItf: foo{FieldInt: 2}},
+			want: Diff{{Path: "FieldItf.FieldInt", Kind: ChangeModified, From: 1, To: 2}},
+		},
+		{
+			name: "interfaces different types",
+			v1:   foo{FieldItf: 1},
+			v2:   foo{FieldItf: "a"},
+			want: Diff{{Path: "FieldItf", Kind: ChangeModified, From: 1, To: "a"}},
+		},
+		{
+			name: "cycles",
+			v1:   cyclic1,
+			v2:   cyclic2,
+			want: Diff{{Path: "Value", Kind: ChangeModified, From: 1, To: 2}},
+		},
+		{
+			name:    "types mismatch",
+			v1:      1,
+			v2:      "a",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepDiff(tt.v1, tt.v2)
+			if tt.wantErr != nil {
+				tt.wantErr(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+	t.Run("copies", func(t *testing.T) {
+		v2 := foo{FieldMap: map[string]int{"a": 1}}
+		got, err := DeepDiff(foo{}, v2)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": 1}, got[0].To)
+		assertNotSame(t, v2.FieldMap, got[0].To)
+	})
+	t.Run("copy error", func(t *testing.T) {
+		_, err := DeepDiff(1, 2, withMockDeepCopyError)
+		assert.EqualError(t, err, "mock DeepCopy error")
+	})
+}
+
+func TestDeepDiff_opaqueStructs(t *testing.T) {
+	t1, t2 := time.Unix(1, 0), time.Unix(2, 0)
+	t.Run("time", func(t *testing.T) {
+		got, err := DeepDiff(t1, t2)
+		assert.NoError(t, err)
+		assert.Equal(t, Diff{{Path: "", Kind: ChangeModified, From: t1, To: t2}}, got)
+		got, err = DeepDiff(t1, t1.In(time.FixedZone("x", 3600)))
+		assert.NoError(t, err)
+		assert.Empty(t, got, "same instant")
+	})
+	t.Run("time field", func(t *testing.T) {
+		type record struct {
+			Name      string
+			UpdatedAt time.Time
+		}
+		got, err := DeepDiff(record{"a", t1}, record{"a", t2})
+		assert.NoError(t, err)
+		assert.Equal(t, Diff{{Path: "UpdatedAt", Kind: ChangeModified, From: t1, To: t2}}, got)
+	})
+	t.Run("nested time field", func(t *testing.T) {
+		type metadata struct {
+			CreatedAt *time.Time
+		}
+		type record struct {
+			Metadata metadata
+		}
+		got, err := DeepDiff(record{metadata{&t1}}, record{metadata{&t2}})
+		assert.NoError(t, err)
+		assert.Equal(t, Diff{{Path: "Metadata.CreatedAt", Kind: ChangeModified, From: t1, To: t2}}, got)
+	})
+	t.Run("unexported fields only", func(t *testing.T) {
+		a1, a2 := netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")
+		got, err := DeepDiff(a1, a2)
+		assert.NoError(t, err)
+		assert.Equal(t, Diff{{Path: "", Kind: ChangeModified, From: a1, To: a2}}, got)
+	})
+	t.Run("custom copier", func(t *testing.T) {
+		type money struct {
+			Cents int
+		}
+		copier := func(v reflect.Value) (reflect.Value, error) { return v, nil }
+		got, err := DeepDiff(money{1}, money{2}, WithTypeCopier(reflect.TypeOf(money{}), copier))
+		assert.NoError(t, err)
+		assert.Equal(t, Diff{{Path: "", Kind: ChangeModified, From: money{1}, To: money{2}}}, got)
+	})
+}
+
+func TestChangeKind_String(t *testing.T) {
+	assert.Equal(t, "modified", ChangeModified.String())
+	assert.Equal(t, "added", ChangeAdded.String())
+	assert.Equal(t, "removed", ChangeRemoved.String())
+	assert.Equal(t, "ChangeKind(42)", ChangeKind(42).String())
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const (
+	// PatchDirectiveKey is the map key holding a patch directive, e.g. `$patch: replace`.
+	PatchDirectiveKey = "$patch"
+	// RetainKeysDirectiveKey is the map key holding the list of keys to retain after the merge.
+	RetainKeysDirectiveKey = "$retainKeys"
+)
+
+const (
+	// PatchDirectiveMerge merges the map normally. This is the default directive.
+	PatchDirectiveMerge = "merge"
+	// PatchDirectiveReplace replaces the map entirely with the map holding the directive.
+	PatchDirectiveReplace = "replace"
+	// PatchDirectiveDelete deletes the map holding the directive.
+	PatchDirectiveDelete = "delete"
+)
+
+// isDirectiveMap returns true if directives are enabled and the given value is a map with string
+// keys, or an interface wrapping such a map.
+func (c *coalescer) isDirectiveMap(v reflect.Value) bool {
+	return c.patchDirectives &&
+		v.IsValid() &&
+		v.Kind() == reflect.Map &&
+		v.Type().Key().Kind() == reflect.String
+}
+
+// patchDirective returns the $patch directive of the given map, if any. An empty string is
+// returned if the map contains no such directive.
+func (c *coalescer) patchDirective(v reflect.Value) (string, error) {
+	v = unwrapInterface(v)
+	if !c.isDirectiveMap(v) {
+		return "", nil
+	}
+	directive := unwrapInterface(v.MapIndex(reflect.ValueOf(PatchDirectiveKey).Convert(v.Type().Key())))
+	if !directive.IsValid() {
+		return "", nil
+	}
+	if directive.Kind() != reflect.String {
+		return "", fmt.Errorf("%s directive must be a string, got: %s", PatchDirectiveKey, directive.Type().String())
+	}
+	switch directive.String() {
+	case PatchDirectiveMerge, PatchDirectiveReplace, PatchDirectiveDelete:
+		return directive.String(), nil
+	}
+	return "", fmt.Errorf("unknown %s directive: %s", PatchDirectiveKey, directive.String())
+}
+
+// retainKeys returns the $retainKeys directive of the given map, if any. A nil map is returned if
+// the map contains no such directive.
+func (c *coalescer) retainKeys(v reflect.Value) (map[string]bool, error) {
+	if !c.isDirectiveMap(v) {
+		return nil, nil
+	}
+	keys := unwrapInterface(v.MapIndex(reflect.ValueOf(RetainKeysDirectiveKey).Convert(v.Type().Key())))
+	if !keys.IsValid() {
+		return nil, nil
+	}
+	if keys.Kind() != reflect.Slice && keys.Kind() != reflect.Array {
+		return nil, fmt.Errorf("%s directive must be a list of strings, got: %s", RetainKeysDirectiveKey, keys.Type().String())
+	}
+	retained := make(map[string]bool, keys.Len())
+	for i := 0; i < keys.Len(); i++ {
+		key := unwrapInterface(keys.Index(i))
+		if !key.IsValid() || key.Kind() != reflect.String {
+			return nil, fmt.Errorf("%s directive must be a list of strings, got: %s", RetainKeysDirectiveKey, keys.Type().String())
+		}
+		retained[key.String()] = true
+	}
+	return retained, nil
+}
+
+// isDirectiveKey returns true if the given map key is a directive key that must never appear in
+// the merged map.
+func (c *coalescer) isDirectiveKey(k reflect.Value) bool {
+	return c.patchDirectives &&
+		k.Kind() == reflect.String &&
+		(k.String() == PatchDirectiveKey || k.String() == RetainKeysDirectiveKey)
+}
+
+// isDeleteDirective returns true if the given value is a map holding a `$patch: delete` directive.
+func (c *coalescer) isDeleteDirective(v reflect.Value) (bool, error) {
+	directive, err := c.patchDirective(v)
+	return directive == PatchDirectiveDelete, err
+}
+
+// unwrapInterface returns the value wrapped by the given interface value, or the value itself if
+// it is not an interface. A nil interface yields an invalid value.
+func unwrapInterface(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v
+}
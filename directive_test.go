@@ -0,0 +1,143 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_coalescer_patchDirectives(t *testing.T) {
+	tests := []struct {
+		name    string
+		v1      interface{}
+		v2      interface{}
+		want    interface{}
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "merge",
+			v1:   map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 3}},
+			v2:   map[string]interface{}{"b": map[string]interface{}{"$patch": "merge", "c": 4}},
+			want: map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 4, "d": 3}},
+		},
+		{
+			name: "replace",
+			v1:   map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 3}},
+			v2:   map[string]interface{}{"b": map[string]interface{}{"$patch": "replace", "c": 4}},
+			want: map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 4}},
+		},
+		{
+			name: "replace top-level",
+			v1:   map[string]interface{}{"a": 1, "b": 2},
+			v2:   map[string]interface{}{"$patch": "replace", "c": 3},
+			want: map[string]interface{}{"c": 3},
+		},
+		{
+			name: "delete",
+			v1:   map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2}},
+			v2:   map[string]interface{}{"b": map[string]interface{}{"$patch": "delete"}},
+			want: map[string]interface{}{"a": 1},
+		},
+		{
+			name: "delete top-level",
+			v1:   map[string]interface{}{"a": 1},
+			v2:   map[string]interface{}{"$patch": "delete"},
+			want: map[string]interface{}(nil),
+		},
+		{
+			name: "delete absent key",
+			v1:   map[string]interface{}{"a": 1},
+			v2:   map[string]interface{}{"b": map[string]interface{}{"$patch": "delete"}},
+			want: map[string]interface{}{"a": 1},
+		},
+		{
+			name: "directives stripped from new entries",
+			v1:   map[string]interface{}{"a": 1},
+			v2: map[string]interface{}{"b": map[string]interface{}{
+				"$patch": "replace",
+				"c":      map[string]interface{}{"$patch": "delete"},
+				"d":      2,
+			}},
+			want: map[string]interface{}{"a": 1, "b": map[string]interface{}{"d": 2}},
+		},
+		{
+			name: "retain keys",
+			v1:   map[string]interface{}{"a": 1, "b": 2, "c": 3},
+			v2:   map[string]interface{}{"$retainKeys": []interface{}{"a", "d"}, "d": 4},
+			want: map[string]interface{}{"a": 1, "d": 4},
+		},
+		{
+			name: "retain keys typed",
+			v1:   map[string]interface{}{"a": 1, "b": 2},
+			v2:   map[string]interface{}{"$retainKeys": []string{"b"}},
+			want: map[string]interface{}{"b": 2},
+		},
+		{
+			name:    "unknown directive",
+			v1:      map[string]interface{}{"a": 1},
+			v2:      map[string]interface{}{"$patch": "unknown"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "directive not a string",
+			v1:      map[string]interface{}{"a": 1},
+			v2:      map[string]interface{}{"$patch": 1},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "retain keys not a list",
+			v1:      map[string]interface{}{"a": 1},
+			v2:      map[string]interface{}{"$retainKeys": "a"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "retain keys not strings",
+			v1:      map[string]interface{}{"a": 1},
+			v2:      map[string]interface{}{"$retainKeys": []interface{}{1}},
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(WithPatchDirectives())
+			got, err := c.deepMerge(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2))
+			if tt.wantErr != nil {
+				tt.wantErr(t, err)
+				assert.False(t, got.IsValid())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got.Interface())
+			}
+		})
+	}
+	t.Run("disabled", func(t *testing.T) {
+		c := newCoalescer()
+		v1 := map[string]interface{}{"a": 1}
+		v2 := map[string]interface{}{"$patch": "replace", "b": 2}
+		got, err := c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"$patch": "replace", "a": 1, "b": 2}, got.Interface())
+	})
+	t.Run("copy", func(t *testing.T) {
+		c := newCoalescer(WithPatchDirectives())
+		v := map[string]interface{}{"$patch": "merge", "a": map[string]interface{}{"$patch": "delete"}, "b": 1}
+		got, err := c.deepCopy(reflect.ValueOf(v))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"b": 1}, got.Interface())
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"sync"
+)
+
+// dryRun collects the changes of dry-run merges into a slice, possibly from several goroutines,
+// see MergeMany.
+type dryRun struct {
+	mu  sync.Mutex
+	dst *[]Change
+}
+
+// recordDryRun records the changes that merging into v1 would make, i.e. the differences between
+// v1 and the merged value.
+func (c *coalescer) recordDryRun(v1, merged reflect.Value) error {
+	d := &differ{coalescer: c, seen: make(map[[2]uintptr]bool)}
+	var diff Diff
+	if err := d.deepDiff("", v1, merged, &diff); err != nil {
+		return err
+	}
+	c.dryRun.mu.Lock()
+	defer c.dryRun.mu.Unlock()
+	*c.dryRun.dst = append(*c.dryRun.dst, diff...)
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepMerge_dryRun(t *testing.T) {
+	type config struct {
+		Name   string
+		Port   int
+		Labels map[string]string
+		Tags   []string
+	}
+	v1 := config{Name: "a", Port: 80, Labels: map[string]string{"a": "1"}, Tags: []string{"x"}}
+	v2 := config{Port: 8080, Labels: map[string]string{"b": "2"}, Tags: []string{"y"}}
+	t.Run("changes", func(t *testing.T) {
+		var changes []Change
+		got, err := DeepMerge(v1, v2, WithDryRun(&changes), WithDefaultSliceSetUnionMerge())
+		require.NoError(t, err)
+		assert.Equal(t, config{}, got)
+		assert.Equal(t, []Change{
+			{Path: "Port", Kind: ChangeModified, From: 80, To: 8080},
+			{Path: `Labels["b"]`, Kind: ChangeAdded, To: "2"},
+			{Path: "Tags[1]", Kind: ChangeAdded, To: "y"},
+		}, changes)
+	})
+	t.Run("no changes", func(t *testing.T) {
+		var changes []Change
+		_, err := DeepMerge(v1, config{Name: "a"}, WithDryRun(&changes))
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+	})
+	t.Run("many", func(t *testing.T) {
+		var changes []Change
+		_, err := MergeMany([]Pair[config]{{First: v1, Second: config{Port: 1}}, {First: v1, Second: config{Port: 2}}}, WithDryRun(&changes))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []Change{
+			{Path: "Port", Kind: ChangeModified, From: 80, To: 1},
+			{Path: "Port", Kind: ChangeModified, From: 80, To: 2},
+		}, changes)
+	})
+	t.Run("error", func(t *testing.T) {
+		var changes []Change
+		_, err := DeepMerge[interface{}](1, "a", WithDryRun(&changes))
+		assert.EqualError(t, err, "types do not match: int != string")
+		assert.Empty(t, changes)
+	})
+}
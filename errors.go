@@ -0,0 +1,151 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrUnknownStrategy is the error wrapped by a *TagError when a field is tagged with an unknown merge
+// strategy.
+var ErrUnknownStrategy = errors.New("unknown merge strategy")
+
+// TypeMismatchError is the error returned when two values of different types cannot be merged, or
+// when a custom merger or copier returns a value of an unexpected type.
+type TypeMismatchError struct {
+	// Path is the path of the values, or empty if they are the root values.
+	Path string
+	// Type1 and Type2 are the mismatched types.
+	Type1, Type2 reflect.Type
+}
+
+func (e *TypeMismatchError) Error() string {
+	return fmt.Sprintf("types do not match: %s != %s", e.Type1.String(), e.Type2.String())
+}
+
+// CycleError is the error returned when a cycle is detected and WithErrorOnCycle is in effect.
+type CycleError struct {
+	// Path is the path of the value where the cycle was detected, or empty if it is the root value,
+	// or if the cycle was detected while copying.
+	Path string
+	// Type is the type of the pointer or interface value that points back to one of its ancestors.
+	Type reflect.Type
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%s: cycle detected", e.Type.String())
+}
+
+// TagError is the error returned when a struct field is tagged with an invalid merge strategy.
+// Unknown strategies are reported with an Err wrapping ErrUnknownStrategy.
+type TagError struct {
+	// Struct is the struct type declaring the field.
+	Struct reflect.Type
+	// Field is the name of the field.
+	Field string
+	// Strategy is the merge strategy declared in the field's tag.
+	Strategy string
+	// Err is the reason why the strategy is invalid.
+	Err error
+}
+
+func (e *TagError) Error() string {
+	return fmt.Sprintf("field %s.%s: %v", e.Struct.String(), e.Field, e.Err)
+}
+
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// LimitError is the error returned when an operation exceeds the limit set with WithMaxElements.
+type LimitError struct {
+	// Path is the path of the slice or map where the limit was exceeded, or empty if it is the root
+	// value.
+	Path string
+	// Limit is the maximum number of slice elements and map entries.
+	Limit int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("too many slice elements or map entries: limit is %d", e.Limit)
+}
+
+// UnexportedFieldError is the error returned when a struct with a non-zero unexported field is
+// copied or merged with the UnexportedFieldsError policy, see WithUnexportedFieldPolicy.
+type UnexportedFieldError struct {
+	// Path is the path of the unexported field.
+	Path string
+	// Struct is the struct type declaring the field.
+	Struct reflect.Type
+	// Field is the name of the unexported field.
+	Field string
+}
+
+func (e *UnexportedFieldError) Error() string {
+	return fmt.Sprintf("unexported field %s.%s would be lost", e.Struct.String(), e.Field)
+}
+
+// ConflictError is the error returned when a field merged with MergeStrategyMustMatch, or with
+// WithFieldMustMatch, has two non-zero values that are not deeply equal.
+type ConflictError struct {
+	// Path is the path of the conflicting values.
+	Path string
+	// First and Second are the conflicting values.
+	First, Second interface{}
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting values: %v != %v", e.First, e.Second)
+}
+
+// pathError is an error that occurred while merging the value located at a given path.
+type pathError struct {
+	path string
+	err  error
+}
+
+func (e *pathError) Error() string {
+	return fmt.Sprintf("at %s: %v", e.path, e.err)
+}
+
+func (e *pathError) Unwrap() error {
+	return e.err
+}
+
+// wrapPath wraps the given error, that occurred while merging the value located at the given path,
+// so that its message includes the path. Errors that already include a path are returned as is, so
+// that the path of the innermost value is reported. The path is also recorded in the wrapped
+// *TypeMismatchError, *CycleError or *ConflictError, if any.
+func wrapPath(path string, err error) error {
+	var pe *pathError
+	if err == nil || path == "" || errors.As(err, &pe) {
+		return err
+	}
+	var mismatch *TypeMismatchError
+	if errors.As(err, &mismatch) && mismatch.Path == "" {
+		mismatch.Path = path
+	}
+	var cycle *CycleError
+	if errors.As(err, &cycle) && cycle.Path == "" {
+		cycle.Path = path
+	}
+	var conflict *ConflictError
+	if errors.As(err, &conflict) && conflict.Path == "" {
+		conflict.Path = path
+	}
+	return &pathError{path: path, err: err}
+}
@@ -0,0 +1,237 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_wrapPath(t *testing.T) {
+	cause := errors.New("fake")
+	assert.NoError(t, wrapPath("Spec", nil))
+	assert.Same(t, cause, wrapPath("", cause))
+	wrapped := wrapPath("Spec.Ports[0]", cause)
+	assert.EqualError(t, wrapped, "at Spec.Ports[0]: fake")
+	assert.ErrorIs(t, wrapped, cause)
+	assert.Same(t, wrapped, wrapPath("Spec", wrapped))
+}
+
+func TestDeepMerge_errorPaths(t *testing.T) {
+	type container struct {
+		Ports []interface{}
+	}
+	type template struct {
+		Containers []container
+	}
+	type spec struct {
+		Template template
+	}
+	type deployment struct {
+		Spec spec
+	}
+	v1 := deployment{Spec: spec{Template: template{Containers: []container{{}, {}, {Ports: []interface{}{80}}}}}}
+	v2 := deployment{Spec: spec{Template: template{Containers: []container{{}, {}, {Ports: []interface{}{8080}}}}}}
+	t.Run("types do not match", func(t *testing.T) {
+		_, err := DeepMerge(v1, v2, WithDefaultSliceMergeByIndex(), WithTypeMerger(reflect.TypeOf([]interface{}{}), func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf("http"), nil
+		}))
+		assert.EqualError(t, err, "at Spec.Template.Containers[2].Ports: types do not match: string != []interface {}")
+	})
+	t.Run("map keys", func(t *testing.T) {
+		m1 := map[string]map[string]int{"a": {"b": 1}}
+		m2 := map[string]map[string]int{"a": {"b": 2}}
+		_, err := DeepMerge(m1, m2, WithTypeMerger(reflect.TypeOf(0), func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf("x"), nil
+		}))
+		assert.EqualError(t, err, `at ["a"]["b"]: types do not match: string != int`)
+	})
+	t.Run("custom merger error", func(t *testing.T) {
+		cause := errors.New("fake")
+		_, err := DeepMerge(v1, v2, WithTypeMerger(reflect.TypeOf(template{}), func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, cause
+		}))
+		assert.EqualError(t, err, "at Spec.Template: fake")
+		assert.ErrorIs(t, err, cause)
+	})
+	t.Run("root", func(t *testing.T) {
+		_, err := DeepMerge[interface{}](1, "a")
+		assert.EqualError(t, err, "types do not match: int != string")
+	})
+}
+
+func TestTypeMismatchError(t *testing.T) {
+	type holder struct {
+		Value int
+	}
+	_, err := DeepMerge[interface{}](1, "a")
+	var mismatch *TypeMismatchError
+	if assert.ErrorAs(t, err, &mismatch) {
+		assert.Equal(t, &TypeMismatchError{Type1: reflect.TypeOf(0), Type2: reflect.TypeOf("")}, mismatch)
+	}
+	_, err = DeepMerge(holder{Value: 1}, holder{Value: 2}, WithTypeMerger(reflect.TypeOf(0), func(v1, v2 reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf("a"), nil
+	}))
+	if assert.ErrorAs(t, err, &mismatch) {
+		assert.Equal(t, &TypeMismatchError{Path: "Value", Type1: reflect.TypeOf(""), Type2: reflect.TypeOf(0)}, mismatch)
+	}
+}
+
+func TestCycleError(t *testing.T) {
+	type cycle struct {
+		Cycle *cycle
+	}
+	v1 := &cycle{}
+	v1.Cycle = v1
+	v2 := &cycle{Cycle: &cycle{Cycle: &cycle{}}}
+	_, err := DeepMerge(v1, v2, WithErrorOnCycle())
+	var cycleErr *CycleError
+	if assert.ErrorAs(t, err, &cycleErr) {
+		assert.Equal(t, &CycleError{Path: "Cycle.Cycle", Type: reflect.TypeOf(v1)}, cycleErr)
+	}
+	_, err = DeepCopy(v1, WithErrorOnCycle())
+	if assert.ErrorAs(t, err, &cycleErr) {
+		assert.Equal(t, &CycleError{Type: reflect.TypeOf(v1)}, cycleErr)
+	}
+}
+
+func TestConflictError(t *testing.T) {
+	type team struct {
+		Name  string
+		Owner string `goalesce:"mustmatch"`
+	}
+	type org struct {
+		Teams []team `goalesce:"id:Name"`
+	}
+	v1 := org{Teams: []team{{Name: "infra", Owner: "alice"}}}
+	v2 := org{Teams: []team{{Name: "infra", Owner: "bob"}}}
+	_, err := DeepMerge(v1, v2)
+	assert.EqualError(t, err, "at Teams[0].Owner: conflicting values: alice != bob")
+	var conflict *ConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, &ConflictError{Path: "Teams[0].Owner", First: "alice", Second: "bob"}, conflict)
+	}
+	got, err := DeepMerge(v1, org{Teams: []team{{Name: "infra"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, v1, got)
+}
+
+func TestTagError(t *testing.T) {
+	type unknown struct {
+		Field int `goalesce:"unknown"`
+	}
+	type invalid struct {
+		Field int `goalesce:"append"`
+	}
+	type invalidDefault struct {
+		Field int `goalesce:"default:abc"`
+	}
+	_, err := DeepMerge(unknown{}, unknown{})
+	var tagErr *TagError
+	if assert.ErrorAs(t, err, &tagErr) {
+		assert.Equal(t, reflect.TypeOf(unknown{}), tagErr.Struct)
+		assert.Equal(t, "Field", tagErr.Field)
+		assert.Equal(t, "unknown", tagErr.Strategy)
+	}
+	assert.ErrorIs(t, err, ErrUnknownStrategy)
+	_, err = DeepMerge(invalid{}, invalid{})
+	if assert.ErrorAs(t, err, &tagErr) {
+		assert.Equal(t, reflect.TypeOf(invalid{}), tagErr.Struct)
+		assert.Equal(t, "append", tagErr.Strategy)
+		assert.EqualError(t, tagErr.Err, "append strategy is only supported for slices")
+	}
+	assert.NotErrorIs(t, err, ErrUnknownStrategy)
+	_, err = DeepMerge(invalidDefault{}, invalidDefault{})
+	if assert.ErrorAs(t, err, &tagErr) {
+		assert.Equal(t, "default:abc", tagErr.Strategy)
+	}
+}
+
+func TestDeepMerge_errorAccumulation(t *testing.T) {
+	type logging struct {
+		Level  string `goalesce:"oneof:debug|info"`
+		Format string `goalesce:"oneof:json|text"`
+	}
+	type config struct {
+		Name    string
+		Logging logging
+		Port    int `goalesce:"oneof:80|443"`
+	}
+	v1 := config{Name: "a", Logging: logging{Level: "info", Format: "json"}, Port: 80}
+	v2 := config{Name: "b", Logging: logging{Level: "trace", Format: "xml"}, Port: 8080}
+	t.Run("disabled", func(t *testing.T) {
+		got, err := DeepMerge(v1, v2)
+		assert.EqualError(t, err, "at Logging.Level: value trace is not one of: debug, info")
+		assert.Equal(t, config{}, got)
+	})
+	t.Run("enabled", func(t *testing.T) {
+		got, err := DeepMerge(v1, v2, WithErrorAccumulation())
+		assert.EqualError(t, err, "at Logging.Level: value trace is not one of: debug, info\n"+
+			"at Logging.Format: value xml is not one of: json, text\n"+
+			"at Port: value 8080 is not one of: 80, 443")
+		assert.Equal(t, config{Name: "b", Logging: logging{Level: "info", Format: "json"}, Port: 80}, got)
+	})
+	t.Run("no errors", func(t *testing.T) {
+		got, err := DeepMerge(v1, config{Name: "b", Port: 443}, WithErrorAccumulation())
+		assert.NoError(t, err)
+		assert.Equal(t, config{Name: "b", Logging: logging{Level: "info", Format: "json"}, Port: 443}, got)
+	})
+	t.Run("reused", func(t *testing.T) {
+		merger, err := NewMerger[config](WithErrorAccumulation())
+		assert.NoError(t, err)
+		_, err = merger.Merge(v1, v2)
+		assert.Error(t, err)
+		_, err = merger.Merge(v1, v1)
+		assert.NoError(t, err)
+	})
+}
+
+func TestLimitError(t *testing.T) {
+	type config struct {
+		Labels map[string]string
+		Tags   []string
+	}
+	v1 := config{Labels: map[string]string{"a": "1", "b": "2"}, Tags: []string{"a"}}
+	v2 := config{Labels: map[string]string{"c": "3"}, Tags: []string{"b", "c"}}
+	t.Run("merge within limit", func(t *testing.T) {
+		_, err := DeepMerge(v1, v2, WithMaxElements(6), WithDefaultSliceListAppendMerge())
+		assert.NoError(t, err)
+	})
+	t.Run("merge exceeding limit", func(t *testing.T) {
+		_, err := DeepMerge(v1, v2, WithMaxElements(5), WithDefaultSliceListAppendMerge())
+		assert.EqualError(t, err, "at Tags: too many slice elements or map entries: limit is 5")
+		var limitErr *LimitError
+		if assert.ErrorAs(t, err, &limitErr) {
+			assert.Equal(t, &LimitError{Path: "Tags", Limit: 5}, limitErr)
+		}
+	})
+	t.Run("copy", func(t *testing.T) {
+		_, err := DeepCopy(v1, WithMaxElements(3))
+		assert.NoError(t, err)
+		_, err = DeepCopy(v1, WithMaxElements(2))
+		assert.EqualError(t, err, "too many slice elements or map entries: limit is 2")
+	})
+	t.Run("reused", func(t *testing.T) {
+		merger, err := NewMerger[[]int](WithMaxElements(2))
+		assert.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			_, err = merger.Copy([]int{1, 2})
+			assert.NoError(t, err)
+		}
+	})
+}
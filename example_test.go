@@ -18,6 +18,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
 	"strings"
 
@@ -516,10 +517,23 @@ func ExampleWithFieldMergerProvider() {
 		fmt.Printf("DeepMerge(%+v, %+v, WithFieldMergerProvider) = %+v, %v\n", v1, v2, merged, err)
 	}
 	// output:
-	// DeepMerge({ID:1 Name:Alice Age:0}, {ID:1 Name: Age:20}, WithFieldMergerProvider) = {ID:0 Name: Age:0}, user 1 has been deleted
+	// DeepMerge({ID:1 Name:Alice Age:0}, {ID:1 Name: Age:20}, WithFieldMergerProvider) = {ID:0 Name: Age:0}, at ID: user 1 has been deleted
 	// DeepMerge({ID:2 Name:Bob Age:0}, {ID:2 Name: Age:30}, WithFieldMergerProvider) = {ID:2 Name:Bob Age:30}, <nil>
 }
 
+func ExampleDiff_Render() {
+	v1 := Movie{Name: "The Matrix", Tags: []string{"sci-fi"}}
+	v2 := Movie{Name: "The Matrix Reloaded", Tags: []string{"sci-fi", "action"}}
+	diff, _ := goalesce.DeepDiff(v1, v2)
+	_ = diff.Render(os.Stdout, false)
+	// output:
+	// @@ Name @@
+	// - "The Matrix"
+	// + "The Matrix Reloaded"
+	// @@ Tags[1] @@
+	// + "action"
+}
+
 func printPtrSlice(i interface{}) string {
 	v := reflect.ValueOf(i)
 	if v.IsNil() {
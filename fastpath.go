@@ -0,0 +1,238 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+)
+
+var (
+	stringSliceType = reflect.TypeOf([]string(nil))
+	intSliceType    = reflect.TypeOf([]int(nil))
+	stringMapType   = reflect.TypeOf(map[string]string(nil))
+	genericMapType  = reflect.TypeOf(map[string]interface{}(nil))
+)
+
+// fastScalarTypes are the types of the values that fast paths copy and merge directly, without
+// reflection. Fast paths are disabled if any of these types has a custom copier or merger.
+var fastScalarTypes = []reflect.Type{
+	reflect.TypeOf(""),
+	reflect.TypeOf(false),
+	reflect.TypeOf(0),
+	reflect.TypeOf(int64(0)),
+	reflect.TypeOf(float64(0)),
+	typeOfInterface,
+}
+
+// initFastPaths determines whether the fast paths for common container types can be used with the
+// options of this coalescer. Fast paths bypass per-value hooks, so they are disabled as soon as an
+// option needs to observe or customize individual container entries.
+func (c *coalescer) initFastPaths() {
+	c.fastCopies = !c.patchDirectives
+	for _, t := range fastScalarTypes {
+		if _, found := c.typeCopier(t); found {
+			c.fastCopies = false
+		}
+	}
+	c.fastMerges = c.fastCopies &&
+		c.mapNoNewKeys == nil &&
+		c.fieldPermission == nil &&
+		len(c.pathMergers) == 0 &&
+		len(c.fieldPathMergers) == 0 &&
+		len(c.fieldHooks) == 0 &&
+		len(c.kindMergers) == 0 &&
+		c.tracer == nil
+	for _, t := range fastScalarTypes {
+		if _, found := c.typeMerger(t); found {
+			c.fastMerges = false
+		}
+	}
+}
+
+// fastCopy copies []string, []int, map[string]string and map[string]interface{} values without
+// reflection. It returns false if the value is not of one of these types, or if fast paths are
+// disabled.
+func (c *coalescer) fastCopy(v reflect.Value) (reflect.Value, bool, error) {
+	if !c.fastCopies || !v.CanInterface() {
+		return reflect.Value{}, false, nil
+	}
+	switch v.Type() {
+	case stringSliceType, intSliceType, stringMapType, genericMapType:
+	default:
+		return reflect.Value{}, false, nil
+	}
+	if v.IsNil() {
+		return reflect.Zero(v.Type()), true, nil
+	}
+	if err := c.visitElements(v.Len()); err != nil {
+		return reflect.Value{}, true, err
+	}
+	switch s := v.Interface().(type) {
+	case []string:
+		return reflect.ValueOf(slices.Clone(s)), true, nil
+	case []int:
+		return reflect.ValueOf(slices.Clone(s)), true, nil
+	case map[string]string:
+		copied := make(map[string]string, len(s))
+		for k, e := range s {
+			copied[k] = e
+		}
+		return reflect.ValueOf(copied), true, nil
+	default:
+		copied, err := c.fastCopyGenericMap(v.Interface().(map[string]interface{}))
+		return reflect.ValueOf(copied), true, err
+	}
+}
+
+func (c *coalescer) fastCopyGenericMap(m map[string]interface{}) (map[string]interface{}, error) {
+	copied := make(map[string]interface{}, len(m))
+	for _, k := range sortedStringKeys(m) {
+		e, err := c.fastCopyGenericValue(m[k])
+		if err != nil {
+			return nil, err
+		}
+		copied[k] = e
+	}
+	return copied, nil
+}
+
+// fastMergeMap merges two non-zero map[string]string or map[string]interface{} values without
+// reflection, with the same semantics as deepMergeMap. It returns false if the maps are not of
+// one of these types, or if fast paths are disabled.
+func (c *coalescer) fastMergeMap(v1, v2 reflect.Value) (reflect.Value, bool, error) {
+	if !c.fastMerges || c.conflicts != nil || len(c.fieldPathScopes) > 0 || len(c.mapKeyMergers[v1.Type()]) > 0 || c.mapAtomicValues[v1.Type()] || !v1.CanInterface() || !v2.CanInterface() {
+		return reflect.Value{}, false, nil
+	}
+	switch v1.Type() {
+	case stringMapType:
+		m1, m2 := v1.Interface().(map[string]string), v2.Interface().(map[string]string)
+		merged := make(map[string]string, len(m1)+len(m2))
+		for k, e1 := range m1 {
+			merged[k] = e1
+		}
+		for k, e2 := range m2 {
+			if e1, found := m1[k]; !found || e2 != "" && !c.mapAddOnly && (!c.firstValuePriority || e1 == "") {
+				merged[k] = e2
+			}
+		}
+		return reflect.ValueOf(merged), true, nil
+	case genericMapType:
+		merged, err := c.fastMergeGenericMaps(v1.Interface().(map[string]interface{}), v2.Interface().(map[string]interface{}))
+		if err != nil {
+			return reflect.Value{}, true, err
+		}
+		return reflect.ValueOf(merged), true, nil
+	}
+	return reflect.Value{}, false, nil
+}
+
+func (c *coalescer) fastMergeGenericMaps(m1, m2 map[string]interface{}) (map[string]interface{}, error) {
+	merged := make(map[string]interface{}, len(m1)+len(m2))
+	for _, k := range sortedStringKeys(m1) {
+		if _, found := m2[k]; !found {
+			copied, err := c.fastCopyGenericValue(m1[k])
+			if err != nil {
+				return nil, err
+			}
+			merged[k] = copied
+		}
+	}
+	parent := c.path
+	defer func() { c.path = parent }()
+	for _, k := range sortedStringKeys(m2) {
+		e1, found := m1[k]
+		e2 := m2[k]
+		var err error
+		switch {
+		case e2 == nil && c.deletesNullEntries(genericMapType):
+			continue
+		case !found:
+			merged[k], err = c.fastCopyGenericValue(e2)
+		case c.mapAddOnly:
+			merged[k], err = c.fastCopyGenericValue(e1)
+		case isFastScalar(e1) && isFastScalar(e2):
+			// same semantics as deepMergeInterface followed by deepMergeAtomic
+			if c.firstValuePriority {
+				if e1 == nil || reflect.TypeOf(e1) == reflect.TypeOf(e2) && isZeroScalar(e1) {
+					merged[k] = e2
+				} else {
+					merged[k] = e1
+				}
+			} else if e2 == nil || reflect.TypeOf(e1) == reflect.TypeOf(e2) && isZeroScalar(e2) {
+				merged[k] = e1
+			} else {
+				merged[k] = e2
+			}
+		default:
+			c.path = keyPath(parent, reflect.ValueOf(k))
+			var mergedValue reflect.Value
+			if mergedValue, err = c.deepMergeAt(reflect.ValueOf(&e1).Elem(), reflect.ValueOf(&e2).Elem()); err == nil {
+				merged[k] = mergedValue.Interface()
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+func (c *coalescer) fastCopyGenericValue(e interface{}) (interface{}, error) {
+	if isFastScalar(e) {
+		return e, nil
+	}
+	copied, err := c.deepCopy(reflect.ValueOf(&e).Elem())
+	if err != nil {
+		return nil, err
+	}
+	return copied.Interface(), nil
+}
+
+// isFastScalar returns true if the given value is nil, or of one of fastScalarTypes.
+func isFastScalar(e interface{}) bool {
+	switch e.(type) {
+	case nil, string, bool, int, int64, float64:
+		return true
+	}
+	return false
+}
+
+func isZeroScalar(e interface{}) bool {
+	switch e := e.(type) {
+	case string:
+		return e == ""
+	case bool:
+		return !e
+	case int:
+		return e == 0
+	case int64:
+		return e == 0
+	case float64:
+		return math.Float64bits(e) == 0
+	}
+	return e == nil
+}
+
+func sortedStringKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
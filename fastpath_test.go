@@ -0,0 +1,211 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_initFastPaths(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []Option
+		wantFastCopies bool
+		wantFastMerges bool
+	}{
+		{"default", nil, true, true},
+		{"map add only", []Option{WithMapAddOnly()}, true, true},
+		{"patch directives", []Option{WithPatchDirectives()}, false, false},
+		{"string copier", []Option{WithAtomicCopy(reflect.TypeOf(""))}, false, false},
+		{"interface merger", []Option{WithAtomicMerge(typeOfInterface)}, true, false},
+		{"trace", []Option{WithTrace(io.Discard)}, true, false},
+		{"field hook", []Option{WithFieldHook(func(string, reflect.Value, reflect.Value, reflect.Value) {})}, true, false},
+		{"path merger", []Option{WithPathAtomic("Labels")}, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(tt.opts...)
+			assert.Equal(t, tt.wantFastCopies, c.fastCopies)
+			assert.Equal(t, tt.wantFastMerges, c.fastMerges)
+		})
+	}
+}
+
+func Test_coalescer_fastCopy(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+	}{
+		{"nil []string", []string(nil)},
+		{"empty []string", []string{}},
+		{"[]string", []string{"a", "b"}},
+		{"[]int", []int{1, 2}},
+		{"nil map[string]string", map[string]string(nil)},
+		{"empty map[string]string", map[string]string{}},
+		{"map[string]string", map[string]string{"a": "1", "b": ""}},
+		{"map[string]interface{}", map[string]interface{}{
+			"string": "a",
+			"number": 1.5,
+			"nil":    nil,
+			"list":   []interface{}{"a", 1.0},
+			"nested": map[string]interface{}{"b": true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.v)
+			c := newCoalescer()
+			got, done, err := c.fastCopy(v)
+			require.NoError(t, err)
+			assert.True(t, done)
+			slow := newCoalescer()
+			slow.fastCopies = false
+			want, err := slow.deepCopy(v)
+			require.NoError(t, err)
+			assert.Equal(t, want.Interface(), got.Interface())
+			assertNotSame(t, tt.v, got.Interface())
+		})
+	}
+	t.Run("nested values are copied", func(t *testing.T) {
+		nested := map[string]interface{}{"b": true}
+		got, err := DeepCopy(map[string]interface{}{"nested": nested})
+		require.NoError(t, err)
+		assert.Equal(t, nested, got["nested"])
+		assertNotSame(t, nested, got["nested"])
+	})
+	t.Run("other types", func(t *testing.T) {
+		type names []string
+		_, done, err := newCoalescer().fastCopy(reflect.ValueOf(names{"a"}))
+		assert.NoError(t, err)
+		assert.False(t, done)
+	})
+	t.Run("limit", func(t *testing.T) {
+		_, err := DeepCopy([]string{"a", "b"}, WithMaxElements(1))
+		assert.Error(t, err)
+	})
+}
+
+func Test_coalescer_fastMergeMap(t *testing.T) {
+	tests := []struct {
+		name string
+		v1   interface{}
+		v2   interface{}
+		opts []Option
+	}{
+		{
+			name: "map[string]string",
+			v1:   map[string]string{"a": "1", "b": "2", "c": "3"},
+			v2:   map[string]string{"b": "", "c": "4", "d": ""},
+		},
+		{
+			name: "map[string]string add only",
+			v1:   map[string]string{"a": "1", "b": "2"},
+			v2:   map[string]string{"b": "3", "c": "4"},
+			opts: []Option{WithMapAddOnly()},
+		},
+		{
+			name: "map[string]interface{}",
+			v1: map[string]interface{}{
+				"a": "1",
+				"b": 2.0,
+				"c": true,
+				"d": nil,
+				"e": "5",
+				"f": map[string]interface{}{"x": 1.0, "y": []interface{}{1.0}},
+				"g": "7",
+			},
+			v2: map[string]interface{}{
+				"b": 0.0,
+				"c": false,
+				"d": "4",
+				"e": nil,
+				"f": map[string]interface{}{"y": []interface{}{2.0}, "z": "z"},
+				"g": 8.0,
+				"h": "",
+			},
+		},
+		{
+			name: "map[string]string first value priority",
+			v1:   map[string]string{"a": "1", "b": "2", "c": ""},
+			v2:   map[string]string{"b": "3", "c": "4", "d": ""},
+			opts: []Option{WithFirstValuePriority()},
+		},
+		{
+			name: "map[string]interface{} first value priority",
+			v1: map[string]interface{}{
+				"a": "1",
+				"b": 0.0,
+				"c": nil,
+				"d": "4",
+				"e": map[string]interface{}{"x": 1.0},
+			},
+			v2: map[string]interface{}{
+				"a": "2",
+				"b": 3.0,
+				"c": "3",
+				"d": 4.0,
+				"e": map[string]interface{}{"x": 2.0, "y": 2.0},
+				"f": "6",
+			},
+			opts: []Option{WithFirstValuePriority()},
+		},
+		{
+			name: "map[string]interface{} null deletion",
+			v1:   map[string]interface{}{"a": "1", "b": "2", "c": map[string]interface{}{"x": 1.0, "y": 2.0}},
+			v2:   map[string]interface{}{"a": nil, "c": map[string]interface{}{"x": nil}, "d": nil},
+			opts: []Option{WithDefaultMapNullDeletion()},
+		},
+		{
+			name: "map[string]interface{} add only",
+			v1:   map[string]interface{}{"a": "1", "b": map[string]interface{}{"x": 1.0}},
+			v2:   map[string]interface{}{"a": "2", "b": map[string]interface{}{"y": 2.0}, "c": "3"},
+			opts: []Option{WithMapAddOnly()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1, v2 := reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2)
+			c := newCoalescer(tt.opts...)
+			got, done, err := c.fastMergeMap(v1, v2)
+			require.NoError(t, err)
+			assert.True(t, done)
+			slow := newCoalescer(tt.opts...)
+			slow.fastMerges = false
+			slow.fastCopies = false
+			want, err := slow.deepMerge(v1, v2)
+			require.NoError(t, err)
+			assert.Equal(t, want.Interface(), got.Interface())
+		})
+	}
+	t.Run("error paths", func(t *testing.T) {
+		v1 := map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{1.0}}}
+		v2 := map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{2.0}}}
+		_, err := DeepMerge(v1, v2, WithTypeMerger(reflect.TypeOf([]interface{}{}), func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, assert.AnError
+		}))
+		assert.EqualError(t, err, `at ["a"]["b"]: `+assert.AnError.Error())
+	})
+	t.Run("disabled", func(t *testing.T) {
+		c := newCoalescer(WithTrace(io.Discard))
+		_, done, err := c.fastMergeMap(reflect.ValueOf(map[string]string{"a": "1"}), reflect.ValueOf(map[string]string{"b": "2"}))
+		assert.NoError(t, err)
+		assert.False(t, done)
+	})
+}
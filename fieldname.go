@@ -0,0 +1,133 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldNameMatching determines how field names passed to options are matched against struct
+// fields, see WithFieldNameMatching. Values can be combined with a bitwise OR.
+type FieldNameMatching int
+
+const (
+	// FieldNameMatchExact matches field names exactly against Go field names. This is the default.
+	FieldNameMatchExact FieldNameMatching = 0
+	// FieldNameMatchCaseInsensitive matches field names case-insensitively.
+	FieldNameMatchCaseInsensitive FieldNameMatching = 1 << 0
+	// FieldNameMatchJSONTag matches field names against the names declared in json struct tags.
+	FieldNameMatchJSONTag FieldNameMatching = 1 << 1
+)
+
+// resolveFieldName resolves the given field name against the exported fields of the given struct
+// type, according to the configured FieldNameMatching. An exact match of a Go field name always
+// wins; otherwise, exactly one field must match.
+func (c *coalescer) resolveFieldName(structType reflect.Type, name string) (string, error) {
+	if c.fieldNameMatching == FieldNameMatchExact {
+		return name, nil
+	}
+	if field, found := structType.FieldByName(name); found && field.IsExported() {
+		return name, nil
+	}
+	var candidates []string
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.IsExported() && c.fieldNameMatches(field, name) {
+			candidates = append(candidates, field.Name)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("struct type %s has no field matching %s", structType.String(), name)
+	case 1:
+		return candidates[0], nil
+	}
+	return "", fmt.Errorf("struct type %s has several fields matching %s: %s", structType.String(), name, strings.Join(candidates, ", "))
+}
+
+func (c *coalescer) fieldNameMatches(field reflect.StructField, name string) bool {
+	names := []string{field.Name}
+	if c.fieldNameMatching&FieldNameMatchJSONTag != 0 {
+		if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); jsonName != "" && jsonName != "-" {
+			names = append(names, jsonName)
+		}
+	}
+	for _, n := range names {
+		if n == name || c.fieldNameMatching&FieldNameMatchCaseInsensitive != 0 && strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveFieldNames resolves the field names registered by options. Resolution errors are recorded
+// per struct type, and reported when a struct of that type is merged.
+func (c *coalescer) resolveFieldNames() {
+	if c.fieldNameMatching == FieldNameMatchExact {
+		return
+	}
+	resolveFieldKeys(c, c.fieldMergers, c.resolveFieldName)
+	resolveFieldKeys(c, c.fieldDefaults, c.resolveFieldName)
+	resolveFieldKeys(c, c.fieldAllowlists, c.resolveFieldName)
+	resolveFieldKeys(c, c.fieldPathMergers, c.resolveFieldPath)
+}
+
+func resolveFieldKeys[V any](c *coalescer, fields map[reflect.Type]map[string]V, resolve func(reflect.Type, string) (string, error)) {
+	for structType, values := range fields {
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		resolved := make(map[string]V, len(values))
+		for _, name := range names {
+			if field, err := resolve(structType, name); err != nil {
+				c.fieldNameErrors[structType] = errors.Join(c.fieldNameErrors[structType], err)
+			} else {
+				resolved[field] = values[name]
+			}
+		}
+		fields[structType] = resolved
+	}
+}
+
+// checkFieldNames returns the errors encountered while resolving field names of the given struct
+// type, if any.
+func (c *coalescer) checkFieldNames(structType reflect.Type) error {
+	return c.fieldNameErrors[structType]
+}
+
+// mergeByField is like newMergeByField, but resolves the given field names according to the
+// configured FieldNameMatching.
+func (c *coalescer) mergeByField(key string) SliceMergeKeyFunc {
+	return func(index int, elem reflect.Value) (reflect.Value, error) {
+		if structType := safeIndirect(elem).Type(); structType.Kind() == reflect.Struct {
+			fields := strings.Split(key, MergeKeySeparator)
+			for i, name := range fields {
+				field, err := c.resolveFieldName(structType, name)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				fields[i] = field
+			}
+			return newMergeByField(strings.Join(fields, MergeKeySeparator))(index, elem)
+		}
+		return newMergeByField(key)(index, elem)
+	}
+}
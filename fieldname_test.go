@@ -0,0 +1,118 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_resolveFieldName(t *testing.T) {
+	type spec struct {
+		Name     string `json:"displayName"`
+		NAME     string
+		Replicas int  `json:"replicas,omitempty"`
+		Paused   bool `json:"-"`
+		internal string
+	}
+	tests := []struct {
+		name     string
+		matching FieldNameMatching
+		field    string
+		want     string
+		wantErr  string
+	}{
+		{"exact", FieldNameMatchExact, "replicas", "replicas", ""},
+		{"exact wins", FieldNameMatchCaseInsensitive, "NAME", "NAME", ""},
+		{"case insensitive", FieldNameMatchCaseInsensitive, "REPLICAS", "Replicas", ""},
+		{"case insensitive ambiguous", FieldNameMatchCaseInsensitive, "name", "", "struct type goalesce.spec has several fields matching name: Name, NAME"},
+		{"case insensitive unexported", FieldNameMatchCaseInsensitive, "Internal", "", "struct type goalesce.spec has no field matching Internal"},
+		{"json", FieldNameMatchJSONTag, "displayName", "Name", ""},
+		{"json with options", FieldNameMatchJSONTag, "replicas", "Replicas", ""},
+		{"json case sensitive", FieldNameMatchJSONTag, "DisplayName", "", "struct type goalesce.spec has no field matching DisplayName"},
+		{"json ignored", FieldNameMatchJSONTag, "-", "", "struct type goalesce.spec has no field matching -"},
+		{"json case insensitive", FieldNameMatchJSONTag | FieldNameMatchCaseInsensitive, "DISPLAYNAME", "Name", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(WithFieldNameMatching(tt.matching))
+			got, err := c.resolveFieldName(reflect.TypeOf(spec{}), tt.field)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_coalescer_resolveFieldNames(t *testing.T) {
+	type item struct {
+		ID   string `json:"id"`
+		Size int
+	}
+	type spec struct {
+		Labels   map[string]string `json:"labels"`
+		Replicas int               `json:"replicas"`
+		Items    []item            `json:"items"`
+		Image    string
+		IMAGE    string
+	}
+	t.Run("resolved", func(t *testing.T) {
+		got, err := DeepMerge(
+			spec{Labels: map[string]string{"a": "1"}, Items: []item{{ID: "x", Size: 1}}, Image: "img1"},
+			spec{Labels: map[string]string{"b": "2"}, Items: []item{{ID: "x", Size: 2}, {ID: "y"}}, Image: "img2"},
+			WithFieldMerger(reflect.TypeOf(spec{}), "labels", func(v1, v2 reflect.Value) (reflect.Value, error) {
+				return v1, nil
+			}),
+			WithFieldDefault(reflect.TypeOf(spec{}), "REPLICAS", 3),
+			WithFieldMergeByID(reflect.TypeOf(spec{}), "items", "id"),
+			WithFieldNameMatching(FieldNameMatchJSONTag|FieldNameMatchCaseInsensitive),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, spec{Labels: map[string]string{"a": "1"}, Replicas: 3, Items: []item{{ID: "x", Size: 2}, {ID: "y"}}, Image: "img2"}, got)
+	})
+	t.Run("slice merge by id", func(t *testing.T) {
+		got, err := DeepMerge(
+			[]item{{ID: "x", Size: 1}},
+			[]item{{ID: "x", Size: 2}, {ID: "y"}},
+			WithSliceMergeByID(reflect.TypeOf([]item{}), "id"),
+			WithFieldNameMatching(FieldNameMatchJSONTag),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []item{{ID: "x", Size: 2}, {ID: "y"}}, got)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := DeepMerge(
+			spec{Image: "img1"},
+			spec{Image: "img2"},
+			WithFieldAllowlist(reflect.TypeOf(spec{}), "image", "unknown"),
+			WithFieldNameMatching(FieldNameMatchCaseInsensitive),
+		)
+		assert.EqualError(t, err, "struct type goalesce.spec has several fields matching image: Image, IMAGE\nstruct type goalesce.spec has no field matching unknown")
+		_, err = DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(spec{})}, WithFieldAllowlist(reflect.TypeOf(spec{}), "unknown"), WithFieldNameMatching(FieldNameMatchJSONTag))
+		assert.EqualError(t, err, "struct type goalesce.spec has no field matching unknown")
+	})
+	t.Run("exact", func(t *testing.T) {
+		// unknown names are ignored
+		got, err := DeepMerge(spec{Image: "img1"}, spec{Image: "img2"}, WithFieldAllowlist(reflect.TypeOf(spec{}), "image"))
+		require.NoError(t, err)
+		assert.Equal(t, spec{Image: "img1"}, got)
+	})
+}
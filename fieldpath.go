@@ -0,0 +1,99 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// fieldPathScope is a struct value of a root type for which field path mergers are registered, and
+// which is being merged.
+type fieldPathScope struct {
+	mergers map[ /* field path */ string]DeepMergeFunc
+	base    string // the path of the struct value
+}
+
+// enterFieldPathScope opens a new scope if field path mergers are registered for the given struct
+// type, whose value is being merged at the current path. The returned function closes the scope.
+func (c *coalescer) enterFieldPathScope(structType reflect.Type) func() {
+	mergers, found := c.fieldPathMergers[structType]
+	if !found {
+		return func() {}
+	}
+	c.fieldPathScopes = append(c.fieldPathScopes, fieldPathScope{mergers: mergers, base: c.path})
+	return func() {
+		c.fieldPathScopes = c.fieldPathScopes[:len(c.fieldPathScopes)-1]
+	}
+}
+
+// relativePath returns the current path relative to the given scope.
+func (c *coalescer) relativePath(scope fieldPathScope) (string, bool) {
+	if scope.base == "" {
+		return c.path, true
+	} else if c.path == scope.base {
+		return "", true
+	}
+	return strings.CutPrefix(c.path, scope.base+".")
+}
+
+// fieldPathMerger returns the merger registered for the current path, if any, relative to the
+// innermost scope that has one.
+func (c *coalescer) fieldPathMerger() (DeepMergeFunc, bool) {
+	for i := len(c.fieldPathScopes) - 1; i >= 0; i-- {
+		if path, ok := c.relativePath(c.fieldPathScopes[i]); ok {
+			if merger, found := c.fieldPathScopes[i].mergers[path]; found {
+				return merger, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// hasFieldPathMergersUnder returns true if mergers are registered for paths nested under the
+// current path, in which case the current value must be merged field by field.
+func (c *coalescer) hasFieldPathMergersUnder() bool {
+	for _, scope := range c.fieldPathScopes {
+		if path, ok := c.relativePath(scope); ok {
+			for p := range scope.mergers {
+				if path == "" || strings.HasPrefix(p, path+".") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// resolveFieldPath resolves each segment of the given field path, relative to the given root type,
+// according to the configured FieldNameMatching.
+func (c *coalescer) resolveFieldPath(rootType reflect.Type, path string) (string, error) {
+	segments := strings.Split(path, ".")
+	t := rootType
+	for i, segment := range segments {
+		if t = indirect(t); t.Kind() != reflect.Struct {
+			return "", fmt.Errorf("field path %s of struct type %s: %s is not a struct", path, rootType.String(), strings.Join(segments[:i], "."))
+		}
+		name, err := c.resolveFieldName(t, segment)
+		if err != nil {
+			return "", err
+		}
+		segments[i] = name
+		field, _ := t.FieldByName(name)
+		t = field.Type
+	}
+	return strings.Join(segments, "."), nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_fieldPathMerger(t *testing.T) {
+	type metadata struct {
+		Labels map[string]string
+	}
+	type template struct {
+		Metadata metadata
+	}
+	type spec struct {
+		Template *template
+	}
+	type deployment struct {
+		Metadata metadata
+		Spec     spec
+	}
+	type cluster struct {
+		Deployments []deployment
+	}
+	atomic := func(v1, v2 reflect.Value) (reflect.Value, error) {
+		return v2, nil
+	}
+	v1 := deployment{
+		Metadata: metadata{Labels: map[string]string{"a": "1"}},
+		Spec:     spec{Template: &template{Metadata: metadata{Labels: map[string]string{"a": "1"}}}},
+	}
+	v2 := deployment{
+		Metadata: metadata{Labels: map[string]string{"b": "2"}},
+		Spec:     spec{Template: &template{Metadata: metadata{Labels: map[string]string{"b": "2"}}}},
+	}
+	want := deployment{
+		Metadata: metadata{Labels: map[string]string{"a": "1", "b": "2"}},
+		Spec:     spec{Template: &template{Metadata: metadata{Labels: map[string]string{"b": "2"}}}},
+	}
+	t.Run("nested path", func(t *testing.T) {
+		got, err := DeepMerge(v1, v2, WithFieldMerger(reflect.TypeOf(deployment{}), "Spec.Template.Metadata.Labels", atomic))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("nested root", func(t *testing.T) {
+		got, err := DeepMerge(
+			cluster{Deployments: []deployment{v1}},
+			cluster{Deployments: []deployment{v2}},
+			WithFieldMerger(reflect.TypeOf(deployment{}), "Spec.Template.Metadata.Labels", atomic),
+			WithSliceMergeByIndex(reflect.TypeOf([]deployment{})),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, cluster{Deployments: []deployment{want}}, got)
+	})
+	t.Run("zero intermediate struct", func(t *testing.T) {
+		got, err := DeepMerge(
+			deployment{Metadata: metadata{Labels: map[string]string{"a": "1"}}},
+			deployment{Metadata: metadata{Labels: map[string]string{"b": "2"}}},
+			WithFieldMerger(reflect.TypeOf(deployment{}), "Metadata.Labels", atomic),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, deployment{Metadata: metadata{Labels: map[string]string{"b": "2"}}}, got)
+	})
+	t.Run("other root", func(t *testing.T) {
+		got, err := DeepMerge(v1.Spec, v2.Spec, WithFieldMerger(reflect.TypeOf(deployment{}), "Spec.Template.Metadata.Labels", atomic))
+		require.NoError(t, err)
+		assert.Equal(t, spec{Template: &template{Metadata: metadata{Labels: map[string]string{"a": "1", "b": "2"}}}}, got)
+	})
+	t.Run("name matching", func(t *testing.T) {
+		got, err := DeepMerge(v1, v2,
+			WithFieldMerger(reflect.TypeOf(deployment{}), "spec.template.metadata.labels", atomic),
+			WithFieldNameMatching(FieldNameMatchCaseInsensitive),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("name matching errors", func(t *testing.T) {
+		_, err := DeepMerge(v1, v2,
+			WithFieldMerger(reflect.TypeOf(deployment{}), "spec.unknown.labels", atomic),
+			WithFieldMerger(reflect.TypeOf(deployment{}), "metadata.labels.foo", atomic),
+			WithFieldNameMatching(FieldNameMatchCaseInsensitive),
+		)
+		assert.EqualError(t, err, "field path metadata.labels.foo of struct type goalesce.deployment: Metadata.Labels is not a struct\nstruct type goalesce.spec has no field matching unknown")
+	})
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"strings"
+)
+
+// genericOrigin returns an identifier for the generic type the given type is an instantiation of,
+// e.g. "example.com/pkg.List" for List[User] and List[Order]. It returns false if the type is not
+// an instantiation of a generic type.
+func genericOrigin(t reflect.Type) (string, bool) {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i > 0 {
+		return t.PkgPath() + "." + name[:i], true
+	}
+	return "", false
+}
+
+// typeCopier returns the custom copier registered for the given type, if any. Copiers registered
+// for the exact type take precedence over copiers registered for its generic origin, which take
+// precedence over copiers registered for the interfaces it implements, which take precedence over
+// the type's own deep copy methods, see WithDeepCopyMethods.
+func (c *coalescer) typeCopier(t reflect.Type) (DeepCopyFunc, bool) {
+	if copier, found := c.typeCopiers[t]; found {
+		return copier, true
+	}
+	if origin, generic := genericOrigin(t); generic {
+		if copier, found := c.genericTypeCopiers[origin]; found {
+			return copier, true
+		}
+	}
+	if copier, found := c.implementerCopier(t); found {
+		return copier, true
+	}
+	return c.deepCopyMethod(t)
+}
+
+// typeMerger returns the custom merger registered for the given type, if any. Mergers registered
+// for the exact type take precedence over mergers registered for its generic origin, which take
+// precedence over mergers registered for the interfaces it implements.
+func (c *coalescer) typeMerger(t reflect.Type) (DeepMergeFunc, bool) {
+	if merger, found := c.typeMergers[t]; found {
+		return merger, true
+	}
+	if origin, generic := genericOrigin(t); generic {
+		if merger, found := c.genericTypeMergers[origin]; found {
+			return merger, true
+		}
+	}
+	return c.implementerMerger(t)
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type genericList[T any] struct {
+	Items []T
+}
+
+type genericPair[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+func Test_genericOrigin(t *testing.T) {
+	tests := []struct {
+		name        string
+		t           reflect.Type
+		wantOrigin  string
+		wantGeneric bool
+	}{
+		{"int", reflect.TypeOf(0), "", false},
+		{"non-generic struct", reflect.TypeOf(struct{}{}), "", false},
+		{"slice of generic", reflect.TypeOf([]genericList[int]{}), "", false},
+		{"generic", reflect.TypeOf(genericList[int]{}), "github.com/adutra/goalesce.genericList", true},
+		{"generic nested", reflect.TypeOf(genericList[genericList[string]]{}), "github.com/adutra/goalesce.genericList", true},
+		{"generic 2 args", reflect.TypeOf(genericPair[string, int]{}), "github.com/adutra/goalesce.genericPair", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			origin, generic := genericOrigin(tt.t)
+			assert.Equal(t, tt.wantOrigin, origin)
+			assert.Equal(t, tt.wantGeneric, generic)
+		})
+	}
+}
+
+func Test_coalescer_typeMerger(t *testing.T) {
+	genericMerger := func(v1, v2 reflect.Value) (reflect.Value, error) { return v1, nil }
+	exactMerger := func(v1, v2 reflect.Value) (reflect.Value, error) { return v2, nil }
+	c := newCoalescer(
+		WithGenericTypeMerger(reflect.TypeOf(genericList[int]{}), genericMerger),
+		WithTypeMerger(reflect.TypeOf(genericList[string]{}), exactMerger),
+	)
+	v1 := reflect.ValueOf(genericList[bool]{Items: []bool{true}})
+	v2 := reflect.ValueOf(genericList[bool]{Items: []bool{false}})
+	merger, found := c.typeMerger(v1.Type())
+	assert.True(t, found)
+	got, _ := merger(v1, v2)
+	assert.Equal(t, v1.Interface(), got.Interface())
+	merger, found = c.typeMerger(reflect.TypeOf(genericList[string]{}))
+	assert.True(t, found)
+	got, _ = merger(v1, v2)
+	assert.Equal(t, v2.Interface(), got.Interface())
+	_, found = c.typeMerger(reflect.TypeOf(genericPair[string, int]{}))
+	assert.False(t, found)
+	_, found = c.typeMerger(reflect.TypeOf(0))
+	assert.False(t, found)
+}
+
+func Test_coalescer_typeCopier(t *testing.T) {
+	genericCopier := func(v reflect.Value) (reflect.Value, error) { return reflect.Zero(v.Type()), nil }
+	exactCopier := func(v reflect.Value) (reflect.Value, error) { return v, nil }
+	c := newCoalescer(
+		WithGenericTypeCopier(reflect.TypeOf(genericList[int]{}), genericCopier),
+		WithTypeCopier(reflect.TypeOf(genericList[string]{}), exactCopier),
+	)
+	v := reflect.ValueOf(genericList[bool]{Items: []bool{true}})
+	copier, found := c.typeCopier(v.Type())
+	assert.True(t, found)
+	got, _ := copier(v)
+	assert.Equal(t, genericList[bool]{}, got.Interface())
+	v = reflect.ValueOf(genericList[string]{Items: []string{"a"}})
+	copier, found = c.typeCopier(v.Type())
+	assert.True(t, found)
+	got, _ = copier(v)
+	assert.Equal(t, v.Interface(), got.Interface())
+	_, found = c.typeCopier(reflect.TypeOf(genericPair[string, int]{}))
+	assert.False(t, found)
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"hash/maphash"
+	"math"
+	"reflect"
+)
+
+// hashKey identifies a value whose structural hash can be memoized: either an addressable value,
+// or a value referencing some memory (pointer, map, slice).
+type hashKey struct {
+	t    reflect.Type
+	addr uintptr
+	len  int
+}
+
+// subtreeHasher computes structural hashes of values, memoizing the hashes of values that can be
+// identified by their address. It is meant to be used for the duration of a single operation, during
+// which the values being hashed are not modified.
+type subtreeHasher struct {
+	seed     maphash.Seed
+	hashes   map[hashKey]uint64
+	visiting map[hashKey]bool
+}
+
+func newSubtreeHasher() *subtreeHasher {
+	return &subtreeHasher{
+		seed:     maphash.MakeSeed(),
+		hashes:   make(map[hashKey]uint64),
+		visiting: make(map[hashKey]bool),
+	}
+}
+
+func (h *subtreeHasher) reset() {
+	clear(h.hashes)
+	clear(h.visiting)
+}
+
+// sameSubtrees returns true if the two values are deeply equal. Values of different hashes are
+// known to be different without walking them; values of equal hashes are compared with
+// reflect.DeepEqual, to rule out hash collisions.
+func (h *subtreeHasher) sameSubtrees(v1, v2 reflect.Value) bool {
+	switch v1.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Struct, reflect.Slice, reflect.Array:
+	default:
+		// not worth hashing
+		return false
+	}
+	if !v1.CanInterface() || !v2.CanInterface() {
+		return false
+	}
+	return h.hash(v1) == h.hash(v2) && reflect.DeepEqual(v1.Interface(), v2.Interface())
+}
+
+func (h *subtreeHasher) hash(v reflect.Value) uint64 {
+	key, memoizable := h.keyOf(v)
+	if memoizable {
+		if sum, found := h.hashes[key]; found {
+			return sum
+		}
+		if h.visiting[key] {
+			// cycle: the hash of the value being computed cannot be used
+			return 0
+		}
+		h.visiting[key] = true
+		defer delete(h.visiting, key)
+	}
+	var mh maphash.Hash
+	mh.SetSeed(h.seed)
+	h.write(&mh, v)
+	sum := mh.Sum64()
+	if memoizable {
+		h.hashes[key] = sum
+	}
+	return sum
+}
+
+func (h *subtreeHasher) keyOf(v reflect.Value) (hashKey, bool) {
+	if v.CanAddr() {
+		return hashKey{t: v.Type(), addr: v.UnsafeAddr()}, true
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if !v.IsNil() {
+			return hashKey{t: v.Type(), addr: v.Pointer()}, true
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			return hashKey{t: v.Type(), addr: v.Pointer(), len: v.Len()}, true
+		}
+	}
+	return hashKey{}, false
+}
+
+func (h *subtreeHasher) write(mh *maphash.Hash, v reflect.Value) {
+	var buf [8]byte
+	writeUint64 := func(u uint64) {
+		for i := range buf {
+			buf[i] = byte(u >> (8 * i))
+		}
+		_, _ = mh.Write(buf[:])
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			writeUint64(1)
+		} else {
+			writeUint64(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeUint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeUint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		writeUint64(math.Float64bits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		writeUint64(math.Float64bits(real(v.Complex())))
+		writeUint64(math.Float64bits(imag(v.Complex())))
+	case reflect.String:
+		_, _ = mh.WriteString(v.String())
+		writeUint64(uint64(v.Len()))
+	case reflect.Interface:
+		if v.IsNil() {
+			writeUint64(0)
+		} else {
+			_, _ = mh.WriteString(v.Elem().Type().String())
+			writeUint64(h.hash(v.Elem()))
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			writeUint64(0)
+		} else {
+			writeUint64(1)
+			writeUint64(h.hash(v.Elem()))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			writeUint64(h.hash(v.Field(i)))
+		}
+	case reflect.Slice, reflect.Array:
+		writeUint64(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			writeUint64(h.hash(v.Index(i)))
+		}
+	case reflect.Map:
+		// map iteration order is random: combine the entries' hashes with a commutative operation
+		var sum uint64
+		iter := v.MapRange()
+		for iter.Next() {
+			var entry maphash.Hash
+			entry.SetSeed(h.seed)
+			h.write(&entry, iter.Key())
+			h.write(&entry, iter.Value())
+			sum += entry.Sum64()
+		}
+		writeUint64(uint64(v.Len()))
+		writeUint64(sum)
+	default:
+		// channels, functions, unsafe pointers: hash their identity
+		writeUint64(uint64(v.Pointer()))
+	}
+}
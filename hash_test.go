@@ -0,0 +1,108 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hashNode struct {
+	Name     string
+	Labels   map[string]string
+	Values   []float64
+	Any      interface{}
+	Next     *hashNode
+	Children [2]*hashNode
+}
+
+func Test_subtreeHasher_hash(t *testing.T) {
+	tree := func() *hashNode {
+		return &hashNode{
+			Name:     "root",
+			Labels:   map[string]string{"a": "1", "b": "2", "c": "3"},
+			Values:   []float64{1.5, 2.5},
+			Any:      []int{1},
+			Children: [2]*hashNode{{Name: "left"}, {Name: "right"}},
+		}
+	}
+	h := newSubtreeHasher()
+	t.Run("equal", func(t *testing.T) {
+		assert.Equal(t, h.hash(reflect.ValueOf(tree())), h.hash(reflect.ValueOf(tree())))
+	})
+	modifications := map[string]func(n *hashNode){
+		"name":           func(n *hashNode) { n.Name = "other" },
+		"map value":      func(n *hashNode) { n.Labels["a"] = "2" },
+		"map key":        func(n *hashNode) { delete(n.Labels, "a"); n.Labels["d"] = "1" },
+		"slice element":  func(n *hashNode) { n.Values[1] = 3 },
+		"slice length":   func(n *hashNode) { n.Values = n.Values[:1] },
+		"interface":      func(n *hashNode) { n.Any = []int{2} },
+		"interface type": func(n *hashNode) { n.Any = []int64{1} },
+		"nil pointer":    func(n *hashNode) { n.Next = &hashNode{} },
+		"array element":  func(n *hashNode) { n.Children[1].Name = "other" },
+	}
+	for name, modify := range modifications {
+		t.Run(name, func(t *testing.T) {
+			modified := tree()
+			modify(modified)
+			assert.NotEqual(t, h.hash(reflect.ValueOf(tree())), h.hash(reflect.ValueOf(modified)))
+		})
+	}
+	t.Run("memoized", func(t *testing.T) {
+		h := newSubtreeHasher()
+		v := tree()
+		sum := h.hash(reflect.ValueOf(v))
+		assert.Equal(t, sum, h.hashes[hashKey{t: reflect.TypeOf(v), addr: reflect.ValueOf(v).Pointer()}])
+		assert.Contains(t, h.hashes, hashKey{t: reflect.TypeOf(""), addr: reflect.ValueOf(&v.Name).Pointer()})
+		assert.Equal(t, sum, h.hash(reflect.ValueOf(v)))
+	})
+	t.Run("cycle", func(t *testing.T) {
+		v := tree()
+		v.Next = v
+		assert.NotPanics(t, func() { newSubtreeHasher().hash(reflect.ValueOf(v)) })
+	})
+}
+
+func Test_subtreeHasher_sameSubtrees(t *testing.T) {
+	h := newSubtreeHasher()
+	assert.True(t, h.sameSubtrees(reflect.ValueOf(&hashNode{Name: "a"}), reflect.ValueOf(&hashNode{Name: "a"})))
+	assert.False(t, h.sameSubtrees(reflect.ValueOf(&hashNode{Name: "a"}), reflect.ValueOf(&hashNode{Name: "b"})))
+	assert.False(t, h.sameSubtrees(reflect.ValueOf("a"), reflect.ValueOf("a")), "atomic values are not hashed")
+	assert.False(t, h.sameSubtrees(reflect.ValueOf(hashNode{Name: "a"}).Field(0), reflect.ValueOf(hashNode{Name: "a"}).Field(0)))
+}
+
+func TestDeepMerge_subtreeHashing(t *testing.T) {
+	called := 0
+	counter := WithFieldMerger(reflect.TypeOf(hashNode{}), "Name", func(v1, v2 reflect.Value) (reflect.Value, error) {
+		called++
+		return v2, nil
+	})
+	v1 := &hashNode{Name: "root", Labels: map[string]string{"a": "1"}, Next: &hashNode{Name: "next", Next: &hashNode{Name: "last"}}}
+	v2 := &hashNode{Name: "root", Labels: map[string]string{"a": "2"}, Next: &hashNode{Name: "next", Next: &hashNode{Name: "last"}}}
+	got, err := DeepMerge(v1, v2, counter, WithSubtreeHashing())
+	require.NoError(t, err)
+	assert.Equal(t, v2, got)
+	assertNotSame(t, v2, got)
+	assertNotSame(t, v1.Next, got.Next)
+	// only the root was merged, the next nodes were identical
+	assert.Equal(t, 1, called)
+	called = 0
+	_, err = DeepMerge(v1, v2, counter)
+	require.NoError(t, err)
+	assert.Equal(t, 3, called)
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "reflect"
+
+// FieldHookFunc is a function called after a struct field, a map entry or a slice or array element
+// has been merged. The passed path is the location of the merged value, relative to the root
+// value, e.g. "Spec.Containers[2].Image"; v1 and v2 are the values that were merged, and merged is
+// the result. Hooks must not modify the passed values. See WithFieldHook.
+type FieldHookFunc func(path string, v1, v2, merged reflect.Value)
+
+// runFieldHooks calls the hooks registered with WithFieldHook for a value merged at the current
+// path.
+func (c *coalescer) runFieldHooks(v1, v2, merged reflect.Value) {
+	for _, hook := range c.fieldHooks {
+		hook(c.path, v1, v2, merged)
+	}
+}
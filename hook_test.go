@@ -0,0 +1,68 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepMerge_fieldHooks(t *testing.T) {
+	type user struct {
+		Name  string
+		Admin bool
+	}
+	type config struct {
+		Owner  user
+		Users  []user
+		Labels map[string]string
+	}
+	var events []string
+	hook := func(path string, v1, v2, merged reflect.Value) {
+		events = append(events, fmt.Sprintf("%s: %v + %v = %v", path, v1.Interface(), v2.Interface(), merged.Interface()))
+	}
+	t.Run("struct fields, map entries and slice elements", func(t *testing.T) {
+		events = nil
+		v1 := config{Users: []user{{Name: "alice"}}, Labels: map[string]string{"a": "1", "b": "1"}}
+		v2 := config{Owner: user{Name: "bob", Admin: true}, Users: []user{{Name: "alice", Admin: true}}, Labels: map[string]string{"a": "2", "c": "2"}}
+		_, err := DeepMerge(v1, v2, WithFieldHook(hook), WithSliceMergeByID(reflect.TypeOf([]user{}), "Name"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"Owner.Name:  + bob = bob",
+			"Owner.Admin: false + true = true",
+			"Owner: { false} + {bob true} = {bob true}",
+			"Users[0].Name: alice + alice = alice",
+			"Users[0].Admin: false + true = true",
+			"Users[0]: {alice false} + {alice true} = {alice true}",
+			"Users: [{alice false}] + [{alice true}] = [{alice true}]",
+			`Labels["a"]: 1 + 2 = 2`,
+			"Labels: map[a:1 b:1] + map[a:2 c:2] = map[a:2 b:1 c:2]",
+		}, events)
+	})
+	t.Run("several hooks", func(t *testing.T) {
+		events = nil
+		var paths []string
+		_, err := DeepMerge(user{}, user{Name: "bob"}, WithFieldHook(hook), WithFieldHook(func(path string, v1, v2, merged reflect.Value) {
+			paths = append(paths, path)
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Name:  + bob = bob", "Admin: false + false = false"}, events)
+		assert.Equal(t, []string{"Name", "Admin"}, paths)
+	})
+}
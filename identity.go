@@ -0,0 +1,52 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "reflect"
+
+// identical returns true if the two values are known to be identical without walking them: either
+// they are non-nil pointers, maps or slices referencing the same memory, or they are flat values
+// (i.e. values containing no references at all) that are equal.
+func identical(v1, v2 reflect.Value) bool {
+	switch v1.Kind() {
+	case reflect.Ptr, reflect.Map:
+		return !v1.IsNil() && v1.Pointer() == v2.Pointer()
+	case reflect.Slice:
+		return !v1.IsNil() && v1.Pointer() == v2.Pointer() && v1.Len() == v2.Len()
+	}
+	return isFlat(v1.Type()) && v1.Equal(v2)
+}
+
+// isFlat returns true if values of the given type cannot contain references to other values.
+func isFlat(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.String:
+		return true
+	case reflect.Array:
+		return isFlat(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if !isFlat(t.Field(i).Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
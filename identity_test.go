@@ -0,0 +1,113 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_identical(t *testing.T) {
+	type flat struct {
+		Name string
+		Age  int
+		Tags [2]string
+	}
+	type notFlat struct {
+		Name *string
+	}
+	p := intPtr(1)
+	m := map[string]int{"a": 1}
+	s := []int{1, 2}
+	tests := []struct {
+		name string
+		v1   interface{}
+		v2   interface{}
+		want bool
+	}{
+		{"same pointer", p, p, true},
+		{"different pointers", intPtr(1), intPtr(1), false},
+		{"nil pointers", (*int)(nil), (*int)(nil), false},
+		{"same map", m, m, true},
+		{"different maps", map[string]int{"a": 1}, map[string]int{"a": 1}, false},
+		{"same slice", s, s, true},
+		{"same slice different lengths", s, s[:1], false},
+		{"different slices", []int{1, 2}, []int{1, 2}, false},
+		{"equal ints", 1, 1, true},
+		{"different ints", 1, 2, false},
+		{"NaN", math.NaN(), math.NaN(), false},
+		{"equal flat structs", flat{"Alice", 20, [2]string{"a"}}, flat{"Alice", 20, [2]string{"a"}}, true},
+		{"different flat structs", flat{"Alice", 20, [2]string{"a"}}, flat{"Alice", 20, [2]string{"b"}}, false},
+		{"not flat structs", notFlat{}, notFlat{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, identical(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2)))
+		})
+	}
+}
+
+func Test_isFlat(t *testing.T) {
+	assert.True(t, isFlat(reflect.TypeOf(0)))
+	assert.True(t, isFlat(reflect.TypeOf("")))
+	assert.True(t, isFlat(reflect.TypeOf([2]float64{})))
+	assert.True(t, isFlat(reflect.TypeOf(struct{ A, B int }{})))
+	assert.False(t, isFlat(reflect.TypeOf(intPtr(0))))
+	assert.False(t, isFlat(reflect.TypeOf([]int{})))
+	assert.False(t, isFlat(reflect.TypeOf(map[int]int{})))
+	assert.False(t, isFlat(reflect.TypeOf([2]*int{})))
+	assert.False(t, isFlat(reflect.TypeOf(struct{ A interface{} }{})))
+}
+
+func TestDeepMerge_identityShortCircuit(t *testing.T) {
+	type user struct {
+		Name string
+		Tags []string
+	}
+	t.Run("same pointer", func(t *testing.T) {
+		called := 0
+		counter := WithFieldMerger(reflect.TypeOf(user{}), "Name", func(v1, v2 reflect.Value) (reflect.Value, error) {
+			called++
+			return v2, nil
+		})
+		v := &user{Name: "Alice", Tags: []string{"a"}}
+		got, err := DeepMerge(v, v, counter, WithIdentityShortCircuit())
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+		assertNotSame(t, v, got)
+		assertNotSame(t, v.Tags, got.Tags)
+		assert.Equal(t, 0, called)
+		_, err = DeepMerge(v, v, counter)
+		require.NoError(t, err)
+		assert.Equal(t, 1, called)
+	})
+	t.Run("equal flat values", func(t *testing.T) {
+		type point struct {
+			X, Y int
+		}
+		got, err := DeepMerge(point{1, 2}, point{1, 2}, WithIdentityShortCircuit())
+		require.NoError(t, err)
+		assert.Equal(t, point{1, 2}, got)
+	})
+	t.Run("different values", func(t *testing.T) {
+		got, err := DeepMerge(&user{Name: "Alice"}, &user{Tags: []string{"b"}}, WithIdentityShortCircuit())
+		require.NoError(t, err)
+		assert.Equal(t, &user{Name: "Alice", Tags: []string{"b"}}, got)
+	})
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"sync"
+)
+
+var immutableTypes sync.Map // map[reflect.Type]bool
+
+// RegisterImmutableType declares, for all subsequent operations, that values of the given type are
+// immutable and can be shared instead of deep-copied. This is equivalent to passing
+// WithImmutableType to every operation. This function is safe for concurrent use, but is typically
+// called from an init function, e.g. for types such as *template.Template or *regexp.Regexp.
+//
+// Options passed to an operation take precedence over the types registered with this function: for
+// example, WithTypeCopier can be used to force the deep copy of a registered type.
+func RegisterImmutableType(t reflect.Type) {
+	immutableTypes.Store(t, true)
+}
+
+// UnregisterImmutableType reverts the effect of RegisterImmutableType for the given type.
+func UnregisterImmutableType(t reflect.Type) {
+	immutableTypes.Delete(t)
+}
+
+// registerImmutableTypes applies the types registered with RegisterImmutableType to this
+// coalescer. It must be called before options are applied, so that options can override them.
+func (c *coalescer) registerImmutableTypes() {
+	immutableTypes.Range(func(t, _ interface{}) bool {
+		c.registerImmutableType(t.(reflect.Type))
+		return true
+	})
+}
+
+// registerImmutableType installs atomic semantics for both copies and merges of the given type:
+// immutable values are shared when copied, and considered indivisible when merged.
+func (c *coalescer) registerImmutableType(t reflect.Type) {
+	c.typeCopiers[t] = c.deepCopyAtomic
+	c.typeMergers[t] = c.deepMergeAtomic
+}
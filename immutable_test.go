@@ -0,0 +1,67 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterImmutableType(t *testing.T) {
+	type template struct {
+		source string
+	}
+	type config struct {
+		Template *template
+		Name     string
+	}
+	templateType := reflect.TypeOf(&template{})
+	RegisterImmutableType(templateType)
+	defer UnregisterImmutableType(templateType)
+	v1 := config{Template: &template{"a"}, Name: "v1"}
+	v2 := config{Template: &template{"b"}}
+	t.Run("copy", func(t *testing.T) {
+		got, err := DeepCopy(v1)
+		require.NoError(t, err)
+		assert.Same(t, v1.Template, got.Template)
+		assert.Equal(t, "v1", got.Name)
+	})
+	t.Run("merge", func(t *testing.T) {
+		got, err := DeepMerge(v1, v2)
+		require.NoError(t, err)
+		assert.Same(t, v2.Template, got.Template)
+		assert.Equal(t, "v1", got.Name)
+		got, err = DeepMerge(v1, config{})
+		require.NoError(t, err)
+		assert.Same(t, v1.Template, got.Template)
+	})
+	t.Run("overridden by options", func(t *testing.T) {
+		got, err := DeepCopy(v1, WithTypeCopier(templateType, func(v reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf(&template{v.Interface().(*template).source}), nil
+		}))
+		require.NoError(t, err)
+		assert.NotSame(t, v1.Template, got.Template)
+		assert.Equal(t, "a", got.Template.source)
+	})
+	t.Run("unregistered", func(t *testing.T) {
+		UnregisterImmutableType(templateType)
+		c := newCoalescer()
+		_, found := c.typeCopiers[templateType]
+		assert.False(t, found)
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+)
+
+// interfaceCopier is a custom copier registered for all the types implementing an interface.
+type interfaceCopier struct {
+	ifaceType reflect.Type
+	copier    DeepCopyFunc
+}
+
+// interfaceMerger is a custom merger registered for all the types implementing an interface.
+type interfaceMerger struct {
+	ifaceType reflect.Type
+	merger    DeepMergeFunc
+}
+
+// implementerCopier returns the custom copier registered for the first interface implemented by the
+// given type, if any. Interface types themselves are not matched, so that the copier applies to the
+// dynamic types of interface values.
+func (c *coalescer) implementerCopier(t reflect.Type) (DeepCopyFunc, bool) {
+	if t.Kind() == reflect.Interface {
+		return nil, false
+	}
+	for _, ic := range c.interfaceCopiers {
+		if t.Implements(ic.ifaceType) {
+			return ic.copier, true
+		}
+	}
+	return nil, false
+}
+
+// implementerMerger is the equivalent of implementerCopier for custom mergers.
+func (c *coalescer) implementerMerger(t reflect.Type) (DeepMergeFunc, bool) {
+	if t.Kind() == reflect.Interface {
+		return nil, false
+	}
+	for _, im := range c.interfaceMergers {
+		if t.Implements(im.ifaceType) {
+			return im.merger, true
+		}
+	}
+	return nil, false
+}
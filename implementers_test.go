@@ -0,0 +1,102 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type resource interface {
+	Kind() string
+}
+
+type configMap struct {
+	Data map[string]string
+}
+
+func (configMap) Kind() string { return "ConfigMap" }
+
+type secret struct {
+	Data map[string][]byte
+}
+
+func (*secret) Kind() string { return "Secret" }
+
+var resourceType = reflect.TypeOf((*resource)(nil)).Elem()
+
+func keepFirst(v1, _ reflect.Value) (reflect.Value, error) {
+	return v1, nil
+}
+
+func Test_coalescer_implementerMerger(t *testing.T) {
+	stringerType := reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	c := newCoalescer(WithInterfaceMerger(resourceType, keepFirst), WithInterfaceMerger(stringerType, deepMergeExcluded))
+	_, found := c.implementerMerger(reflect.TypeOf(configMap{}))
+	assert.True(t, found)
+	_, found = c.implementerMerger(reflect.TypeOf(&secret{}))
+	assert.True(t, found)
+	_, found = c.implementerMerger(reflect.TypeOf(secret{}))
+	assert.False(t, found, "pointer receiver")
+	_, found = c.implementerMerger(resourceType)
+	assert.False(t, found, "interface type itself")
+	_, found = c.implementerMerger(reflect.TypeOf(0))
+	assert.False(t, found)
+}
+
+func TestDeepMerge_interfaceMerger(t *testing.T) {
+	type inventory struct {
+		Resources []resource
+		Main      resource
+		ConfigMap configMap
+	}
+	v1 := inventory{
+		Resources: []resource{configMap{map[string]string{"a": "1"}}},
+		Main:      &secret{map[string][]byte{"a": []byte("1")}},
+		ConfigMap: configMap{map[string]string{"a": "1"}},
+	}
+	v2 := inventory{
+		Resources: []resource{configMap{map[string]string{"b": "2"}}},
+		Main:      &secret{map[string][]byte{"b": []byte("2")}},
+		ConfigMap: configMap{map[string]string{"b": "2"}},
+	}
+	got, err := DeepMerge(v1, v2, WithInterfaceMerger(resourceType, keepFirst), WithSliceMergeByIndex(reflect.TypeOf([]resource{})))
+	require.NoError(t, err)
+	assert.Equal(t, v1, got)
+	t.Run("type merger takes precedence", func(t *testing.T) {
+		got, err := DeepMerge(v1, v2,
+			WithInterfaceMerger(resourceType, keepFirst),
+			WithTypeMerger(reflect.TypeOf(configMap{}), func(v1, v2 reflect.Value) (reflect.Value, error) { return v2, nil }))
+		require.NoError(t, err)
+		assert.Equal(t, inventory{Resources: v2.Resources, Main: v1.Main, ConfigMap: v2.ConfigMap}, got)
+	})
+}
+
+func TestDeepCopy_interfaceCopier(t *testing.T) {
+	type inventory struct {
+		Main resource
+		Data map[string]string
+	}
+	v := inventory{Main: configMap{map[string]string{"a": "1"}}, Data: map[string]string{"a": "1"}}
+	got, err := DeepCopy(v, WithInterfaceCopier(resourceType, func(v reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(configMap{map[string]string{"copied": "true"}}), nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, inventory{Main: configMap{map[string]string{"copied": "true"}}, Data: map[string]string{"a": "1"}}, got)
+}
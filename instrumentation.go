@@ -0,0 +1,73 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "time"
+
+// Operation is the kind of operation reported to an Instrumentation.
+type Operation string
+
+const (
+	// OperationMerge denotes a merge, e.g. DeepMerge.
+	OperationMerge Operation = "merge"
+	// OperationCopy denotes a copy, e.g. DeepCopy.
+	OperationCopy Operation = "copy"
+)
+
+// OperationStats holds the statistics of a completed copy or merge, see Instrumentation.
+type OperationStats struct {
+	// Operation is the kind of the operation.
+	Operation Operation
+	// ValuesMerged is the number of values merged, including nested values.
+	ValuesMerged int
+	// ValuesCopied is the number of values copied, including nested values, and values copied
+	// during a merge.
+	ValuesCopied int
+	// CyclesDetected is the number of cycles detected.
+	CyclesDetected int
+	// Duration is the duration of the operation.
+	Duration time.Duration
+	// Err is the error returned by the operation, if any.
+	Err error
+}
+
+// Instrumentation receives the statistics of copies and merges, e.g. to expose them as metrics.
+// Implementations must be safe for concurrent use, see MergeMany. See WithInstrumentation.
+type Instrumentation interface {
+	// OperationCompleted is called once per operation, when it completes.
+	OperationCompleted(stats OperationStats)
+}
+
+// InstrumentationFunc is a function that implements Instrumentation.
+type InstrumentationFunc func(stats OperationStats)
+
+// OperationCompleted calls f(stats).
+func (f InstrumentationFunc) OperationCompleted(stats OperationStats) {
+	f(stats)
+}
+
+// completeOperation reports the statistics of the completed operation, started at the given time,
+// if WithInstrumentation is in effect, and resets them.
+func (c *coalescer) completeOperation(op Operation, start time.Time, err error) {
+	stats := c.stats
+	c.stats = OperationStats{}
+	if c.instrumentation == nil {
+		return
+	}
+	stats.Operation = op
+	stats.Duration = time.Since(start)
+	stats.Err = err
+	c.instrumentation.OperationCompleted(stats)
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingInstrumentation struct {
+	mu    sync.Mutex
+	stats []OperationStats
+}
+
+func (r *recordingInstrumentation) OperationCompleted(stats OperationStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stats.Duration = 0
+	r.stats = append(r.stats, stats)
+}
+
+func TestWithInstrumentation_operations(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	t.Run("merge", func(t *testing.T) {
+		instrumentation := &recordingInstrumentation{}
+		_, err := DeepMerge(node{Name: "a", Next: &node{Name: "b"}}, node{Name: "c", Next: &node{}}, WithInstrumentation(instrumentation))
+		require.NoError(t, err)
+		assert.Equal(t, []OperationStats{{Operation: OperationMerge, ValuesMerged: 4, ValuesCopied: 4}}, instrumentation.stats)
+	})
+	t.Run("copy", func(t *testing.T) {
+		instrumentation := &recordingInstrumentation{}
+		cycle := &node{Name: "a"}
+		cycle.Next = cycle
+		_, err := DeepCopy(cycle, WithInstrumentation(instrumentation))
+		require.NoError(t, err)
+		assert.Equal(t, []OperationStats{{Operation: OperationCopy, ValuesCopied: 7, CyclesDetected: 1}}, instrumentation.stats)
+	})
+	t.Run("error", func(t *testing.T) {
+		instrumentation := &recordingInstrumentation{}
+		_, err := DeepMerge[interface{}](1, "a", WithInstrumentation(instrumentation))
+		require.Error(t, err)
+		assert.Equal(t, []OperationStats{{Operation: OperationMerge, Err: err}}, instrumentation.stats)
+	})
+	t.Run("many", func(t *testing.T) {
+		instrumentation := &recordingInstrumentation{}
+		_, err := MergeMany([]Pair[int]{{First: 1, Second: 2}, {First: 3, Second: 4}}, WithInstrumentation(instrumentation), WithParallelism(2))
+		require.NoError(t, err)
+		assert.Equal(t, []OperationStats{
+			{Operation: OperationMerge, ValuesMerged: 1, ValuesCopied: 1},
+			{Operation: OperationMerge, ValuesMerged: 1, ValuesCopied: 1},
+		}, instrumentation.stats)
+	})
+	t.Run("func", func(t *testing.T) {
+		var got []OperationStats
+		_, err := DeepCopy(1, WithInstrumentation(InstrumentationFunc(func(stats OperationStats) {
+			got = append(got, stats)
+		})))
+		require.NoError(t, err)
+		if assert.Len(t, got, 1) {
+			assert.Equal(t, OperationCopy, got[0].Operation)
+			assert.Equal(t, 1, got[0].ValuesCopied)
+		}
+	})
+}
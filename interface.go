@@ -17,14 +17,40 @@ package goalesce
 import "reflect"
 
 func (c *coalescer) deepMergeInterface(v1, v2 reflect.Value) (reflect.Value, error) {
-	if value, done := checkZero(v1, v2); done {
+	if value, done := checkZero(v1, v2); done && !c.mustCheckPermissions(v2) {
+		c.traceZeroShortcut(v1)
 		return c.deepCopy(value)
 	}
-	if v1.Elem().Type() != v2.Elem().Type() {
+	target1 := v1.Elem()
+	if v1.IsNil() {
+		target1 = reflect.Zero(v2.Elem().Type())
+	}
+	if target1.Type() != v2.Elem().Type() {
 		// the two interfaces are implemented by different runtime types, so we can't merge them
+		if c.firstValuePriority && !v1.IsNil() {
+			return c.deepCopy(v1)
+		}
+		if err := c.reportConflict(v1, v2); err != nil {
+			return reflect.Value{}, err
+		}
+		return c.deepCopy(v2)
+	}
+	if c.checkCycle(v1) {
+		if c.errorOnCycle {
+			return reflect.Value{}, &CycleError{Type: target1.Type()}
+		}
 		return c.deepCopy(v2)
 	}
-	mergedTarget, err := c.deepMerge(v1.Elem(), v2.Elem())
+	if !sameCycleKey(v1, v2) && c.checkCycle(v2) {
+		if c.errorOnCycle {
+			return reflect.Value{}, &CycleError{Type: target1.Type()}
+		}
+		c.unsee(v1) // because checkCycle(v1) was called
+		return c.deepCopy(v1)
+	}
+	defer c.unsee(v1)
+	defer c.unsee(v2)
+	mergedTarget, err := c.deepMerge(target1, v2.Elem())
 	if err != nil {
 		return reflect.Value{}, err
 	}
@@ -37,6 +63,13 @@ func (c *coalescer) deepCopyInterface(v reflect.Value) (reflect.Value, error) {
 	if v.IsZero() {
 		return reflect.Zero(v.Type()), nil
 	}
+	if c.checkCycle(v) {
+		if c.errorOnCycle {
+			return reflect.Value{}, &CycleError{Type: v.Elem().Type()}
+		}
+		return reflect.Zero(v.Type()), nil
+	}
+	defer c.unsee(v)
 	copied := reflect.New(v.Type())
 	copiedTarget, err := c.deepCopy(v.Elem())
 	if err != nil {
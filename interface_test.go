@@ -159,6 +159,35 @@ func Test_coalescer_deepMergeInterface(t *testing.T) {
 		assert.Equal(t, &Goose{""}, got.Interface())
 		assert.NoError(t, err)
 	})
+	t.Run("different runtime types first value priority", func(t *testing.T) {
+		var v1 Bird = &Duck{"Donald"}
+		var v2 Bird = &Goose{"Scrooge"}
+		c := newCoalescer(WithFirstValuePriority())
+		got, err := c.deepMergeInterface(reflect.ValueOf(&v1).Elem(), reflect.ValueOf(&v2).Elem())
+		assert.Equal(t, &Duck{"Donald"}, got.Interface())
+		assert.NoError(t, err)
+	})
+	type node struct {
+		Name string
+		Any  interface{}
+	}
+	t.Run("cycle", func(t *testing.T) {
+		v1 := &node{Name: "a"}
+		v1.Any = v1
+		v2 := &node{Name: "b"}
+		v2.Any = v2
+		got, err := DeepMerge(v1, v2)
+		assert.Equal(t, &node{Name: "b", Any: &node{Name: "b"}}, got)
+		assert.NoError(t, err)
+		_, err = DeepMerge(v1, v2, WithErrorOnCycle())
+		assert.EqualError(t, err, "at Any.Any: *goalesce.node: cycle detected")
+	})
+	t.Run("same pointer", func(t *testing.T) {
+		shared := &node{Name: "a", Any: &node{Name: "b"}}
+		got, err := DeepMerge(node{Any: shared}, node{Any: shared}, WithErrorOnCycle())
+		assert.Equal(t, node{Any: &node{Name: "a", Any: &node{Name: "b"}}}, got)
+		assert.NoError(t, err)
+	})
 }
 
 func Test_coalescer_deepCopyInterface(t *testing.T) {
@@ -208,4 +237,24 @@ func Test_coalescer_deepCopyInterface(t *testing.T) {
 			}
 		})
 	}
+	type node struct {
+		Name string
+		Any  interface{}
+	}
+	t.Run("cycle", func(t *testing.T) {
+		v := &node{Name: "a"}
+		v.Any = v
+		got, err := DeepCopy(v)
+		assert.Equal(t, &node{Name: "a", Any: &node{Name: "a"}}, got)
+		assert.NoError(t, err)
+		_, err = DeepCopy(v, WithErrorOnCycle())
+		assert.EqualError(t, err, "*goalesce.node: cycle detected")
+	})
+	t.Run("shared pointers", func(t *testing.T) {
+		shared := &node{Name: "a", Any: &node{Name: "b"}}
+		v := []interface{}{shared, shared}
+		got, err := DeepCopy(v, WithErrorOnCycle())
+		assert.Equal(t, []interface{}{&node{Name: "a", Any: &node{Name: "b"}}, &node{Name: "a", Any: &node{Name: "b"}}}, got)
+		assert.NoError(t, err)
+	})
 }
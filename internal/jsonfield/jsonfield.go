@@ -0,0 +1,226 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonfield resolves JSON object members against Go values, following the rules of
+// encoding/json for struct fields and map keys.
+package jsonfield
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Field returns the field of the given struct value with the given JSON name, following the rules
+// of encoding/json: an exact match of the name is preferred, but a case-insensitive match is also
+// accepted, and fields promoted from embedded structs are resolved with the dominance rules of Go,
+// amended by json tags. If alloc is true, nil embedded pointers are allocated; otherwise, fields
+// promoted through nil embedded pointers are reported as not found.
+func Field(v reflect.Value, name string, alloc bool) (reflect.Value, bool) {
+	index, found := fieldIndex(jsonFields(v.Type()), name)
+	if !found {
+		return reflect.Value{}, false
+	}
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				// like encoding/json, don't allocate pointers to unexported embedded structs
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldIndex returns the index of the field with the given JSON name, or of the first field whose
+// JSON name is equal to it under Unicode case-folding.
+func fieldIndex(fields []jsonField, name string) ([]int, bool) {
+	for _, field := range fields {
+		if field.name == name {
+			return field.index, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(field.name, name) {
+			return field.index, true
+		}
+	}
+	return nil, false
+}
+
+// jsonField is a struct field as seen by encoding/json.
+type jsonField struct {
+	name   string
+	tagged bool
+	index  []int
+}
+
+// jsonFields returns the fields of the given struct type as seen by encoding/json, in index order.
+// Fields of embedded structs that are not tagged are promoted, breadth first; among the fields with
+// the same JSON name, the shallowest one wins, unless there are several at that depth, in which
+// case the tagged one wins; if there is no single winner, all of them are dropped.
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	type embedded struct {
+		typ   reflect.Type
+		index []int
+	}
+	next := []embedded{{typ: t}}
+	var count, nextCount map[reflect.Type]int
+	visited := make(map[reflect.Type]bool)
+	for len(next) > 0 {
+		current := next
+		next = nil
+		count, nextCount = nextCount, make(map[reflect.Type]int)
+		for _, e := range current {
+			if visited[e.typ] {
+				continue
+			}
+			visited[e.typ] = true
+			for i := 0; i < e.typ.NumField(); i++ {
+				field := e.typ.Field(i)
+				if field.Anonymous {
+					if !field.IsExported() && indirect(field.Type).Kind() != reflect.Struct {
+						continue
+					}
+				} else if !field.IsExported() {
+					continue
+				}
+				name, tagged := jsonName(field)
+				if name == "-" && !tagged {
+					continue
+				}
+				index := make([]int, len(e.index)+1)
+				copy(index, e.index)
+				index[len(e.index)] = i
+				typ := field.Type
+				if typ.Name() == "" && typ.Kind() == reflect.Ptr {
+					typ = typ.Elem()
+				}
+				if tagged || !field.Anonymous || typ.Kind() != reflect.Struct {
+					fields = append(fields, jsonField{name: name, tagged: tagged, index: index})
+					if count[e.typ] > 1 {
+						// the same struct is embedded several times at this depth: add a duplicate
+						// so that its fields annihilate each other
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+				nextCount[typ]++
+				if nextCount[typ] == 1 {
+					next = append(next, embedded{typ: typ, index: index})
+				}
+			}
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].name != fields[j].name {
+			return fields[i].name < fields[j].name
+		}
+		if len(fields[i].index) != len(fields[j].index) {
+			return len(fields[i].index) < len(fields[j].index)
+		}
+		if fields[i].tagged != fields[j].tagged {
+			return fields[i].tagged
+		}
+		return lessIndex(fields[i].index, fields[j].index)
+	})
+	dominant := fields[:0]
+	for i, n := 0, 0; i < len(fields); i += n {
+		for n = 1; i+n < len(fields) && fields[i+n].name == fields[i].name; n++ {
+		}
+		if n > 1 && len(fields[i].index) == len(fields[i+1].index) && fields[i].tagged == fields[i+1].tagged {
+			continue
+		}
+		dominant = append(dominant, fields[i])
+	}
+	sort.Slice(dominant, func(i, j int) bool {
+		return lessIndex(dominant[i].index, dominant[j].index)
+	})
+	return dominant
+}
+
+// lessIndex compares 2 field indices in declaration order.
+func lessIndex(x, y []int) bool {
+	for k, xk := range x {
+		if k >= len(y) {
+			return false
+		}
+		if xk != y[k] {
+			return xk < y[k]
+		}
+	}
+	return len(x) < len(y)
+}
+
+// jsonName returns the JSON name of the given field, and whether the name was declared in a json
+// struct tag. Fields ignored by encoding/json are named "-" and are not tagged.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag, found := field.Tag.Lookup("json")
+	if tag == "-" {
+		return "-", false
+	}
+	if found {
+		if name := strings.Split(tag, ",")[0]; name != "" {
+			return name, true
+		}
+	}
+	return field.Name, false
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// MapKey parses the given token into a map key of the given type, following the rules of
+// encoding/json for map keys.
+func MapKey(t reflect.Type, token string) (reflect.Value, error) {
+	key := reflect.New(t)
+	if unmarshaler, ok := key.Interface().(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText([]byte(token)); err != nil {
+			return reflect.Value{}, err
+		}
+		return key.Elem(), nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		key.Elem().SetString(token)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(token, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid key %s: %w", token, err)
+		}
+		key.Elem().SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := strconv.ParseUint(token, 10, t.Bits())
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("invalid key %s: %w", token, err)
+		}
+		key.Elem().SetUint(u)
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported map key type: %s", t.String())
+	}
+	return key.Elem(), nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonfield
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Inner struct {
+	Name string
+	ID   int `json:"id"`
+}
+
+type Other struct {
+	Name  string
+	Count int
+}
+
+type Tagged struct {
+	Count int `json:"Count"`
+}
+
+type outer struct {
+	Inner
+	*Other
+	Tagged
+	Age    int
+	Nested Inner  `json:"nested"`
+	Hidden string `json:"-"`
+	Dash   string `json:"-,"`
+	ID     string `json:"id"`
+}
+
+func TestField(t *testing.T) {
+	tests := []struct {
+		name      string
+		jsonName  string
+		wantIndex []int
+		wantFound bool
+	}{
+		{name: "exact", jsonName: "Age", wantIndex: []int{3}, wantFound: true},
+		{name: "case-insensitive", jsonName: "age", wantIndex: []int{3}, wantFound: true},
+		{name: "case-insensitive upper", jsonName: "NESTED", wantIndex: []int{4}, wantFound: true},
+		{name: "shallowest wins", jsonName: "id", wantIndex: []int{7}, wantFound: true},
+		{name: "tagged wins at same depth", jsonName: "Count", wantIndex: []int{2, 0}, wantFound: true},
+		{name: "ambiguous at same depth", jsonName: "Name", wantFound: false},
+		{name: "ignored", jsonName: "Hidden", wantFound: false},
+		{name: "dash", jsonName: "-", wantIndex: []int{6}, wantFound: true},
+		{name: "tagged struct not promoted", jsonName: "nested.Name", wantFound: false},
+		{name: "unknown", jsonName: "foo", wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := reflect.ValueOf(&outer{Other: &Other{}}).Elem()
+			got, found := Field(v, tt.jsonName, false)
+			assert.Equal(t, tt.wantFound, found)
+			if tt.wantFound {
+				assert.Equal(t, v.FieldByIndex(tt.wantIndex).Addr().Pointer(), got.Addr().Pointer())
+			}
+		})
+	}
+	t.Run("same as encoding/json", func(t *testing.T) {
+		var want outer
+		err := json.Unmarshal([]byte(`{"age":1,"NESTED":{"name":"n"},"id":"x","Count":2,"Name":"y","-":"z"}`), &want)
+		assert.NoError(t, err)
+		var got outer
+		for name, value := range map[string]interface{}{"age": 1, "NESTED": Inner{Name: "n"}, "id": "x", "Count": 2, "Name": "y", "-": "z"} {
+			if field, found := Field(reflect.ValueOf(&got).Elem(), name, true); found {
+				field.Set(reflect.ValueOf(value))
+			}
+		}
+		assert.Equal(t, want, got)
+	})
+	t.Run("nil embedded pointer", func(t *testing.T) {
+		v := reflect.ValueOf(&struct{ *Other }{}).Elem()
+		_, found := Field(v, "Count", false)
+		assert.False(t, found)
+		field, found := Field(v, "count", true)
+		assert.True(t, found)
+		field.SetInt(1)
+		assert.Equal(t, 1, v.Field(0).Elem().Field(1).Interface())
+	})
+}
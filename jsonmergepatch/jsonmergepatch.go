@@ -0,0 +1,287 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonmergepatch generates and applies JSON Merge Patches, as defined by RFC 7386, to Go
+// values, e.g. to implement HTTP PATCH endpoints.
+//
+// Patches are generated from the JSON representation of Go values, obtained with encoding/json;
+// therefore, these values must be JSON-serializable. Patches are applied directly to Go values
+// instead: only the members present in the patch are decoded, with encoding/json, into the values
+// they target, so that fields that are not serialized, e.g. unexported fields or fields tagged with
+// `json:"-"`, are preserved.
+package jsonmergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/adutra/goalesce"
+	"github.com/adutra/goalesce/internal/jsonfield"
+)
+
+type object = map[string]interface{}
+
+// Create returns the JSON Merge Patch that transforms the first value into the second value: keys
+// that only exist in the first value are set to null, and keys that differ are set to their new
+// value. Objects are compared recursively; other values, including arrays, are replaced entirely.
+//
+// Note that JSON Merge Patches cannot set keys to null: if the second value has keys with null
+// values that are not null in the first value, applying the patch removes these keys instead.
+func Create[T any](o1, o2 T) ([]byte, error) {
+	doc1, err := toDocument(o1)
+	if err != nil {
+		return nil, err
+	}
+	doc2, err := toDocument(o2)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(diff(doc1, doc2))
+}
+
+// Apply applies the given JSON Merge Patch to the target value and returns the patched value. The
+// target value is deep-copied first with goalesce.DeepCopy, including its unexported fields, and is
+// never modified.
+//
+// As mandated by RFC 7386, patch objects are applied member by member: null members remove the
+// corresponding map keys, or reset the corresponding struct fields to their zero-value, object
+// members are applied recursively, and other members, including arrays, replace the target values
+// entirely. Struct fields are resolved by their JSON names, as declared in json struct tags, and
+// following the rules of encoding/json for embedded structs and case-insensitive matches; members
+// without a matching field are ignored, like encoding/json does. A patch that is not an object
+// replaces the whole target value. Values whose type implements json.Unmarshaler are patched through
+// their JSON representation.
+//
+// This function returns an error if the patch is not valid JSON, or if a patch member cannot be
+// decoded into the type of the value it targets.
+func Apply[T any](target T, patch []byte) (T, error) {
+	var zero T
+	var node json.RawMessage
+	if err := json.Unmarshal(patch, &node); err != nil {
+		return zero, fmt.Errorf("invalid patch: %w", err)
+	}
+	members, isObject, err := decodeObject(node)
+	if err != nil {
+		return zero, fmt.Errorf("invalid patch: %w", err)
+	}
+	if !isObject {
+		var patched T
+		if err := json.Unmarshal(node, &patched); err != nil {
+			return zero, fmt.Errorf("cannot decode patched value: %w", err)
+		}
+		return patched, nil
+	}
+	copied, err := goalesce.DeepCopy(target, goalesce.WithUnexportedFieldCopy())
+	if err != nil {
+		return zero, err
+	}
+	if err := applyObject(reflect.ValueOf(&copied).Elem(), members, ""); err != nil {
+		return zero, err
+	}
+	return copied, nil
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// decodeObject decodes the members of the given JSON value, if it is an object. Members are kept in
+// their raw form, null members included, until they are applied to the values they target.
+func decodeObject(node json.RawMessage) (map[string]json.RawMessage, bool, error) {
+	if trimmed := bytes.TrimSpace(node); len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false, nil
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(node, &members); err != nil {
+		return nil, false, err
+	}
+	return members, true, nil
+}
+
+// isNull returns true if the given JSON value is null.
+func isNull(node json.RawMessage) bool {
+	return string(bytes.TrimSpace(node)) == "null"
+}
+
+// applyValue applies the given patch member to the settable value v, located at the given path.
+func applyValue(v reflect.Value, node json.RawMessage, path string) error {
+	if isNull(node) {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	members, isObject, err := decodeObject(node)
+	if err != nil {
+		return fmt.Errorf("cannot decode patched value at %s: %w", path, err)
+	} else if isObject {
+		return applyObject(v, members, path)
+	}
+	decoded := reflect.New(v.Type())
+	if err := json.Unmarshal(node, decoded.Interface()); err != nil {
+		return fmt.Errorf("cannot decode patched value at %s: %w", path, err)
+	}
+	v.Set(decoded.Elem())
+	return nil
+}
+
+// applyObject applies the given patch object to the settable value v, located at the given path.
+func applyObject(v reflect.Value, members map[string]json.RawMessage, path string) error {
+	if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface && reflect.PointerTo(v.Type()).Implements(unmarshalerType) {
+		return applyJSON(v, members, path)
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return applyObject(v.Elem(), members, path)
+	case reflect.Interface:
+		// values wrapped in interfaces are not addressable: patch a copy, then set it back
+		var elem reflect.Value
+		if !v.IsNil() && isObjectKind(v.Elem().Type()) {
+			elem = reflect.New(v.Elem().Type()).Elem()
+			elem.Set(v.Elem())
+		} else if v.NumMethod() == 0 {
+			// the target value is not an object: as mandated by RFC 7386, the patch is applied to an
+			// empty object instead
+			elem = reflect.ValueOf(object{})
+		} else {
+			return fmt.Errorf("cannot decode patched value at %s: cannot create value of type %s", path, v.Type().String())
+		}
+		if err := applyObject(elem, members, path); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case reflect.Struct:
+		for _, name := range sortedNames(members) {
+			if field, found := jsonfield.Field(v, name, !isNull(members[name])); found && field.CanSet() {
+				if err := applyValue(field, members[name], memberPath(path, name)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		for _, name := range sortedNames(members) {
+			key, err := jsonfield.MapKey(v.Type().Key(), name)
+			if err != nil {
+				return fmt.Errorf("cannot decode patched value at %s: %w", path, err)
+			}
+			if isNull(members[name]) {
+				v.SetMapIndex(key, reflect.Value{})
+				continue
+			}
+			// map entries are not addressable: patch a copy, then set it back
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if existing := v.MapIndex(key); existing.IsValid() {
+				elem.Set(existing)
+			}
+			if err := applyValue(elem, members[name], memberPath(path, name)); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, elem)
+		}
+		return nil
+	}
+	return applyJSON(v, members, path)
+}
+
+// applyJSON applies the given patch object to the JSON representation of the settable value v,
+// then decodes the result back into v. This is used for values that are not objects, which the
+// patch replaces, and for values implementing json.Unmarshaler, which control their own decoding.
+func applyJSON(v reflect.Value, members map[string]json.RawMessage, path string) error {
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if err := applyObject(reflect.ValueOf(&doc).Elem(), members, path); err != nil {
+		return err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	decoded := reflect.New(v.Type())
+	if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+		return fmt.Errorf("cannot decode patched value at %s: %w", path, err)
+	}
+	v.Set(decoded.Elem())
+	return nil
+}
+
+// isObjectKind returns true if patch objects can be applied member by member to values of the given
+// type.
+func isObjectKind(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct || t.Kind() == reflect.Map || t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct
+}
+
+// memberPath returns the JSON pointer (RFC 6901) of the given object member, relative to the given
+// parent pointer.
+func memberPath(parent, name string) string {
+	return parent + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
+
+func sortedNames(members map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// diff computes the JSON Merge Patch that transforms doc1 into doc2.
+func diff(doc1, doc2 interface{}) interface{} {
+	obj1, ok1 := doc1.(object)
+	obj2, ok2 := doc2.(object)
+	if !ok1 || !ok2 {
+		return doc2
+	}
+	patch := object{}
+	for k := range obj1 {
+		if _, found := obj2[k]; !found {
+			patch[k] = nil
+		}
+	}
+	for k, v2 := range obj2 {
+		v1, found := obj1[k]
+		if !found {
+			patch[k] = v2
+		} else if !reflect.DeepEqual(v1, v2) {
+			patch[k] = diff(v1, v2)
+		}
+	}
+	return patch
+}
+
+// toDocument converts the given value into its generic JSON representation.
+func toDocument(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
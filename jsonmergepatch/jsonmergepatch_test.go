@@ -0,0 +1,195 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonmergepatch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type author struct {
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type post struct {
+	Title   string            `json:"title"`
+	Author  *author           `json:"author,omitempty"`
+	Tags    []string          `json:"tags"`
+	Content string            `json:"content"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Draft   bool              `json:"draft"`
+}
+
+func TestCreate(t *testing.T) {
+	v1 := post{Title: "Goodbye!", Author: &author{GivenName: "John", FamilyName: "Doe"}, Tags: []string{"example", "sample"}, Content: "This will be unchanged", Draft: true}
+	v2 := post{Title: "Hello!", Author: &author{GivenName: "John"}, Tags: []string{"example"}, Content: "This will be unchanged", Labels: map[string]string{"a": "b"}}
+	patch, err := Create(v1, v2)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"title":"Hello!","author":{"familyName":null},"tags":["example"],"labels":{"a":"b"},"draft":false}`, string(patch))
+	t.Run("round trip", func(t *testing.T) {
+		got, err := Apply(v1, patch)
+		assert.NoError(t, err)
+		assert.Equal(t, v2, got)
+	})
+	t.Run("no changes", func(t *testing.T) {
+		patch, err := Create(v1, v1)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{}`, string(patch))
+	})
+	t.Run("not serializable", func(t *testing.T) {
+		_, err := Create[interface{}](func() {}, 1)
+		assert.EqualError(t, err, "json: unsupported type: func()")
+	})
+}
+
+func TestApply(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		target := post{Title: "Goodbye!", Author: &author{GivenName: "John", FamilyName: "Doe"}, Tags: []string{"example", "sample"}, Content: "This will be unchanged", Draft: true}
+		got, err := Apply(target, []byte(`{"title":"Hello!","author":{"familyName":null},"tags":["example"],"draft":false}`))
+		assert.NoError(t, err)
+		assert.Equal(t, post{Title: "Hello!", Author: &author{GivenName: "John"}, Tags: []string{"example"}, Content: "This will be unchanged"}, got)
+		assert.Equal(t, "Goodbye!", target.Title)
+		assert.Equal(t, "Doe", target.Author.FamilyName)
+	})
+	t.Run("null deletes pointer", func(t *testing.T) {
+		got, err := Apply(&post{Author: &author{GivenName: "John"}}, []byte(`{"author":null}`))
+		assert.NoError(t, err)
+		assert.Equal(t, &post{}, got)
+	})
+	t.Run("invalid patch", func(t *testing.T) {
+		_, err := Apply(post{}, []byte(`{`))
+		assert.EqualError(t, err, "invalid patch: unexpected end of JSON input")
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		_, err := Apply(post{}, []byte(`{"title":1}`))
+		assert.EqualError(t, err, "cannot decode patched value at /title: json: cannot unmarshal number into Go value of type string")
+		_, err = Apply(post{}, []byte(`{"labels":{"a/b":1}}`))
+		assert.EqualError(t, err, "cannot decode patched value at /labels/a~1b: json: cannot unmarshal number into Go value of type string")
+	})
+	t.Run("unserialized fields", func(t *testing.T) {
+		type session struct {
+			User   string `json:"user"`
+			Secret string `json:"-"`
+			token  string
+		}
+		got, err := Apply(session{User: "alice", Secret: "s3cr3t", token: "t0k3n"}, []byte(`{"user":"bob"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, session{User: "bob", Secret: "s3cr3t", token: "t0k3n"}, got)
+		got, err = Apply(session{User: "alice", Secret: "s3cr3t"}, []byte(`{"user":null,"Secret":"x","token":"x"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, session{Secret: "s3cr3t"}, got)
+	})
+	t.Run("case-insensitive names", func(t *testing.T) {
+		type person struct {
+			Name string `json:"name"`
+			Age  int
+		}
+		got, err := Apply(person{Name: "alice", Age: 1}, []byte(`{"NAME":"bob","age":5}`))
+		assert.NoError(t, err)
+		assert.Equal(t, person{Name: "bob", Age: 5}, got)
+	})
+	t.Run("embedded fields", func(t *testing.T) {
+		type Base struct {
+			ID   int
+			Name string `json:"name"`
+		}
+		type Other struct {
+			ID int
+		}
+		type document struct {
+			Base
+			Other
+			Name string
+		}
+		got, err := Apply(document{Name: "doc"}, []byte(`{"ID":1,"name":"base"}`))
+		assert.NoError(t, err)
+		assert.Equal(t, document{Base: Base{Name: "base"}, Name: "doc"}, got)
+	})
+	t.Run("nested values", func(t *testing.T) {
+		type Base struct {
+			ID int `json:"id"`
+		}
+		type document struct {
+			*Base
+			Post      *post           `json:"post"`
+			Posts     map[string]post `json:"posts"`
+			UpdatedAt time.Time       `json:"updatedAt"`
+			Extra     interface{}     `json:"extra"`
+		}
+		target := document{
+			Posts:     map[string]post{"a": {Title: "a", Draft: true}, "b": {Title: "b"}},
+			UpdatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			Extra:     map[string]interface{}{"a": 1.0, "b": []interface{}{"x"}},
+		}
+		got, err := Apply(target, []byte(`{
+			"id": 1,
+			"post": {"title": "new", "tags": ["x"], "author": {"givenName": "John", "familyName": null}},
+			"posts": {"a": {"draft": false, "labels": {"k": "v", "l": null}}, "b": null, "c": {"title": "c"}},
+			"updatedAt": "2023-01-01T00:00:00Z",
+			"extra": {"a": null, "c": {"d": null}}
+		}`))
+		assert.NoError(t, err)
+		assert.Equal(t, document{
+			Base:      &Base{ID: 1},
+			Post:      &post{Title: "new", Tags: []string{"x"}, Author: &author{GivenName: "John"}},
+			Posts:     map[string]post{"a": {Title: "a", Labels: map[string]string{"k": "v"}}, "c": {Title: "c"}},
+			UpdatedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			Extra:     map[string]interface{}{"b": []interface{}{"x"}, "c": map[string]interface{}{}},
+		}, got)
+		assert.Len(t, target.Posts, 2)
+		assert.True(t, target.Posts["a"].Draft)
+		assert.Equal(t, map[string]interface{}{"a": 1.0, "b": []interface{}{"x"}}, target.Extra)
+	})
+	// test cases from RFC 7386, Appendix A
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{`{"a":true}`, `{"a":false}`, `{"a":false}`},
+		{`{"a":1}`, `{"a":0}`, `{"a":0}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+			var target interface{}
+			require.NoError(t, json.Unmarshal([]byte(tt.target), &target))
+			got, err := Apply(target, []byte(tt.patch))
+			require.NoError(t, err)
+			data, err := json.Marshal(got)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(data))
+		})
+	}
+}
@@ -0,0 +1,522 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonpatch generates and applies JSON Patches, as defined by RFC 6902, to Go values.
+//
+// Patches are applied directly to Go structs, maps, slices and arrays: the JSON pointers of the
+// operations (RFC 6901) are resolved against struct fields by their JSON names, as declared in json
+// struct tags and following the rules of encoding/json for embedded structs and case-insensitive
+// matches. The values of the operations are decoded with encoding/json into the type of the value
+// they target.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adutra/goalesce"
+	"github.com/adutra/goalesce/internal/jsonfield"
+)
+
+const (
+	opAdd     = "add"
+	opRemove  = "remove"
+	opReplace = "replace"
+	opMove    = "move"
+	opCopy    = "copy"
+	opTest    = "test"
+)
+
+// operation is a single operation of a JSON Patch.
+type operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies the given JSON Patch to the target value and returns the patched value. The target
+// value is deep-copied first with goalesce.DeepCopy, including its unexported fields, and is never
+// modified.
+//
+// All operations of RFC 6902 are supported: add, remove, replace, move, copy and test. Since structs
+// and arrays have a fixed shape, removing a struct field or an array element resets it to its
+// zero-value, and adding one replaces its value. Nil pointers found along a path are allocated as
+// needed. Values of move and copy operations are converted through their JSON representation, and
+// test operations compare JSON representations.
+//
+// Patches are applied atomically: if any operation fails, including a test operation, the zero-value
+// and an error are returned.
+func Apply[T any](target T, patch []byte) (T, error) {
+	var zero T
+	var ops []operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return zero, fmt.Errorf("invalid patch: %w", err)
+	}
+	copied, err := goalesce.DeepCopy(target, goalesce.WithUnexportedFieldCopy())
+	if err != nil {
+		return zero, err
+	}
+	root := reflect.ValueOf(&copied).Elem()
+	for i, op := range ops {
+		if err := apply(root, op); err != nil {
+			return zero, fmt.Errorf("operation %d: %s %s: %w", i, op.Op, op.Path, err)
+		}
+	}
+	return copied, nil
+}
+
+func apply(root reflect.Value, op operation) error {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case opAdd, opReplace, opTest:
+		if len(op.Value) == 0 {
+			return fmt.Errorf("missing value")
+		}
+	case opMove, opCopy:
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+		if op.Op == opMove && isPrefix(from, path) && len(from) < len(path) {
+			return fmt.Errorf("cannot move a value into one of its children")
+		}
+		value, err := get(root, from)
+		if err != nil {
+			return err
+		}
+		if op.Value, err = json.Marshal(value.Interface()); err != nil {
+			return err
+		}
+		if op.Op == opMove {
+			if err := remove(root, from); err != nil {
+				return err
+			}
+		}
+		return add(root, path, op.Value, false)
+	case opRemove:
+		return remove(root, path)
+	default:
+		return fmt.Errorf("unknown operation")
+	}
+	switch op.Op {
+	case opAdd:
+		return add(root, path, op.Value, false)
+	case opReplace:
+		return add(root, path, op.Value, true)
+	}
+	value, err := get(root, path)
+	if err != nil {
+		return err
+	}
+	if equal, err := jsonEqual(value, op.Value); err != nil {
+		return err
+	} else if !equal {
+		return fmt.Errorf("test failed")
+	}
+	return nil
+}
+
+// add sets the value at the given path to the given JSON value. If replace is true, the value must
+// already exist; otherwise, slice elements are inserted and map entries are created.
+func add(root reflect.Value, path []string, data json.RawMessage, replace bool) error {
+	if len(path) == 0 {
+		return decodeInto(root, data)
+	}
+	return walk(root, path[:len(path)-1], func(parent reflect.Value) error {
+		token := path[len(path)-1]
+		switch parent.Kind() {
+		case reflect.Struct:
+			field, err := structField(parent, token, true)
+			if err != nil {
+				return err
+			}
+			return decodeInto(field, data)
+		case reflect.Map:
+			key, err := mapKey(parent.Type().Key(), token)
+			if err != nil {
+				return err
+			}
+			if replace && (parent.IsNil() || !parent.MapIndex(key).IsValid()) {
+				return fmt.Errorf("key not found: %s", token)
+			}
+			value := reflect.New(parent.Type().Elem()).Elem()
+			if err := decodeInto(value, data); err != nil {
+				return err
+			}
+			if parent.IsNil() {
+				parent.Set(reflect.MakeMap(parent.Type()))
+			}
+			parent.SetMapIndex(key, value)
+			return nil
+		case reflect.Slice:
+			if !replace {
+				index, err := sliceIndex(parent, token, true)
+				if err != nil {
+					return err
+				}
+				value := reflect.New(parent.Type().Elem()).Elem()
+				if err := decodeInto(value, data); err != nil {
+					return err
+				}
+				inserted := reflect.MakeSlice(parent.Type(), parent.Len()+1, parent.Len()+1)
+				reflect.Copy(inserted, parent.Slice(0, index))
+				inserted.Index(index).Set(value)
+				reflect.Copy(inserted.Slice(index+1, inserted.Len()), parent.Slice(index, parent.Len()))
+				parent.Set(inserted)
+				return nil
+			}
+			fallthrough
+		case reflect.Array:
+			index, err := sliceIndex(parent, token, false)
+			if err != nil {
+				return err
+			}
+			return decodeInto(parent.Index(index), data)
+		}
+		return fmt.Errorf("cannot resolve %s in type %s", token, parent.Type().String())
+	})
+}
+
+// remove removes the value at the given path.
+func remove(root reflect.Value, path []string) error {
+	if len(path) == 0 {
+		root.Set(reflect.Zero(root.Type()))
+		return nil
+	}
+	return walk(root, path[:len(path)-1], func(parent reflect.Value) error {
+		token := path[len(path)-1]
+		switch parent.Kind() {
+		case reflect.Struct:
+			field, err := structField(parent, token, false)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		case reflect.Map:
+			key, err := mapKey(parent.Type().Key(), token)
+			if err != nil {
+				return err
+			}
+			if parent.IsNil() || !parent.MapIndex(key).IsValid() {
+				return fmt.Errorf("key not found: %s", token)
+			}
+			parent.SetMapIndex(key, reflect.Value{})
+			return nil
+		case reflect.Slice:
+			index, err := sliceIndex(parent, token, false)
+			if err != nil {
+				return err
+			}
+			removed := reflect.MakeSlice(parent.Type(), 0, parent.Len()-1)
+			removed = reflect.AppendSlice(removed, parent.Slice(0, index))
+			removed = reflect.AppendSlice(removed, parent.Slice(index+1, parent.Len()))
+			parent.Set(removed)
+			return nil
+		case reflect.Array:
+			index, err := sliceIndex(parent, token, false)
+			if err != nil {
+				return err
+			}
+			parent.Index(index).Set(reflect.Zero(parent.Type().Elem()))
+			return nil
+		}
+		return fmt.Errorf("cannot resolve %s in type %s", token, parent.Type().String())
+	})
+}
+
+// get returns the value at the given path.
+func get(root reflect.Value, path []string) (reflect.Value, error) {
+	if len(path) == 0 {
+		return root, nil
+	}
+	var value reflect.Value
+	err := walk(root, path[:len(path)-1], func(parent reflect.Value) error {
+		token := path[len(path)-1]
+		switch parent.Kind() {
+		case reflect.Struct:
+			field, err := structField(parent, token, false)
+			value = field
+			return err
+		case reflect.Map:
+			key, err := mapKey(parent.Type().Key(), token)
+			if err != nil {
+				return err
+			}
+			if value = parent.MapIndex(key); !value.IsValid() {
+				return fmt.Errorf("key not found: %s", token)
+			}
+			return nil
+		case reflect.Slice, reflect.Array:
+			index, err := sliceIndex(parent, token, false)
+			if err != nil {
+				return err
+			}
+			value = parent.Index(index)
+			return nil
+		}
+		return fmt.Errorf("cannot resolve %s in type %s", token, parent.Type().String())
+	})
+	return value, err
+}
+
+// walk resolves the given path from the settable value v, and calls fn with the resolved value,
+// after dereferencing pointers and interfaces. Values that are not addressable, i.e. map entries
+// and values wrapped in interfaces, are copied before calling fn, then set back.
+func walk(v reflect.Value, path []string, fn func(v reflect.Value) error) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return walk(v.Elem(), path, fn)
+	case reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("cannot resolve path in nil value")
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := walk(elem, path, fn); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+	if len(path) == 0 {
+		return fn(v)
+	}
+	token := path[0]
+	switch v.Kind() {
+	case reflect.Struct:
+		field, err := structField(v, token, true)
+		if err != nil {
+			return err
+		}
+		return walk(field, path[1:], fn)
+	case reflect.Map:
+		key, err := mapKey(v.Type().Key(), token)
+		if err != nil {
+			return err
+		}
+		existing := v.MapIndex(key)
+		if !existing.IsValid() {
+			return fmt.Errorf("key not found: %s", token)
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		elem.Set(existing)
+		if err := walk(elem, path[1:], fn); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	case reflect.Slice, reflect.Array:
+		index, err := sliceIndex(v, token, false)
+		if err != nil {
+			return err
+		}
+		return walk(v.Index(index), path[1:], fn)
+	}
+	return fmt.Errorf("cannot resolve %s in type %s", token, v.Type().String())
+}
+
+// structField returns the field of the given struct value with the given JSON name, see
+// jsonfield.Field.
+func structField(v reflect.Value, name string, alloc bool) (reflect.Value, error) {
+	field, found := jsonfield.Field(v, name, alloc)
+	if !found {
+		return reflect.Value{}, fmt.Errorf("struct type %s has no field %s", v.Type().String(), name)
+	}
+	return field, nil
+}
+
+// mapKey parses the given token into a map key of the given type, see jsonfield.MapKey.
+func mapKey(t reflect.Type, token string) (reflect.Value, error) {
+	return jsonfield.MapKey(t, token)
+}
+
+// sliceIndex parses the given token into an index of the given slice or array. If insert is true,
+// the index can be equal to the length of the slice, and "-" denotes the length of the slice.
+func sliceIndex(v reflect.Value, token string, insert bool) (int, error) {
+	if insert && token == "-" {
+		return v.Len(), nil
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || token != strconv.Itoa(index) {
+		return 0, fmt.Errorf("invalid index: %s", token)
+	}
+	if index > v.Len() || index == v.Len() && !insert {
+		return 0, fmt.Errorf("index out of range: %d", index)
+	}
+	return index, nil
+}
+
+// decodeInto decodes the given JSON value into the settable value v. If v is an interface wrapping
+// a value, the value is decoded into the type of the wrapped value.
+func decodeInto(v reflect.Value, data json.RawMessage) error {
+	t := v.Type()
+	if v.Kind() == reflect.Interface && !v.IsNil() && string(data) != "null" {
+		t = v.Elem().Type()
+	}
+	decoded := reflect.New(t)
+	if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+		return err
+	}
+	v.Set(decoded.Elem())
+	return nil
+}
+
+// jsonEqual returns true if the JSON representation of v is equal to the given JSON value.
+func jsonEqual(v reflect.Value, data json.RawMessage) (bool, error) {
+	actual, err := json.Marshal(v.Interface())
+	if err != nil {
+		return false, err
+	}
+	var doc1, doc2 interface{}
+	if err := json.Unmarshal(actual, &doc1); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, &doc2); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(doc1, doc2), nil
+}
+
+// parsePointer parses the given JSON pointer (RFC 6901) into its reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer: %s", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+// formatPointer formats the given reference tokens into a JSON pointer (RFC 6901).
+func formatPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/")
+		sb.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+	}
+	return sb.String()
+}
+
+func isPrefix(prefix, path []string) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Create returns a JSON Patch that transforms the first value into the second value. The values
+// are compared through their JSON representations: objects are compared recursively, member by
+// member, in lexical order; other values, including arrays, are replaced entirely when they differ.
+func Create[T any](o1, o2 T) ([]byte, error) {
+	doc1, err := toDocument(o1)
+	if err != nil {
+		return nil, err
+	}
+	doc2, err := toDocument(o2)
+	if err != nil {
+		return nil, err
+	}
+	ops := []operation{}
+	if err := diff(nil, doc1, doc2, &ops); err != nil {
+		return nil, err
+	}
+	return json.Marshal(ops)
+}
+
+func diff(path []string, doc1, doc2 interface{}, ops *[]operation) error {
+	if reflect.DeepEqual(doc1, doc2) {
+		return nil
+	}
+	obj1, ok1 := doc1.(map[string]interface{})
+	obj2, ok2 := doc2.(map[string]interface{})
+	if !ok1 || !ok2 {
+		return appendOperation(ops, opReplace, path, doc2)
+	}
+	keys := make([]string, 0, len(obj1)+len(obj2))
+	for k := range obj1 {
+		keys = append(keys, k)
+	}
+	for k := range obj2 {
+		if _, found := obj1[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		child := append(path[:len(path):len(path)], k)
+		v1, found1 := obj1[k]
+		v2, found2 := obj2[k]
+		var err error
+		switch {
+		case !found2:
+			err = appendOperation(ops, opRemove, child, nil)
+		case !found1:
+			err = appendOperation(ops, opAdd, child, v2)
+		default:
+			err = diff(child, v1, v2, ops)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendOperation(ops *[]operation, op string, path []string, value interface{}) error {
+	operation := operation{Op: op, Path: formatPointer(path)}
+	if op != opRemove {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		operation.Value = data
+	}
+	*ops = append(*ops, operation)
+	return nil
+}
+
+// toDocument converts the given value into its generic JSON representation.
+func toDocument(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonpatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Meta struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type port struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+type service struct {
+	Meta
+	Name     string      `json:"name"`
+	Ports    []port      `json:"ports"`
+	Replicas *int        `json:"replicas,omitempty"`
+	Weights  map[int]int `json:"weights,omitempty"`
+	Pair     [2]string   `json:"pair"`
+	Extra    interface{} `json:"extra,omitempty"`
+	Ignored  string      `json:"-"`
+	Untagged string
+}
+
+func TestApply(t *testing.T) {
+	target := service{
+		Meta:  Meta{Labels: map[string]string{"a/b": "1", "c~d": "2"}},
+		Name:  "web",
+		Ports: []port{{Name: "http", Port: 80}, {Name: "https", Port: 443}},
+		Pair:  [2]string{"x", "y"},
+		Extra: map[string]interface{}{"k": "v"},
+	}
+	tests := []struct {
+		name    string
+		patch   string
+		want    service
+		wantErr string
+	}{
+		{
+			name:  "add",
+			patch: `[{"op":"add","path":"/ports/1","value":{"name":"grpc","port":9090}},{"op":"add","path":"/ports/-","value":{"name":"admin","port":8080}},{"op":"add","path":"/labels/e","value":"3"},{"op":"add","path":"/replicas","value":2},{"op":"add","path":"/weights/1","value":10}]`,
+			want: func() service {
+				s := target
+				s.Ports = []port{{Name: "http", Port: 80}, {Name: "grpc", Port: 9090}, {Name: "https", Port: 443}, {Name: "admin", Port: 8080}}
+				s.Labels = map[string]string{"a/b": "1", "c~d": "2", "e": "3"}
+				s.Replicas = intPtr(2)
+				s.Weights = map[int]int{1: 10}
+				return s
+			}(),
+		},
+		{
+			name:  "remove",
+			patch: `[{"op":"remove","path":"/ports/0"},{"op":"remove","path":"/labels/a~1b"},{"op":"remove","path":"/name"},{"op":"remove","path":"/pair/1"}]`,
+			want: func() service {
+				s := target
+				s.Ports = []port{{Name: "https", Port: 443}}
+				s.Labels = map[string]string{"c~d": "2"}
+				s.Name = ""
+				s.Pair = [2]string{"x", ""}
+				return s
+			}(),
+		},
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/ports/1/port","value":8443},{"op":"replace","path":"/labels/c~0d","value":"3"},{"op":"replace","path":"/Untagged","value":"u"},{"op":"replace","path":"/extra/k","value":"w"}]`,
+			want: func() service {
+				s := target
+				s.Ports = []port{{Name: "http", Port: 80}, {Name: "https", Port: 8443}}
+				s.Labels = map[string]string{"a/b": "1", "c~d": "3"}
+				s.Untagged = "u"
+				s.Extra = map[string]interface{}{"k": "w"}
+				return s
+			}(),
+		},
+		{
+			name:  "move and copy",
+			patch: `[{"op":"move","path":"/ports/0","from":"/ports/1"},{"op":"copy","path":"/name","from":"/labels/a~1b"},{"op":"test","path":"/ports/0/name","value":"https"}]`,
+			want: func() service {
+				s := target
+				s.Ports = []port{{Name: "https", Port: 443}, {Name: "http", Port: 80}}
+				s.Name = "1"
+				return s
+			}(),
+		},
+		{
+			name:  "test whole value",
+			patch: `[{"op":"test","path":"/ports","value":[{"name":"http","port":80},{"name":"https","port":443}]}]`,
+			want:  target,
+		},
+		{name: "test failed", patch: `[{"op":"test","path":"/name","value":"api"}]`, wantErr: "operation 0: test /name: test failed"},
+		{name: "unknown field", patch: `[{"op":"replace","path":"/foo","value":1}]`, wantErr: "operation 0: replace /foo: struct type jsonpatch.service has no field foo"},
+		{name: "ignored field", patch: `[{"op":"replace","path":"/Ignored","value":"a"}]`, wantErr: "operation 0: replace /Ignored: struct type jsonpatch.service has no field Ignored"},
+		{name: "missing key", patch: `[{"op":"replace","path":"/labels/z","value":"a"}]`, wantErr: "operation 0: replace /labels/z: key not found: z"},
+		{name: "remove missing key", patch: `[{"op":"remove","path":"/labels/z"}]`, wantErr: "operation 0: remove /labels/z: key not found: z"},
+		{name: "index out of range", patch: `[{"op":"replace","path":"/ports/2","value":{}}]`, wantErr: "operation 0: replace /ports/2: index out of range: 2"},
+		{name: "invalid index", patch: `[{"op":"add","path":"/ports/01","value":{}}]`, wantErr: "operation 0: add /ports/01: invalid index: 01"},
+		{name: "invalid key", patch: `[{"op":"add","path":"/weights/a","value":1}]`, wantErr: `operation 0: add /weights/a: invalid key a: strconv.ParseInt: parsing "a": invalid syntax`},
+		{name: "wrong type", patch: `[{"op":"replace","path":"/name","value":1}]`, wantErr: "operation 0: replace /name: json: cannot unmarshal number into Go value of type string"},
+		{name: "missing value", patch: `[{"op":"add","path":"/name"}]`, wantErr: "operation 0: add /name: missing value"},
+		{name: "unknown operation", patch: `[{"op":"foo","path":"/name"}]`, wantErr: "operation 0: foo /name: unknown operation"},
+		{name: "invalid pointer", patch: `[{"op":"remove","path":"name"}]`, wantErr: "operation 0: remove name: invalid JSON pointer: name"},
+		{name: "move into child", patch: `[{"op":"move","path":"/ports/0/name","from":"/ports"}]`, wantErr: "operation 0: move /ports/0/name: cannot move a value into one of its children"},
+		{name: "atomic", patch: `[{"op":"add","path":"/name/foo","value":1}]`, wantErr: "operation 0: add /name/foo: cannot resolve foo in type string"},
+		{name: "invalid patch", patch: `{`, wantErr: "invalid patch: unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply(target, []byte(tt.patch))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Zero(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+	t.Run("target not modified", func(t *testing.T) {
+		_, err := Apply(&target, []byte(`[{"op":"remove","path":"/ports/0"},{"op":"replace","path":"/labels/a~1b","value":"x"}]`))
+		require.NoError(t, err)
+		assert.Len(t, target.Ports, 2)
+		assert.Equal(t, "1", target.Labels["a/b"])
+	})
+	t.Run("root", func(t *testing.T) {
+		got, err := Apply([]int{1}, []byte(`[{"op":"replace","path":"","value":[2,3]},{"op":"add","path":"/0","value":1}]`))
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3}, got)
+	})
+	t.Run("nil pointer", func(t *testing.T) {
+		got, err := Apply((*service)(nil), []byte(`[{"op":"add","path":"/name","value":"web"}]`))
+		assert.NoError(t, err)
+		assert.Equal(t, &service{Name: "web"}, got)
+	})
+	t.Run("unexported fields", func(t *testing.T) {
+		type secret struct {
+			Name   string
+			secret string
+		}
+		got, err := Apply(secret{Name: "a", secret: "s"}, []byte(`[{"op":"replace","path":"/Name","value":"b"}]`))
+		assert.NoError(t, err)
+		assert.Equal(t, secret{Name: "b", secret: "s"}, got)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	v1 := service{Meta: Meta{Labels: map[string]string{"a": "1", "b": "2"}}, Name: "web", Ports: []port{{Name: "http", Port: 80}}}
+	v2 := service{Meta: Meta{Labels: map[string]string{"a": "3", "c/d": "4"}}, Name: "web", Ports: []port{{Name: "https", Port: 443}}, Replicas: intPtr(2)}
+	patch, err := Create(v1, v2)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op":"replace","path":"/labels/a","value":"3"},
+		{"op":"remove","path":"/labels/b"},
+		{"op":"add","path":"/labels/c~1d","value":"4"},
+		{"op":"replace","path":"/ports","value":[{"name":"https","port":443}]},
+		{"op":"add","path":"/replicas","value":2}
+	]`, string(patch))
+	got, err := Apply(v1, patch)
+	assert.NoError(t, err)
+	assert.Equal(t, v2, got)
+	t.Run("no changes", func(t *testing.T) {
+		patch, err := Create(v1, v1)
+		assert.NoError(t, err)
+		assert.Equal(t, `[]`, string(patch))
+	})
+	t.Run("not serializable", func(t *testing.T) {
+		_, err := Create[interface{}](func() {}, 1)
+		assert.EqualError(t, err, "json: unsupported type: func()")
+	})
+}
+
+func Test_parsePointer(t *testing.T) {
+	tokens, err := parsePointer("/a~1b/c~0d/~01/")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a/b", "c~d", "~1", ""}, tokens)
+	assert.Equal(t, "/a~1b/c~0d/~01/", formatPointer(tokens))
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// IndexByKey indexes the elements of the given slice by their merge keys, as extracted by the given
+// merge key func, e.g. SliceUnion or the func passed to WithSliceMergeByKeyFunc. Keys are extracted
+// exactly as they are when merging slices with that func. When several elements share the same key,
+// the last one wins. The elements are not copied.
+//
+// An error is returned if the merge key func fails, or if a merge key is not of type K.
+func IndexByKey[K comparable, T any](slice []T, mergeKeyFunc SliceMergeKeyFunc) (map[K]T, error) {
+	index := make(map[K]T, len(slice))
+	err := forEachKey(slice, mergeKeyFunc, func(k K, elem T) {
+		index[k] = elem
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// GroupByKey groups the elements of the given slice by their merge keys, as extracted by the given
+// merge key func; see IndexByKey. Within each group, elements keep their order in the slice. The
+// elements are not copied.
+func GroupByKey[K comparable, T any](slice []T, mergeKeyFunc SliceMergeKeyFunc) (map[K][]T, error) {
+	groups := make(map[K][]T)
+	err := forEachKey(slice, mergeKeyFunc, func(k K, elem T) {
+		groups[k] = append(groups[k], elem)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func forEachKey[K comparable, T any](slice []T, mergeKeyFunc SliceMergeKeyFunc, f func(k K, elem T)) error {
+	v := reflect.ValueOf(slice)
+	for i := 0; i < v.Len(); i++ {
+		k, err := mergeKey(mergeKeyFunc, i, v.Index(i))
+		if err != nil {
+			return err
+		}
+		key, ok := k.Interface().(K)
+		if !ok {
+			return fmt.Errorf("merge key %v of type %s is not of type %s", k.Interface(), k.Type(), reflect.TypeOf((*K)(nil)).Elem())
+		}
+		f(key, slice[i])
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type keyedUser struct {
+	ID   int
+	Name string
+	Team string
+}
+
+func TestIndexByKey(t *testing.T) {
+	users := []*keyedUser{{1, "Alice", "a"}, {2, "Bob", "b"}, {1, "Alice 2", "a"}}
+	t.Run("by field", func(t *testing.T) {
+		got, err := IndexByKey[int](users, SliceMergeByField("ID"))
+		require.NoError(t, err)
+		assert.Equal(t, map[int]*keyedUser{1: users[2], 2: users[1]}, got)
+		assert.Same(t, users[2], got[1])
+	})
+	t.Run("union", func(t *testing.T) {
+		got, err := IndexByKey[int]([]*int{intPtr(1), intPtr(2)}, SliceUnion)
+		require.NoError(t, err)
+		assert.Equal(t, map[int]*int{1: intPtr(1), 2: intPtr(2)}, got)
+	})
+	t.Run("interface keys", func(t *testing.T) {
+		got, err := IndexByKey[interface{}]([]string{"a", "b"}, SliceIndex)
+		require.NoError(t, err)
+		assert.Equal(t, map[interface{}]string{0: "a", 1: "b"}, got)
+	})
+	t.Run("wrong key type", func(t *testing.T) {
+		_, err := IndexByKey[string](users, SliceMergeByField("ID"))
+		assert.EqualError(t, err, "merge key 1 of type int is not of type string")
+	})
+	t.Run("key func error", func(t *testing.T) {
+		_, err := IndexByKey[int](users, func(int, reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, errors.New("fake")
+		})
+		assert.EqualError(t, err, "fake")
+	})
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := IndexByKey[int](users, func(int, reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, nil
+		})
+		assert.EqualError(t, err, "slice merge key func returned nil")
+	})
+}
+
+func TestGroupByKey(t *testing.T) {
+	users := []keyedUser{{1, "Alice", "a"}, {2, "Bob", "b"}, {3, "Carol", "a"}}
+	got, err := GroupByKey[string](users, SliceMergeByField("Team"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]keyedUser{"a": {users[0], users[2]}, "b": {users[1]}}, got)
+	got, err = GroupByKey[string]([]keyedUser(nil), SliceMergeByField("Team"))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	_, err = GroupByKey[int](users, SliceMergeByField("Team"))
+	assert.EqualError(t, err, "merge key a of type string is not of type int")
+}
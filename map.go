@@ -17,11 +17,39 @@ package goalesce
 import "reflect"
 
 func (c *coalescer) deepMergeMap(v1, v2 reflect.Value) (reflect.Value, error) {
-	if value, done := checkZero(v1, v2); done {
+	// with WithMapNoNewKeys, a zero v1 can't be replaced with v2, since all its keys are new; with
+	// WithMapNullDeletion, it can't either, since the null entries of v2 must be removed
+	if value, done := checkZero(v1, v2); done && !c.mustCheckPermissions(v2) && (c.mapNoNewKeys == nil && !c.deletesNullEntries(v1.Type()) || !v1.IsZero() || v2.IsZero()) {
+		c.traceZeroShortcut(v1)
 		return c.deepCopy(value)
 	}
+	directive, err := c.patchDirective(v2)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	switch directive {
+	case PatchDirectiveReplace:
+		return c.deepCopy(v2)
+	case PatchDirectiveDelete:
+		return reflect.Zero(v1.Type()), nil
+	}
+	retained, err := c.retainKeys(v2)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if err := c.visitElements(v1.Len() + v2.Len()); err != nil {
+		return reflect.Value{}, err
+	}
+	if merged, done, err := c.fastMergeMap(v1, v2); done {
+		return merged, err
+	}
 	merged := reflect.MakeMap(v1.Type())
-	for _, k := range v1.MapKeys() {
+	parent := c.path
+	defer func() { c.path = parent }()
+	for _, k := range sortedMapKeys(v1) {
+		if retained != nil && !retained[k.String()] {
+			continue
+		}
 		if !v2.MapIndex(k).IsValid() {
 			copiedKey, err := c.deepCopy(k)
 			if err != nil {
@@ -34,13 +62,44 @@ func (c *coalescer) deepMergeMap(v1, v2 reflect.Value) (reflect.Value, error) {
 			merged.SetMapIndex(copiedKey, copiedValue)
 		}
 	}
-	for _, k := range v2.MapKeys() {
+	for _, k := range sortedMapKeys(v2) {
+		if c.isDirectiveKey(k) {
+			continue
+		}
+		if deleted, err := c.isDeleteDirective(v2.MapIndex(k)); err != nil {
+			return reflect.Value{}, err
+		} else if deleted {
+			continue
+		}
+		if c.deletesNullEntries(v1.Type()) && isNull(v2.MapIndex(k)) {
+			c.trace("null entry, removing key %v", k.Interface())
+			continue
+		}
+		if c.mapNoNewKeys != nil && !v1.MapIndex(k).IsValid() {
+			c.mapNoNewKeys(keyPath(parent, k))
+			c.warnAt(keyPath(parent, k), "new map key ignored")
+			continue
+		}
 		copiedKey, err := c.deepCopy(k)
 		if err != nil {
 			return reflect.Value{}, err
 		}
-		if v1.MapIndex(k).IsValid() {
-			mergedValue, err := c.deepMerge(v1.MapIndex(k), v2.MapIndex(k))
+		c.path = keyPath(parent, k)
+		if v1.MapIndex(k).IsValid() && c.mapAddOnly {
+			copiedValue, err := c.deepCopy(v1.MapIndex(k))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			merged.SetMapIndex(copiedKey, copiedValue)
+		} else if v1.MapIndex(k).IsValid() {
+			mergedValue, err := c.mergeEntryAt(v1.Type(), k, v1.MapIndex(k), v2.MapIndex(k))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			merged.SetMapIndex(copiedKey, mergedValue)
+		} else if c.mustCheckPermissions(v2.MapIndex(k)) {
+			// merge with a zero-value to check the permissions and allowlists of nested fields
+			mergedValue, err := c.deepMergeAt(reflect.Zero(v1.Type().Elem()), v2.MapIndex(k))
 			if err != nil {
 				return reflect.Value{}, err
 			}
@@ -53,15 +112,66 @@ func (c *coalescer) deepMergeMap(v1, v2 reflect.Value) (reflect.Value, error) {
 			merged.SetMapIndex(copiedKey, copiedValue)
 		}
 	}
+	if c.mapNoNewKeys != nil && v1.IsNil() && merged.Len() == 0 {
+		return v1, nil
+	}
 	return merged, nil
 }
 
+// mergeEntryAt merges the 2 values of the given key of a map of the given type, located at the
+// current path, with the map key merger registered for that key, if any, atomically if the map type
+// has atomic values, or with the default merge otherwise. See WithMapKeyMerger and
+// WithMapAtomicValues.
+func (c *coalescer) mergeEntryAt(mapType reflect.Type, k, e1, e2 reflect.Value) (reflect.Value, error) {
+	keyMerger, found := c.mapKeyMergers[mapType][k.Interface()]
+	if !found && c.mapAtomicValues[mapType] {
+		return c.mergeAt(c.deepMergeAtomic, e1, e2)
+	} else if !found {
+		return c.deepMergeAt(e1, e2)
+	}
+	return c.mergeAt(func(e1, e2 reflect.Value) (reflect.Value, error) {
+		c.trace("using map key merger")
+		merged, err := keyMerger(e1, e2)
+		if done, merged, err := checkCustomResult(merged, err, e1.Type()); done {
+			return merged, err
+		}
+		return c.deepMerge(e1, e2)
+	}, e1, e2)
+}
+
+// deletesNullEntries returns true if null entries of the second map remove the corresponding keys
+// from merged maps of the given type, see WithDefaultMapNullDeletion and WithMapNullDeletion.
+func (c *coalescer) deletesNullEntries(mapType reflect.Type) bool {
+	return c.mapNullDeletion || c.mapNullDeletions[mapType]
+}
+
+// isNull returns true if the given map value is a nil pointer or a nil interface.
+func isNull(v reflect.Value) bool {
+	return (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil()
+}
+
 func (c *coalescer) deepCopyMap(v reflect.Value) (reflect.Value, error) {
 	if v.IsZero() {
 		return reflect.Zero(v.Type()), nil
 	}
+	if deleted, err := c.isDeleteDirective(v); err != nil {
+		return reflect.Value{}, err
+	} else if deleted {
+		return reflect.Zero(v.Type()), nil
+	}
+	if err := c.visitElements(v.Len()); err != nil {
+		return reflect.Value{}, err
+	}
 	copied := reflect.MakeMapWithSize(v.Type(), v.Len())
-	for _, k := range v.MapKeys() {
+	for _, k := range sortedMapKeys(v) {
+		if c.isDirectiveKey(k) {
+			continue
+		}
+		if deleted, err := c.isDeleteDirective(v.MapIndex(k)); err != nil {
+			return reflect.Value{}, err
+		} else if deleted {
+			continue
+		}
 		copiedKey, err := c.deepCopy(k)
 		if err != nil {
 			return reflect.Value{}, err
@@ -367,6 +367,117 @@ func Test_coalescer_deepMergeMap(t *testing.T) {
 			}
 		})
 	}
+	t.Run("no new keys", func(t *testing.T) {
+		var ignored []string
+		c := newCoalescer(WithMapNoNewKeys(func(path string) { ignored = append(ignored, path) }))
+		v1 := map[string]map[string]int{"a": {"x": 1}, "b": {"y": 2}}
+		v2 := map[string]map[string]int{"a": {"x": -1, "z": -3}, "c": {"w": -4}}
+		got, err := c.deepMergeMap(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]map[string]int{"a": {"x": -1}, "b": {"y": 2}}, got.Interface())
+		assert.Equal(t, []string{`["a"]["z"]`, `["c"]`}, ignored)
+		got, err = c.deepMergeMap(reflect.ValueOf(map[string]int(nil)), reflect.ValueOf(map[string]int{"a": 1}))
+		assert.NoError(t, err)
+		assert.Nil(t, got.Interface())
+		assert.Equal(t, `["a"]`, ignored[2])
+	})
+	t.Run("add only", func(t *testing.T) {
+		c := newCoalescer(WithMapAddOnly())
+		v1 := map[string]map[string]int{"a": {"x": 1}, "b": {"y": 2}}
+		v2 := map[string]map[string]int{"a": {"x": -1, "z": -3}, "c": {"w": -4}}
+		got, err := c.deepMergeMap(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]map[string]int{"a": {"x": 1}, "b": {"y": 2}, "c": {"w": -4}}, got.Interface())
+		assertNotSame(t, v1["a"], got.Interface().(map[string]map[string]int)["a"])
+	})
+	t.Run("atomic values", func(t *testing.T) {
+		c := newCoalescer(WithMapAtomicValues(reflect.TypeOf(map[string]interface{}{})))
+		v1 := map[string]interface{}{"a": map[string]interface{}{"x": 1.0, "y": 2.0}, "b": "b", "c": map[string]interface{}{"z": 3.0}}
+		v2 := map[string]interface{}{"a": map[string]interface{}{"x": 4.0}, "b": nil, "d": "d"}
+		got, err := c.deepMergeMap(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{"x": 4.0}, "b": "b", "c": map[string]interface{}{"z": 3.0}, "d": "d"}, got.Interface())
+		assertNotSame(t, v2["a"], got.Interface().(map[string]interface{})["a"])
+	})
+	t.Run("map key merger", func(t *testing.T) {
+		appendMerger := func(v1, v2 reflect.Value) (reflect.Value, error) {
+			s1, ok1 := v1.Interface().([]interface{})
+			s2, ok2 := v2.Interface().([]interface{})
+			if !ok1 || !ok2 {
+				return reflect.Value{}, nil // fall back to the default merge
+			}
+			merged := append(append([]interface{}{}, s1...), s2...)
+			return reflect.ValueOf(&merged).Elem().Convert(v1.Type()), nil
+		}
+		c := newCoalescer(WithMapKeyMerger(reflect.TypeOf(map[string]interface{}{}), "args", appendMerger))
+		v1 := map[string]interface{}{
+			"args":  []interface{}{"-v"},
+			"other": []interface{}{"a"},
+			"sub":   map[string]interface{}{"args": []interface{}{"-x"}},
+		}
+		v2 := map[string]interface{}{
+			"args":  []interface{}{"-q"},
+			"other": []interface{}{"b"},
+			"sub":   map[string]interface{}{"args": []interface{}{"-y"}},
+		}
+		got, err := c.deepMergeMap(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"args":  []interface{}{"-v", "-q"},
+			"other": []interface{}{"b"},
+			"sub":   map[string]interface{}{"args": []interface{}{"-x", "-y"}},
+		}, got.Interface())
+		got, err = c.deepMergeMap(reflect.ValueOf(map[string]interface{}{"args": "a"}), reflect.ValueOf(map[string]interface{}{"args": "b"}))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"args": "b"}, got.Interface())
+		c = newCoalescer(WithMapKeyMerger(reflect.TypeOf(map[string]int{}), "a", func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, assert.AnError
+		}))
+		_, err = c.deepMergeMap(reflect.ValueOf(map[string]int{"a": 1}), reflect.ValueOf(map[string]int{"a": 2}))
+		assert.EqualError(t, err, `at ["a"]: `+assert.AnError.Error())
+	})
+	t.Run("null deletion", func(t *testing.T) {
+		c := newCoalescer(WithDefaultMapNullDeletion())
+		v1 := map[string]*int{"a": intPtr(1), "b": intPtr(2), "c": intPtr(3)}
+		v2 := map[string]*int{"b": nil, "c": intPtr(4), "d": nil}
+		got, err := c.deepMergeMap(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*int{"a": intPtr(1), "c": intPtr(4)}, got.Interface())
+		got, err = c.deepMergeMap(reflect.ValueOf(map[string]*int(nil)), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*int{"c": intPtr(4)}, got.Interface())
+		got, err = c.deepMergeMap(reflect.ValueOf(map[string]*int(nil)), reflect.ValueOf(map[string]*int(nil)))
+		assert.NoError(t, err)
+		assert.Nil(t, got.Interface())
+		nested1 := map[string]interface{}{"a": map[string]interface{}{"x": 1.0, "y": 2.0}, "b": "b"}
+		nested2 := map[string]interface{}{"a": map[string]interface{}{"x": nil}, "b": nil}
+		got, err = c.deepMergeMap(reflect.ValueOf(nested1), reflect.ValueOf(nested2))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{"y": 2.0}}, got.Interface())
+	})
+	t.Run("null deletion per type", func(t *testing.T) {
+		c := newCoalescer(WithMapNullDeletion(reflect.TypeOf(map[string]*int{})))
+		got, err := c.deepMergeMap(reflect.ValueOf(map[string]*int{"a": intPtr(1)}), reflect.ValueOf(map[string]*int{"a": nil}))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]*int{}, got.Interface())
+		got, err = c.deepMergeMap(reflect.ValueOf(map[string]interface{}{"a": 1}), reflect.ValueOf(map[string]interface{}{"a": nil}))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"a": 1}, got.Interface())
+	})
+	t.Run("deterministic order", func(t *testing.T) {
+		type role struct {
+			Admin bool
+		}
+		v1 := map[string]role{}
+		v2 := map[string]role{}
+		for _, k := range []string{"d", "b", "e", "a", "c"} {
+			v2[k] = role{Admin: true}
+		}
+		for i := 0; i < 20; i++ {
+			_, err := DeepMerge(v1, v2, WithErrorOnFieldPermissionDenied(), WithFieldPermission(func(string, reflect.StructField) bool { return false }))
+			assert.EqualError(t, err, `at ["a"].Admin: field modification not permitted`)
+		}
+	})
 }
 
 func Test_coalescer_deepCopyMap(t *testing.T) {
@@ -0,0 +1,84 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "reflect"
+
+// memoKey identifies a non-nil pointer whose copy or merge can be memoized.
+type memoKey struct {
+	t    reflect.Type
+	addr uintptr
+}
+
+// memoizer caches the copies and merges of pointers, so that subtrees referenced from many places
+// are only copied or merged once, see WithMemoization. It is meant to be used for the duration of a
+// single operation, during which the values being copied or merged are not modified.
+type memoizer struct {
+	copies map[memoKey]reflect.Value
+	merges map[[2]memoKey]reflect.Value
+}
+
+func newMemoizer() *memoizer {
+	return &memoizer{
+		copies: make(map[memoKey]reflect.Value),
+		merges: make(map[[2]memoKey]reflect.Value),
+	}
+}
+
+func (m *memoizer) reset() {
+	clear(m.copies)
+	clear(m.merges)
+}
+
+func memoKeyOf(v reflect.Value) memoKey {
+	return memoKey{v.Type(), v.Pointer()}
+}
+
+// memoizeCopy copies the given non-nil pointer with the given function, unless a copy of the same
+// pointer was already made during this operation, in which case that copy is returned instead.
+func (c *coalescer) memoizeCopy(v reflect.Value, copyPointer func() (reflect.Value, error)) (reflect.Value, error) {
+	if c.memoizer == nil {
+		return copyPointer()
+	}
+	key := memoKeyOf(v)
+	if copied, found := c.memoizer.copies[key]; found {
+		return copied, nil
+	}
+	cycles := c.stats.CyclesDetected
+	copied, err := copyPointer()
+	// copies of subtrees with cycles depend on where the cycle was entered: they can't be reused
+	if err == nil && c.stats.CyclesDetected == cycles {
+		c.memoizer.copies[key] = copied
+	}
+	return copied, err
+}
+
+// memoizeMerge is like memoizeCopy, but for merges of two non-nil pointers.
+func (c *coalescer) memoizeMerge(v1, v2 reflect.Value, mergePointers func() (reflect.Value, error)) (reflect.Value, error) {
+	if c.memoizer == nil {
+		return mergePointers()
+	}
+	key := [2]memoKey{memoKeyOf(v1), memoKeyOf(v2)}
+	if merged, found := c.memoizer.merges[key]; found {
+		c.trace("pointers already merged, reusing result")
+		return merged, nil
+	}
+	cycles := c.stats.CyclesDetected
+	merged, err := mergePointers()
+	if err == nil && c.stats.CyclesDetected == cycles {
+		c.memoizer.merges[key] = merged
+	}
+	return merged, err
+}
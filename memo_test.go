@@ -0,0 +1,98 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepCopy_memoization(t *testing.T) {
+	type schema struct {
+		Fields []string
+	}
+	type table struct {
+		Name   string
+		Schema *schema
+	}
+	shared := &schema{Fields: []string{"id", "name"}}
+	v := []table{{"a", shared}, {"b", shared}, {"c", &schema{Fields: []string{"id"}}}}
+	t.Run("without memoization", func(t *testing.T) {
+		got, err := DeepCopy(v)
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+		assert.NotSame(t, got[0].Schema, got[1].Schema)
+	})
+	t.Run("with memoization", func(t *testing.T) {
+		var stats OperationStats
+		got, err := DeepCopy(v, WithMemoization(), WithInstrumentation(InstrumentationFunc(func(s OperationStats) { stats = s })))
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+		assert.Same(t, got[0].Schema, got[1].Schema)
+		assert.NotSame(t, shared, got[0].Schema)
+		assert.NotSame(t, got[0].Schema, got[2].Schema)
+		var unmemoized OperationStats
+		_, _ = DeepCopy(v, WithInstrumentation(InstrumentationFunc(func(s OperationStats) { unmemoized = s })))
+		assert.Less(t, stats.ValuesCopied, unmemoized.ValuesCopied)
+	})
+	t.Run("reset between operations", func(t *testing.T) {
+		merger, err := NewMerger[[]table](WithMemoization())
+		require.NoError(t, err)
+		got1, err := merger.Copy(v)
+		require.NoError(t, err)
+		got2, err := merger.Copy(v)
+		require.NoError(t, err)
+		assert.NotSame(t, got1[0].Schema, got2[0].Schema)
+	})
+	t.Run("cycles", func(t *testing.T) {
+		type node struct {
+			Next *node
+		}
+		n1 := &node{}
+		n2 := &node{Next: n1}
+		n1.Next = n2
+		v := []*node{n1, n2}
+		got, err := DeepCopy(v, WithMemoization())
+		require.NoError(t, err)
+		want, err := DeepCopy(v)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestDeepMerge_memoization(t *testing.T) {
+	type schema struct {
+		Fields  []string
+		Comment string
+	}
+	type table struct {
+		Name   string
+		Schema *schema
+	}
+	shared1 := &schema{Fields: []string{"id"}}
+	shared2 := &schema{Comment: "shared"}
+	v1 := []table{{"a", shared1}, {"b", shared1}}
+	v2 := []table{{"a", shared2}, {"b", shared2}}
+	got, err := DeepMerge(v1, v2, WithDefaultSliceMergeByIndex(), WithMemoization())
+	require.NoError(t, err)
+	want, err := DeepMerge(v1, v2, WithDefaultSliceMergeByIndex())
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Same(t, got[0].Schema, got[1].Schema)
+	assert.NotSame(t, shared1, got[0].Schema)
+	assert.NotSame(t, shared2, got[0].Schema)
+}
@@ -14,7 +14,14 @@
 
 package goalesce
 
-import "reflect"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
 
 // DeepMerge merges the 2 values and returns the merged value.
 //
@@ -38,17 +45,55 @@ import "reflect"
 // overwrites the first one completely. It is possible to change this behavior and use list-append,
 // set-union, or merge-by semantics. See Option.
 //
-// This function returns an error if the values are not of the same type, or if the merge encounters
-// an error.
+// This function returns an error if the values are not of the same type, if the merge encounters
+// an error, or if the merged value fails validation (see WithValidator).
 func DeepMerge[T any](o1, o2 T, opts ...Option) (T, error) {
+	return deepMerge(newCoalescer(opts...), o1, o2)
+}
+
+func deepMerge[T any](coalescer *coalescer, o1, o2 T) (_ T, err error) {
+	if coalescer.instrumentation != nil {
+		start := time.Now()
+		defer func() { coalescer.completeOperation(OperationMerge, start, err) }()
+	}
 	v1 := reflect.ValueOf(o1)
 	v2 := reflect.ValueOf(o2)
-	coalescer := newCoalescer(opts...)
 	result, err := coalescer.deepMerge(v1, v2)
-	if !result.IsValid() || err != nil {
+	partial := err == nil && len(coalescer.errs) > 0
+	if partial {
+		err = errors.Join(coalescer.errs...)
+	} else if err == nil {
+		err = coalescer.validate(result)
+	}
+	if coalescer.recorder != nil {
+		typeName := reflect.TypeOf((*T)(nil)).Elem().String()
+		if recordErr := coalescer.recorder.record(typeName, coalescer.fingerprint(), v1, v2, result, err); recordErr != nil && err == nil {
+			err = fmt.Errorf("cannot record merge: %w", recordErr)
+		}
+	}
+	if !result.IsValid() || (err != nil && !partial) {
 		return zero[T](), err
 	}
-	return cast[T](result)
+	if coalescer.dryRun != nil {
+		if dryRunErr := coalescer.recordDryRun(v1, result); dryRunErr != nil {
+			return zero[T](), dryRunErr
+		}
+		return zero[T](), err
+	}
+	merged, castErr := cast[T](result)
+	if castErr != nil {
+		return zero[T](), castErr
+	}
+	return merged, err
+}
+
+// DeepMergeContext is like DeepMerge, but attaches the given context to the merge, see WithContext.
+// The context is checked periodically while the values are traversed, and the merge is aborted
+// with the context's error once it is canceled, which makes merges of large, untrusted values
+// cancellable. The context is also passed to the custom functions registered with
+// WithTypeCopierContext, WithTypeMergerContext and WithFieldMergerContext.
+func DeepMergeContext[T any](ctx context.Context, o1, o2 T, opts ...Option) (T, error) {
+	return deepMerge(newCoalescer(append(opts[:len(opts):len(opts)], WithContext(ctx))...), o1, o2)
 }
 
 // MustDeepMerge is like DeepMerge, but panics if the merge returns an error.
@@ -59,3 +104,99 @@ func MustDeepMerge[T any](o1, o2 T, opts ...Option) T {
 	}
 	return merged
 }
+
+// AreMergeable checks whether values of the given types can be merged by DeepMerge with the given
+// options, without merging actual values. It returns nil if the types are mergeable, or the error
+// the merge would fail with otherwise. A nil type denotes a nil interface value, which can be merged
+// with values of any type.
+//
+// Values of different types are not mergeable. Besides, the struct tags of the struct types
+// reachable from the given types are validated, see DescribeMergeStrategies. Note that interfaces
+// wrapping values of different types are mergeable, and that errors depending on actual values,
+// e.g. errors returned by custom mergers, cannot be detected.
+func AreMergeable(t1, t2 reflect.Type, opts ...Option) error {
+	if t1 == nil || t2 == nil {
+		return nil
+	}
+	if err := checkTypesMatch(t1, t2); err != nil {
+		return err
+	}
+	_, err := DescribeMergeStrategies([]reflect.Type{t1}, opts...)
+	return err
+}
+
+// Pair is a pair of values to be merged by MergeMany.
+type Pair[T any] struct {
+	First  T
+	Second T
+}
+
+// MergeMany merges many independent pairs of values of the same type, and returns the merged
+// values, in the same order as the pairs. It is equivalent to calling DeepMerge for each pair, but
+// the options are processed once for all pairs, instead of once per pair. The pairs can also be
+// merged concurrently, see WithParallelism.
+//
+// If any pair fails to merge, a nil slice and the error of the first failing pair are returned.
+func MergeMany[T any](pairs []Pair[T], opts ...Option) ([]T, error) {
+	first := newCoalescer(opts...)
+	workers := first.parallelism
+	if workers < 1 {
+		workers = 1
+	} else if workers > len(pairs) {
+		workers = len(pairs)
+	}
+	results := make([]T, len(pairs))
+	errs := make([]error, len(pairs))
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		coalescer := first
+		if w > 0 {
+			coalescer = newCoalescer(opts...)
+		}
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := w; i < len(pairs); i += workers {
+				results[i], errs[i] = deepMerge(coalescer, pairs[i].First, pairs[i].Second)
+				coalescer.reset()
+			}
+		}(w)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("pair %d: %w", i, err)
+		}
+	}
+	return results, nil
+}
+
+// ConcurrentModificationError is the error returned by MergeIfUnchanged when the current value
+// differs from the base value.
+type ConcurrentModificationError struct {
+	// Diff holds the differences between the base value and the current value.
+	Diff Diff
+}
+
+// Error implements the error interface.
+func (e *ConcurrentModificationError) Error() string {
+	return fmt.Sprintf("concurrent modification detected: %d change(s) since base value", len(e.Diff))
+}
+
+// MergeIfUnchanged merges update into current, but only if current is still equal to base. This
+// captures the compare-and-merge pattern of optimistic concurrency control: base is the value that
+// was read when the update was computed, and current is the value as it is now.
+//
+// Equality is checked with DeepDiff semantics. If current differs from base, the zero-value and a
+// *ConcurrentModificationError are returned. Otherwise, this function behaves exactly like
+// DeepMerge(current, update, opts...).
+func MergeIfUnchanged[T any](base, current, update T, opts ...Option) (T, error) {
+	diff, err := DeepDiff(base, current, opts...)
+	if err != nil {
+		return zero[T](), err
+	}
+	if len(diff) > 0 {
+		return zero[T](), &ConcurrentModificationError{Diff: diff}
+	}
+	return DeepMerge(current, update, opts...)
+}
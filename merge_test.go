@@ -15,8 +15,11 @@
 package goalesce
 
 import (
+	"context"
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -458,6 +461,36 @@ func TestDeepMerge(t *testing.T) {
 		assert.Equal(t, "", got)
 		assert.EqualError(t, err, "mock DeepMerge error")
 	})
+	t.Run("validation error", func(t *testing.T) {
+		got, err := DeepMerge("abc", "", WithValidator(func(merged interface{}) error {
+			return errors.New("invalid: " + merged.(string))
+		}))
+		assert.Equal(t, "", got)
+		assert.EqualError(t, err, "validation failed: invalid: abc")
+	})
+}
+
+func TestDeepMerge_firstValuePriority(t *testing.T) {
+	type config struct {
+		Host    string
+		Port    int
+		Tags    []string
+		Plugins []string `goalesce:"append"`
+		Labels  map[string]string
+		Ptr     *int
+	}
+	user := config{Host: "example.com", Tags: []string{"a"}, Plugins: []string{"auth"}, Labels: map[string]string{"env": "prod"}, Ptr: intPtr(1)}
+	defaults := config{Host: "localhost", Port: 8080, Tags: []string{"b"}, Plugins: []string{"metrics"}, Labels: map[string]string{"env": "dev", "team": "x"}, Ptr: intPtr(2)}
+	got, err := DeepMerge(user, defaults, WithFirstValuePriority())
+	assert.NoError(t, err)
+	assert.Equal(t, config{
+		Host:    "example.com",
+		Port:    8080,
+		Tags:    []string{"a"},
+		Plugins: []string{"auth", "metrics"},
+		Labels:  map[string]string{"env": "prod", "team": "x"},
+		Ptr:     intPtr(1),
+	}, got)
 }
 
 func TestMustDeepMerge(t *testing.T) {
@@ -471,3 +504,169 @@ func TestMustDeepMerge(t *testing.T) {
 		MustDeepMerge("abc", "def", withMockDeepMergeError)
 	})
 }
+
+func TestAreMergeable(t *testing.T) {
+	type valid struct {
+		Tags []string `goalesce:"union"`
+	}
+	type invalid struct {
+		Name string `goalesce:"unknown"`
+	}
+	type parent struct {
+		Children []*invalid
+	}
+	tests := []struct {
+		name    string
+		t1      reflect.Type
+		t2      reflect.Type
+		opts    []Option
+		wantErr string
+	}{
+		{"same types", reflect.TypeOf(0), reflect.TypeOf(0), nil, ""},
+		{"nil types", nil, reflect.TypeOf(0), nil, ""},
+		{"different types", reflect.TypeOf(0), reflect.TypeOf(""), nil, "types do not match: int != string"},
+		{"valid tags", reflect.TypeOf(&valid{}), reflect.TypeOf(&valid{}), nil, ""},
+		{"invalid tags", reflect.TypeOf(invalid{}), reflect.TypeOf(invalid{}), nil, "field goalesce.invalid.Name: unknown merge strategy: unknown"},
+		{"nested invalid tags", reflect.TypeOf(parent{}), reflect.TypeOf(parent{}), nil, "field goalesce.invalid.Name: unknown merge strategy: unknown"},
+		{"lenient tags", reflect.TypeOf(invalid{}), reflect.TypeOf(invalid{}), []Option{WithLenientTags(nil)}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := AreMergeable(tt.t1, tt.t2, tt.opts...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMergeMany(t *testing.T) {
+	type config struct {
+		Name     string
+		Replicas int
+		Next     *config
+	}
+	shared := &config{Name: "shared"}
+	var pairs []Pair[*config]
+	var want []*config
+	for i := 0; i < 100; i++ {
+		pairs = append(pairs, Pair[*config]{
+			First:  &config{Name: "web", Next: shared},
+			Second: &config{Replicas: i, Next: shared},
+		})
+		want = append(want, &config{Name: "web", Replicas: i, Next: &config{Name: "shared"}})
+	}
+	t.Run("sequential", func(t *testing.T) {
+		got, err := MergeMany(pairs)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("parallel", func(t *testing.T) {
+		got, err := MergeMany(pairs, WithParallelism(8), WithSubtreeHashing())
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+	t.Run("more workers than pairs", func(t *testing.T) {
+		got, err := MergeMany(pairs[:2], WithParallelism(8))
+		assert.NoError(t, err)
+		assert.Equal(t, want[:2], got)
+	})
+	t.Run("empty", func(t *testing.T) {
+		got, err := MergeMany[*config](nil, WithParallelism(8))
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+	t.Run("error", func(t *testing.T) {
+		got, err := MergeMany([]Pair[string]{{"a", "b"}, {"", "c"}, {"d", "e"}}, WithParallelism(2), WithValidator(func(v interface{}) error {
+			if v == "c" || v == "e" {
+				return errors.New("invalid")
+			}
+			return nil
+		}))
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "pair 1: validation failed: invalid")
+	})
+}
+
+func TestMergeIfUnchanged(t *testing.T) {
+	type config struct {
+		Name     string
+		Replicas int
+	}
+	t.Run("unchanged", func(t *testing.T) {
+		base := &config{Name: "web", Replicas: 1}
+		current := &config{Name: "web", Replicas: 1}
+		got, err := MergeIfUnchanged(base, current, &config{Replicas: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, &config{Name: "web", Replicas: 3}, got)
+		assert.NotSame(t, current, got)
+	})
+	t.Run("changed", func(t *testing.T) {
+		base := &config{Name: "web", Replicas: 1}
+		current := &config{Name: "web", Replicas: 2}
+		got, err := MergeIfUnchanged(base, current, &config{Replicas: 3})
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "concurrent modification detected: 1 change(s) since base value")
+		var target *ConcurrentModificationError
+		assert.ErrorAs(t, err, &target)
+		assert.Equal(t, Diff{{Path: "Replicas", Kind: ChangeModified, From: 1, To: 2}}, target.Diff)
+	})
+	t.Run("changed time field only", func(t *testing.T) {
+		type record struct {
+			Name      string
+			UpdatedAt time.Time
+		}
+		t1, t2 := time.Unix(1, 0), time.Unix(2, 0)
+		got, err := MergeIfUnchanged(record{"web", t1}, record{"web", t2}, record{Name: "api"})
+		assert.Equal(t, record{}, got)
+		var target *ConcurrentModificationError
+		assert.ErrorAs(t, err, &target)
+		assert.Equal(t, Diff{{Path: "UpdatedAt", Kind: ChangeModified, From: t1, To: t2}}, target.Diff)
+	})
+	t.Run("diff error", func(t *testing.T) {
+		got, err := MergeIfUnchanged[interface{}](1, "a", 2)
+		assert.Nil(t, got)
+		assert.EqualError(t, err, "types do not match: int != string")
+	})
+	t.Run("merge error", func(t *testing.T) {
+		got, err := MergeIfUnchanged("abc", "abc", "def", withMockDeepMergeError)
+		assert.Equal(t, "", got)
+		assert.EqualError(t, err, "mock DeepMerge error")
+	})
+}
+
+func TestDeepMergeContext(t *testing.T) {
+	large := make([]map[string]int, 1000)
+	for i := range large {
+		large[i] = map[string]int{"a": i}
+	}
+	t.Run("not canceled", func(t *testing.T) {
+		got, err := DeepMergeContext(context.Background(), map[string]int{"a": 1}, map[string]int{"b": 2})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, got)
+	})
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := DeepMergeContext(ctx, large, large, WithDefaultSliceMergeByIndex())
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+	t.Run("context propagated", func(t *testing.T) {
+		type key struct{}
+		ctx := context.WithValue(context.Background(), key{}, "b")
+		got, err := DeepMergeContext(ctx, "a", "c", WithTypeMergerContext(reflect.TypeOf(""), func(ctx context.Context, v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf(ctx.Value(key{})), nil
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, "b", got)
+	})
+	t.Run("options not modified", func(t *testing.T) {
+		opts := make([]Option, 1, 2)
+		opts[0] = WithErrorOnCycle()
+		_, err := DeepMergeContext(context.Background(), 1, 2, opts...)
+		assert.NoError(t, err)
+		assert.Nil(t, opts[:2][1])
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Merger merges and copies values of type T with a fixed set of options. Contrary to DeepMerge and
+// DeepCopy, which process their options on every call, a Merger validates its options once, and
+// keeps a pool of internal mergers built from them: each pooled instance processes the options once,
+// when it is created, then reuses the resulting state, e.g. compiled struct plans, across calls;
+// this makes Mergers suitable for hot paths, e.g. merging configuration on every request. Mergers
+// are safe for concurrent use; concurrent calls may create several pooled instances, therefore
+// options, and the providers they hold, may be applied more than once.
+type Merger[T any] struct {
+	pool sync.Pool
+}
+
+// NewMerger creates a new Merger for values of type T with the given options. The options are
+// validated up front against T: an error is returned if DeepMerge would fail for any values of type
+// T because of the options or of the struct tags reachable from T, see AreMergeable.
+func NewMerger[T any](opts ...Option) (*Merger[T], error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if err := AreMergeable(t, t, opts...); err != nil {
+		return nil, err
+	}
+	m := &Merger[T]{}
+	m.pool.New = func() interface{} { return newCoalescer(opts...) }
+	return m, nil
+}
+
+// Merge merges the 2 values and returns the merged value, as DeepMerge(o1, o2, opts...) would.
+func (m *Merger[T]) Merge(o1, o2 T) (T, error) {
+	coalescer := m.acquire()
+	defer m.release(coalescer)
+	return deepMerge(coalescer, o1, o2)
+}
+
+// Copy deep-copies the value and returns the copy, as DeepCopy(o, opts...) would.
+func (m *Merger[T]) Copy(o T) (T, error) {
+	coalescer := m.acquire()
+	defer m.release(coalescer)
+	return deepCopy(coalescer, o)
+}
+
+// acquire returns a coalescer that is not in use by any other goroutine.
+func (m *Merger[T]) acquire() *coalescer {
+	return m.pool.Get().(*coalescer)
+}
+
+// release makes the given coalescer available for reuse.
+func (m *Merger[T]) release(coalescer *coalescer) {
+	coalescer.reset()
+	m.pool.Put(coalescer)
+}
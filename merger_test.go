@@ -0,0 +1,85 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMerger(t *testing.T) {
+	type config struct {
+		Name   string
+		Labels map[string]string
+		Tags   []string
+	}
+	t.Run("merge and copy", func(t *testing.T) {
+		m, err := NewMerger[*config](WithDefaultSliceSetUnionMerge())
+		require.NoError(t, err)
+		merged, err := m.Merge(&config{Name: "a", Tags: []string{"x"}}, &config{Labels: map[string]string{"k": "v"}, Tags: []string{"x", "y"}})
+		assert.NoError(t, err)
+		assert.Equal(t, &config{Name: "a", Labels: map[string]string{"k": "v"}, Tags: []string{"x", "y"}}, merged)
+		original := &config{Name: "a", Tags: []string{"x"}}
+		copied, err := m.Copy(original)
+		assert.NoError(t, err)
+		assert.Equal(t, original, copied)
+		assert.NotSame(t, original, copied)
+		copied, err = m.Copy(nil)
+		assert.NoError(t, err)
+		assert.Nil(t, copied)
+	})
+	t.Run("errors", func(t *testing.T) {
+		m, err := NewMerger[interface{}]()
+		require.NoError(t, err)
+		_, err = m.Merge(1, "a")
+		assert.EqualError(t, err, "types do not match: int != string")
+		// the merger is still usable after an error
+		merged, err := m.Merge(1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, merged)
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		type foo struct {
+			Field int `goalesce:"append"`
+		}
+		_, err := NewMerger[foo]()
+		assert.EqualError(t, err, "field goalesce.foo.Field: append strategy is only supported for slices")
+		_, err = NewMerger[config](WithFieldNameMatching(FieldNameMatchCaseInsensitive), WithAtomicFieldMerge(reflect.TypeOf(config{}), "Unknown"))
+		assert.EqualError(t, err, "struct type goalesce.config has no field matching Unknown")
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		m, err := NewMerger[config](WithDefaultSliceListAppendMerge())
+		require.NoError(t, err)
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					name := fmt.Sprint(i, j)
+					merged, err := m.Merge(config{Tags: []string{name}}, config{Name: name, Tags: []string{name}})
+					assert.NoError(t, err)
+					assert.Equal(t, config{Name: name, Tags: []string{name, name}}, merged)
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
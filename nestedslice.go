@@ -0,0 +1,91 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// nestedSliceMerger returns a merger for nested slices, e.g. [][]float64, that merges each nesting
+// level with the corresponding strategy: the first strategy applies to the outermost slices, the
+// second one to their elements, and so on. Nesting levels beyond the given strategies are merged
+// as usual.
+func (c *coalescer) nestedSliceMerger(strategies []string) DeepMergeFunc {
+	if len(strategies) == 0 {
+		return func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, fmt.Errorf("no merge strategies for nested slices of type %s", v1.Type().String())
+		}
+	}
+	var merger func(depth int) DeepMergeFunc
+	merger = func(depth int) DeepMergeFunc {
+		return func(v1, v2 reflect.Value) (reflect.Value, error) {
+			merge, err := c.sliceStrategyMerger(v1.Type(), strategies[depth])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("nesting level %d: %w", depth, err)
+			}
+			if inner := v1.Type().Elem(); depth+1 < len(strategies) {
+				if inner.Kind() != reflect.Slice {
+					return reflect.Value{}, fmt.Errorf("nesting level %d: expecting slice, got: %s", depth+1, inner.String())
+				}
+				// merge the inner slices with the next strategy, for the duration of this merge only
+				previous, found := c.sliceMergerOverrides[inner]
+				c.sliceMergerOverrides[inner] = merger(depth + 1)
+				defer func() {
+					if found {
+						c.sliceMergerOverrides[inner] = previous
+					} else {
+						delete(c.sliceMergerOverrides, inner)
+					}
+				}()
+			}
+			return merge(v1, v2)
+		}
+	}
+	return merger(0)
+}
+
+// sliceStrategyMerger returns the merger of the given slice type for the given strategy, expressed
+// as in MergeStrategyTag struct tags. Only slice strategies are supported: MergeStrategyAtomic,
+// MergeStrategyAppend, MergeStrategyUnion, MergeStrategyIndex and MergeStrategyID.
+func (c *coalescer) sliceStrategyMerger(sliceType reflect.Type, strategy string) (DeepMergeFunc, error) {
+	name, key := ParseMergeStrategyTag(strategy)
+	switch name {
+	case MergeStrategyAtomic:
+		return c.deepMergeAtomic, nil
+	case MergeStrategyAppend:
+		return c.deepMergeSliceWithListAppend, nil
+	case MergeStrategyUnion:
+		return func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return c.deepMergeSliceWithMergeKey(v1, v2, SliceUnion)
+		}, nil
+	case MergeStrategyIndex:
+		return c.deepMergeSliceByIndex, nil
+	case MergeStrategyID:
+		if key == "" {
+			return nil, fmt.Errorf("%s strategy must be followed by a colon and the merge key", MergeStrategyID)
+		}
+		elemType := indirect(sliceType.Elem())
+		if elemType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("expecting slice of struct or pointer thereto, got: %s", sliceType.String())
+		} else if err := checkMergeKeyFields(elemType, key); err != nil {
+			return nil, err
+		}
+		return func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return c.deepMergeSliceWithMergeKey(v1, v2, newMergeByField(key))
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown slice merge strategy: %s", strategy)
+}
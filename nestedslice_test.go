@@ -0,0 +1,138 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_coalescer_nestedSliceMerger(t *testing.T) {
+	type point struct {
+		Name string
+		X, Y int
+	}
+	tests := []struct {
+		name       string
+		v1         interface{}
+		v2         interface{}
+		strategies []string
+		opts       []Option
+		want       interface{}
+		wantErr    string
+	}{
+		{
+			name:       "index then atomic",
+			v1:         [][]float64{{1, 2, 3}, {4, 5}},
+			v2:         [][]float64{{6}},
+			strategies: []string{MergeStrategyIndex, MergeStrategyAtomic},
+			want:       [][]float64{{6}, {4, 5}},
+		},
+		{
+			name:       "index then index",
+			v1:         [][]float64{{1, 2, 3}, {4, 5}},
+			v2:         [][]float64{{6}},
+			strategies: []string{MergeStrategyIndex, MergeStrategyIndex},
+			want:       [][]float64{{6, 2, 3}, {4, 5}},
+		},
+		{
+			name:       "append then union",
+			v1:         [][]float64{{1, 2}},
+			v2:         [][]float64{{2, 3}},
+			strategies: []string{MergeStrategyAppend, MergeStrategyUnion},
+			want:       [][]float64{{1, 2}, {2, 3}},
+		},
+		{
+			name:       "index then id",
+			v1:         [][]point{{{"a", 1, 1}, {"b", 2, 2}}},
+			v2:         [][]point{{{"b", 0, 3}, {"c", 4, 4}}},
+			strategies: []string{MergeStrategyIndex, MergeStrategyID + ":Name"},
+			want:       [][]point{{{"a", 1, 1}, {"b", 2, 3}, {"c", 4, 4}}},
+		},
+		{
+			name:       "remaining levels merged as usual",
+			v1:         [][][]int{{{1, 2}}},
+			v2:         [][][]int{{{3}}},
+			strategies: []string{MergeStrategyIndex},
+			opts:       []Option{WithDefaultSliceMergeByIndex()},
+			want:       [][][]int{{{3, 2}}},
+		},
+		{
+			name:       "overrides default strategy",
+			v1:         [][]int{{1, 2}},
+			v2:         [][]int{{3}},
+			strategies: []string{MergeStrategyIndex, MergeStrategyAtomic},
+			opts:       []Option{WithDefaultSliceMergeByIndex()},
+			want:       [][]int{{3}},
+		},
+		{
+			name:       "no strategies",
+			v1:         [][]int{{1}},
+			v2:         [][]int{{2}},
+			strategies: nil,
+			wantErr:    "no merge strategies for nested slices of type [][]int",
+		},
+		{
+			name:       "unknown strategy",
+			v1:         [][]int{{1}},
+			v2:         [][]int{{2}},
+			strategies: []string{MergeStrategyIndex, "unknown"},
+			wantErr:    "at [0]: nesting level 1: unknown slice merge strategy: unknown",
+		},
+		{
+			name:       "too many strategies",
+			v1:         [][]int{{1}},
+			v2:         [][]int{{2}},
+			strategies: []string{MergeStrategyIndex, MergeStrategyIndex, MergeStrategyIndex},
+			wantErr:    "at [0]: nesting level 2: expecting slice, got: int",
+		},
+		{
+			name:       "id without key",
+			v1:         []point{{Name: "a"}},
+			v2:         []point{{Name: "b"}},
+			strategies: []string{MergeStrategyID},
+			wantErr:    "nesting level 0: id strategy must be followed by a colon and the merge key",
+		},
+		{
+			name:       "id unknown field",
+			v1:         []point{{Name: "a"}},
+			v2:         []point{{Name: "b"}},
+			strategies: []string{MergeStrategyID + ":Z"},
+			wantErr:    "nesting level 0: slice element type goalesce.point has no field named Z",
+		},
+		{
+			name:       "id not struct",
+			v1:         []int{1},
+			v2:         []int{2},
+			strategies: []string{MergeStrategyID + ":Z"},
+			wantErr:    "nesting level 0: expecting slice of struct or pointer thereto, got: []int",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(tt.opts...)
+			got, err := c.nestedSliceMerger(tt.strategies)(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got.Interface())
+			}
+			assert.Empty(t, c.sliceMergerOverrides)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// NumericAggregation is the way 2 numbers are combined when merged, see WithFieldNumericMerge.
+type NumericAggregation int
+
+const (
+	// NumericSum adds both numbers.
+	NumericSum NumericAggregation = iota
+	// NumericMin selects the smaller number.
+	NumericMin
+	// NumericMax selects the larger number.
+	NumericMax
+)
+
+func (a NumericAggregation) String() string {
+	switch a {
+	case NumericSum:
+		return MergeStrategySum
+	case NumericMin:
+		return MergeStrategyMin
+	case NumericMax:
+		return MergeStrategyMax
+	}
+	return fmt.Sprintf("NumericAggregation(%d)", int(a))
+}
+
+// newNumericMerger returns a DeepMergeFunc that merges 2 numbers, or pointers thereto, with the
+// given aggregation. Zero values and nil pointers are ignored. Sums that overflow the type of the
+// numbers are reported as errors, rather than wrapping around.
+func (c *coalescer) newNumericMerger(aggregation NumericAggregation) DeepMergeFunc {
+	return func(v1, v2 reflect.Value) (reflect.Value, error) {
+		if !isNumeric(indirect(v1.Type())) {
+			return reflect.Value{}, fmt.Errorf("expecting number or pointer thereto, got: %s", v1.Type().String())
+		}
+		if value, done := checkZero(v1, v2); done {
+			return c.deepCopy(value)
+		}
+		e1, e2 := reflect.Indirect(v1), reflect.Indirect(v2)
+		merged := reflect.New(e1.Type())
+		var overflow bool
+		switch e1.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n := aggregate(e1.Int(), e2.Int(), aggregation)
+			// the sum may also overflow int64 itself, in which case it wraps around
+			overflow = merged.Elem().OverflowInt(n) || aggregation == NumericSum && (e2.Int() > 0) != (n > e1.Int())
+			merged.Elem().SetInt(n)
+		case reflect.Float32, reflect.Float64:
+			n := aggregate(e1.Float(), e2.Float(), aggregation)
+			overflow = merged.Elem().OverflowFloat(n) || math.IsInf(n, 0) && !math.IsInf(e1.Float(), 0) && !math.IsInf(e2.Float(), 0)
+			merged.Elem().SetFloat(n)
+		default:
+			n := aggregate(e1.Uint(), e2.Uint(), aggregation)
+			overflow = merged.Elem().OverflowUint(n) || aggregation == NumericSum && n < e1.Uint()
+			merged.Elem().SetUint(n)
+		}
+		if overflow {
+			return reflect.Value{}, fmt.Errorf("%s of %v and %v overflows %s", aggregation, e1, e2, e1.Type().String())
+		}
+		if v1.Kind() == reflect.Ptr {
+			return merged, nil
+		}
+		return merged.Elem(), nil
+	}
+}
+
+func aggregate[T int64 | uint64 | float64](n1, n2 T, aggregation NumericAggregation) T {
+	switch aggregation {
+	case NumericMin:
+		return min(n1, n2)
+	case NumericMax:
+		return max(n1, n2)
+	}
+	return n1 + n2
+}
+
+func isNumeric(t reflect.Type) bool {
+	return isInteger(t) || t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+}
+
+func (c *coalescer) numericFieldMerger(field reflect.StructField, aggregation NumericAggregation) (DeepMergeFunc, error) {
+	if !isNumeric(indirect(field.Type)) {
+		return nil, fmt.Errorf("%s strategy is only supported for numbers and pointers thereto", aggregation)
+	}
+	return c.newNumericMerger(aggregation), nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_newNumericMerger(t *testing.T) {
+	type limits struct {
+		Quota    int     `goalesce:"sum"`
+		Disk     uint16  `goalesce:"sum"`
+		Ratio    float32 `goalesce:"min"`
+		Timeout  int64   `goalesce:"max"`
+		Replicas *int    `goalesce:"min"`
+	}
+	tests := []struct {
+		name string
+		v1   limits
+		v2   limits
+		want limits
+	}{
+		{
+			name: "zero",
+			v1:   limits{},
+			v2:   limits{},
+			want: limits{},
+		},
+		{
+			name: "v1 only",
+			v1:   limits{Quota: 1, Disk: 1, Ratio: 0.5, Timeout: 10, Replicas: intPtr(3)},
+			v2:   limits{},
+			want: limits{Quota: 1, Disk: 1, Ratio: 0.5, Timeout: 10, Replicas: intPtr(3)},
+		},
+		{
+			name: "v2 only",
+			v1:   limits{},
+			v2:   limits{Quota: 2, Disk: 2, Ratio: 0.25, Timeout: 20, Replicas: intPtr(2)},
+			want: limits{Quota: 2, Disk: 2, Ratio: 0.25, Timeout: 20, Replicas: intPtr(2)},
+		},
+		{
+			name: "both",
+			v1:   limits{Quota: 1, Disk: 1, Ratio: 0.5, Timeout: 10, Replicas: intPtr(3)},
+			v2:   limits{Quota: -3, Disk: 2, Ratio: 0.25, Timeout: 20, Replicas: intPtr(2)},
+			want: limits{Quota: -2, Disk: 3, Ratio: 0.25, Timeout: 20, Replicas: intPtr(2)},
+		},
+		{
+			name: "pointer to zero",
+			v1:   limits{Replicas: intPtr(3)},
+			v2:   limits{Replicas: intPtr(0)},
+			want: limits{Replicas: intPtr(0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			if tt.v1.Replicas != nil {
+				assert.NotSame(t, tt.v1.Replicas, got.Replicas)
+			}
+			if tt.v2.Replicas != nil {
+				assert.NotSame(t, tt.v2.Replicas, got.Replicas)
+			}
+		})
+	}
+	t.Run("overflow", func(t *testing.T) {
+		type counters struct {
+			U8  uint8   `goalesce:"sum"`
+			I8  int8    `goalesce:"sum"`
+			I64 int64   `goalesce:"sum"`
+			U64 uint64  `goalesce:"sum"`
+			F32 float32 `goalesce:"sum"`
+			F64 float64 `goalesce:"sum"`
+			Min uint8   `goalesce:"min"`
+		}
+		got, err := DeepMerge(
+			counters{U8: 200, I8: -100, I64: math.MaxInt64 - 1, U64: math.MaxUint64 - 1, F32: math.MaxFloat32, F64: math.MaxFloat64, Min: 255},
+			counters{U8: 55, I8: -28, I64: 1, U64: 1, F32: -1, F64: -math.MaxFloat64, Min: 1},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, counters{U8: 255, I8: -128, I64: math.MaxInt64, U64: math.MaxUint64, F32: math.MaxFloat32, Min: 1}, got)
+		for _, tt := range []struct {
+			name    string
+			v1, v2  counters
+			wantErr string
+		}{
+			{"uint8", counters{U8: 200}, counters{U8: 100}, "at U8: sum of 200 and 100 overflows uint8"},
+			{"uint8 boundary", counters{U8: 255}, counters{U8: 1}, "at U8: sum of 255 and 1 overflows uint8"},
+			{"int8 positive", counters{I8: 127}, counters{I8: 1}, "at I8: sum of 127 and 1 overflows int8"},
+			{"int8 negative", counters{I8: -128}, counters{I8: -1}, "at I8: sum of -128 and -1 overflows int8"},
+			{"int64", counters{I64: math.MaxInt64}, counters{I64: 1}, "at I64: sum of 9223372036854775807 and 1 overflows int64"},
+			{"int64 negative", counters{I64: math.MinInt64}, counters{I64: -1}, "at I64: sum of -9223372036854775808 and -1 overflows int64"},
+			{"uint64", counters{U64: math.MaxUint64}, counters{U64: 1}, "at U64: sum of 18446744073709551615 and 1 overflows uint64"},
+			{"float32", counters{F32: math.MaxFloat32}, counters{F32: math.MaxFloat32}, "at F32: sum of 3.4028235e+38 and 3.4028235e+38 overflows float32"},
+			{"float64", counters{F64: math.MaxFloat64}, counters{F64: math.MaxFloat64}, "at F64: sum of 1.7976931348623157e+308 and 1.7976931348623157e+308 overflows float64"},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := DeepMerge(tt.v1, tt.v2)
+				assert.EqualError(t, err, tt.wantErr)
+			})
+		}
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		c := newCoalescer()
+		_, err := c.newNumericMerger(NumericMax)(reflect.ValueOf("a"), reflect.ValueOf("b"))
+		assert.EqualError(t, err, "expecting number or pointer thereto, got: string")
+	})
+}
+
+func TestNumericAggregation_String(t *testing.T) {
+	assert.Equal(t, "sum", NumericSum.String())
+	assert.Equal(t, "min", NumericMin.String())
+	assert.Equal(t, "max", NumericMax.String())
+	assert.Equal(t, "NumericAggregation(42)", NumericAggregation(42).String())
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// oneOfFieldMerger returns a merger that merges the field with default merge semantics, then
+// checks that the merged value, if not zero, is one of the values allowed by the given strategy,
+// e.g. "oneof:debug|info|warn|error".
+func (c *coalescer) oneOfFieldMerger(field reflect.StructField, strategy string) (DeepMergeFunc, error) {
+	_, argument := ParseMergeStrategyTag(strategy)
+	if argument == "" {
+		return nil, fmt.Errorf("%s strategy must be followed by a colon and the allowed values", MergeStrategyOneOf)
+	}
+	values := strings.Split(argument, "|")
+	allowed := make([]interface{}, len(values))
+	for i, value := range values {
+		parsed, err := parseDefaultValue(indirect(field.Type), value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed value %q: %w", value, err)
+		}
+		allowed[i] = parsed.Interface()
+	}
+	return func(v1, v2 reflect.Value) (reflect.Value, error) {
+		merged, err := c.deepMerge(v1, v2)
+		if err != nil || merged.IsZero() {
+			return merged, err
+		}
+		actual := reflect.Indirect(merged).Interface()
+		for _, a := range allowed {
+			if reflect.DeepEqual(a, actual) {
+				return merged, nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("value %v is not one of: %s", actual, strings.Join(values, ", "))
+	}, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_oneOfFieldMerger(t *testing.T) {
+	type logging struct {
+		Level    string  `goalesce:"oneof:debug|info|warn|error"`
+		Verbose  *int    `goalesce:"oneof:1|2|3"`
+		Sampling float64 `goalesce:"oneof:0.5|1"`
+	}
+	type config struct {
+		Logging *logging
+	}
+	tests := []struct {
+		name    string
+		v1      config
+		v2      config
+		want    config
+		wantErr string
+	}{
+		{
+			name: "zero",
+			v1:   config{Logging: &logging{}},
+			v2:   config{Logging: &logging{}},
+			want: config{Logging: &logging{}},
+		},
+		{
+			name: "allowed",
+			v1:   config{Logging: &logging{Level: "info", Verbose: intPtr(1)}},
+			v2:   config{Logging: &logging{Level: "debug", Verbose: intPtr(3), Sampling: 0.5}},
+			want: config{Logging: &logging{Level: "debug", Verbose: intPtr(3), Sampling: 0.5}},
+		},
+		{
+			name: "v1 only",
+			v1:   config{Logging: &logging{Level: "warn"}},
+			v2:   config{Logging: &logging{}},
+			want: config{Logging: &logging{Level: "warn"}},
+		},
+		{
+			name:    "string not allowed",
+			v1:      config{Logging: &logging{Level: "info"}},
+			v2:      config{Logging: &logging{Level: "trace"}},
+			wantErr: "at Logging.Level: value trace is not one of: debug, info, warn, error",
+		},
+		{
+			name:    "pointer not allowed",
+			v1:      config{Logging: &logging{}},
+			v2:      config{Logging: &logging{Verbose: intPtr(4)}},
+			wantErr: "at Logging.Verbose: value 4 is not one of: 1, 2, 3",
+		},
+		{
+			name:    "float not allowed",
+			v1:      config{Logging: &logging{Sampling: 0.2}},
+			v2:      config{Logging: &logging{}},
+			wantErr: "at Logging.Sampling: value 0.2 is not one of: 0.5, 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
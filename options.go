@@ -14,7 +14,12 @@
 
 package goalesce
 
-import "reflect"
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+)
 
 // Option is an option that can be passed to DeepCopy or DeepMerge to customize the function
 // behavior.
@@ -26,6 +31,10 @@ type Option func(c *coalescer)
 // never be invalid values. The returned value must be of same type as the passed value. By
 // convention, when the function returns an invalid value and a nil error, it is assumed that the
 // function is delegating the copy to the main copy function. See examples for more.
+//
+// The values returned by custom copiers are trusted: they are used as is, without any additional
+// defensive copy. It is therefore the responsibility of custom copiers to return values that do not
+// share references with the passed value, if that is desired.
 type DeepCopyFunc func(v reflect.Value) (reflect.Value, error)
 
 // DeepMergeFunc is a function for merging objects. A deep merge function is expected to abide by
@@ -35,6 +44,10 @@ type DeepCopyFunc func(v reflect.Value) (reflect.Value, error)
 // also be of that same type. By convention, when the function returns an invalid value and a nil
 // error, it is assumed that the function is delegating the merge to the main merge function. See
 // examples for more.
+//
+// The values returned by custom mergers are trusted: they are used as is, without any additional
+// defensive copy. It is therefore the responsibility of custom mergers to return values that do not
+// share references with the passed values, if that is desired.
 type DeepMergeFunc func(v1, v2 reflect.Value) (reflect.Value, error)
 
 // DeepCopyFuncProvider is a factory for DeepCopyFunc instances. It takes the main DeepCopyFunc
@@ -49,10 +62,21 @@ type DeepCopyFuncProvider func(global DeepCopyFunc) DeepCopyFunc
 // internally. See examples for more.
 type DeepMergeFuncProvider func(globalMerger DeepMergeFunc, globalCopier DeepCopyFunc) DeepMergeFunc
 
+// ContextDeepCopyFunc is like DeepCopyFunc, but also receives the context of the copy operation,
+// see WithContext. This allows custom copiers to access values attached to the operation by the
+// caller, e.g. tenant or locale information.
+type ContextDeepCopyFunc func(ctx context.Context, v reflect.Value) (reflect.Value, error)
+
+// ContextDeepMergeFunc is like DeepMergeFunc, but also receives the context of the merge operation,
+// see WithContext. This allows custom mergers to access values attached to the operation by the
+// caller, e.g. tenant or locale information.
+type ContextDeepMergeFunc func(ctx context.Context, v1, v2 reflect.Value) (reflect.Value, error)
+
 // COMMON OPTIONS
 
 // WithErrorOnCycle instructs the operation to return an error when a cycle is detected. By default,
-// cycles are replaced with a nil pointer.
+// cycles are replaced with a nil pointer, or with a nil interface when the cycle goes through an
+// interface.
 func WithErrorOnCycle() Option {
 	return func(c *coalescer) {
 		c.errorOnCycle = true
@@ -61,6 +85,50 @@ func WithErrorOnCycle() Option {
 
 // DEEP COPY OPTIONS
 
+// WithContext attaches the given context to the operation. The context is passed to custom
+// copiers and mergers registered with WithTypeCopierContext, WithTypeMergerContext and
+// WithFieldMergerContext; by default, they receive context.Background(). The operation is also
+// aborted with the context's error shortly after the context is canceled, see DeepMergeContext.
+func WithContext(ctx context.Context) Option {
+	return func(c *coalescer) {
+		c.ctx = ctx
+	}
+}
+
+// WithUnexportedFieldCopy instructs the operation to deep-copy unexported struct fields as well,
+// using unsafe reflection, instead of leaving them zero. This makes it possible to copy types whose
+// state is not entirely exported, e.g. third-party types. Use with caution: the internals of such
+// types may hold values that must not be copied, such as locks, or references that are meant to
+// be shared. Unexported fields are still not merged; when a struct is merged, its unexported fields
+// are only copied if the struct as a whole is copied, e.g. because the other struct is zero. This is
+// equivalent to WithUnexportedFieldPolicy(UnexportedFieldsCopy).
+func WithUnexportedFieldCopy() Option {
+	return WithUnexportedFieldPolicy(UnexportedFieldsCopy)
+}
+
+// WithDeepCopyMethods instructs the operation to delegate the copy of values to their own DeepCopy or
+// DeepCopyInto methods, if any, following the convention of controller-gen: for a type T, the
+// methods func (in *T) DeepCopy() *T and func (in *T) DeepCopyInto(out *T) are honored, as well as
+// their equivalents with value receivers. This makes it possible to preserve the hand-tuned copy
+// logic of types such as Kubernetes API types. Custom copiers registered with other options take
+// precedence. Merges are not affected, except when a value is copied as a whole, e.g. because the
+// other value is zero.
+func WithDeepCopyMethods() Option {
+	return func(c *coalescer) {
+		c.deepCopyMethods = true
+	}
+}
+
+// WithUnexportedFieldPolicy determines what happens when structs with unexported fields are copied
+// or merged. By default, unexported fields are silently left zero (UnexportedFieldsSkip); the other
+// policies make this data loss explicit (UnexportedFieldsError), or avoid it by sharing such structs
+// as a whole (UnexportedFieldsShare) or by copying their unexported fields (UnexportedFieldsCopy).
+func WithUnexportedFieldPolicy(policy UnexportedFieldPolicy) Option {
+	return func(c *coalescer) {
+		c.unexportedFieldPolicy = policy
+	}
+}
+
 // WithAtomicCopy causes the given type to be copied with atomic semantics, instead of its default
 // copy semantics. When a non-zero value of this type is copied, the value is returned as is.
 func WithAtomicCopy(t reflect.Type) Option {
@@ -69,6 +137,18 @@ func WithAtomicCopy(t reflect.Type) Option {
 	}
 }
 
+// WithImmutableType declares that values of the given type are immutable and can therefore be
+// shared instead of deep-copied, e.g. interned strings, parsed templates or compiled regular
+// expressions. Values of this type are copied and merged with atomic semantics: a copy returns the
+// value as is, and a merge returns the second value if it is non-zero, or the first one otherwise.
+// This option can also be used for types whose internals cannot be correctly deep-copied through
+// reflection. To declare an immutable type for all operations, use RegisterImmutableType instead.
+func WithImmutableType(t reflect.Type) Option {
+	return func(c *coalescer) {
+		c.registerImmutableType(t)
+	}
+}
+
 // WithTypeCopier will defer the copy of the given type to the given custom copier. This option does
 // not allow the type copier to access the global DeepCopyFunc instance. For that, use
 // WithTypeCopierProvider instead.
@@ -88,6 +168,70 @@ func WithTypeCopierProvider(t reflect.Type, provider DeepCopyFuncProvider) Optio
 	}
 }
 
+// WithTypeCopierContext will defer the copy of the given type to the given custom copier, that
+// receives the context of the operation, see WithContext. Like other custom copiers, it can return
+// an invalid value and a nil error to delegate the copy to the main copy function.
+func WithTypeCopierContext(t reflect.Type, copier ContextDeepCopyFunc) Option {
+	return func(c *coalescer) {
+		c.typeCopiers[t] = func(v reflect.Value) (reflect.Value, error) {
+			return copier(c.context(), v)
+		}
+	}
+}
+
+// WithGenericTypeCopier will defer the copy of all instantiations of a generic type to the given
+// custom copier. The generic type is designated by any of its instantiations, e.g.
+// reflect.TypeOf(List[int]{}) designates List[T] for all T. Copiers registered for a specific
+// instantiation with WithTypeCopier take precedence.
+func WithGenericTypeCopier(t reflect.Type, copier DeepCopyFunc) Option {
+	return WithGenericTypeCopierProvider(t, func(DeepCopyFunc) DeepCopyFunc {
+		return copier
+	})
+}
+
+// WithGenericTypeCopierProvider is the equivalent of WithTypeCopierProvider for all instantiations
+// of a generic type. See WithGenericTypeCopier. If the given type is not an instantiation of a
+// generic type, this option behaves like WithTypeCopierProvider.
+func WithGenericTypeCopierProvider(t reflect.Type, provider DeepCopyFuncProvider) Option {
+	origin, generic := genericOrigin(t)
+	if !generic {
+		return WithTypeCopierProvider(t, provider)
+	}
+	return func(c *coalescer) {
+		c.genericTypeCopiers[origin] = provider(c.deepCopy)
+	}
+}
+
+// WithInterfaceCopier will defer the copy of all the types implementing the given interface type to
+// the given custom copier. The copier applies to the dynamic types of values, which are resolved at
+// copy time, so that implementations need not be known in advance. If a type implements several
+// interfaces with a registered copier, the first one registered wins. Copiers registered for a
+// specific type with WithTypeCopier, or for a generic type, take precedence.
+func WithInterfaceCopier(ifaceType reflect.Type, copier DeepCopyFunc) Option {
+	return WithInterfaceCopierProvider(ifaceType, func(DeepCopyFunc) DeepCopyFunc {
+		return copier
+	})
+}
+
+// WithInterfaceCopierProvider is the equivalent of WithTypeCopierProvider for all the types
+// implementing the given interface type. See WithInterfaceCopier. If the given type is not an
+// interface type, this option behaves like WithTypeCopierProvider.
+func WithInterfaceCopierProvider(ifaceType reflect.Type, provider DeepCopyFuncProvider) Option {
+	if ifaceType.Kind() != reflect.Interface {
+		return WithTypeCopierProvider(ifaceType, provider)
+	}
+	return func(c *coalescer) {
+		copier := provider(c.deepCopy)
+		for i, ic := range c.interfaceCopiers {
+			if ic.ifaceType == ifaceType {
+				c.interfaceCopiers[i].copier = copier
+				return
+			}
+		}
+		c.interfaceCopiers = append(c.interfaceCopiers, interfaceCopier{ifaceType, copier})
+	}
+}
+
 // DEEP MERGE OPTIONS
 
 // WithAtomicMerge causes the given type to be merged with atomic semantics, instead of its default
@@ -123,6 +267,26 @@ func WithTrileanMerge() Option {
 	return WithAtomicMerge(reflect.PointerTo(reflect.TypeOf(false)))
 }
 
+// WithLatestTimeMerge causes all time.Time values to be merged by selecting the later of the two
+// timestamps, instead of their default merge semantics. Zero timestamps are ignored. This is
+// typically useful for fields like LastSeen. To apply this strategy to specific fields only, use
+// WithFieldLatestTimeMerge or the `goalesce:latest` struct tag.
+func WithLatestTimeMerge() Option {
+	return func(c *coalescer) {
+		c.typeMergers[timeType] = c.deepMergeTimeLatest
+	}
+}
+
+// WithEarliestTimeMerge causes all time.Time values to be merged by selecting the earlier of the
+// two timestamps, instead of their default merge semantics. Zero timestamps are ignored. To apply
+// this strategy to specific fields only, use WithFieldEarliestTimeMerge or the
+// `goalesce:earliest` struct tag.
+func WithEarliestTimeMerge() Option {
+	return func(c *coalescer) {
+		c.typeMergers[timeType] = c.deepMergeTimeEarliest
+	}
+}
+
 // WithTypeMerger will defer the merge of the given type to the given custom merger. This option
 // does not allow the type merger to access the global DeepMergeFunc instance. For
 // that, use WithTypeMergerProvider instead.
@@ -142,6 +306,193 @@ func WithTypeMergerProvider(t reflect.Type, provider DeepMergeFuncProvider) Opti
 	}
 }
 
+// WithTypeMergerContext will defer the merge of the given type to the given custom merger, that
+// receives the context of the operation, see WithContext. Like other custom mergers, it can return
+// an invalid value and a nil error to delegate the merge to the main merge function.
+func WithTypeMergerContext(t reflect.Type, merger ContextDeepMergeFunc) Option {
+	return func(c *coalescer) {
+		c.typeMergers[t] = func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return merger(c.context(), v1, v2)
+		}
+	}
+}
+
+// WithGenericTypeMerger will defer the merge of all instantiations of a generic type to the given
+// custom merger. The generic type is designated by any of its instantiations, e.g.
+// reflect.TypeOf(List[int]{}) designates List[T] for all T. Mergers registered for a specific
+// instantiation with WithTypeMerger take precedence. Since the merger is shared by all
+// instantiations, it must not assume a specific type argument; it typically delegates to the
+// global DeepMergeFunc instance, see WithGenericTypeMergerProvider.
+func WithGenericTypeMerger(t reflect.Type, merger DeepMergeFunc) Option {
+	return WithGenericTypeMergerProvider(t, func(DeepMergeFunc, DeepCopyFunc) DeepMergeFunc {
+		return merger
+	})
+}
+
+// WithGenericTypeMergerProvider is the equivalent of WithTypeMergerProvider for all instantiations
+// of a generic type. See WithGenericTypeMerger. If the given type is not an instantiation of a
+// generic type, this option behaves like WithTypeMergerProvider.
+func WithGenericTypeMergerProvider(t reflect.Type, provider DeepMergeFuncProvider) Option {
+	origin, generic := genericOrigin(t)
+	if !generic {
+		return WithTypeMergerProvider(t, provider)
+	}
+	return func(c *coalescer) {
+		c.genericTypeMergers[origin] = provider(c.deepMerge, c.deepCopy)
+	}
+}
+
+// WithKindMerger will defer the merge of all values of the given kind, e.g. reflect.Map,
+// reflect.Slice or reflect.Ptr, to the given custom merger, replacing the built-in behavior for that
+// kind. Like other custom mergers, it can return an invalid value and a nil error to delegate the
+// merge to the built-in behavior. Path mergers and mergers registered for specific types take
+// precedence; fields with a strategy declared in a struct tag are not affected. This option does not
+// allow the merger to access the global DeepMergeFunc instance. For that, use
+// WithKindMergerProvider instead.
+func WithKindMerger(kind reflect.Kind, merger DeepMergeFunc) Option {
+	return WithKindMergerProvider(kind, func(DeepMergeFunc, DeepCopyFunc) DeepMergeFunc {
+		return merger
+	})
+}
+
+// WithKindMergerProvider is the equivalent of WithTypeMergerProvider for all values of the given
+// kind. See WithKindMerger. Beware that delegating the merge of a value to the global DeepMergeFunc
+// instance calls the kind merger again; to delegate to the built-in behavior, return an invalid
+// value instead.
+func WithKindMergerProvider(kind reflect.Kind, provider DeepMergeFuncProvider) Option {
+	return func(c *coalescer) {
+		c.kindMergers[kind] = provider(c.deepMerge, c.deepCopy)
+	}
+}
+
+// WithInterfaceMerger will defer the merge of all the types implementing the given interface type
+// to the given custom merger. The merger applies to the dynamic types of values, which are resolved
+// at merge time, so that implementations need not be known in advance. If a type implements
+// several interfaces with a registered merger, the first one registered wins. Mergers registered
+// for a specific type with WithTypeMerger, or for a generic type, take precedence.
+func WithInterfaceMerger(ifaceType reflect.Type, merger DeepMergeFunc) Option {
+	return WithInterfaceMergerProvider(ifaceType, func(DeepMergeFunc, DeepCopyFunc) DeepMergeFunc {
+		return merger
+	})
+}
+
+// WithInterfaceMergerProvider is the equivalent of WithTypeMergerProvider for all the types
+// implementing the given interface type. See WithInterfaceMerger. If the given type is not an
+// interface type, this option behaves like WithTypeMergerProvider.
+func WithInterfaceMergerProvider(ifaceType reflect.Type, provider DeepMergeFuncProvider) Option {
+	if ifaceType.Kind() != reflect.Interface {
+		return WithTypeMergerProvider(ifaceType, provider)
+	}
+	return func(c *coalescer) {
+		merger := provider(c.deepMerge, c.deepCopy)
+		for i, im := range c.interfaceMergers {
+			if im.ifaceType == ifaceType {
+				c.interfaceMergers[i].merger = merger
+				return
+			}
+		}
+		c.interfaceMergers = append(c.interfaceMergers, interfaceMerger{ifaceType, merger})
+	}
+}
+
+// WithContainerAdapter causes the given container type to be copied and merged through the given
+// ContainerAdapter, instead of its default copy and merge semantics. When copying, each entry of
+// the container is deep-copied. When merging, entries are merged key by key, with the same
+// semantics as regular maps: entries existing in both containers are deep-merged, other entries
+// are deep-copied.
+func WithContainerAdapter(containerType reflect.Type, adapter ContainerAdapter) Option {
+	return func(c *coalescer) {
+		c.typeCopiers[containerType] = func(v reflect.Value) (reflect.Value, error) {
+			return c.deepCopyContainer(adapter, v)
+		}
+		c.typeMergers[containerType] = func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return c.deepMergeContainer(adapter, v1, v2)
+		}
+	}
+}
+
+// WithMapNoNewKeys restricts map merges to the keys of the first map: entries of the second map
+// whose keys do not exist in the first map are ignored, and only existing entries can be updated.
+// This applies to all maps, including nested ones; in particular, a nil first map remains nil. If
+// the given function is not nil, it is called with the path of each ignored entry, e.g.
+// "Settings[unknown]".
+func WithMapNoNewKeys(ignored func(path string)) Option {
+	return func(c *coalescer) {
+		if ignored == nil {
+			ignored = func(string) {}
+		}
+		c.mapNoNewKeys = ignored
+	}
+}
+
+// WithMapAddOnly restricts map merges to adding new entries: entries of the second map whose keys do
+// not exist in the first map are added, but existing entries of the first map are never modified,
+// not even merged. This applies to all maps, including nested ones. This is useful to seed missing
+// defaults into user-managed maps, without overwriting the user's entries.
+func WithMapAddOnly() Option {
+	return func(c *coalescer) {
+		c.mapAddOnly = true
+	}
+}
+
+// WithMapAtomicValues applies atomic semantics to the values of maps of the given type: keys are
+// unioned as usual, but when both maps hold the same key, the value of the second map replaces the
+// value of the first one wholesale, unless it is zero, instead of being merged recursively. This is
+// useful when map values are opaque documents, e.g. nested JSON objects, that must not be spliced
+// together.
+func WithMapAtomicValues(mapType reflect.Type) Option {
+	return func(c *coalescer) {
+		c.mapAtomicValues[mapType] = true
+	}
+}
+
+// WithMapKeyMerger will defer the merge of the values of the given key, in maps of the given type,
+// to the given custom merger. The key must be of the map's key type, e.g. a string for a
+// map[string]interface{}. This is useful for dynamic maps, where values of the same type must be
+// merged differently depending on their key, e.g. to append the values of an "args" key. The merger
+// is only invoked when both maps hold the key; as with other custom mergers, it can return an
+// invalid value to fall back to the default merge.
+func WithMapKeyMerger(mapType reflect.Type, key interface{}, merger DeepMergeFunc) Option {
+	return func(c *coalescer) {
+		if c.mapKeyMergers[mapType] == nil {
+			c.mapKeyMergers[mapType] = make(map[interface{}]DeepMergeFunc)
+		}
+		c.mapKeyMergers[mapType][key] = merger
+	}
+}
+
+// WithDefaultMapNullDeletion causes entries of the second map whose values are nil pointers or nil
+// interfaces to remove the corresponding keys from the merged map, instead of being merged. This
+// matches the deletion semantics of JSON Merge Patch (RFC 7386), where a null member removes the
+// target member. This applies to all maps; see WithMapNullDeletion to apply it to specific map
+// types only.
+func WithDefaultMapNullDeletion() Option {
+	return func(c *coalescer) {
+		c.mapNullDeletion = true
+	}
+}
+
+// WithMapNullDeletion is like WithDefaultMapNullDeletion, but only applies to maps of the given
+// type, e.g. map[string]*Config.
+func WithMapNullDeletion(mapType reflect.Type) Option {
+	return func(c *coalescer) {
+		c.mapNullDeletions[mapType] = true
+	}
+}
+
+// WithFirstValuePriority flips the default merge semantics, so that the first value is favored over
+// the second one whenever both are non-zero and cannot be merged further: atomic values, slices and
+// arrays without a specific merger, and interfaces implemented by different runtime types, are
+// merged by keeping the first value. Zero values are still replaced. This is useful to apply
+// defaults underneath an existing configuration, without swapping the arguments, which would also
+// invert the order of appended slices. Explicit strategies, such as MergeStrategyAppend or
+// MergeStrategyLatest, are not affected.
+func WithFirstValuePriority() Option {
+	return func(c *coalescer) {
+		c.firstValuePriority = true
+	}
+}
+
 // WithZeroEmptySliceMerge instructs the merger to consider empty slices as zero (nil) slices. This
 // changes the default behavior: when merging a non-empty slice with an empty slice, normally the
 // empty slice is returned, but with this option, the non-empty slice is returned.
@@ -151,6 +502,140 @@ func WithZeroEmptySliceMerge() Option {
 	}
 }
 
+// WithIdentityShortCircuit instructs the merger to detect, before descending into two values, when
+// they are identical: either non-nil pointers, maps or slices referencing the same memory, or equal
+// values of a type that contains no references (e.g. a struct of strings and ints). In that case,
+// the values are not merged: a copy of the first value is returned immediately. This is useful
+// when merging large objects with themselves, or with identical snapshots of themselves.
+//
+// This option assumes that merging a value with itself yields the same value. This is true for
+// the default merge semantics, but not for list-append semantics, nor for some custom mergers;
+// also, default values declared for fields inside identical values are not applied.
+func WithIdentityShortCircuit() Option {
+	return func(c *coalescer) {
+		c.identityShortCircuit = true
+	}
+}
+
+// WithSubtreeHashing instructs the merger to compute structural hashes of the values being merged,
+// and to skip merging subtrees that are equal on both sides: a copy of the first value is returned
+// instead. Hashes are memoized during the operation, so that subtrees that differ are detected
+// cheaply at every level; subtrees with equal hashes are compared with reflect.DeepEqual, to rule
+// out collisions. This is useful when merging large values that are mostly identical.
+//
+// The same caveats as for WithIdentityShortCircuit apply. Besides, the values being merged must not
+// be modified during the merge, e.g. by custom mergers.
+func WithSubtreeHashing() Option {
+	return func(c *coalescer) {
+		c.subtreeHasher = newSubtreeHasher()
+	}
+}
+
+// WithMemoization instructs the operation to memoize the copies and merges of pointers: when the
+// same pointer is reachable from many places, its target is only copied once, and all the copies
+// of the pointer share the same copied target; likewise, when the same pair of pointers is merged
+// many times, the merge is only performed once. This saves time and memory when values share large
+// subtrees, and preserves that sharing in the result.
+//
+// Memoized merges are reused regardless of where they happen: this option should therefore not be
+// combined with options that behave differently depending on the path of the merged values, such as
+// WithPathMerger, WithFieldHook or WithFieldPermission. Besides, the values being copied or merged
+// must not be modified during the operation, e.g. by custom functions.
+func WithMemoization() Option {
+	return func(c *coalescer) {
+		c.memoizer = newMemoizer()
+	}
+}
+
+// WithPatchDirectives instructs the merger to honor strategic-merge-style directives embedded in
+// maps with string keys, typically maps of type map[string]interface{} obtained by unmarshalling
+// JSON or YAML documents. The following directives are recognized in the second map:
+//
+//   - `$patch: replace`: the first map is replaced entirely with the second map.
+//   - `$patch: delete`: the map is deleted; when the map is a value of an enclosing map, the
+//     corresponding key is removed from the merged enclosing map.
+//   - `$patch: merge`: the maps are merged normally; this is the default.
+//   - `$retainKeys: [key1, key2, ...]`: after the merge, keys from the first map that are not in
+//     the list are removed from the merged map.
+//
+// Directive keys never appear in merged or copied values.
+func WithPatchDirectives() Option {
+	return func(c *coalescer) {
+		c.patchDirectives = true
+	}
+}
+
+// WithValidator instructs DeepMerge to validate the merged value with the given function before
+// returning it. If validation fails, DeepMerge returns the zero-value and a *ValidationError, or
+// several of them joined with errors.Join. This option integrates seamlessly with
+// go-playground/validator:
+//
+//	validate := validator.New()
+//	merged, err := goalesce.DeepMerge(v1, v2, goalesce.WithValidator(validate.Struct))
+//
+// In that case, each field violation is reported as a separate *ValidationError whose Path is the
+// violating field's path, relative to the merged value.
+func WithValidator(validator ValidateFunc) Option {
+	return func(c *coalescer) {
+		c.validator = validator
+	}
+}
+
+// WithParallelism instructs MergeMany to merge up to the given number of pairs concurrently. By
+// default, pairs are merged sequentially. This option has no effect on other functions. When it is
+// used, custom mergers and copiers, as well as validators, must be safe for concurrent use.
+func WithParallelism(n int) Option {
+	return func(c *coalescer) {
+		c.parallelism = n
+	}
+}
+
+// WithRecorder instructs DeepMerge to record every merge to the given writer, as a JSON-encoded
+// MergeRecord followed by a newline. A record holds the merged values, the merge result or error,
+// and a fingerprint of the options; it can be re-executed with ReplayMerge, e.g. to reproduce a
+// merge issue. If redact is not nil, it is called on each value before it is recorded, to remove
+// sensitive data. The writer is called under a lock, and can be shared by concurrent merges.
+func WithRecorder(w io.Writer, redact RedactFunc) Option {
+	r := &recorder{w: w, redact: redact}
+	return func(c *coalescer) {
+		c.recorder = r
+	}
+}
+
+// WithFieldPermission instructs the merger to call the given function for each struct field to be
+// merged, and to prevent the second value from modifying the field if the function returns false.
+// In that case, the field keeps the first value's value, unless WithErrorOnFieldPermissionDenied is
+// also used. The permission is also checked for struct fields reached through pointers,
+// interfaces, map entries, slices and arrays that are absent or nil in the first value. Slices and
+// arrays that would otherwise be merged atomically are then merged element by element instead, each
+// element of the second value being merged with the element at the same index in the first value,
+// so that the permissions of their elements' fields are checked.
+func WithFieldPermission(permission FieldPermissionFunc) Option {
+	return func(c *coalescer) {
+		c.fieldPermission = permission
+	}
+}
+
+// WithErrorOnFieldPermissionDenied instructs the merger to return an error when the second value
+// attempts to modify a struct field that it is not allowed to modify. By default, such
+// modifications are silently ignored. See WithFieldPermission.
+func WithErrorOnFieldPermissionDenied() Option {
+	return func(c *coalescer) {
+		c.errorOnFieldPermission = true
+	}
+}
+
+// WithErrorAccumulation instructs the merger to continue merging when a struct field cannot be
+// merged, instead of aborting at the first error. The field then keeps the first value's value,
+// and the error is collected. Once the merge is complete, the merged value is returned along with
+// all the collected errors, joined with errors.Join. This is useful to report all the invalid
+// fields of a large value in one pass.
+func WithErrorAccumulation() Option {
+	return func(c *coalescer) {
+		c.errorAccumulation = true
+	}
+}
+
 // WithDefaultSliceListAppendMerge applies list-append merge semantics to all slices to be merged.
 func WithDefaultSliceListAppendMerge() Option {
 	return func(c *coalescer) {
@@ -174,11 +659,23 @@ func WithDefaultSliceSetUnionMerge() Option {
 func WithDefaultSliceMergeByIndex() Option {
 	return func(c *coalescer) {
 		c.sliceMerger = func(v1, v2 reflect.Value) (reflect.Value, error) {
-			return c.deepMergeSliceWithMergeKey(v1, v2, SliceIndex)
+			return c.deepMergeSliceByIndex(v1, v2)
 		}
 	}
 }
 
+// WithStrictIndexMerge causes merge-by-index to fail when both slices are non-nil and of different
+// lengths, instead of keeping the trailing elements of the longer slice. This is useful when slices
+// are expected to be parallel, and a length mismatch denotes corrupted input. This option applies
+// to all merge-by-index strategies: WithDefaultSliceMergeByIndex, WithSliceMergeByIndex,
+// WithFieldMergeByIndex and the `goalesce:index` struct tag. Arrays of a given type always have the
+// same length, and are therefore not affected.
+func WithStrictIndexMerge() Option {
+	return func(c *coalescer) {
+		c.strictIndexMerge = true
+	}
+}
+
 // WithDefaultArrayMergeByIndex applies merge-by-index semantics to all arrays to be merged.
 func WithDefaultArrayMergeByIndex() Option {
 	return func(c *coalescer) {
@@ -196,6 +693,42 @@ func WithSliceSetUnionMerge(sliceType reflect.Type) Option {
 	return WithSliceMergeByKeyFunc(sliceType, SliceUnion)
 }
 
+// WithListAppendDedup removes duplicate elements from slices merged with list-append semantics, e.g.
+// with WithSliceListAppendMerge or the MergeStrategyAppend struct tag: when several elements have
+// the same merge key, only the last occurrence is kept. Elements are compared as with
+// WithSliceSetUnionMerge; use WithListAppendDedupByKeyFunc to compare the elements of a given slice
+// type by merge key instead, e.g. by ID.
+func WithListAppendDedup() Option {
+	return func(c *coalescer) {
+		c.listAppendDedup = true
+	}
+}
+
+// WithListAppendDedupByKeyFunc removes duplicate elements from slices of the given type merged with
+// list-append semantics, comparing elements by the merge keys returned by the given
+// SliceMergeKeyFunc. See WithListAppendDedup.
+func WithListAppendDedupByKeyFunc(sliceType reflect.Type, mergeKeyFunc SliceMergeKeyFunc) Option {
+	return func(c *coalescer) {
+		c.listAppendDedupKeys[sliceType] = mergeKeyFunc
+	}
+}
+
+// WithSliceSetDifferenceMerge applies set-difference merge semantics to the given slice type: the
+// elements of the second slice are removed from the first slice, and the merged slice never contains
+// elements of the second slice. Elements are compared as with WithSliceSetUnionMerge. This is useful
+// for overlays that express entries to remove.
+func WithSliceSetDifferenceMerge(sliceType reflect.Type) Option {
+	return WithSliceDifferenceByKeyFunc(sliceType, SliceUnion)
+}
+
+// WithSliceDifferenceByKeyFunc is like WithSliceSetDifferenceMerge, but elements are compared by
+// the merge keys returned by the given SliceMergeKeyFunc.
+func WithSliceDifferenceByKeyFunc(sliceType reflect.Type, mergeKeyFunc SliceMergeKeyFunc) Option {
+	return func(c *coalescer) {
+		c.sliceDifferences[sliceType] = mergeKeyFunc
+	}
+}
+
 // WithSliceListAppendMerge applies list-append merge semantics to the given slice type.
 func WithSliceListAppendMerge(sliceType reflect.Type) Option {
 	return func(c *coalescer) {
@@ -206,7 +739,9 @@ func WithSliceListAppendMerge(sliceType reflect.Type) Option {
 // WithSliceMergeByIndex applies merge-by-index semantics to the given slice type. The given
 // mergeKeyFunc will be used to extract the element merge key.
 func WithSliceMergeByIndex(sliceType reflect.Type) Option {
-	return WithSliceMergeByKeyFunc(sliceType, SliceIndex)
+	return func(c *coalescer) {
+		c.sliceMergers[sliceType] = c.deepMergeSliceByIndex
+	}
 }
 
 // WithArrayMergeByIndex applies merge-by-index semantics to the given slice type. The given
@@ -222,13 +757,23 @@ func WithArrayMergeByIndex(arrayType reflect.Type) Option {
 // WithSliceMergeByID applies merge-by-key semantics to slices whose elements are of some struct
 // type, or a pointer thereto. The passed field name will be used to extract the element's merge
 // key; therefore, the field should generally be a unique identifier or primary key for objects of
-// this type.
-func WithSliceMergeByID(sliceOfStructType reflect.Type, elemField string) Option {
+// this type. When more fields are passed, the merge key is a composite key made of all the fields,
+// e.g. WithSliceMergeByID(sliceType, "Namespace", "Name"): elements match when all fields are equal.
+func WithSliceMergeByID(sliceOfStructType reflect.Type, elemField string, moreElemFields ...string) Option {
+	key := strings.Join(append([]string{elemField}, moreElemFields...), MergeKeySeparator)
 	return func(c *coalescer) {
-		WithSliceMergeByKeyFunc(sliceOfStructType, newMergeByField(elemField))(c)
+		WithSliceMergeByKeyFunc(sliceOfStructType, c.mergeByField(key))(c)
 	}
 }
 
+// WithSliceMergeByMapKey applies merge-by-key semantics to slices whose elements are maps, or
+// pointers or interfaces thereto: elements are matched when their entries with the given key are
+// equal, see SliceMergeByMapKey. This is the equivalent of WithSliceMergeByID for untyped lists, e.g.
+// []map[string]interface{} decoded from JSON or YAML.
+func WithSliceMergeByMapKey(sliceOfMapType reflect.Type, key interface{}) Option {
+	return WithSliceMergeByKeyFunc(sliceOfMapType, SliceMergeByMapKey(key))
+}
+
 // WithSliceMergeByKeyFunc applies merge-by-key semantics to the given slice type. The given
 // SliceMergeKeyFunc will be used to extract the element merge key.
 func WithSliceMergeByKeyFunc(sliceType reflect.Type, mergeKeyFunc SliceMergeKeyFunc) Option {
@@ -239,9 +784,135 @@ func WithSliceMergeByKeyFunc(sliceType reflect.Type, mergeKeyFunc SliceMergeKeyF
 	}
 }
 
+// WithMergeKey applies merge-by-key semantics to the slice type S, extracting the element merge key
+// with the given function. This is a type-safe alternative to WithSliceMergeByKeyFunc and
+// WithSliceMergeByID: the key function operates on the element type directly, and field accesses
+// are checked at compile time, e.g. WithMergeKey[[]User](func(u User) string { return u.ID }).
+func WithMergeKey[S ~[]E, E any, K comparable](keyFunc func(E) K) Option {
+	sliceType := reflect.TypeOf((*S)(nil)).Elem()
+	return WithSliceMergeByKeyFunc(sliceType, func(_ int, elem reflect.Value) (reflect.Value, error) {
+		// a nil interface element is passed to the key function as the zero E
+		e, _ := elem.Interface().(E)
+		key := keyFunc(e)
+		return reflect.ValueOf(&key).Elem(), nil
+	})
+}
+
+// WithSliceORSetMerge applies observed-remove set (OR-Set) semantics to the given slice type. The
+// given SliceMergeKeyFunc will be used to extract the element merge key, and the given
+// SliceVersionFunc will be used to extract the element version stamp and tombstone marker.
+//
+// When both slices contain an element with the same merge key, the element with the highest version
+// wins, and is copied as is to the merged slice; if both versions are equal, live elements win over
+// tombstones ("add-wins" semantics). Tombstones are retained in the merged slice so that removals
+// propagate. The merged slice is sorted by merge key.
+//
+// With this strategy, merges are commutative, associative and idempotent, as long as distinct
+// updates of a given element carry distinct version stamps. Therefore, replicas converge to the
+// same state regardless of the order in which they are merged.
+func WithSliceORSetMerge(sliceType reflect.Type, mergeKeyFunc SliceMergeKeyFunc, versionFunc SliceVersionFunc) Option {
+	return func(c *coalescer) {
+		c.sliceMergers[sliceType] = func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return c.deepMergeSliceWithORSet(v1, v2, mergeKeyFunc, versionFunc)
+		}
+	}
+}
+
+// WithDefaultSliceKeyOrder determines the order of the elements of all slices merged by key, e.g.
+// with WithSliceMergeByID, WithSliceMergeByKeyFunc or the corresponding struct tags. By default,
+// elements appear in the order of the first slice, followed by the elements that exist only in the
+// second slice (SliceKeyOrderFirst). This option has no effect on OR-Set merges, which are always
+// sorted by merge key.
+func WithDefaultSliceKeyOrder(order SliceKeyOrder) Option {
+	return func(c *coalescer) {
+		c.defaultSliceKeyOrder = order
+	}
+}
+
+// WithSliceKeyOrder determines the order of the elements of slices of the given type, when they are
+// merged by key. See WithDefaultSliceKeyOrder.
+func WithSliceKeyOrder(sliceType reflect.Type, order SliceKeyOrder) Option {
+	return func(c *coalescer) {
+		c.sliceKeyOrders[sliceType] = order
+	}
+}
+
+// WithSortedSliceResult sorts the slices of the given type that are merged by key, e.g. with
+// WithSliceSetUnionMerge, WithSliceMergeByID or the MergeStrategyID struct tag, with the given less
+// function, so that the merged slice has a canonical order regardless of the order of the merged
+// slices. The sort is stable. To sort merged slices by merge key instead, use WithSliceKeyOrder
+// with SliceKeyOrderSorted.
+func WithSortedSliceResult(sliceType reflect.Type, less func(e1, e2 reflect.Value) bool) Option {
+	return func(c *coalescer) {
+		c.sliceSorts[sliceType] = less
+	}
+}
+
+// WithSliceDeleteMarker declares deletion markers for slices of the given type, when they are merged
+// by key, e.g. with WithSliceMergeByID or the MergeStrategyID struct tag: elements of the second
+// slice identified as markers remove the elements of the first slice with the same merge key, and
+// do not appear in the merged slice. For example, the following option removes the elements whose
+// Deleted field is true:
+//
+//	WithSliceDeleteMarker(reflect.TypeOf([]*User{}), SliceDeleteMarkerField("Deleted"))
+//
+// With WithPatchDirectives, map elements holding a `$patch: delete` directive are always deletion
+// markers.
+func WithSliceDeleteMarker(sliceType reflect.Type, marker SliceDeleteMarker) Option {
+	return func(c *coalescer) {
+		c.sliceDeleteMarkers[sliceType] = marker
+	}
+}
+
+// WithNestedSliceMerge applies different merge strategies to the nesting levels of the given
+// nested slice type, e.g. [][]float64. Strategies are expressed as in MergeStrategyTag struct tags:
+// the first strategy applies to the slices of the given type, the second one to their elements,
+// and so on. For example, the following option merges the outer slices by index, and the inner
+// slices atomically:
+//
+//	WithNestedSliceMerge(reflect.TypeOf([][]float64{}), MergeStrategyIndex, MergeStrategyAtomic)
+//
+// Supported strategies are MergeStrategyAtomic, MergeStrategyAppend, MergeStrategyUnion,
+// MergeStrategyIndex and MergeStrategyID followed by a colon and the merge key, e.g. "id:Name".
+// Nesting levels beyond the given strategies are merged as usual. Invalid strategies cause the
+// merge to fail.
+func WithNestedSliceMerge(sliceType reflect.Type, strategies ...string) Option {
+	return func(c *coalescer) {
+		c.sliceMergers[sliceType] = c.nestedSliceMerger(strategies)
+	}
+}
+
+// WithSliceElementMerger merges the matching elements of slices of the given type with the given
+// custom merger, instead of the default merge, e.g. to merge the inner slices of a nested slice type
+// differently from the outer ones. It only applies when the slices themselves are merged by key,
+// e.g. with WithSliceMergeByIndex, WithSliceSetUnionMerge or WithSliceMergeByID; deeper nesting
+// levels are merged as usual. To use built-in strategies at each nesting level, see
+// WithNestedSliceMerge.
+func WithSliceElementMerger(sliceType reflect.Type, elemMerger DeepMergeFunc) Option {
+	return func(c *coalescer) {
+		c.sliceElemMergers[sliceType] = elemMerger
+	}
+}
+
+// WithFieldNestedSliceMerge merges the given struct field, which must be of a nested slice type,
+// with different merge strategies for each nesting level. See WithNestedSliceMerge.
+func WithFieldNestedSliceMerge(structType reflect.Type, field string, strategies ...string) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.nestedSliceMerger(strategies)
+	}
+}
+
 // WithFieldMerger merges the given struct field with the given custom merger. This option does not
 // allow the type merger to access the parent DeepMergeFunc instance being created. For that, use
 // WithFieldMergerProvider instead.
+//
+// The field can also be a path of fields separated by dots, e.g. "Spec.Template.Labels", relative
+// to the given struct type, going through nested structs and pointers thereto. The merger then only
+// applies to that nested field when a value of the given struct type is merged, whereas a merger
+// registered for the nested field's struct type would apply to all values of that type.
 func WithFieldMerger(structType reflect.Type, field string, merger DeepMergeFunc) Option {
 	return WithFieldMergerProvider(structType, field, func(DeepMergeFunc, DeepCopyFunc) DeepMergeFunc {
 		return merger
@@ -251,9 +922,17 @@ func WithFieldMerger(structType reflect.Type, field string, merger DeepMergeFunc
 // WithFieldMergerProvider merges the given struct field with a custom merger that will be obtained
 // by calling the given provider function with the global DeepMergeFunc and DeepCopyFunc instances.
 // This option allows the type merger to access those instances in order to delegate the merge and
-// copy of nested objects. See ExampleWithFieldMergerProvider.
+// copy of nested objects. See ExampleWithFieldMergerProvider. The field can also be a path of fields,
+// see WithFieldMerger.
 func WithFieldMergerProvider(structType reflect.Type, field string, provider DeepMergeFuncProvider) Option {
 	return func(c *coalescer) {
+		if strings.Contains(field, ".") {
+			if c.fieldPathMergers[structType] == nil {
+				c.fieldPathMergers[structType] = make(map[string]DeepMergeFunc)
+			}
+			c.fieldPathMergers[structType][field] = provider(c.deepMerge, c.deepCopy)
+			return
+		}
 		if c.fieldMergers[structType] == nil {
 			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
 		}
@@ -261,6 +940,184 @@ func WithFieldMergerProvider(structType reflect.Type, field string, provider Dee
 	}
 }
 
+// WithFieldMergerContext merges the given struct field with the given custom merger, that receives
+// the context of the operation, see WithContext. The field can also be a path of fields, see
+// WithFieldMerger.
+func WithFieldMergerContext(structType reflect.Type, field string, merger ContextDeepMergeFunc) Option {
+	return func(c *coalescer) {
+		WithFieldMerger(structType, field, func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return merger(c.context(), v1, v2)
+		})(c)
+	}
+}
+
+// WithPathMerger merges the values located at the given path with the given custom merger,
+// regardless of their types. This is useful when values of the same type appear at several
+// locations and must be merged differently depending on their location. Paths are relative to the
+// merged value, and use the syntax of the paths reported in errors and diffs, e.g.
+// "Spec.Template.Labels", "Spec.Containers[0].Env" or `Labels["app"]`; "*" can be used as a field
+// name or in brackets to match any field, slice index or map key, e.g. "Spec.Containers[*].Env".
+//
+// Path mergers take precedence over all other mergers; when several paths match a value, the last
+// registered one wins. Note that values nested in slices are only visited if the slices are merged
+// element by element, e.g. with WithSliceMergeByIndex or WithSliceMergeByID. An invalid path makes
+// the merge fail.
+func WithPathMerger(path string, merger DeepMergeFunc) Option {
+	return WithPathMergerProvider(path, func(DeepMergeFunc, DeepCopyFunc) DeepMergeFunc {
+		return merger
+	})
+}
+
+// WithPathMergerProvider is like WithPathMerger, but the merger is obtained by calling the given
+// provider function with the global DeepMergeFunc and DeepCopyFunc instances.
+func WithPathMergerProvider(path string, provider DeepMergeFuncProvider) Option {
+	return func(c *coalescer) {
+		c.addPathMerger(path, provider(c.deepMerge, c.deepCopy))
+	}
+}
+
+// WithPathAtomic merges the values located at the given path with atomic semantics, see
+// WithPathMerger for the path syntax.
+func WithPathAtomic(path string) Option {
+	return func(c *coalescer) {
+		c.addPathMerger(path, c.deepMergeAtomic)
+	}
+}
+
+// WithFieldDefault declares a default value for the given struct field. When the merged value of
+// that field is zero, which with default merge semantics happens when both values are zero, the
+// default value is deep-copied and used instead. The default value must be assignable to the field
+// type. This allows defaults to be applied during the merge itself, rather than in a separate pass.
+func WithFieldDefault(structType reflect.Type, field string, value interface{}) Option {
+	return func(c *coalescer) {
+		if c.fieldDefaults[structType] == nil {
+			c.fieldDefaults[structType] = make(map[string]reflect.Value)
+		}
+		c.fieldDefaults[structType][field] = reflect.ValueOf(value)
+	}
+}
+
+// WithFieldAllowlist restricts the merge of the given struct type to the given fields: only these
+// fields are merged, while all other fields are retained from the first value, even if they are
+// zero. This is useful when only a subset of a struct may be modified, e.g. through a user-facing
+// API. The allowlist also applies to structs that only exist in the second value, e.g. behind a nil
+// pointer or under a new map key: they are merged with a zero-value rather than copied as is. This
+// option can be used several times for the same struct type, in which case the allowed fields
+// accumulate.
+func WithFieldAllowlist(structType reflect.Type, fields ...string) Option {
+	return func(c *coalescer) {
+		if c.fieldAllowlists[structType] == nil {
+			c.fieldAllowlists[structType] = make(map[string]bool)
+		}
+		for _, field := range fields {
+			c.fieldAllowlists[structType][field] = true
+		}
+	}
+}
+
+// WithFieldNameMatching determines how the field names passed to options are matched against the
+// fields of struct types, e.g. with WithFieldMerger, WithFieldDefault or WithSliceMergeByID. By
+// default, names must match Go field names exactly. With FieldNameMatchCaseInsensitive and/or
+// FieldNameMatchJSONTag, names are also matched case-insensitively and/or against json tag names;
+// an exact match of a Go field name always wins. This is useful when options are generated from
+// external schemas. Names that match no field, or several fields, cause the merge of their struct
+// type to fail, with an error listing the candidates.
+func WithFieldNameMatching(matching FieldNameMatching) Option {
+	return func(c *coalescer) {
+		c.fieldNameMatching = matching
+	}
+}
+
+// WithWarnings collects recoverable issues encountered during the operation into the given slice,
+// instead of silently ignoring them:
+//
+//   - non-zero unexported struct fields, that are not copied nor merged;
+//   - unknown strategies ignored because of WithLenientTags;
+//   - slices of different lengths merged by index, whose trailing elements are kept as is;
+//   - map entries ignored because of WithMapNoNewKeys.
+//
+// Warnings are appended to the slice; with MergeMany, warnings of concurrent merges are safely
+// appended to the same slice.
+func WithWarnings(dst *[]Warning) Option {
+	w := &warnings{dst: dst}
+	return func(c *coalescer) {
+		c.warnings = w
+	}
+}
+
+// WithFieldHook registers a hook that is called after each struct field, map entry, and slice or
+// array element is merged, e.g. to emit audit events when security-relevant fields are overridden.
+// Entries and elements that exist only in one of the values, and are thus copied rather than merged,
+// are not reported. This option can be used several times to register several hooks, which are
+// called in registration order.
+func WithFieldHook(hook FieldHookFunc) Option {
+	return func(c *coalescer) {
+		c.fieldHooks = append(c.fieldHooks, hook)
+	}
+}
+
+// WithTrace instructs the merger to write a trace of its decisions to the given writer: the type of
+// each value being merged, the merger or strategy chosen for it, and the shortcuts taken, e.g. when
+// one of the values is zero. Lines are indented according to the depth of the values. This is
+// useful to understand why a value was merged in a certain way; write errors are ignored.
+func WithTrace(w io.Writer) Option {
+	t := &tracer{w: w}
+	return func(c *coalescer) {
+		c.tracer = t
+	}
+}
+
+// WithInstrumentation reports the statistics of each copy or merge to the given Instrumentation,
+// once the operation completes: the number of values merged and copied, the number of cycles
+// detected, the duration, and the error if any. This allows exposing metrics about the operations,
+// e.g. to Prometheus.
+func WithInstrumentation(instrumentation Instrumentation) Option {
+	return func(c *coalescer) {
+		c.instrumentation = instrumentation
+	}
+}
+
+// WithMaxElements limits the total number of slice elements and map entries visited by the
+// operation to the given number, so that copying or merging adversarial values cannot allocate
+// unbounded memory. Each element of each slice, and each entry of each map, copied or merged counts
+// towards the limit; when it is exceeded, the operation fails with a *LimitError. A limit of zero
+// or less means no limit, which is the default.
+func WithMaxElements(n int) Option {
+	return func(c *coalescer) {
+		c.maxElements = n
+	}
+}
+
+// WithDryRun instructs the merger to record the changes that the merge would make to the first
+// value into the given slice, instead of returning the merged value: the merge returns the zero
+// value of the merged type instead. The changes are computed as with DeepDiff, between the first
+// value and the merged value; this is useful to implement dry-run modes in tools built on top of
+// this library.
+//
+// A dry run is not cheaper than a real merge: the merged value is fully built, then diffed against
+// the first value, and finally discarded.
+//
+// Changes are appended to the slice; with MergeMany, changes of concurrent merges are safely
+// appended to the same slice.
+func WithDryRun(changes *[]Change) Option {
+	d := &dryRun{dst: changes}
+	return func(c *coalescer) {
+		c.dryRun = d
+	}
+}
+
+// WithLenientTags instructs the merger to tolerate unknown merge strategies in struct tags, e.g.
+// strategies introduced by a newer version of this library. Fields with unknown strategies are
+// merged as if they had no tag, instead of failing the whole merge. Each time an unknown strategy
+// is encountered, the given function, if not nil, is called with an error describing it, e.g. to
+// log a warning.
+func WithLenientTags(warn func(err error)) Option {
+	return func(c *coalescer) {
+		c.lenientTags = true
+		c.lenientTagsWarn = warn
+	}
+}
+
 // WithFieldListAppendMerge merges the given struct field with list-append semantics. The field must
 // be of slice type. This is the programmatic equivalent of adding a `goalesce:append` struct tag to
 // that field.
@@ -284,17 +1141,25 @@ func WithFieldSetUnionMerge(structType reflect.Type, field string) Option {
 // be of slice type. This is the programmatic equivalent of adding a `goalesce:index` struct tag to
 // that field.
 func WithFieldMergeByIndex(structType reflect.Type, field string) Option {
-	return WithFieldMergeByKeyFunc(structType, field, SliceIndex)
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.deepMergeSliceByIndex
+	}
 }
 
 // WithFieldMergeByID merges the given struct field with merge-by-key semantics. The field must be
 // of slice type. The slice element type must be of some other struct type, or a pointer thereto.
 // The passed key must be a valid field name for that struct type and will be used to extract the
 // slice element's merge key; therefore, that field should generally be a unique identifier or
-// primary key for objects of this type. This is the programmatic equivalent of adding a
-// `goalesce:id:key` struct tag to the struct field.
+// primary key for objects of this type. The key can also be a composite key, e.g. "Namespace+Name",
+// see MergeKeySeparator. This is the programmatic equivalent of adding a `goalesce:id:key` struct
+// tag to the struct field.
 func WithFieldMergeByID(structType reflect.Type, field string, key string) Option {
-	return WithFieldMergeByKeyFunc(structType, field, newMergeByField(key))
+	return func(c *coalescer) {
+		WithFieldMergeByKeyFunc(structType, field, c.mergeByField(key))(c)
+	}
 }
 
 // WithFieldMergeByKeyFunc merges the given struct field with merge-by-key semantics. The field must
@@ -324,3 +1189,106 @@ func WithAtomicFieldMerge(structType reflect.Type, field string) Option {
 		c.fieldMergers[structType][field] = c.deepMergeAtomic
 	}
 }
+
+// WithFieldKeepFirst causes the given field to be merged with "keep-first" semantics: when 2
+// non-zero-values of this field are merged, the first value is kept, and the second one is
+// discarded. This is the programmatic equivalent of adding a `goalesce:keep` struct tag to that
+// field.
+func WithFieldKeepFirst(structType reflect.Type, field string) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.deepMergeKeepFirst
+	}
+}
+
+// WithFieldMustMatch causes the merge to fail with a *ConflictError when 2 non-zero-values of the
+// given field are not deeply equal. This is the programmatic equivalent of adding a
+// `goalesce:mustmatch` struct tag to that field.
+func WithFieldMustMatch(structType reflect.Type, field string) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.deepMergeMustMatch
+	}
+}
+
+// WithFieldLatestRecordMerge merges the given struct field atomically, by keeping the value whose
+// timestamp field with the given name is the later of the two, that is, with "last-writer-wins"
+// semantics. The field must be of a struct type, or a pointer thereto, and the timestamp field must
+// be of type time.Time, or a pointer thereto. This is the programmatic equivalent of adding a
+// `goalesce:latest:<timestampField>` struct tag to that field.
+func WithFieldLatestRecordMerge(structType reflect.Type, field string, timestampField string) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.newRecordTimeMerger(timestampField, true)
+	}
+}
+
+// WithFieldLatestTimeMerge merges the given struct field by selecting the later of the two
+// timestamps. The field must be of type time.Time, or a pointer thereto. This is the programmatic
+// equivalent of adding a `goalesce:latest` struct tag to that field.
+func WithFieldLatestTimeMerge(structType reflect.Type, field string) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.deepMergeTimeLatest
+	}
+}
+
+// WithFieldEarliestTimeMerge merges the given struct field by selecting the earlier of the two
+// timestamps. The field must be of type time.Time, or a pointer thereto. This is the programmatic
+// equivalent of adding a `goalesce:earliest` struct tag to that field.
+func WithFieldEarliestTimeMerge(structType reflect.Type, field string) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.deepMergeTimeEarliest
+	}
+}
+
+// WithFieldSemverMaxMerge merges the given struct field by parsing both values as semantic versions
+// and selecting the highest one. The field must be of type string, or a pointer thereto. Empty
+// strings are ignored; unparseable versions cause the merge to fail. This is the programmatic
+// equivalent of adding a `goalesce:semverMax` struct tag to that field.
+func WithFieldSemverMaxMerge(structType reflect.Type, field string) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.deepMergeSemverMax
+	}
+}
+
+// WithFieldBitwiseOrMerge merges the given struct field by combining both values with a bitwise OR,
+// which is useful for bit flags, e.g. permission masks. The field must be of an integer type, or a
+// pointer thereto. This is the programmatic equivalent of adding a `goalesce:bitor` struct tag to
+// that field.
+func WithFieldBitwiseOrMerge(structType reflect.Type, field string) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.deepMergeBitwiseOr
+	}
+}
+
+// WithFieldNumericMerge merges the given struct field by combining both numbers with the given
+// aggregation, e.g. NumericSum to add resource quotas, or NumericMax to keep the larger timeout. The
+// field must be of an integer or floating-point type, or a pointer thereto. Zero values and nil
+// pointers are ignored; sums overflowing the field's type fail the merge. This is the programmatic
+// equivalent of adding a `goalesce:sum`, `goalesce:min` or `goalesce:max` struct tag to that field.
+func WithFieldNumericMerge(structType reflect.Type, field string, aggregation NumericAggregation) Option {
+	return func(c *coalescer) {
+		if c.fieldMergers[structType] == nil {
+			c.fieldMergers[structType] = make(map[string]DeepMergeFunc)
+		}
+		c.fieldMergers[structType][field] = c.newNumericMerger(aggregation)
+	}
+}
@@ -15,9 +15,13 @@
 package goalesce
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -100,6 +104,14 @@ func TestWithTypeCopier(t *testing.T) {
 		assert.NoError(t, err)
 		assert.True(t, called)
 	})
+	t.Run("result used as is", func(t *testing.T) {
+		result := intPtr(42)
+		got, err := DeepCopy(intPtr(1), WithTypeCopier(reflect.TypeOf(intPtr(0)), func(v reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf(result), nil
+		}))
+		assert.NoError(t, err)
+		assert.Same(t, result, got)
+	})
 	t.Run("slice", func(t *testing.T) {
 		called := false
 		c := newCoalescer(
@@ -146,6 +158,64 @@ func TestWithTypeCopierProvider(t *testing.T) {
 	assert.Equal(t, 2, called)
 }
 
+func TestWithGenericTypeCopier(t *testing.T) {
+	c := newCoalescer(WithGenericTypeCopier(reflect.TypeOf(genericList[int]{}), func(v reflect.Value) (reflect.Value, error) {
+		return reflect.Zero(v.Type()), nil
+	}))
+	assert.NotNil(t, c.genericTypeCopiers["github.com/adutra/goalesce.genericList"])
+	got, err := c.deepCopy(reflect.ValueOf(genericList[string]{Items: []string{"a"}}))
+	assert.Equal(t, genericList[string]{}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithGenericTypeCopierProvider(t *testing.T) {
+	t.Run("generic", func(t *testing.T) {
+		called := 0
+		c := newCoalescer(
+			WithGenericTypeCopierProvider(reflect.TypeOf(genericList[int]{}), func(DeepCopyFunc) DeepCopyFunc {
+				called++
+				return func(v reflect.Value) (reflect.Value, error) {
+					called++
+					return reflect.Value{}, nil
+				}
+			}))
+		v := genericList[string]{Items: []string{"a"}}
+		got, err := c.deepCopy(reflect.ValueOf(v))
+		assert.Equal(t, v, got.Interface())
+		assertNotSame(t, v, got.Interface())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, called)
+	})
+	t.Run("non generic", func(t *testing.T) {
+		c := newCoalescer(
+			WithGenericTypeCopierProvider(reflect.TypeOf(map[string]int{}), func(DeepCopyFunc) DeepCopyFunc {
+				return func(v reflect.Value) (reflect.Value, error) {
+					return v, nil
+				}
+			}))
+		assert.NotNil(t, c.typeCopiers[reflect.TypeOf(map[string]int{})])
+		assert.Empty(t, c.genericTypeCopiers)
+	})
+}
+
+func TestWithUnexportedFieldCopy(t *testing.T) {
+	c := newCoalescer(WithUnexportedFieldCopy())
+	assert.Equal(t, UnexportedFieldsCopy, c.unexportedFieldPolicy)
+}
+
+func TestWithDeepCopyMethods(t *testing.T) {
+	c := newCoalescer(WithDeepCopyMethods())
+	assert.True(t, c.deepCopyMethods)
+	got, err := c.deepCopy(reflect.ValueOf(&podSpec{Image: "nginx"}))
+	assert.Equal(t, &podSpec{Image: "nginx", Copies: 1}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithUnexportedFieldPolicy(t *testing.T) {
+	c := newCoalescer(WithUnexportedFieldPolicy(UnexportedFieldsShare))
+	assert.Equal(t, UnexportedFieldsShare, c.unexportedFieldPolicy)
+}
+
 func TestWithAtomicCopy(t *testing.T) {
 	v := intPtr(1)
 	c := newCoalescer(WithAtomicCopy(reflect.TypeOf(v)))
@@ -155,6 +225,20 @@ func TestWithAtomicCopy(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestWithImmutableType(t *testing.T) {
+	v1 := intPtr(1)
+	v2 := intPtr(2)
+	c := newCoalescer(WithImmutableType(reflect.TypeOf(v1)))
+	assert.NotNil(t, c.typeCopiers[reflect.TypeOf(v1)])
+	assert.NotNil(t, c.typeMergers[reflect.TypeOf(v1)])
+	got, err := c.deepCopy(reflect.ValueOf(v1))
+	assert.Same(t, v1, got.Interface())
+	assert.NoError(t, err)
+	got, err = c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+	assert.Same(t, v2, got.Interface())
+	assert.NoError(t, err)
+}
+
 func TestWithAtomicMerge(t *testing.T) {
 	v1 := intPtr(1)
 	v2 := intPtr(0)
@@ -174,6 +258,26 @@ func TestWithTrileanMerge(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestWithLatestTimeMerge(t *testing.T) {
+	early := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCoalescer(WithLatestTimeMerge())
+	assert.NotNil(t, c.typeMergers[reflect.TypeOf(time.Time{})])
+	got, err := c.deepMerge(reflect.ValueOf(late), reflect.ValueOf(early))
+	assert.Equal(t, late, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithEarliestTimeMerge(t *testing.T) {
+	early := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCoalescer(WithEarliestTimeMerge())
+	assert.NotNil(t, c.typeMergers[reflect.TypeOf(time.Time{})])
+	got, err := c.deepMerge(reflect.ValueOf(timePtr(early)), reflect.ValueOf(timePtr(late)))
+	assert.Equal(t, timePtr(early), got.Interface())
+	assert.NoError(t, err)
+}
+
 func TestWithTypeMerger(t *testing.T) {
 	t.Run("map", func(t *testing.T) {
 		called := false
@@ -217,6 +321,14 @@ func TestWithTypeMerger(t *testing.T) {
 		assert.NoError(t, err)
 		assert.True(t, called)
 	})
+	t.Run("result used as is", func(t *testing.T) {
+		result := intPtr(42)
+		got, err := DeepMerge(intPtr(1), intPtr(2), WithTypeMerger(reflect.TypeOf(intPtr(0)), func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.ValueOf(result), nil
+		}))
+		assert.NoError(t, err)
+		assert.Same(t, result, got)
+	})
 	t.Run("slice", func(t *testing.T) {
 		called := false
 		c := newCoalescer(
@@ -402,6 +514,126 @@ func TestWithTypeMergerProvider(t *testing.T) {
 	assert.Equal(t, 2, called)
 }
 
+func TestWithGenericTypeMerger(t *testing.T) {
+	c := newCoalescer(WithGenericTypeMerger(reflect.TypeOf(genericList[int]{}), func(v1, v2 reflect.Value) (reflect.Value, error) {
+		return v1, nil
+	}))
+	assert.NotNil(t, c.genericTypeMergers["github.com/adutra/goalesce.genericList"])
+	got, err := c.deepMerge(reflect.ValueOf(genericList[string]{Items: []string{"a"}}), reflect.ValueOf(genericList[string]{Items: []string{"b"}}))
+	assert.Equal(t, genericList[string]{Items: []string{"a"}}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithKindMerger(t *testing.T) {
+	c := newCoalescer(WithKindMerger(reflect.Map, keepFirst))
+	assert.NotNil(t, c.kindMergers[reflect.Map])
+	type config struct {
+		Labels map[string]string
+		Nested map[string]map[string]int
+		Tags   []string
+	}
+	v1 := config{Labels: map[string]string{"a": "1"}, Nested: map[string]map[string]int{"a": {"x": 1}}, Tags: []string{"a"}}
+	v2 := config{Labels: map[string]string{"b": "2"}, Nested: map[string]map[string]int{"b": {"y": 2}}, Tags: []string{"b"}}
+	got, err := c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+	assert.Equal(t, config{Labels: v1.Labels, Nested: v1.Nested, Tags: v2.Tags}, got.Interface())
+	assert.NoError(t, err)
+	t.Run("type merger takes precedence", func(t *testing.T) {
+		c := newCoalescer(WithKindMerger(reflect.Map, keepFirst), WithTypeMerger(reflect.TypeOf(map[string]string{}), func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return v2, nil
+		}))
+		got, err := c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.Equal(t, config{Labels: v2.Labels, Nested: v1.Nested, Tags: v2.Tags}, got.Interface())
+		assert.NoError(t, err)
+	})
+	t.Run("delegate to built-in behavior", func(t *testing.T) {
+		c := newCoalescer(WithKindMerger(reflect.Map, func(v1, v2 reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, nil
+		}))
+		got, err := c.deepMerge(reflect.ValueOf(v1.Labels), reflect.ValueOf(v2.Labels))
+		assert.Equal(t, map[string]string{"a": "1", "b": "2"}, got.Interface())
+		assert.NoError(t, err)
+	})
+}
+
+func TestWithKindMergerProvider(t *testing.T) {
+	called := 0
+	c := newCoalescer(WithKindMergerProvider(reflect.Ptr, func(merger DeepMergeFunc, copier DeepCopyFunc) DeepMergeFunc {
+		called++
+		return func(v1, v2 reflect.Value) (reflect.Value, error) {
+			called++
+			// merge the pointer targets, but always return a new pointer
+			merged, err := merger(v1.Elem(), v2.Elem())
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			ptr := reflect.New(merged.Type())
+			ptr.Elem().Set(merged)
+			return ptr, nil
+		}
+	}))
+	got, err := c.deepMerge(reflect.ValueOf(intPtr(1)), reflect.ValueOf(intPtr(2)))
+	assert.Equal(t, intPtr(2), got.Interface())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, called)
+}
+
+func TestWithInterfaceMerger(t *testing.T) {
+	c := newCoalescer(WithInterfaceMerger(resourceType, keepFirst), WithInterfaceMerger(resourceType, deepMergeExcluded))
+	assert.Len(t, c.interfaceMergers, 1)
+	got, err := c.deepMerge(reflect.ValueOf(configMap{map[string]string{"a": "1"}}), reflect.ValueOf(configMap{map[string]string{"b": "2"}}))
+	assert.Equal(t, configMap{}, got.Interface())
+	assert.NoError(t, err)
+	t.Run("not an interface", func(t *testing.T) {
+		c := newCoalescer(WithInterfaceMerger(reflect.TypeOf(configMap{}), keepFirst))
+		assert.Empty(t, c.interfaceMergers)
+		assert.NotNil(t, c.typeMergers[reflect.TypeOf(configMap{})])
+	})
+}
+
+func TestWithInterfaceCopier(t *testing.T) {
+	copier := func(v reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(&secret{}), nil
+	}
+	c := newCoalescer(WithInterfaceCopier(resourceType, copier))
+	assert.Len(t, c.interfaceCopiers, 1)
+	got, err := c.deepCopy(reflect.ValueOf(&secret{map[string][]byte{"a": nil}}))
+	assert.Equal(t, &secret{}, got.Interface())
+	assert.NoError(t, err)
+	t.Run("not an interface", func(t *testing.T) {
+		c := newCoalescer(WithInterfaceCopier(reflect.TypeOf(configMap{}), copier))
+		assert.Empty(t, c.interfaceCopiers)
+		assert.NotNil(t, c.typeCopiers[reflect.TypeOf(configMap{})])
+	})
+}
+
+func TestWithGenericTypeMergerProvider(t *testing.T) {
+	t.Run("generic", func(t *testing.T) {
+		called := 0
+		c := newCoalescer(
+			WithGenericTypeMergerProvider(reflect.TypeOf(genericList[int]{}), func(DeepMergeFunc, DeepCopyFunc) DeepMergeFunc {
+				called++
+				return func(v1, v2 reflect.Value) (reflect.Value, error) {
+					called++
+					return v2, nil
+				}
+			}))
+		got, err := c.deepMerge(reflect.ValueOf(genericList[bool]{Items: []bool{true}}), reflect.ValueOf(genericList[bool]{Items: []bool{false}}))
+		assert.Equal(t, genericList[bool]{Items: []bool{false}}, got.Interface())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, called)
+	})
+	t.Run("non generic", func(t *testing.T) {
+		c := newCoalescer(
+			WithGenericTypeMergerProvider(reflect.TypeOf(map[string]int{}), func(DeepMergeFunc, DeepCopyFunc) DeepMergeFunc {
+				return func(v1, v2 reflect.Value) (reflect.Value, error) {
+					return v2, nil
+				}
+			}))
+		assert.NotNil(t, c.typeMergers[reflect.TypeOf(map[string]int{})])
+		assert.Empty(t, c.genericTypeMergers)
+	})
+}
+
 func TestWithFieldMerger(t *testing.T) {
 	type User struct {
 		ID string
@@ -439,6 +671,32 @@ func TestWithFieldMergerProvider(t *testing.T) {
 	assert.Equal(t, 2, called)
 }
 
+func TestWithFieldKeepFirst(t *testing.T) {
+	type User struct {
+		Name string
+		Role string
+	}
+	c := newCoalescer(WithFieldKeepFirst(reflect.TypeOf(User{}), "Role"))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(User{})]["Role"])
+	got, err := c.deepMerge(reflect.ValueOf(User{Name: "alice", Role: "admin"}), reflect.ValueOf(User{Name: "bob", Role: "guest"}))
+	assert.NoError(t, err)
+	assert.Equal(t, User{Name: "bob", Role: "admin"}, got.Interface())
+}
+
+func TestWithFieldMustMatch(t *testing.T) {
+	type User struct {
+		ID   string
+		Name string
+	}
+	c := newCoalescer(WithFieldMustMatch(reflect.TypeOf(User{}), "ID"))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(User{})]["ID"])
+	got, err := c.deepMerge(reflect.ValueOf(User{ID: "1", Name: "alice"}), reflect.ValueOf(User{ID: "1", Name: "bob"}))
+	assert.NoError(t, err)
+	assert.Equal(t, User{ID: "1", Name: "bob"}, got.Interface())
+	_, err = c.deepMerge(reflect.ValueOf(User{ID: "1"}), reflect.ValueOf(User{ID: "2"}))
+	assert.EqualError(t, err, "at ID: conflicting values: 1 != 2")
+}
+
 func TestWithAtomicFieldMerge(t *testing.T) {
 	t.Run("struct field", func(t *testing.T) {
 		type Uuid struct {
@@ -485,6 +743,21 @@ func TestWithDefaultSliceMergeByIndex(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestWithStrictIndexMerge(t *testing.T) {
+	type User struct {
+		Tags []string `goalesce:"index"`
+	}
+	c := newCoalescer(WithStrictIndexMerge(), WithDefaultSliceMergeByIndex())
+	assert.True(t, c.strictIndexMerge)
+	got, err := c.deepMerge(reflect.ValueOf([]int{1, 2}), reflect.ValueOf([]int{-1, -2}))
+	assert.Equal(t, []int{-1, -2}, got.Interface())
+	assert.NoError(t, err)
+	_, err = c.deepMerge(reflect.ValueOf([]int{1, 2}), reflect.ValueOf([]int{-1}))
+	assert.EqualError(t, err, "cannot merge slices of different lengths by index: 2 != 1")
+	_, err = c.deepMerge(reflect.ValueOf(User{Tags: []string{"tag1"}}), reflect.ValueOf(User{Tags: []string{"tag1a", "tag2a"}}))
+	assert.EqualError(t, err, "at Tags: cannot merge slices of different lengths by index: 1 != 2")
+}
+
 func TestWithDefaultArrayMergeByIndex(t *testing.T) {
 	c := newCoalescer(WithDefaultArrayMergeByIndex())
 	assert.NotNil(t, c.arrayMerger)
@@ -564,6 +837,255 @@ func TestWithSliceMergeByID(t *testing.T) {
 	got, err := c.deepMerge(reflect.ValueOf([]User{{"Alice"}, {"Bob"}}), reflect.ValueOf([]User{{"Bob"}, {"Alice"}}))
 	assert.Equal(t, []User{{"Alice"}, {"Bob"}}, got.Interface())
 	assert.NoError(t, err)
+	t.Run("composite key", func(t *testing.T) {
+		type Resource struct {
+			Namespace string
+			Name      string
+			Replicas  int
+		}
+		c := newCoalescer(WithSliceMergeByID(reflect.TypeOf([]Resource{}), "Namespace", "Name"))
+		got, err := c.deepMerge(
+			reflect.ValueOf([]Resource{{"a", "web", 1}, {"b", "web", 1}}),
+			reflect.ValueOf([]Resource{{"b", "web", 2}, {"a", "db", 1}}),
+		)
+		assert.Equal(t, []Resource{{"a", "web", 1}, {"b", "web", 2}, {"a", "db", 1}}, got.Interface())
+		assert.NoError(t, err)
+	})
+}
+
+func TestWithMergeKey(t *testing.T) {
+	type User struct {
+		ID   string
+		Name string
+	}
+	c := newCoalescer(WithMergeKey[[]User](func(u User) string { return u.ID }))
+	assert.NotNil(t, c.sliceMergers[reflect.TypeOf([]User{})])
+	got, err := c.deepMerge(reflect.ValueOf([]User{{"1", "Alice"}, {"2", "Bob"}}), reflect.ValueOf([]User{{"2", "Robert"}, {"3", "Carol"}}))
+	assert.Equal(t, []User{{"1", "Alice"}, {"2", "Robert"}, {"3", "Carol"}}, got.Interface())
+	assert.NoError(t, err)
+	t.Run("pointers", func(t *testing.T) {
+		type Users []*User
+		keyFunc := func(u *User) interface{} {
+			if u == nil {
+				return nil
+			}
+			return u.ID
+		}
+		c := newCoalescer(WithMergeKey[Users](keyFunc))
+		got, err := c.deepMerge(reflect.ValueOf(Users{{"1", "Alice"}, nil}), reflect.ValueOf(Users{{"1", "Alicia"}}))
+		assert.Equal(t, Users{{"1", "Alicia"}, nil}, got.Interface())
+		assert.NoError(t, err)
+	})
+	t.Run("nil interface", func(t *testing.T) {
+		type Named interface{ GetName() string }
+		keyFunc := func(n Named) string {
+			if n == nil {
+				return ""
+			}
+			return n.GetName()
+		}
+		got, err := DeepMerge([]Named{named{"a"}, nil}, []Named{named{"b"}}, WithMergeKey[[]Named](keyFunc))
+		assert.Equal(t, []Named{named{"a"}, nil, named{"b"}}, got)
+		assert.NoError(t, err)
+	})
+}
+
+type named struct {
+	Name string
+}
+
+func (n named) GetName() string { return n.Name }
+
+func TestWithSliceMergeByMapKey(t *testing.T) {
+	sliceType := reflect.TypeOf([]interface{}{})
+	c := newCoalescer(WithSliceMergeByMapKey(sliceType, "name"))
+	assert.NotNil(t, c.sliceMergers[sliceType])
+	v1 := []interface{}{map[string]interface{}{"name": "a", "port": 80}, map[string]interface{}{"name": "b", "port": 81}}
+	v2 := []interface{}{map[string]interface{}{"name": "b", "port": 8081}, map[string]interface{}{"name": "c", "port": 82}}
+	got, err := c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "a", "port": 80},
+		map[string]interface{}{"name": "b", "port": 8081},
+		map[string]interface{}{"name": "c", "port": 82},
+	}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithSliceElementMerger(t *testing.T) {
+	sliceType := reflect.TypeOf([][]string{})
+	appendMerger := func(v1, v2 reflect.Value) (reflect.Value, error) {
+		return reflect.AppendSlice(v1, v2), nil
+	}
+	c := newCoalescer(WithSliceMergeByIndex(sliceType), WithSliceElementMerger(sliceType, appendMerger))
+	assert.NotNil(t, c.sliceElemMergers[sliceType])
+	got, err := c.deepMerge(reflect.ValueOf([][]string{{"a"}, {"b"}}), reflect.ValueOf([][]string{{"c"}}))
+	assert.Equal(t, [][]string{{"a", "c"}, {"b"}}, got.Interface())
+	assert.NoError(t, err)
+	assert.Nil(t, c.elemMerger)
+	t.Run("atomic slices", func(t *testing.T) {
+		c := newCoalescer(WithSliceElementMerger(sliceType, appendMerger))
+		got, err := c.deepMerge(reflect.ValueOf([][]string{{"a"}, {"b"}}), reflect.ValueOf([][]string{{"c"}}))
+		assert.Equal(t, [][]string{{"c"}}, got.Interface())
+		assert.NoError(t, err)
+	})
+}
+
+func TestWithSliceORSetMerge(t *testing.T) {
+	type item struct {
+		ID      string
+		Version int64
+	}
+	versionFunc := func(elem reflect.Value) (int64, bool, error) {
+		return elem.FieldByName("Version").Int(), false, nil
+	}
+	c := newCoalescer(WithSliceORSetMerge(reflect.TypeOf([]item{}), newMergeByField("ID"), versionFunc))
+	assert.NotNil(t, c.sliceMergers[reflect.TypeOf([]item{})])
+	got, err := c.deepMerge(reflect.ValueOf([]item{{"b", 1}, {"a", 2}}), reflect.ValueOf([]item{{"a", 1}, {"c", 1}}))
+	assert.Equal(t, []item{{"a", 2}, {"b", 1}, {"c", 1}}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithDefaultSliceKeyOrder(t *testing.T) {
+	type User struct {
+		ID string
+	}
+	c := newCoalescer(WithSliceMergeByID(reflect.TypeOf([]User{}), "ID"), WithDefaultSliceKeyOrder(SliceKeyOrderSecond))
+	assert.NotNil(t, c.defaultSliceKeyOrder)
+	got, err := c.deepMerge(reflect.ValueOf([]User{{"Alice"}, {"Bob"}}), reflect.ValueOf([]User{{"Bob"}, {"Alice"}}))
+	assert.Equal(t, []User{{"Bob"}, {"Alice"}}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithSliceKeyOrder(t *testing.T) {
+	type User struct {
+		ID string
+	}
+	c := newCoalescer(WithSliceMergeByID(reflect.TypeOf([]User{}), "ID"), WithSliceKeyOrder(reflect.TypeOf([]User{}), SliceKeyOrderExplicit("Carol", "Bob")))
+	assert.NotNil(t, c.sliceKeyOrders[reflect.TypeOf([]User{})])
+	got, err := c.deepMerge(reflect.ValueOf([]User{{"Alice"}, {"Bob"}}), reflect.ValueOf([]User{{"Carol"}}))
+	assert.Equal(t, []User{{"Carol"}, {"Bob"}, {"Alice"}}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithNestedSliceMerge(t *testing.T) {
+	c := newCoalescer(WithNestedSliceMerge(reflect.TypeOf([][]float64{}), MergeStrategyIndex, MergeStrategyAtomic))
+	assert.NotNil(t, c.sliceMergers[reflect.TypeOf([][]float64{})])
+	got, err := c.deepMerge(reflect.ValueOf([][]float64{{1, 2}, {3}}), reflect.ValueOf([][]float64{{4}}))
+	assert.Equal(t, [][]float64{{4}, {3}}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldNestedSliceMerge(t *testing.T) {
+	type Matrix struct {
+		Rows [][]float64
+	}
+	c := newCoalescer(WithFieldNestedSliceMerge(reflect.TypeOf(Matrix{}), "Rows", MergeStrategyIndex, MergeStrategyIndex))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(Matrix{})]["Rows"])
+	got, err := c.deepMerge(reflect.ValueOf(Matrix{Rows: [][]float64{{1, 2}, {3}}}), reflect.ValueOf(Matrix{Rows: [][]float64{{4}}}))
+	assert.Equal(t, Matrix{Rows: [][]float64{{4, 2}, {3}}}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithContainerAdapter(t *testing.T) {
+	c := newCoalescer(WithContainerAdapter(reflect.TypeOf(&orderedMap{}), orderedMapAdapter{}))
+	assert.NotNil(t, c.typeCopiers[reflect.TypeOf(&orderedMap{})])
+	assert.NotNil(t, c.typeMergers[reflect.TypeOf(&orderedMap{})])
+	got, err := c.deepMerge(reflect.ValueOf(newOrderedMap("a", 1)), reflect.ValueOf(newOrderedMap("b", 2)))
+	assert.Equal(t, newOrderedMap("a", 1, "b", 2), got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithMapNoNewKeys(t *testing.T) {
+	c := newCoalescer(WithMapNoNewKeys(nil))
+	assert.NotNil(t, c.mapNoNewKeys)
+	got, err := c.deepMerge(reflect.ValueOf(map[string]int{"a": 1}), reflect.ValueOf(map[string]int{"a": 2, "b": 3}))
+	assert.Equal(t, map[string]int{"a": 2}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithMapAddOnly(t *testing.T) {
+	c := newCoalescer(WithMapAddOnly())
+	assert.True(t, c.mapAddOnly)
+	got, err := c.deepMerge(reflect.ValueOf(map[string]int{"a": 1}), reflect.ValueOf(map[string]int{"a": 2, "b": 3}))
+	assert.Equal(t, map[string]int{"a": 1, "b": 3}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithMapAtomicValues(t *testing.T) {
+	c := newCoalescer(WithMapAtomicValues(reflect.TypeOf(map[string]map[string]int{})))
+	assert.True(t, c.mapAtomicValues[reflect.TypeOf(map[string]map[string]int{})])
+	got, err := c.deepMerge(reflect.ValueOf(map[string]map[string]int{"a": {"x": 1, "y": 2}}), reflect.ValueOf(map[string]map[string]int{"a": {"x": 3}, "b": {"z": 4}}))
+	assert.Equal(t, map[string]map[string]int{"a": {"x": 3}, "b": {"z": 4}}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithMapKeyMerger(t *testing.T) {
+	c := newCoalescer(WithMapKeyMerger(reflect.TypeOf(map[string]int{}), "a", func(v1, v2 reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(int(v1.Int() + v2.Int())), nil
+	}))
+	assert.NotNil(t, c.mapKeyMergers[reflect.TypeOf(map[string]int{})]["a"])
+	got, err := c.deepMerge(reflect.ValueOf(map[string]int{"a": 1, "b": 1}), reflect.ValueOf(map[string]int{"a": 2, "b": 2}))
+	assert.Equal(t, map[string]int{"a": 3, "b": 2}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithListAppendDedup(t *testing.T) {
+	c := newCoalescer(WithListAppendDedup())
+	assert.True(t, c.listAppendDedup)
+}
+
+func TestWithListAppendDedupByKeyFunc(t *testing.T) {
+	c := newCoalescer(WithListAppendDedupByKeyFunc(reflect.TypeOf([]int{}), SliceUnion))
+	assert.NotNil(t, c.listAppendDedupKeys[reflect.TypeOf([]int{})])
+}
+
+func TestWithSliceSetDifferenceMerge(t *testing.T) {
+	c := newCoalescer(WithSliceSetDifferenceMerge(reflect.TypeOf([]int{})))
+	assert.NotNil(t, c.sliceDifferences[reflect.TypeOf([]int{})])
+	got, err := c.deepMerge(reflect.ValueOf([]int{1, 2, 3}), reflect.ValueOf([]int{2}))
+	assert.Equal(t, []int{1, 3}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithSliceDifferenceByKeyFunc(t *testing.T) {
+	c := newCoalescer(WithSliceDifferenceByKeyFunc(reflect.TypeOf([]int{}), func(_ int, elem reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(elem.Int() % 2), nil
+	}))
+	got, err := c.deepMerge(reflect.ValueOf([]int{1, 2, 3}), reflect.ValueOf([]int{5}))
+	assert.Equal(t, []int{2}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithSortedSliceResult(t *testing.T) {
+	c := newCoalescer(WithSortedSliceResult(reflect.TypeOf([]int{}), func(e1, e2 reflect.Value) bool {
+		return e1.Int() > e2.Int()
+	}))
+	assert.NotNil(t, c.sliceSorts[reflect.TypeOf([]int{})])
+}
+
+func TestWithSliceDeleteMarker(t *testing.T) {
+	c := newCoalescer(WithSliceDeleteMarker(reflect.TypeOf([]int{}), func(element reflect.Value) (bool, error) {
+		return element.Int() < 0, nil
+	}))
+	assert.NotNil(t, c.sliceDeleteMarkers[reflect.TypeOf([]int{})])
+}
+
+func TestWithDefaultMapNullDeletion(t *testing.T) {
+	c := newCoalescer(WithDefaultMapNullDeletion())
+	assert.True(t, c.mapNullDeletion)
+}
+
+func TestWithMapNullDeletion(t *testing.T) {
+	c := newCoalescer(WithMapNullDeletion(reflect.TypeOf(map[string]*int{})))
+	assert.True(t, c.mapNullDeletions[reflect.TypeOf(map[string]*int{})])
+}
+
+func TestWithFirstValuePriority(t *testing.T) {
+	c := newCoalescer(WithFirstValuePriority())
+	assert.True(t, c.firstValuePriority)
+	got, err := c.deepMerge(reflect.ValueOf(map[string]int{"a": 1, "b": 0}), reflect.ValueOf(map[string]int{"a": 2, "b": 2, "c": 3}))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2, "c": 3}, got.Interface())
+	assert.NoError(t, err)
 }
 
 func TestWithZeroEmptySliceMerge(t *testing.T) {
@@ -571,6 +1093,283 @@ func TestWithZeroEmptySliceMerge(t *testing.T) {
 	assert.Equal(t, true, c.zeroEmptySlice)
 }
 
+func TestWithIdentityShortCircuit(t *testing.T) {
+	c := newCoalescer(WithIdentityShortCircuit())
+	assert.Equal(t, true, c.identityShortCircuit)
+}
+
+func TestWithSubtreeHashing(t *testing.T) {
+	c := newCoalescer(WithSubtreeHashing())
+	assert.NotNil(t, c.subtreeHasher)
+}
+
+func TestWithMemoization(t *testing.T) {
+	c := newCoalescer(WithMemoization())
+	assert.NotNil(t, c.memoizer)
+}
+
+func TestWithPatchDirectives(t *testing.T) {
+	c := newCoalescer(WithPatchDirectives())
+	assert.Equal(t, true, c.patchDirectives)
+}
+
+func TestWithValidator(t *testing.T) {
+	c := newCoalescer(WithValidator(func(interface{}) error { return nil }))
+	assert.NotNil(t, c.validator)
+}
+
+func TestWithParallelism(t *testing.T) {
+	c := newCoalescer(WithParallelism(4))
+	assert.Equal(t, 4, c.parallelism)
+}
+
+func TestWithRecorder(t *testing.T) {
+	c := newCoalescer(WithRecorder(io.Discard, nil))
+	assert.NotNil(t, c.recorder)
+}
+
+func TestWithFieldPermission(t *testing.T) {
+	c := newCoalescer(WithFieldPermission(func(string, reflect.StructField) bool { return true }))
+	assert.NotNil(t, c.fieldPermission)
+}
+
+func TestWithErrorOnFieldPermissionDenied(t *testing.T) {
+	c := newCoalescer(WithErrorOnFieldPermissionDenied())
+	assert.Equal(t, true, c.errorOnFieldPermission)
+}
+
+func TestWithErrorAccumulation(t *testing.T) {
+	c := newCoalescer(WithErrorAccumulation())
+	assert.Equal(t, true, c.errorAccumulation)
+}
+
+func TestWithPathMerger(t *testing.T) {
+	type container struct {
+		Name   string
+		Labels map[string]string
+		Env    map[string]string
+	}
+	type spec struct {
+		Labels     map[string]string
+		Containers []container
+	}
+	v1 := spec{
+		Labels:     map[string]string{"a": "1"},
+		Containers: []container{{Name: "web", Labels: map[string]string{"a": "1"}, Env: map[string]string{"A": "1"}}},
+	}
+	v2 := spec{
+		Labels:     map[string]string{"b": "2"},
+		Containers: []container{{Name: "web", Labels: map[string]string{"b": "2"}, Env: map[string]string{"B": "2"}}},
+	}
+	t.Run("wildcard", func(t *testing.T) {
+		var paths []string
+		merger := func(v1, v2 reflect.Value) (reflect.Value, error) {
+			paths = append(paths, "called")
+			return v2, nil
+		}
+		got, err := DeepMerge(v1, v2, WithSliceMergeByID(reflect.TypeOf([]container{}), "Name"), WithPathMerger("Containers[*].Env", merger))
+		assert.NoError(t, err)
+		assert.Equal(t, spec{
+			Labels:     map[string]string{"a": "1", "b": "2"},
+			Containers: []container{{Name: "web", Labels: map[string]string{"a": "1", "b": "2"}, Env: map[string]string{"B": "2"}}},
+		}, got)
+		assert.Len(t, paths, 1)
+	})
+	t.Run("provider", func(t *testing.T) {
+		got, err := DeepMerge(v1, v2, WithPathMergerProvider("Labels", func(_ DeepMergeFunc, copier DeepCopyFunc) DeepMergeFunc {
+			return func(v1, v2 reflect.Value) (reflect.Value, error) {
+				return copier(v1)
+			}
+		}))
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"a": "1"}, got.Labels)
+	})
+	t.Run("zero parent", func(t *testing.T) {
+		type outer struct {
+			Spec spec
+		}
+		var called bool
+		_, err := DeepMerge(outer{}, outer{Spec: v2}, WithPathMerger("Spec.Labels", func(v1, v2 reflect.Value) (reflect.Value, error) {
+			called = true
+			return v2, nil
+		}))
+		assert.NoError(t, err)
+		assert.True(t, called)
+	})
+	t.Run("invalid path", func(t *testing.T) {
+		_, err := DeepMerge(v1, v2, WithPathMerger("Labels[", nil))
+		assert.EqualError(t, err, "invalid path Labels[: missing closing bracket")
+	})
+}
+
+func TestWithPathAtomic(t *testing.T) {
+	type meta struct {
+		Labels      map[string]string
+		Annotations map[string]string
+	}
+	got, err := DeepMerge(
+		meta{Labels: map[string]string{"a": "1"}, Annotations: map[string]string{"a": "1"}},
+		meta{Labels: map[string]string{"b": "2"}, Annotations: map[string]string{"b": "2"}},
+		WithPathAtomic("Labels"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, meta{Labels: map[string]string{"b": "2"}, Annotations: map[string]string{"a": "1", "b": "2"}}, got)
+	t.Run("tagged field", func(t *testing.T) {
+		type foo struct {
+			Tags []string `goalesce:"append"`
+		}
+		got, err := DeepMerge(foo{Tags: []string{"a"}}, foo{Tags: []string{"b"}}, WithPathAtomic("Tags"))
+		assert.NoError(t, err)
+		assert.Equal(t, foo{Tags: []string{"b"}}, got)
+	})
+}
+
+func TestWithFieldDefault(t *testing.T) {
+	type foo struct {
+		Port int
+	}
+	c := newCoalescer(WithFieldDefault(reflect.TypeOf(foo{}), "Port", 8080))
+	assert.Equal(t, 8080, c.fieldDefaults[reflect.TypeOf(foo{})]["Port"].Interface())
+	got, err := c.deepMerge(reflect.ValueOf(foo{}), reflect.ValueOf(foo{}))
+	assert.Equal(t, foo{Port: 8080}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldAllowlist(t *testing.T) {
+	type User struct {
+		Name string
+		Age  int
+	}
+	c := newCoalescer(WithFieldAllowlist(reflect.TypeOf(User{}), "Name"))
+	assert.Equal(t, map[string]bool{"Name": true}, c.fieldAllowlists[reflect.TypeOf(User{})])
+	got, err := c.deepMerge(reflect.ValueOf(User{Name: "Alice", Age: 20}), reflect.ValueOf(User{Name: "Bob", Age: 30}))
+	assert.Equal(t, User{Name: "Bob", Age: 20}, got.Interface())
+	assert.NoError(t, err)
+	t.Run("nil pointer", func(t *testing.T) {
+		got, err := DeepMerge((*User)(nil), &User{Name: "Bob", Age: 30}, WithFieldAllowlist(reflect.TypeOf(User{}), "Name"))
+		assert.Equal(t, &User{Name: "Bob"}, got)
+		assert.NoError(t, err)
+	})
+	t.Run("new map key", func(t *testing.T) {
+		got, err := DeepMerge(
+			map[string]User{"a": {Name: "Alice", Age: 20}},
+			map[string]User{"a": {Name: "Alicia", Age: 21}, "b": {Name: "Bob", Age: 30}},
+			WithFieldAllowlist(reflect.TypeOf(User{}), "Name"))
+		assert.Equal(t, map[string]User{"a": {Name: "Alicia", Age: 20}, "b": {Name: "Bob"}}, got)
+		assert.NoError(t, err)
+	})
+	t.Run("nil map", func(t *testing.T) {
+		got, err := DeepMerge(map[string]*User(nil), map[string]*User{"b": {Name: "Bob", Age: 30}}, WithFieldAllowlist(reflect.TypeOf(User{}), "Name"))
+		assert.Equal(t, map[string]*User{"b": {Name: "Bob"}}, got)
+		assert.NoError(t, err)
+	})
+}
+
+func TestWithWarnings(t *testing.T) {
+	type foo struct {
+		Field    int `goalesce:"unknown"`
+		unexp    int
+		Labels   map[string]string
+		Replicas []int
+	}
+	t.Run("merge", func(t *testing.T) {
+		var warnings []Warning
+		c := newCoalescer(WithWarnings(&warnings), WithLenientTags(nil), WithMapNoNewKeys(nil), WithSliceMergeByIndex(reflect.TypeOf([]int{})))
+		v1 := foo{Field: 1, unexp: 1, Labels: map[string]string{"a": "1"}, Replicas: []int{1, 2}}
+		v2 := foo{Field: 2, Labels: map[string]string{"a": "2", "b": "3"}, Replicas: []int{3}}
+		got, err := c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.Equal(t, foo{Field: 2, Labels: map[string]string{"a": "2"}, Replicas: []int{3, 2}}, got.Interface())
+		assert.NoError(t, err)
+		assert.Equal(t, []Warning{
+			{Path: "Field", Message: "ignoring unknown merge strategy: unknown"},
+			{Path: "unexp", Message: "unexported field not merged"},
+			{Path: `Labels["b"]`, Message: "new map key ignored"},
+			{Path: "Replicas", Message: "slices of different lengths merged by index: 2 != 1"},
+		}, warnings)
+	})
+	t.Run("copy", func(t *testing.T) {
+		var warnings []Warning
+		c := newCoalescer(WithWarnings(&warnings))
+		got, err := c.deepCopy(reflect.ValueOf(&foo{unexp: 1}))
+		assert.Equal(t, &foo{}, got.Interface())
+		assert.NoError(t, err)
+		assert.Equal(t, []Warning{{Path: "unexp", Message: "unexported field not copied"}}, warnings)
+	})
+	t.Run("zero unexported fields", func(t *testing.T) {
+		var warnings []Warning
+		c := newCoalescer(WithWarnings(&warnings), WithLenientTags(nil))
+		_, err := c.deepMerge(reflect.ValueOf(foo{Labels: map[string]string{"a": "1"}}), reflect.ValueOf(foo{Field: 1}))
+		assert.NoError(t, err)
+		assert.Equal(t, []Warning{{Path: "Field", Message: "ignoring unknown merge strategy: unknown"}}, warnings)
+	})
+	t.Run("many", func(t *testing.T) {
+		var warnings []Warning
+		pairs := []Pair[foo]{{First: foo{unexp: 1}, Second: foo{Field: 1}}, {First: foo{unexp: 2}, Second: foo{Field: 2}}}
+		_, err := MergeMany(pairs, WithWarnings(&warnings), WithLenientTags(nil), WithParallelism(2))
+		assert.NoError(t, err)
+		assert.Len(t, warnings, 4)
+	})
+}
+
+func TestWithFieldHook(t *testing.T) {
+	hook := func(path string, v1, v2, merged reflect.Value) {}
+	c := newCoalescer(WithFieldHook(hook), WithFieldHook(hook))
+	assert.Len(t, c.fieldHooks, 2)
+}
+
+func TestWithTrace(t *testing.T) {
+	var buf bytes.Buffer
+	c := newCoalescer(WithTrace(&buf))
+	assert.Equal(t, &buf, c.tracer.w)
+}
+
+func TestWithInstrumentation(t *testing.T) {
+	instrumentation := InstrumentationFunc(func(stats OperationStats) {})
+	c := newCoalescer(WithInstrumentation(instrumentation))
+	assert.NotNil(t, c.instrumentation)
+}
+
+func TestWithMaxElements(t *testing.T) {
+	c := newCoalescer(WithMaxElements(10))
+	assert.Equal(t, 10, c.maxElements)
+}
+
+func TestWithDryRun(t *testing.T) {
+	var changes []Change
+	c := newCoalescer(WithDryRun(&changes))
+	assert.Equal(t, &changes, c.dryRun.dst)
+}
+
+func TestWithLenientTags(t *testing.T) {
+	type foo struct {
+		Field int `goalesce:"unknown"`
+		Other int `goalesce:"atomic"`
+	}
+	type bar struct {
+		Invalid int `goalesce:"append"`
+	}
+	t.Run("warn", func(t *testing.T) {
+		var warnings []string
+		c := newCoalescer(WithLenientTags(func(err error) { warnings = append(warnings, err.Error()) }))
+		assert.True(t, c.lenientTags)
+		got, err := c.deepMerge(reflect.ValueOf(foo{Field: 1, Other: 1}), reflect.ValueOf(foo{Field: 2, Other: 2}))
+		assert.Equal(t, foo{Field: 2, Other: 2}, got.Interface())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"field goalesce.foo.Field: unknown merge strategy: unknown"}, warnings)
+	})
+	t.Run("nil", func(t *testing.T) {
+		c := newCoalescer(WithLenientTags(nil))
+		got, err := c.deepMerge(reflect.ValueOf(foo{Field: 1}), reflect.ValueOf(foo{Field: 2}))
+		assert.Equal(t, foo{Field: 2}, got.Interface())
+		assert.NoError(t, err)
+	})
+	t.Run("invalid usage still fails", func(t *testing.T) {
+		c := newCoalescer(WithLenientTags(nil))
+		_, err := c.deepMerge(reflect.ValueOf(bar{Invalid: 1}), reflect.ValueOf(bar{Invalid: 2}))
+		assert.EqualError(t, err, "at Invalid: field goalesce.bar.Invalid: append strategy is only supported for slices")
+	})
+}
+
 func TestWithFieldListAppendMerge(t *testing.T) {
 	type User struct {
 		Tags []string
@@ -628,3 +1427,145 @@ func TestWithFieldMergeByKeyFunc(t *testing.T) {
 	assert.Equal(t, User{Tags: []string{"tag1", "tag2", "tag3"}}, got.Interface())
 	assert.NoError(t, err)
 }
+
+func TestWithFieldLatestRecordMerge(t *testing.T) {
+	type record struct {
+		Value     string
+		UpdatedAt time.Time
+	}
+	type foo struct {
+		Record record
+	}
+	early := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCoalescer(WithFieldLatestRecordMerge(reflect.TypeOf(foo{}), "Record", "UpdatedAt"))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(foo{})]["Record"])
+	got, err := c.deepMerge(reflect.ValueOf(foo{Record: record{Value: "a", UpdatedAt: late}}), reflect.ValueOf(foo{Record: record{Value: "b", UpdatedAt: early}}))
+	assert.Equal(t, foo{Record: record{Value: "a", UpdatedAt: late}}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldLatestTimeMerge(t *testing.T) {
+	type foo struct {
+		LastSeen time.Time
+	}
+	early := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCoalescer(WithFieldLatestTimeMerge(reflect.TypeOf(foo{}), "LastSeen"))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(foo{})]["LastSeen"])
+	got, err := c.deepMerge(reflect.ValueOf(foo{LastSeen: late}), reflect.ValueOf(foo{LastSeen: early}))
+	assert.Equal(t, foo{LastSeen: late}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldEarliestTimeMerge(t *testing.T) {
+	type foo struct {
+		ExpiresAt time.Time
+	}
+	early := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := newCoalescer(WithFieldEarliestTimeMerge(reflect.TypeOf(foo{}), "ExpiresAt"))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(foo{})]["ExpiresAt"])
+	got, err := c.deepMerge(reflect.ValueOf(foo{ExpiresAt: early}), reflect.ValueOf(foo{ExpiresAt: late}))
+	assert.Equal(t, foo{ExpiresAt: early}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldSemverMaxMerge(t *testing.T) {
+	type foo struct {
+		Version string
+	}
+	c := newCoalescer(WithFieldSemverMaxMerge(reflect.TypeOf(foo{}), "Version"))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(foo{})]["Version"])
+	got, err := c.deepMerge(reflect.ValueOf(foo{Version: "1.10.0"}), reflect.ValueOf(foo{Version: "1.9.0"}))
+	assert.Equal(t, foo{Version: "1.10.0"}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldBitwiseOrMerge(t *testing.T) {
+	type foo struct {
+		Flags uint
+	}
+	c := newCoalescer(WithFieldBitwiseOrMerge(reflect.TypeOf(foo{}), "Flags"))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(foo{})]["Flags"])
+	got, err := c.deepMerge(reflect.ValueOf(foo{Flags: 0b01}), reflect.ValueOf(foo{Flags: 0b10}))
+	assert.Equal(t, foo{Flags: 0b11}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldNumericMerge(t *testing.T) {
+	type quota struct {
+		CPU     int
+		Timeout float64
+	}
+	c := newCoalescer(
+		WithFieldNumericMerge(reflect.TypeOf(quota{}), "CPU", NumericSum),
+		WithFieldNumericMerge(reflect.TypeOf(quota{}), "Timeout", NumericMax),
+	)
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(quota{})]["CPU"])
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(quota{})]["Timeout"])
+	got, err := c.deepMerge(reflect.ValueOf(quota{CPU: 2, Timeout: 30}), reflect.ValueOf(quota{CPU: 3, Timeout: 10}))
+	assert.Equal(t, quota{CPU: 5, Timeout: 30}, got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldNameMatching(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+		Age  int
+	}
+	c := newCoalescer(WithFieldAllowlist(reflect.TypeOf(User{}), "name"), WithFieldNameMatching(FieldNameMatchJSONTag))
+	assert.Equal(t, FieldNameMatchJSONTag, c.fieldNameMatching)
+	assert.Equal(t, map[string]bool{"Name": true}, c.fieldAllowlists[reflect.TypeOf(User{})])
+}
+
+type tenantKey struct{}
+
+func TestWithContext(t *testing.T) {
+	c := newCoalescer()
+	assert.Equal(t, context.Background(), c.context())
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	c = newCoalescer(WithContext(ctx))
+	assert.Equal(t, ctx, c.context())
+}
+
+func TestWithTypeCopierContext(t *testing.T) {
+	copier := func(ctx context.Context, v reflect.Value) (reflect.Value, error) {
+		if ctx.Value(tenantKey{}) == nil {
+			return reflect.Value{}, nil // delegate
+		}
+		return reflect.ValueOf(v.String() + "@" + ctx.Value(tenantKey{}).(string)), nil
+	}
+	// the context option can be passed after the copier
+	got, err := DeepCopy("alice", WithTypeCopierContext(reflect.TypeOf(""), copier), WithContext(context.WithValue(context.Background(), tenantKey{}, "acme")))
+	assert.Equal(t, "alice@acme", got)
+	assert.NoError(t, err)
+	got, err = DeepCopy("alice", WithTypeCopierContext(reflect.TypeOf(""), copier))
+	assert.Equal(t, "alice", got)
+	assert.NoError(t, err)
+}
+
+func TestWithTypeMergerContext(t *testing.T) {
+	merger := func(ctx context.Context, v1, v2 reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(v2.String() + "@" + ctx.Value(tenantKey{}).(string)), nil
+	}
+	c := newCoalescer(WithContext(context.WithValue(context.Background(), tenantKey{}, "acme")), WithTypeMergerContext(reflect.TypeOf(""), merger))
+	assert.NotNil(t, c.typeMergers[reflect.TypeOf("")])
+	got, err := c.deepMerge(reflect.ValueOf("alice"), reflect.ValueOf("bob"))
+	assert.Equal(t, "bob@acme", got.Interface())
+	assert.NoError(t, err)
+}
+
+func TestWithFieldMergerContext(t *testing.T) {
+	type User struct {
+		Name string
+	}
+	merger := func(ctx context.Context, v1, v2 reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(v2.String() + "@" + ctx.Value(tenantKey{}).(string)), nil
+	}
+	c := newCoalescer(WithFieldMergerContext(reflect.TypeOf(User{}), "Name", merger), WithContext(context.WithValue(context.Background(), tenantKey{}, "acme")))
+	assert.NotNil(t, c.fieldMergers[reflect.TypeOf(User{})]["Name"])
+	got, err := c.deepMerge(reflect.ValueOf(User{"alice"}), reflect.ValueOf(User{"bob"}))
+	assert.Equal(t, User{"bob@acme"}, got.Interface())
+	assert.NoError(t, err)
+}
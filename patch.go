@@ -0,0 +1,424 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PatchOpAdd adds a value that did not exist before.
+	PatchOpAdd = "add"
+	// PatchOpRemove removes an existing value.
+	PatchOpRemove = "remove"
+	// PatchOpReplace replaces an existing value.
+	PatchOpReplace = "replace"
+)
+
+// PatchOperation is a single operation of a Patch.
+type PatchOperation struct {
+	// Op is the operation: PatchOpAdd, PatchOpRemove or PatchOpReplace.
+	Op string `json:"op"`
+	// Path is the location of the operation, using the same syntax as Change.Path.
+	Path string `json:"path"`
+	// Value is the JSON-encoded new value; it is empty for PatchOpRemove operations.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Patch is a serializable list of operations. Contrary to a Diff, which holds Go values, a Patch
+// holds JSON-encoded values and can therefore be shipped over the network, then decoded and
+// applied by another process. Use NewPatch to create a Patch from a Diff, and EncodePatch and
+// DecodePatch to serialize and deserialize patches.
+type Patch []PatchOperation
+
+// NewPatch converts the given Diff into a Patch. The new values of each change are encoded with
+// encoding/json; therefore, they must be JSON-serializable.
+func NewPatch(diff Diff) (Patch, error) {
+	patch := make(Patch, 0, len(diff))
+	for _, change := range diff {
+		op := PatchOperation{Path: change.Path}
+		switch change.Kind {
+		case ChangeAdded:
+			op.Op = PatchOpAdd
+		case ChangeRemoved:
+			op.Op = PatchOpRemove
+		case ChangeModified:
+			op.Op = PatchOpReplace
+		default:
+			return nil, fmt.Errorf("%s: unknown change kind: %v", change.Path, change.Kind)
+		}
+		if op.Op != PatchOpRemove {
+			value, err := json.Marshal(change.To)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", change.Path, err)
+			}
+			op.Value = value
+		}
+		patch = append(patch, op)
+	}
+	return patch, nil
+}
+
+// ExtractPatch computes the differences between the 2 values and returns them as a Patch. It is
+// equivalent to calling DeepDiff, then NewPatch. Applying the returned patch to the first value with
+// ApplyPatch yields a value equal to the second value.
+func ExtractPatch[T any](o1, o2 T, opts ...Option) (Patch, error) {
+	diff, err := DeepDiff(o1, o2, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return NewPatch(diff)
+}
+
+// ApplyPatch applies the given patch to the target value and returns the patched value. The
+// operations are applied in order: PatchOpAdd and PatchOpReplace operations set the value at their
+// path to their decoded value, and PatchOpRemove operations delete map entries, truncate slices at
+// the removed index, and reset other values to their zero-value. Nil pointers and maps found along
+// a path are allocated as needed.
+//
+// Like DeepMerge, this function never modifies its input: the target is deep-copied first, honoring
+// the options that customize copy behavior. Values wrapped in interfaces are decoded into the type
+// of the value they replace, if any, and into the generic types of encoding/json otherwise.
+//
+// This function returns an error if a path cannot be resolved in the target value, or if a value
+// cannot be decoded into the type at its path.
+func ApplyPatch[T any](target T, patch Patch, opts ...Option) (T, error) {
+	c := newCoalescer(opts...)
+	copied, err := c.deepCopy(reflect.ValueOf(target))
+	if err != nil {
+		return zero[T](), err
+	}
+	root := reflect.New(reflect.TypeOf((*T)(nil)).Elem()).Elem()
+	if copied.IsValid() {
+		root.Set(copied)
+	}
+	for i, op := range patch {
+		segments, err := parsePatchPath(op.Path)
+		if err != nil {
+			return zero[T](), fmt.Errorf("operation %d: %w", i, err)
+		}
+		if err := applyPatchOperation(root, "", segments, op); err != nil {
+			return zero[T](), fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+	return root.Interface().(T), nil
+}
+
+// patchPathSegment is a segment of a Patch path: either a struct field, or a bracketed slice index
+// or map key.
+type patchPathSegment struct {
+	field string
+	key   string
+	quote bool // whether the key was quoted, i.e. is a string key
+}
+
+// parsePatchPath parses a path created by fieldPath, indexPath and keyPath into its segments.
+func parsePatchPath(path string) ([]patchPathSegment, error) {
+	var segments []patchPathSegment
+	for i := 0; i < len(path); {
+		switch {
+		case path[i] == '[':
+			if i+1 < len(path) && path[i+1] == '"' {
+				quoted, err := strconv.QuotedPrefix(path[i+1:])
+				if err != nil {
+					return nil, fmt.Errorf("invalid path %s: %w", path, err)
+				}
+				key, _ := strconv.Unquote(quoted)
+				i += 1 + len(quoted)
+				if i >= len(path) || path[i] != ']' {
+					return nil, fmt.Errorf("invalid path %s: missing closing bracket", path)
+				}
+				segments = append(segments, patchPathSegment{key: key, quote: true})
+				i++
+			} else {
+				end := strings.IndexByte(path[i:], ']')
+				if end < 0 {
+					return nil, fmt.Errorf("invalid path %s: missing closing bracket", path)
+				}
+				segments = append(segments, patchPathSegment{key: path[i+1 : i+end]})
+				i += end + 1
+			}
+		case path[i] == '.' || i == 0:
+			if i > 0 {
+				i++
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid path %s: empty field name", path)
+			}
+			segments = append(segments, patchPathSegment{field: path[i : i+end]})
+			i += end
+		default:
+			return nil, fmt.Errorf("invalid path %s: unexpected character %q", path, path[i])
+		}
+	}
+	return segments, nil
+}
+
+// applyPatchOperation applies the given operation to the settable value v, located at the given
+// path; segments is the remainder of the operation path, relative to v.
+func applyPatchOperation(v reflect.Value, path string, segments []patchPathSegment, op PatchOperation) error {
+	if len(segments) == 0 {
+		if op.Op == PatchOpRemove {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		decoded, err := decodePatchValue(v, op.Value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op.Path, err)
+		}
+		v.Set(decoded)
+		return nil
+	}
+	segment := segments[0]
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			if op.Op == PatchOpRemove {
+				return nil
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return applyPatchOperation(v.Elem(), path, segments, op)
+	case reflect.Interface:
+		if v.IsNil() {
+			return fmt.Errorf("%s: cannot resolve path in nil interface", op.Path)
+		}
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		if err := applyPatchOperation(elem, path, segments, op); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	case reflect.Struct:
+		if segment.field == "" {
+			return fmt.Errorf("%s: expecting field name, got: [%s]", op.Path, segment.key)
+		}
+		field, found := v.Type().FieldByName(segment.field)
+		if !found || !field.IsExported() || len(field.Index) > 1 {
+			return fmt.Errorf("%s: struct type %s has no field %s", op.Path, v.Type().String(), segment.field)
+		}
+		return applyPatchOperation(v.FieldByIndex(field.Index), fieldPath(path, segment.field), segments[1:], op)
+	case reflect.Slice, reflect.Array:
+		index, err := strconv.Atoi(segment.key)
+		if segment.field != "" || segment.quote || err != nil || index < 0 {
+			return fmt.Errorf("%s: invalid index for type %s", op.Path, v.Type().String())
+		}
+		if v.Kind() == reflect.Slice && len(segments) == 1 {
+			switch {
+			case op.Op == PatchOpRemove && index >= v.Len():
+				// the element was already removed by a previous truncation
+				return nil
+			case op.Op == PatchOpRemove:
+				v.Set(v.Slice(0, index))
+				return nil
+			case index == v.Len():
+				v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+			}
+		}
+		if index >= v.Len() {
+			return fmt.Errorf("%s: index out of range: %d >= %d", op.Path, index, v.Len())
+		}
+		return applyPatchOperation(v.Index(index), indexPath(path, index), segments[1:], op)
+	case reflect.Map:
+		if segment.field != "" {
+			return fmt.Errorf("%s: expecting map key, got: %s", op.Path, segment.field)
+		}
+		key, err := parsePatchKey(v.Type().Key(), segment)
+		if err != nil {
+			return fmt.Errorf("%s: invalid key for type %s: %w", op.Path, v.Type().String(), err)
+		}
+		if len(segments) == 1 && op.Op == PatchOpRemove {
+			if !v.IsNil() {
+				v.SetMapIndex(key, reflect.Value{})
+			}
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		} else if len(segments) > 1 {
+			return fmt.Errorf("%s: map key not found: %s", op.Path, keyPath(path, key))
+		}
+		if err := applyPatchOperation(elem, keyPath(path, key), segments[1:], op); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, elem)
+		return nil
+	}
+	return fmt.Errorf("%s: cannot resolve path in type %s", op.Path, v.Type().String())
+}
+
+// parsePatchKey parses the given path segment into a map key of the given type.
+func parsePatchKey(t reflect.Type, segment patchPathSegment) (reflect.Value, error) {
+	if t.Kind() == reflect.String {
+		if !segment.quote {
+			return reflect.Value{}, fmt.Errorf("expecting quoted key, got: %s", segment.key)
+		}
+		return reflect.ValueOf(segment.key).Convert(t), nil
+	}
+	if t.Kind() == reflect.Interface && segment.quote {
+		return reflect.ValueOf(segment.key), nil
+	}
+	return parseDefaultValue(t, segment.key)
+}
+
+// decodePatchValue decodes the given JSON value into a value of the type of v. If v is an
+// interface wrapping a value, the value is decoded into the type of the wrapped value.
+func decodePatchValue(v reflect.Value, data json.RawMessage) (reflect.Value, error) {
+	t := v.Type()
+	if v.Kind() == reflect.Interface && string(data) == "null" {
+		return reflect.Zero(t), nil
+	} else if v.Kind() == reflect.Interface && !v.IsNil() {
+		t = v.Elem().Type()
+	}
+	decoded := reflect.New(t)
+	if err := json.Unmarshal(data, decoded.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return decoded.Elem(), nil
+}
+
+// EncodePatch encodes the given Patch into its compact JSON representation.
+func EncodePatch(patch Patch) ([]byte, error) {
+	if patch == nil {
+		patch = Patch{}
+	}
+	return json.Marshal(patch)
+}
+
+// DecodePatch decodes a Patch previously encoded with EncodePatch. It returns an error if the data
+// is not a valid encoded Patch.
+func DecodePatch(data []byte) (Patch, error) {
+	var patch Patch
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return nil, err
+	}
+	for i, op := range patch {
+		switch op.Op {
+		case PatchOpAdd, PatchOpReplace:
+			if len(op.Value) == 0 {
+				return nil, fmt.Errorf("operation %d: %s operation requires a value", i, op.Op)
+			}
+		case PatchOpRemove:
+			if len(op.Value) != 0 {
+				return nil, fmt.Errorf("operation %d: %s operation must not have a value", i, op.Op)
+			}
+		default:
+			return nil, fmt.Errorf("operation %d: unknown operation: %q", i, op.Op)
+		}
+	}
+	return patch, nil
+}
+
+// ComposePatches composes the given patches into one single patch, equivalent to applying the
+// patches in sequence. Operations targeting the same path are collapsed into one operation, at the
+// position of the first one; an operation targeting a path supersedes all previous operations targeting that path or any of its
+// descendants. When an addition is followed by a removal of the same path, both operations are
+// dropped; when a removal is followed by an addition, they are collapsed into a replacement.
+//
+// Since ApplyPatch truncates slices at the removed index, a removal also supersedes all previous
+// operations targeting greater indices of the same slice, or any of their descendants. Paths do not
+// tell slice indices from integer map keys: unquoted integer keys are assumed to be slice indices.
+func ComposePatches(patches ...Patch) Patch {
+	composed := Patch{}
+	for _, patch := range patches {
+		for _, op := range patch {
+			kept := make(Patch, 0, len(composed)+1)
+			// the collapsed operation takes the place of the previous operation on the same path, since
+			// later operations may depend on it, e.g. adding an element after the one it adds
+			previous := -1
+			for _, existing := range composed {
+				if existing.Path == op.Path {
+					previous = len(kept)
+					kept = append(kept, existing)
+				} else if !isDescendantPath(existing.Path, op.Path) && (op.Op != PatchOpRemove || !isTruncatedPath(existing.Path, op.Path)) {
+					kept = append(kept, existing)
+				}
+			}
+			composed = kept
+			if previous < 0 {
+				composed = append(composed, op)
+				continue
+			}
+			switch {
+			case composed[previous].Op == PatchOpAdd && op.Op == PatchOpRemove:
+				composed = append(composed[:previous], composed[previous+1:]...)
+				continue
+			case composed[previous].Op == PatchOpAdd:
+				op.Op = PatchOpAdd
+			case composed[previous].Op == PatchOpRemove && op.Op == PatchOpAdd:
+				op.Op = PatchOpReplace
+			}
+			composed[previous] = op
+		}
+	}
+	return composed
+}
+
+// isDescendantPath returns true if path is a strict descendant of ancestor.
+func isDescendantPath(path, ancestor string) bool {
+	if len(path) <= len(ancestor) || !strings.HasPrefix(path, ancestor) {
+		return false
+	}
+	if ancestor == "" {
+		return true
+	}
+	next := path[len(ancestor)]
+	return next == '.' || next == '['
+}
+
+// isTruncatedPath returns true if path is, or is a descendant of, an index of the same slice as
+// removed, but greater than the removed index: truncating the slice at the removed index discards
+// it.
+func isTruncatedPath(path, removed string) bool {
+	parent, removedIndex, ok := splitIndexPath(removed)
+	if !ok || !strings.HasPrefix(path, parent+"[") {
+		return false
+	}
+	rest := path[len(parent)+1:]
+	end := strings.IndexByte(rest, ']')
+	if end < 0 {
+		return false
+	}
+	index, err := strconv.Atoi(rest[:end])
+	return err == nil && rest[:end] == strconv.Itoa(index) && index > removedIndex
+}
+
+// splitIndexPath splits the given path into its parent path and its last segment, if that segment
+// is an unquoted, non-negative integer, i.e. a slice index, as created by indexPath.
+func splitIndexPath(path string) (parent string, index int, ok bool) {
+	start := strings.LastIndexByte(path, '[')
+	if start < 0 || !strings.HasSuffix(path, "]") {
+		return "", 0, false
+	}
+	token := path[start+1 : len(path)-1]
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || token != strconv.Itoa(index) {
+		return "", 0, false
+	}
+	return path[:start], index, true
+}
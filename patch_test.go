@@ -0,0 +1,467 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPatch(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		diff := Diff{
+			{Path: "Name", Kind: ChangeModified, From: "Alice", To: "Bob"},
+			{Path: `Labels["tier"]`, Kind: ChangeAdded, To: "backend"},
+			{Path: "Tags[1]", Kind: ChangeRemoved, From: "action"},
+			{Path: "Ptr", Kind: ChangeModified, From: intPtr(1), To: (*int)(nil)},
+		}
+		got, err := NewPatch(diff)
+		assert.NoError(t, err)
+		assert.Equal(t, Patch{
+			{Op: PatchOpReplace, Path: "Name", Value: json.RawMessage(`"Bob"`)},
+			{Op: PatchOpAdd, Path: `Labels["tier"]`, Value: json.RawMessage(`"backend"`)},
+			{Op: PatchOpRemove, Path: "Tags[1]"},
+			{Op: PatchOpReplace, Path: "Ptr", Value: json.RawMessage(`null`)},
+		}, got)
+	})
+	t.Run("empty", func(t *testing.T) {
+		got, err := NewPatch(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, Patch{}, got)
+	})
+	t.Run("unknown kind", func(t *testing.T) {
+		_, err := NewPatch(Diff{{Path: "Name", Kind: ChangeKind(42)}})
+		assert.EqualError(t, err, "Name: unknown change kind: ChangeKind(42)")
+	})
+	t.Run("not serializable", func(t *testing.T) {
+		_, err := NewPatch(Diff{{Path: "Func", Kind: ChangeAdded, To: func() {}}})
+		assert.EqualError(t, err, "Func: json: unsupported type: func()")
+	})
+}
+
+func TestEncodePatch(t *testing.T) {
+	patch := Patch{
+		{Op: PatchOpReplace, Path: "Name", Value: json.RawMessage(`"Bob"`)},
+		{Op: PatchOpRemove, Path: "Tags[1]"},
+	}
+	got, err := EncodePatch(patch)
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"op":"replace","path":"Name","value":"Bob"},{"op":"remove","path":"Tags[1]"}]`, string(got))
+	got, err = EncodePatch(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, string(got))
+}
+
+func TestDecodePatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Patch
+		wantErr string
+	}{
+		{
+			name: "success",
+			data: `[{"op":"replace","path":"Name","value":"Bob"},{"op":"add","path":"Age","value":20},{"op":"remove","path":"Tags[1]"}]`,
+			want: Patch{
+				{Op: PatchOpReplace, Path: "Name", Value: json.RawMessage(`"Bob"`)},
+				{Op: PatchOpAdd, Path: "Age", Value: json.RawMessage(`20`)},
+				{Op: PatchOpRemove, Path: "Tags[1]"},
+			},
+		},
+		{
+			name: "empty",
+			data: `[]`,
+			want: Patch{},
+		},
+		{
+			name:    "invalid json",
+			data:    `{`,
+			wantErr: "unexpected end of JSON input",
+		},
+		{
+			name:    "missing value",
+			data:    `[{"op":"add","path":"Age"}]`,
+			wantErr: "operation 0: add operation requires a value",
+		},
+		{
+			name:    "unexpected value",
+			data:    `[{"op":"remove","path":"Age","value":1}]`,
+			wantErr: "operation 0: remove operation must not have a value",
+		},
+		{
+			name:    "unknown op",
+			data:    `[{"op":"move","path":"Age"}]`,
+			wantErr: `operation 0: unknown operation: "move"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodePatch([]byte(tt.data))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+	t.Run("round trip", func(t *testing.T) {
+		type user struct {
+			Name   string
+			Labels map[string]string
+		}
+		diff, err := DeepDiff(user{Name: "Alice"}, user{Name: "Bob", Labels: map[string]string{"a": "b"}})
+		assert.NoError(t, err)
+		patch, err := NewPatch(diff)
+		assert.NoError(t, err)
+		data, err := EncodePatch(patch)
+		assert.NoError(t, err)
+		decoded, err := DecodePatch(data)
+		assert.NoError(t, err)
+		assert.Equal(t, patch, decoded)
+	})
+}
+
+func TestComposePatches(t *testing.T) {
+	v := func(s string) json.RawMessage { return json.RawMessage(s) }
+	tests := []struct {
+		name    string
+		patches []Patch
+		want    Patch
+	}{
+		{
+			name:    "none",
+			patches: nil,
+			want:    Patch{},
+		},
+		{
+			name: "disjoint",
+			patches: []Patch{
+				{{Op: PatchOpReplace, Path: "Name", Value: v(`"Bob"`)}},
+				{{Op: PatchOpAdd, Path: "Age", Value: v(`20`)}},
+			},
+			want: Patch{
+				{Op: PatchOpReplace, Path: "Name", Value: v(`"Bob"`)},
+				{Op: PatchOpAdd, Path: "Age", Value: v(`20`)},
+			},
+		},
+		{
+			name: "same path replace replace",
+			patches: []Patch{
+				{{Op: PatchOpReplace, Path: "Name", Value: v(`"Bob"`)}},
+				{{Op: PatchOpReplace, Path: "Name", Value: v(`"Carol"`)}},
+			},
+			want: Patch{{Op: PatchOpReplace, Path: "Name", Value: v(`"Carol"`)}},
+		},
+		{
+			name: "same path add replace",
+			patches: []Patch{
+				{{Op: PatchOpAdd, Path: "Name", Value: v(`"Bob"`)}},
+				{{Op: PatchOpReplace, Path: "Name", Value: v(`"Carol"`)}},
+			},
+			want: Patch{{Op: PatchOpAdd, Path: "Name", Value: v(`"Carol"`)}},
+		},
+		{
+			name: "same path add remove",
+			patches: []Patch{
+				{{Op: PatchOpReplace, Path: "Age", Value: v(`20`)}, {Op: PatchOpAdd, Path: "Name", Value: v(`"Bob"`)}},
+				{{Op: PatchOpRemove, Path: "Name"}},
+			},
+			want: Patch{{Op: PatchOpReplace, Path: "Age", Value: v(`20`)}},
+		},
+		{
+			name: "same path remove add",
+			patches: []Patch{
+				{{Op: PatchOpRemove, Path: "Name"}},
+				{{Op: PatchOpAdd, Path: "Name", Value: v(`"Bob"`)}},
+			},
+			want: Patch{{Op: PatchOpReplace, Path: "Name", Value: v(`"Bob"`)}},
+		},
+		{
+			name: "same path replace remove",
+			patches: []Patch{
+				{{Op: PatchOpReplace, Path: "Name", Value: v(`"Bob"`)}},
+				{{Op: PatchOpRemove, Path: "Name"}},
+			},
+			want: Patch{{Op: PatchOpRemove, Path: "Name"}},
+		},
+		{
+			name: "same path collapsed in place",
+			patches: []Patch{
+				{{Op: PatchOpAdd, Path: "L[0]", Value: v(`1`)}, {Op: PatchOpAdd, Path: "L[1]", Value: v(`2`)}},
+				{{Op: PatchOpReplace, Path: "L[0]", Value: v(`5`)}},
+			},
+			want: Patch{
+				{Op: PatchOpAdd, Path: "L[0]", Value: v(`5`)},
+				{Op: PatchOpAdd, Path: "L[1]", Value: v(`2`)},
+			},
+		},
+		{
+			name: "ancestor supersedes descendants",
+			patches: []Patch{
+				{
+					{Op: PatchOpReplace, Path: `Labels["a"]`, Value: v(`"1"`)},
+					{Op: PatchOpReplace, Path: "Spec.Replicas", Value: v(`2`)},
+					{Op: PatchOpReplace, Path: "SpecName", Value: v(`"x"`)},
+				},
+				{{Op: PatchOpRemove, Path: "Labels"}, {Op: PatchOpReplace, Path: "Spec", Value: v(`{}`)}},
+			},
+			want: Patch{
+				{Op: PatchOpReplace, Path: "SpecName", Value: v(`"x"`)},
+				{Op: PatchOpRemove, Path: "Labels"},
+				{Op: PatchOpReplace, Path: "Spec", Value: v(`{}`)},
+			},
+		},
+		{
+			name: "descendant after ancestor",
+			patches: []Patch{
+				{{Op: PatchOpReplace, Path: "Spec", Value: v(`{}`)}},
+				{{Op: PatchOpReplace, Path: "Spec.Replicas", Value: v(`2`)}},
+			},
+			want: Patch{
+				{Op: PatchOpReplace, Path: "Spec", Value: v(`{}`)},
+				{Op: PatchOpReplace, Path: "Spec.Replicas", Value: v(`2`)},
+			},
+		},
+		{
+			name: "slice removal truncates",
+			patches: []Patch{
+				{{Op: PatchOpAdd, Path: "S[2]", Value: v(`"c"`)}, {Op: PatchOpAdd, Path: "S[3]", Value: v(`"d"`)}},
+				{{Op: PatchOpRemove, Path: "S[2]"}},
+			},
+			want: Patch{},
+		},
+		{
+			name: "slice removal truncates descendants",
+			patches: []Patch{
+				{
+					{Op: PatchOpReplace, Path: "S[1].Name", Value: v(`"b"`)},
+					{Op: PatchOpReplace, Path: "S[3].Name", Value: v(`"d"`)},
+					{Op: PatchOpReplace, Path: "S[10]", Value: v(`{}`)},
+					{Op: PatchOpReplace, Path: "T[3]", Value: v(`{}`)},
+					{Op: PatchOpReplace, Path: `M["3"]`, Value: v(`{}`)},
+				},
+				{{Op: PatchOpRemove, Path: "S[2]"}},
+			},
+			want: Patch{
+				{Op: PatchOpReplace, Path: "S[1].Name", Value: v(`"b"`)},
+				{Op: PatchOpReplace, Path: "T[3]", Value: v(`{}`)},
+				{Op: PatchOpReplace, Path: `M["3"]`, Value: v(`{}`)},
+				{Op: PatchOpRemove, Path: "S[2]"},
+			},
+		},
+		{
+			name: "root",
+			patches: []Patch{
+				{{Op: PatchOpReplace, Path: "Name", Value: v(`"Bob"`)}},
+				{{Op: PatchOpReplace, Path: "", Value: v(`{}`)}},
+			},
+			want: Patch{{Op: PatchOpReplace, Path: "", Value: v(`{}`)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ComposePatches(tt.patches...))
+		})
+	}
+	t.Run("apply slice removal", func(t *testing.T) {
+		type list struct {
+			S []string
+		}
+		p1 := Patch{{Op: PatchOpAdd, Path: "S[2]", Value: v(`"c"`)}, {Op: PatchOpAdd, Path: "S[3]", Value: v(`"d"`)}}
+		p2 := Patch{{Op: PatchOpRemove, Path: "S[2]"}}
+		sequential, err := ApplyPatch(list{S: []string{"a", "b"}}, p1)
+		require.NoError(t, err)
+		sequential, err = ApplyPatch(sequential, p2)
+		require.NoError(t, err)
+		composed, err := ApplyPatch(list{S: []string{"a", "b"}}, ComposePatches(p1, p2))
+		require.NoError(t, err)
+		assert.Equal(t, list{S: []string{"a", "b"}}, sequential)
+		assert.Equal(t, sequential, composed)
+	})
+	t.Run("apply collapsed in place", func(t *testing.T) {
+		type list struct {
+			L []int
+		}
+		p1, err := ExtractPatch(list{L: []int{}}, list{L: []int{1, 2}})
+		require.NoError(t, err)
+		p2 := Patch{{Op: PatchOpReplace, Path: "L[0]", Value: v(`5`)}}
+		sequential, err := ApplyPatch(list{L: []int{}}, p1)
+		require.NoError(t, err)
+		sequential, err = ApplyPatch(sequential, p2)
+		require.NoError(t, err)
+		composed, err := ApplyPatch(list{L: []int{}}, ComposePatches(p1, p2))
+		require.NoError(t, err)
+		assert.Equal(t, list{L: []int{5, 2}}, sequential)
+		assert.Equal(t, sequential, composed)
+	})
+}
+
+func TestExtractPatch(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		type foo struct {
+			Name   string
+			Labels map[string]string
+		}
+		got, err := ExtractPatch(foo{Name: "Alice", Labels: map[string]string{"a": "1"}}, foo{Name: "Bob"})
+		assert.NoError(t, err)
+		assert.Equal(t, Patch{
+			{Op: PatchOpReplace, Path: "Name", Value: json.RawMessage(`"Bob"`)},
+			{Op: PatchOpRemove, Path: "Labels"},
+		}, got)
+	})
+	t.Run("type mismatch", func(t *testing.T) {
+		_, err := ExtractPatch[interface{}](1, "a")
+		assert.EqualError(t, err, "types do not match: int != string")
+	})
+}
+
+func TestApplyPatch(t *testing.T) {
+	type inner struct {
+		ID    int
+		Ports []int
+	}
+	type foo struct {
+		Name     string
+		Ptr      *int
+		Labels   map[string]string
+		Counts   map[int]int
+		Tags     []string
+		Inners   []inner
+		Array    [2]int
+		Nested   *inner
+		Any      interface{}
+		unexp    int
+		Children map[string]*inner
+	}
+	t.Run("round trip", func(t *testing.T) {
+		v1 := foo{
+			Name:     "Alice",
+			Labels:   map[string]string{"a": "1", "b": "2"},
+			Counts:   map[int]int{1: 1},
+			Tags:     []string{"a", "b", "c"},
+			Inners:   []inner{{ID: 1, Ports: []int{80}}},
+			Array:    [2]int{1, 2},
+			Any:      inner{ID: 1},
+			Children: map[string]*inner{"x": {ID: 1}},
+		}
+		v2 := foo{
+			Name:     "Bob",
+			Ptr:      intPtr(1),
+			Labels:   map[string]string{"a": "3", `c"d`: "4"},
+			Counts:   map[int]int{1: 2, 2: 2},
+			Tags:     []string{"a"},
+			Inners:   []inner{{ID: 1, Ports: []int{80, 443}}, {ID: 2}},
+			Array:    [2]int{1, 3},
+			Nested:   &inner{ID: 3},
+			Any:      inner{ID: 2},
+			Children: map[string]*inner{"x": {ID: 2, Ports: []int{1}}},
+		}
+		patch, err := ExtractPatch(v1, v2)
+		require.NoError(t, err)
+		data, err := EncodePatch(patch)
+		require.NoError(t, err)
+		decoded, err := DecodePatch(data)
+		require.NoError(t, err)
+		got, err := ApplyPatch(v1, decoded)
+		assert.NoError(t, err)
+		assert.Equal(t, v2, got)
+		assert.Equal(t, "Alice", v1.Name)
+		assert.Equal(t, map[string]string{"a": "1", "b": "2"}, v1.Labels)
+		assert.Equal(t, []string{"a", "b", "c"}, v1.Tags)
+	})
+	t.Run("pointer target", func(t *testing.T) {
+		got, err := ApplyPatch(&foo{Name: "Alice"}, Patch{{Op: PatchOpReplace, Path: "Name", Value: json.RawMessage(`"Bob"`)}})
+		assert.NoError(t, err)
+		assert.Equal(t, &foo{Name: "Bob"}, got)
+	})
+	t.Run("root", func(t *testing.T) {
+		got, err := ApplyPatch(1, Patch{{Op: PatchOpReplace, Path: "", Value: json.RawMessage(`2`)}})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, got)
+	})
+	t.Run("nil interface", func(t *testing.T) {
+		got, err := ApplyPatch(foo{Any: 1}, Patch{{Op: PatchOpReplace, Path: "Any", Value: json.RawMessage(`null`)}})
+		assert.NoError(t, err)
+		assert.Equal(t, foo{}, got)
+	})
+	t.Run("allocates", func(t *testing.T) {
+		got, err := ApplyPatch(foo{}, Patch{
+			{Op: PatchOpAdd, Path: "Nested.ID", Value: json.RawMessage(`1`)},
+			{Op: PatchOpAdd, Path: `Labels["a"]`, Value: json.RawMessage(`"b"`)},
+			{Op: PatchOpAdd, Path: "Tags[0]", Value: json.RawMessage(`"c"`)},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, foo{Nested: &inner{ID: 1}, Labels: map[string]string{"a": "b"}, Tags: []string{"c"}}, got)
+	})
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name string
+			op   PatchOperation
+			want string
+		}{
+			{"unknown field", PatchOperation{Op: PatchOpReplace, Path: "Foo", Value: json.RawMessage(`1`)}, "operation 0: Foo: struct type goalesce.foo has no field Foo"},
+			{"unexported field", PatchOperation{Op: PatchOpReplace, Path: "unexp", Value: json.RawMessage(`1`)}, "operation 0: unexp: struct type goalesce.foo has no field unexp"},
+			{"wrong type", PatchOperation{Op: PatchOpReplace, Path: "Name", Value: json.RawMessage(`1`)}, "operation 0: Name: json: cannot unmarshal number into Go value of type string"},
+			{"index out of range", PatchOperation{Op: PatchOpReplace, Path: "Tags[2]", Value: json.RawMessage(`"a"`)}, "operation 0: Tags[2]: index out of range: 2 >= 0"},
+			{"invalid index", PatchOperation{Op: PatchOpReplace, Path: `Tags["a"]`, Value: json.RawMessage(`"a"`)}, `operation 0: Tags["a"]: invalid index for type []string`},
+			{"unquoted key", PatchOperation{Op: PatchOpReplace, Path: "Labels[a]", Value: json.RawMessage(`"a"`)}, "operation 0: Labels[a]: invalid key for type map[string]string: expecting quoted key, got: a"},
+			{"invalid key", PatchOperation{Op: PatchOpReplace, Path: "Counts[a]", Value: json.RawMessage(`1`)}, `operation 0: Counts[a]: invalid key for type map[int]int: strconv.ParseInt: parsing "a": invalid syntax`},
+			{"missing key", PatchOperation{Op: PatchOpReplace, Path: `Children["x"].ID`, Value: json.RawMessage(`1`)}, `operation 0: Children["x"].ID: map key not found: Children["x"]`},
+			{"atomic", PatchOperation{Op: PatchOpReplace, Path: "Name.Foo", Value: json.RawMessage(`1`)}, "operation 0: Name.Foo: cannot resolve path in type string"},
+			{"nil interface", PatchOperation{Op: PatchOpReplace, Path: "Any.ID", Value: json.RawMessage(`1`)}, "operation 0: Any.ID: cannot resolve path in nil interface"},
+			{"invalid path", PatchOperation{Op: PatchOpReplace, Path: "Tags[0", Value: json.RawMessage(`1`)}, "operation 0: invalid path Tags[0: missing closing bracket"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := ApplyPatch(foo{}, Patch{tt.op})
+				assert.EqualError(t, err, tt.want)
+			})
+		}
+	})
+}
+
+func Test_parsePatchPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []patchPathSegment
+		wantErr string
+	}{
+		{"", nil, ""},
+		{"Foo", []patchPathSegment{{field: "Foo"}}, ""},
+		{`Foo.Bar[1]["a.b]"][true]`, []patchPathSegment{{field: "Foo"}, {field: "Bar"}, {key: "1"}, {key: "a.b]", quote: true}, {key: "true"}}, ""},
+		{"[1].Foo", []patchPathSegment{{key: "1"}, {field: "Foo"}}, ""},
+		{".Foo", nil, "invalid path .Foo: empty field name"},
+		{"Foo..Bar", nil, "invalid path Foo..Bar: empty field name"},
+		{`Foo["a`, nil, `invalid path Foo["a: invalid syntax`},
+		{`Foo["a"`, nil, `invalid path Foo["a": missing closing bracket`},
+		{"Foo[1]Bar", nil, `invalid path Foo[1]Bar: unexpected character 'B'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := parsePatchPath(tt.path)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pathMerger is a merger registered for the values located at paths matching a path expression,
+// see WithPathMerger.
+type pathMerger struct {
+	expr     string
+	pattern  *regexp.Regexp   // matches the paths the merger applies to
+	prefixes []*regexp.Regexp // match the paths of the ancestors of the values the merger applies to
+	merger   DeepMergeFunc
+}
+
+// anyBracket matches any slice index or map key, including quoted string keys containing brackets.
+const anyBracket = `\[(?:"(?:[^"\\]|\\.)*"|[^\]]*)\]`
+
+// newPathMerger compiles the given path expression. Path expressions use the same syntax as the
+// paths reported in errors and diffs, e.g. `Spec.Containers[0].Env` or `Labels["app"]`, where "*"
+// can be used as a field name or in brackets to match any field, slice index or map key.
+func newPathMerger(expr string, merger DeepMergeFunc) (pathMerger, error) {
+	segments, err := parsePatchPath(expr)
+	if err != nil {
+		return pathMerger{}, err
+	}
+	if len(segments) == 0 {
+		return pathMerger{}, fmt.Errorf("invalid path %s: empty path", expr)
+	}
+	var sb strings.Builder
+	pm := pathMerger{expr: expr, merger: merger}
+	for i, segment := range segments {
+		if i > 0 {
+			pm.prefixes = append(pm.prefixes, regexp.MustCompile("^"+sb.String()+"$"))
+		}
+		switch {
+		case segment.field == "*":
+			if i > 0 {
+				sb.WriteString(`\.`)
+			}
+			sb.WriteString(`[^.\[]+`)
+		case segment.field != "":
+			if i > 0 {
+				sb.WriteString(`\.`)
+			}
+			sb.WriteString(regexp.QuoteMeta(segment.field))
+		case segment.key == "*" && !segment.quote:
+			sb.WriteString(anyBracket)
+		case segment.quote:
+			sb.WriteString(regexp.QuoteMeta("[" + strconv.Quote(segment.key) + "]"))
+		default:
+			sb.WriteString(regexp.QuoteMeta("[" + segment.key + "]"))
+		}
+	}
+	pm.pattern = regexp.MustCompile("^" + sb.String() + "$")
+	return pm, nil
+}
+
+// addPathMerger registers the given merger for the given path expression. Invalid expressions are
+// reported when merging, see pathMerger.
+func (c *coalescer) addPathMerger(expr string, merger DeepMergeFunc) {
+	pm, err := newPathMerger(expr, merger)
+	if err != nil {
+		c.pathMergerErr = errors.Join(c.pathMergerErr, err)
+		return
+	}
+	c.pathMergers = append(c.pathMergers, pm)
+}
+
+// pathMerger returns the merger registered for the current path, if any. When several path
+// expressions match, the last registered one wins. An error is returned if any registered path
+// expression is invalid.
+func (c *coalescer) pathMerger() (DeepMergeFunc, bool, error) {
+	if c.pathMergerErr != nil {
+		return nil, false, c.pathMergerErr
+	}
+	for i := len(c.pathMergers) - 1; i >= 0; i-- {
+		if c.pathMergers[i].pattern.MatchString(c.path) {
+			return c.pathMergers[i].merger, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// hasPathMergersUnder returns true if mergers are registered for paths nested under the current
+// path, in which case the current value must not be merged with shortcuts that skip its children.
+func (c *coalescer) hasPathMergersUnder() bool {
+	for _, pm := range c.pathMergers {
+		if c.path == "" {
+			return true
+		}
+		for _, prefix := range pm.prefixes {
+			if prefix.MatchString(c.path) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newPathMerger(t *testing.T) {
+	tests := []struct {
+		expr     string
+		matches  []string
+		nomatch  []string
+		prefixes []string
+	}{
+		{
+			expr:     "Spec.Labels",
+			matches:  []string{"Spec.Labels"},
+			nomatch:  []string{"Spec", "Spec.LabelsX", "Spec.Labels.Foo", "XSpec.Labels", "SpecXLabels"},
+			prefixes: []string{"Spec"},
+		},
+		{
+			expr:     "Spec.Containers[*].Env",
+			matches:  []string{"Spec.Containers[0].Env", "Spec.Containers[12].Env"},
+			nomatch:  []string{"Spec.Containers.Env", "Spec.Containers[0].Image"},
+			prefixes: []string{"Spec", "Spec.Containers", "Spec.Containers[3]"},
+		},
+		{
+			expr:     "*.Labels[*]",
+			matches:  []string{"Spec.Labels[\"a\"]", `Meta.Labels["a]b"]`, `Meta.Labels["a\"]"]`},
+			nomatch:  []string{"Spec.Labels", "Labels[\"a\"]", "Spec.Sub.Labels[\"a\"]"},
+			prefixes: []string{"Meta", "Meta.Labels"},
+		},
+		{
+			expr:    `Labels["a.b"]`,
+			matches: []string{`Labels["a.b"]`},
+			nomatch: []string{`Labels[a.b]`, `Labels["aXb"]`},
+		},
+		{
+			expr:    "[1].Name",
+			matches: []string{"[1].Name"},
+			nomatch: []string{"[2].Name"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			pm, err := newPathMerger(tt.expr, nil)
+			require.NoError(t, err)
+			for _, path := range tt.matches {
+				assert.True(t, pm.pattern.MatchString(path), path)
+			}
+			for _, path := range tt.nomatch {
+				assert.False(t, pm.pattern.MatchString(path), path)
+			}
+			c := newCoalescer()
+			c.pathMergers = []pathMerger{pm}
+			for _, path := range tt.prefixes {
+				c.path = path
+				assert.True(t, c.hasPathMergersUnder(), path)
+			}
+			c.path = tt.expr + ".Other"
+			assert.False(t, c.hasPathMergersUnder())
+		})
+	}
+	t.Run("invalid", func(t *testing.T) {
+		_, err := newPathMerger("Spec..Labels", nil)
+		assert.EqualError(t, err, "invalid path Spec..Labels: empty field name")
+		_, err = newPathMerger("", nil)
+		assert.EqualError(t, err, "invalid path : empty path")
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"reflect"
+)
+
+// FieldPermissionFunc is a function that tells whether the second value of a merge is allowed to
+// modify the given struct field. The passed path is the location of the field, relative to the
+// merged value, e.g. "Spec.Containers[2].Image". See WithFieldPermission.
+type FieldPermissionFunc func(path string, field reflect.StructField) bool
+
+// mustCheckPermissions returns true if field permissions or field allowlists are enabled and the
+// given value, coming from the second value of a merge, is non-zero and thus may modify protected
+// fields. In that case, mergers must not shortcut the merge by copying the value as is, but merge it
+// with a zero-value instead.
+func (c *coalescer) mustCheckPermissions(v2 reflect.Value) bool {
+	return (c.fieldPermission != nil || len(c.fieldAllowlists) > 0) && !v2.IsZero()
+}
+
+// checkFieldPermission merges the given struct field values with the given merger, if the field
+// can be modified. Otherwise, it returns a copy of the first value, or an error if
+// WithErrorOnFieldPermissionDenied is in effect and the merge would have modified the field.
+func (c *coalescer) checkFieldPermission(field reflect.StructField, fieldMerger DeepMergeFunc, v1, v2 reflect.Value) (reflect.Value, error) {
+	if c.fieldPermission == nil || c.fieldPermission(c.path, field) {
+		return fieldMerger(v1, v2)
+	}
+	if c.errorOnFieldPermission && !v2.IsZero() {
+		merged, err := fieldMerger(v1, v2)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !reflect.DeepEqual(merged.Interface(), v1.Interface()) {
+			return reflect.Value{}, errors.New("field modification not permitted")
+		}
+	}
+	return c.deepCopy(v1)
+}
+
+// deepMergeCheckedElements merges 2 slices or arrays atomically, like deepMergeAtomic, when the
+// permissions of the struct fields held by their elements must be checked: instead of replacing v1
+// with a copy of v2, which would bypass these permissions, each element of v2 is merged with the
+// element at the same index in v1, or with a zero-value if there is none. The merged value has the
+// length of v2.
+func (c *coalescer) deepMergeCheckedElements(v1, v2 reflect.Value) (reflect.Value, error) {
+	var merged reflect.Value
+	if v1.Kind() == reflect.Slice {
+		merged = reflect.MakeSlice(v1.Type(), v2.Len(), v2.Len())
+	} else {
+		merged = reflect.New(v1.Type()).Elem()
+	}
+	parent := c.path
+	defer func() { c.path = parent }()
+	for i := 0; i < v2.Len(); i++ {
+		e1 := reflect.Zero(v1.Type().Elem())
+		if i < v1.Len() {
+			e1 = v1.Index(i)
+		}
+		c.path = indexPath(parent, i)
+		elem, err := c.deepMergeAt(e1, v2.Index(i))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		merged.Index(i).Set(elem)
+	}
+	return merged, nil
+}
+
+// mayHoldStructs returns true if values of the given type may hold struct values, and thus struct
+// fields subject to permissions.
+func mayHoldStructs(t reflect.Type) bool {
+	for depth := 0; depth < 8; depth++ {
+		switch t.Kind() {
+		case reflect.Struct, reflect.Interface:
+			return true
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+	// deeply nested or recursive container types, e.g. type T []T
+	return true
+}
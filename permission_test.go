@@ -0,0 +1,214 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_coalescer_checkFieldPermission(t *testing.T) {
+	type role struct {
+		Name  string
+		Admin bool
+	}
+	type user struct {
+		Name    string
+		Admin   bool
+		Role    *role
+		Roles   map[string]role
+		Others  []role `goalesce:"index"`
+		Any     interface{}
+		Default role
+		Users   []role
+		Arr     [1]role
+	}
+	denyAdmin := func(path string, field reflect.StructField) bool {
+		return field.Name != "Admin"
+	}
+	tests := []struct {
+		name    string
+		v1      user
+		v2      user
+		want    user
+		wantErr string
+		opts    []Option
+	}{
+		{
+			name: "allowed",
+			v1:   user{Name: "Alice"},
+			v2:   user{Name: "Bob"},
+			want: user{Name: "Bob"},
+		},
+		{
+			name: "denied",
+			v1:   user{Name: "Alice"},
+			v2:   user{Name: "Bob", Admin: true},
+			want: user{Name: "Bob"},
+		},
+		{
+			name: "denied nested",
+			v1:   user{Default: role{Name: "a"}},
+			v2:   user{Default: role{Name: "b", Admin: true}},
+			want: user{Default: role{Name: "b"}},
+		},
+		{
+			name: "denied v1 zero",
+			v1:   user{},
+			v2:   user{Name: "Bob", Admin: true, Default: role{Admin: true}},
+			want: user{Name: "Bob"},
+		},
+		{
+			name: "denied nil pointer",
+			v1:   user{},
+			v2:   user{Role: &role{Name: "b", Admin: true}},
+			want: user{Role: &role{Name: "b"}},
+		},
+		{
+			name: "denied non-nil pointer",
+			v1:   user{Role: &role{Name: "a"}},
+			v2:   user{Role: &role{Admin: true}},
+			want: user{Role: &role{Name: "a"}},
+		},
+		{
+			name: "denied map entries",
+			v1:   user{Roles: map[string]role{"a": {Name: "a"}}},
+			v2:   user{Roles: map[string]role{"a": {Admin: true}, "b": {Name: "b", Admin: true}}},
+			want: user{Roles: map[string]role{"a": {Name: "a"}, "b": {Name: "b"}}},
+		},
+		{
+			name: "denied nil map",
+			v1:   user{},
+			v2:   user{Roles: map[string]role{"b": {Name: "b", Admin: true}}},
+			want: user{Roles: map[string]role{"b": {Name: "b"}}},
+		},
+		{
+			name: "denied nil interface",
+			v1:   user{},
+			v2:   user{Any: role{Name: "b", Admin: true}},
+			want: user{Any: role{Name: "b"}},
+		},
+		{
+			name: "denied slice elements",
+			v1:   user{Others: []role{{Name: "a"}}},
+			v2:   user{Others: []role{{Admin: true}}},
+			want: user{Others: []role{{Name: "a"}}},
+		},
+		{
+			name: "denied atomic slice elements",
+			v1:   user{Users: []role{{Name: "a"}}},
+			v2:   user{Users: []role{{Name: "b", Admin: true}, {Name: "c", Admin: true}}},
+			want: user{Users: []role{{Name: "b"}, {Name: "c"}}},
+		},
+		{
+			name: "denied atomic slice elements v1 zero",
+			v1:   user{},
+			v2:   user{Users: []role{{Name: "b", Admin: true}}},
+			want: user{Users: []role{{Name: "b"}}},
+		},
+		{
+			name: "denied array elements",
+			v1:   user{Arr: [1]role{{Name: "a"}}},
+			v2:   user{Arr: [1]role{{Name: "b", Admin: true}}},
+			want: user{Arr: [1]role{{Name: "b"}}},
+		},
+		{
+			name: "denied array elements v1 zero",
+			v1:   user{},
+			v2:   user{Arr: [1]role{{Name: "b", Admin: true}}},
+			want: user{Arr: [1]role{{Name: "b"}}},
+		},
+		{
+			name:    "error",
+			v1:      user{Name: "Alice"},
+			v2:      user{Name: "Bob", Admin: true},
+			wantErr: "at Admin: field modification not permitted",
+			opts:    []Option{WithErrorOnFieldPermissionDenied()},
+		},
+		{
+			name:    "error nested",
+			v1:      user{},
+			v2:      user{Roles: map[string]role{"b": {Name: "b", Admin: true}}},
+			wantErr: `at Roles["b"].Admin: field modification not permitted`,
+			opts:    []Option{WithErrorOnFieldPermissionDenied()},
+		},
+		{
+			name:    "error slice elements",
+			v1:      user{Others: []role{{Name: "a"}}},
+			v2:      user{Others: []role{{Admin: true}}},
+			wantErr: `at Others[0].Admin: field modification not permitted`,
+			opts:    []Option{WithErrorOnFieldPermissionDenied()},
+		},
+		{
+			name:    "error atomic slice elements",
+			v1:      user{Users: []role{{Name: "a"}}},
+			v2:      user{Users: []role{{Admin: true}}},
+			wantErr: `at Users[0].Admin: field modification not permitted`,
+			opts:    []Option{WithErrorOnFieldPermissionDenied()},
+		},
+		{
+			name:    "error array elements",
+			v1:      user{},
+			v2:      user{Arr: [1]role{{Admin: true}}},
+			wantErr: `at Arr[0].Admin: field modification not permitted`,
+			opts:    []Option{WithErrorOnFieldPermissionDenied()},
+		},
+		{
+			name: "no error when unchanged",
+			v1:   user{Name: "Alice", Admin: true},
+			v2:   user{Name: "Bob", Admin: true},
+			want: user{Name: "Bob", Admin: true},
+			opts: []Option{WithErrorOnFieldPermissionDenied()},
+		},
+		{
+			name:    "merge error",
+			v1:      user{Name: "Alice"},
+			v2:      user{Name: "Bob", Admin: true},
+			wantErr: "mock DeepMerge error",
+			opts:    []Option{WithErrorOnFieldPermissionDenied(), withMockDeepMergeError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2, append(tt.opts, WithFieldPermission(denyAdmin))...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+	t.Run("paths", func(t *testing.T) {
+		var paths []string
+		recordPaths := func(path string, field reflect.StructField) bool {
+			paths = append(paths, path)
+			return true
+		}
+		v1 := user{Roles: map[string]role{"a": {}}, Others: []role{{}}}
+		v2 := user{Name: "Bob", Role: &role{Name: "b"}, Roles: map[string]role{"a": {Name: "a"}}, Others: []role{{Name: "c"}}}
+		_, err := DeepMerge(v1, v2, WithFieldPermission(recordPaths))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{
+			"Name", "Admin",
+			"Role", "Role.Name", "Role.Admin",
+			"Roles", `Roles["a"].Name`, `Roles["a"].Admin`,
+			"Others", "Others[0].Name", "Others[0].Admin",
+			"Any", "Default", "Users", "Arr",
+		}, paths)
+	})
+}
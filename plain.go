@@ -0,0 +1,65 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "reflect"
+
+// isPlainType returns true if values of the given type are plain value aggregates, that can be
+// copied with a direct assignment instead of a recursive copy: booleans, numbers and strings, and
+// arrays and structs thereof. Structs must only have exported fields, since a recursive copy does
+// not copy unexported fields. Types with custom copiers, or containing types with custom copiers,
+// are not plain. Results are cached per coalescer, since they depend on the registered copiers.
+func (c *coalescer) isPlainType(t reflect.Type) bool {
+	if plain, found := c.plainTypes[t]; found {
+		return plain
+	}
+	plain := c.computePlainType(t)
+	c.plainTypes[t] = plain
+	return plain
+}
+
+func (c *coalescer) computePlainType(t reflect.Type) bool {
+	if _, found := c.typeCopier(t); found {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.String:
+		return true
+	case reflect.Array:
+		return c.isPlainType(t.Elem())
+	case reflect.Struct:
+		if isSyncAtomicType(t) || t == syncMapType || strategiesFor(t).excludes || strategiesFor(t).anyCopy {
+			return false
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if field := t.Field(i); !field.IsExported() || !c.isPlainType(field.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// copyPlain copies a value of a plain type, see isPlainType.
+func copyPlain(v reflect.Value) reflect.Value {
+	copied := reflect.New(v.Type()).Elem()
+	copied.Set(v)
+	return copied
+}
@@ -0,0 +1,97 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_isPlainType(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	type rect struct {
+		Min, Max point
+		Name     string
+		Corners  [4]point
+	}
+	type withPointer struct {
+		X *int
+	}
+	type withUnexported struct {
+		X int
+		y int
+	}
+	type nested struct {
+		Point point
+		Ptr   withPointer
+	}
+	tests := []struct {
+		name string
+		t    reflect.Type
+		opts []Option
+		want bool
+	}{
+		{"int", reflect.TypeOf(0), nil, true},
+		{"string", reflect.TypeOf(""), nil, true},
+		{"array", reflect.TypeOf([2]float64{}), nil, true},
+		{"struct", reflect.TypeOf(point{}), nil, true},
+		{"nested struct", reflect.TypeOf(rect{}), nil, true},
+		{"pointer", reflect.TypeOf(intPtr(1)), nil, false},
+		{"slice", reflect.TypeOf([]int{}), nil, false},
+		{"map", reflect.TypeOf(map[string]int{}), nil, false},
+		{"interface", reflect.TypeOf((*interface{})(nil)).Elem(), nil, false},
+		{"func", reflect.TypeOf(func() {}), nil, false},
+		{"struct with pointer", reflect.TypeOf(withPointer{}), nil, false},
+		{"struct with unexported field", reflect.TypeOf(withUnexported{}), nil, false},
+		{"nested struct with pointer", reflect.TypeOf(nested{}), nil, false},
+		{"time", reflect.TypeOf(time.Time{}), nil, false},
+		{"sync atomic", reflect.TypeOf(atomic.Int64{}), nil, false},
+		{"custom copier", reflect.TypeOf(point{}), []Option{WithAtomicCopy(reflect.TypeOf(point{}))}, false},
+		{"nested custom copier", reflect.TypeOf(rect{}), []Option{WithAtomicCopy(reflect.TypeOf(""))}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(tt.opts...)
+			assert.Equal(t, tt.want, c.isPlainType(tt.t))
+			assert.Equal(t, tt.want, c.plainTypes[tt.t])
+		})
+	}
+}
+
+func TestDeepCopy_plainTypes(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	type rect struct {
+		Min, Max point
+		Name     string
+	}
+	v := rect{Min: point{1, 2}, Max: point{3, 4}, Name: "r"}
+	got, err := DeepCopy(v)
+	require.NoError(t, err)
+	assert.Equal(t, v, got)
+	got, err = DeepCopy(v, WithTypeCopier(reflect.TypeOf(point{}), func(v reflect.Value) (reflect.Value, error) {
+		return reflect.ValueOf(point{}), nil
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, rect{Name: "r"}, got)
+}
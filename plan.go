@@ -0,0 +1,153 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"sync"
+)
+
+// structPlan is the compiled merge plan of a struct type: its exported fields, and how to merge
+// each of them. Plans only depend on the type and on the options, so they are compiled once per
+// coalescer and struct type, instead of parsing tags and looking up field mergers on every merge.
+type structPlan struct {
+	// err is the error encountered while resolving the field names of the type, if any.
+	err error
+	// fields are the plans of the fields of the type, in declaration order.
+	fields []fieldPlan
+	// allowlist holds the fields that can be merged, if a field allowlist is declared for the type.
+	allowlist map[string]bool
+	// shortcut is true when zero values of the type can be replaced by a copy of the other value,
+	// instead of being merged field by field.
+	shortcut bool
+}
+
+// fieldPlan is the compiled merge plan of a struct field; it is empty for unexported fields.
+type fieldPlan struct {
+	index int
+	field reflect.StructField
+	// merger is the merger declared for the field, or nil to use the default merger.
+	merger DeepMergeFunc
+	// err is the error encountered while compiling the merger declared for the field, if any.
+	err error
+	// ignored is the unknown strategy declared for the field, ignored because of WithLenientTags.
+	ignored *TagError
+	// excluded is true when the field is excluded with MergeStrategyExclude.
+	excluded bool
+	// hasDefault is true when a default value is declared for the field.
+	hasDefault bool
+	// source describes where the merger comes from, see WithTrace.
+	source string
+}
+
+// structPlan returns the merge plan of the given struct type, compiling it if necessary.
+func (c *coalescer) structPlan(structType reflect.Type) *structPlan {
+	if plan, found := c.structPlans[structType]; found {
+		return plan
+	}
+	plan := &structPlan{
+		err:       c.checkFieldNames(structType),
+		allowlist: c.fieldAllowlists[structType],
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fp := fieldPlan{index: i, field: field, excluded: excludedField(structType, i)}
+		if !field.IsExported() {
+			plan.fields = append(plan.fields, fp)
+			continue
+		}
+		fp.merger, fp.err = c.strictFieldMergerFromTag(structType, field)
+		if unknown, ok := unknownStrategy(fp.err); ok && c.lenientTags {
+			fp.ignored, fp.err = unknown, nil
+		}
+		if fp.merger != nil {
+			strategy, _ := fieldStrategy(structType, field)
+			fp.source = "merge strategy from tag: " + strategy
+		} else if fp.err == nil {
+			if customFieldMerger, found := c.fieldMergers[structType][field.Name]; found {
+				fp.merger = c.customFieldMerger(customFieldMerger)
+				fp.source = "field merger"
+			}
+		}
+		_, fp.hasDefault = c.fieldDefaults[structType][field.Name]
+		if _, found := defaultFromTag(structType, field); found {
+			fp.hasDefault = true
+		}
+		plan.fields = append(plan.fields, fp)
+	}
+	plan.shortcut = !c.hasFieldMergers(structType) && !c.hasFieldDefaults(structType) && plan.allowlist == nil
+	c.structPlans[structType] = plan
+	return plan
+}
+
+// plannedFieldMerger returns the merger to use for the given field: mergers registered for nested
+// field paths or path expressions take precedence over the merger declared for the field, see
+// fieldPathMerger and pathMerger.
+func (c *coalescer) plannedFieldMerger(fp *fieldPlan) (DeepMergeFunc, error) {
+	if pathMerger, found := c.fieldPathMerger(); found {
+		c.trace("using field path merger")
+		return c.customFieldMerger(pathMerger), nil
+	}
+	if pathMerger, found, err := c.pathMerger(); err != nil {
+		return nil, err
+	} else if found {
+		c.trace("using path merger")
+		return c.customFieldMerger(pathMerger), nil
+	}
+	if fp.err != nil {
+		return nil, fp.err
+	}
+	if fp.ignored != nil {
+		c.ignoreUnknownStrategy(fp.ignored)
+	}
+	if fp.merger == nil {
+		return c.deepMerge, nil
+	}
+	c.trace("using %s", fp.source)
+	return fp.merger, nil
+}
+
+type fieldIndexKey struct {
+	structType reflect.Type
+	name       string
+}
+
+// fieldIndices caches the results of reflect.Type.FieldByName, which is slow for types with many
+// or embedded fields, and is called for every element of slices merged by field, see
+// newMergeByField.
+var fieldIndices sync.Map // map[fieldIndexKey][]int
+
+// fieldByName is like reflect.Value.FieldByName, but caches the index of the field per type.
+func fieldByName(v reflect.Value, name string) reflect.Value {
+	key := fieldIndexKey{structType: v.Type(), name: name}
+	index, found := fieldIndices.Load(key)
+	if !found {
+		field, ok := v.Type().FieldByName(name)
+		if !ok {
+			index = []int(nil)
+		} else {
+			index = field.Index
+		}
+		fieldIndices.Store(key, index)
+	}
+	if index.([]int) == nil {
+		return reflect.Value{}
+	}
+	field, err := v.FieldByIndexErr(index.([]int))
+	if err != nil {
+		return reflect.Value{}
+	}
+	return field
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_coalescer_structPlan(t *testing.T) {
+	type foo struct {
+		Tagged  []int `goalesce:"append"`
+		Custom  int
+		Default int `goalesce:"default:1"`
+		Invalid int `goalesce:"append"`
+		Unknown int `goalesce:"unknown"`
+		unexp   int
+	}
+	type bar struct {
+		Plain int
+	}
+	customMerger := func(v1, v2 reflect.Value) (reflect.Value, error) { return v1, nil }
+	t.Run("compiled once", func(t *testing.T) {
+		c := newCoalescer(WithFieldMerger(reflect.TypeOf(foo{}), "Custom", customMerger))
+		plan := c.structPlan(reflect.TypeOf(foo{}))
+		assert.Same(t, plan, c.structPlan(reflect.TypeOf(foo{})))
+		require.Len(t, plan.fields, 6)
+		assert.NotNil(t, plan.fields[0].merger)
+		assert.NotNil(t, plan.fields[1].merger)
+		assert.True(t, plan.fields[2].hasDefault)
+		assert.EqualError(t, plan.fields[3].err, "field goalesce.foo.Invalid: append strategy is only supported for slices")
+		assert.EqualError(t, plan.fields[4].err, "field goalesce.foo.Unknown: unknown merge strategy: unknown")
+		assert.Nil(t, plan.fields[4].ignored)
+		assert.False(t, plan.fields[5].field.IsExported())
+		assert.False(t, plan.shortcut)
+		assert.True(t, c.structPlan(reflect.TypeOf(bar{})).shortcut)
+	})
+	t.Run("allowlist", func(t *testing.T) {
+		c := newCoalescer(WithFieldAllowlist(reflect.TypeOf(bar{}), "Plain"))
+		plan := c.structPlan(reflect.TypeOf(bar{}))
+		assert.Equal(t, map[string]bool{"Plain": true}, plan.allowlist)
+		assert.False(t, plan.shortcut)
+	})
+	t.Run("field name errors", func(t *testing.T) {
+		c := newCoalescer(WithFieldNameMatching(FieldNameMatchCaseInsensitive), WithAtomicFieldMerge(reflect.TypeOf(bar{}), "Missing"))
+		assert.EqualError(t, c.structPlan(reflect.TypeOf(bar{})).err, "struct type goalesce.bar has no field matching Missing")
+	})
+	t.Run("ignored strategies reported on every merge", func(t *testing.T) {
+		type baz struct {
+			Field int `goalesce:"unknown"`
+		}
+		var warnings []Warning
+		c := newCoalescer(WithLenientTags(nil), WithWarnings(&warnings))
+		assert.NotNil(t, c.structPlan(reflect.TypeOf(baz{})).fields[0].ignored)
+		for i := 0; i < 2; i++ {
+			got, err := c.deepMerge(reflect.ValueOf(baz{Field: 1}), reflect.ValueOf(baz{Field: 2}))
+			assert.NoError(t, err)
+			assert.Equal(t, baz{Field: 2}, got.Interface())
+			c.reset()
+		}
+		assert.Len(t, warnings, 2)
+	})
+}
+
+func Test_fieldByName(t *testing.T) {
+	type Inner struct {
+		ID int
+	}
+	type outer struct {
+		*Inner
+		Name string
+	}
+	v := reflect.ValueOf(outer{Inner: &Inner{ID: 1}, Name: "a"})
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, "a", fieldByName(v, "Name").Interface())
+		assert.Equal(t, 1, fieldByName(v, "ID").Interface())
+		assert.False(t, fieldByName(v, "Missing").IsValid())
+	}
+	assert.False(t, fieldByName(reflect.ValueOf(outer{}), "ID").IsValid())
+}
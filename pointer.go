@@ -14,29 +14,35 @@
 
 package goalesce
 
-import (
-	"fmt"
-	"reflect"
-)
+import "reflect"
 
 func (c *coalescer) deepMergePointer(v1, v2 reflect.Value) (reflect.Value, error) {
-	if value, done := checkZero(v1, v2); done {
+	if value, done := checkZero(v1, v2); done && !c.mustCheckPermissions(v2) {
+		c.traceZeroShortcut(v1)
 		return c.deepCopy(value)
 	}
+	return c.memoizeMerge(v1, v2, func() (reflect.Value, error) {
+		return c.deepMergeNonNilPointers(v1, v2)
+	})
+}
+
+func (c *coalescer) deepMergeNonNilPointers(v1, v2 reflect.Value) (reflect.Value, error) {
 	if c.checkCycle(v1) {
 		if c.errorOnCycle {
-			return reflect.Value{}, fmt.Errorf("%s: cycle detected", v1.Type().String())
+			return reflect.Value{}, &CycleError{Type: v1.Type()}
 		}
 		return c.deepCopy(v2)
 	}
 	if c.checkCycle(v2) {
 		if c.errorOnCycle {
-			return reflect.Value{}, fmt.Errorf("%s: cycle detected", v2.Type().String())
+			return reflect.Value{}, &CycleError{Type: v2.Type()}
 		}
 		c.unsee(v1) // because checkCycle(v1) was called
 		return c.deepCopy(v1)
 	}
-	mergedTarget, err := c.deepMerge(v1.Elem(), v2.Elem())
+	defer c.unsee(v1)
+	defer c.unsee(v2)
+	mergedTarget, err := c.deepMerge(safeIndirect(v1), v2.Elem())
 	if err != nil {
 		return reflect.Value{}, err
 	}
@@ -49,12 +55,19 @@ func (c *coalescer) deepCopyPointer(v reflect.Value) (reflect.Value, error) {
 	if v.IsZero() {
 		return reflect.Zero(v.Type()), nil
 	}
+	return c.memoizeCopy(v, func() (reflect.Value, error) {
+		return c.deepCopyNonNilPointer(v)
+	})
+}
+
+func (c *coalescer) deepCopyNonNilPointer(v reflect.Value) (reflect.Value, error) {
 	if c.checkCycle(v) {
 		if c.errorOnCycle {
-			return reflect.Value{}, fmt.Errorf("%s: cycle detected", v.Type().String())
+			return reflect.Value{}, &CycleError{Type: v.Type()}
 		}
 		return reflect.Zero(v.Type()), nil
 	}
+	defer c.unsee(v)
 	copiedTarget, err := c.deepCopy(v.Elem())
 	if err != nil {
 		return reflect.Value{}, err
@@ -64,20 +77,48 @@ func (c *coalescer) deepCopyPointer(v reflect.Value) (reflect.Value, error) {
 	return copied, nil
 }
 
+// cycleKey identifies a pointer value being visited. The type is required because distinct values
+// can share the same address, e.g. a struct and its first field.
+type cycleKey struct {
+	addr uintptr
+	t    reflect.Type
+}
+
+// checkCycle returns true if the given pointer or interface value is already being visited, that
+// is, if it is one of its own ancestors. Otherwise, it marks the value as being visited; callers
+// must call unsee when they are done visiting the value, so that values reachable through different
+// paths are not mistaken for cycles.
 func (c *coalescer) checkCycle(v reflect.Value) bool {
-	if v.CanAddr() {
-		addr := v.UnsafeAddr()
-		if _, found := c.seen[addr]; found {
+	if key, ok := cycleKeyOf(v); ok {
+		if c.seen[key] {
+			c.stats.CyclesDetected++
 			return true
 		}
-		c.seen[addr] = true
+		c.seen[key] = true
 	}
 	return false
 }
 
 func (c *coalescer) unsee(v reflect.Value) {
-	if v.CanAddr() {
-		addr := v.UnsafeAddr()
-		delete(c.seen, addr)
+	if key, ok := cycleKeyOf(v); ok {
+		delete(c.seen, key)
+	}
+}
+
+// sameCycleKey returns true if the two values are tracked as the same value by checkCycle, e.g.
+// two interfaces holding the same pointer. Merging such values together is not a cycle.
+func sameCycleKey(v1, v2 reflect.Value) bool {
+	k1, ok1 := cycleKeyOf(v1)
+	k2, ok2 := cycleKeyOf(v2)
+	return ok1 && ok2 && k1 == k2
+}
+
+func cycleKeyOf(v reflect.Value) (cycleKey, bool) {
+	if v.Kind() == reflect.Interface && !v.IsNil() && v.Elem().Kind() == reflect.Ptr && !v.Elem().IsNil() {
+		// pointers unwrapped from interfaces are not addressable: track the value they point to
+		return cycleKey{v.Elem().Pointer(), v.Elem().Type().Elem()}, true
+	} else if v.CanAddr() {
+		return cycleKey{v.UnsafeAddr(), v.Type()}, true
 	}
+	return cycleKey{}, false
 }
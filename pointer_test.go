@@ -133,7 +133,7 @@ func Test_coalescer_deepMergePointer(t *testing.T) {
 			v1:   simpleCycle(),
 			v2:   &cycle{Cycle: &cycle{Cycle: &cycle{Cycle: &cycle{Cycle: &cycle{}}}}},
 			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
-				return assert.EqualError(t, err, "*goalesce.cycle: cycle detected")
+				return assert.EqualError(t, err, "at Cycle.Cycle: *goalesce.cycle: cycle detected")
 			},
 			opts: []Option{WithErrorOnCycle()},
 		},
@@ -142,10 +142,16 @@ func Test_coalescer_deepMergePointer(t *testing.T) {
 			v1:   &cycle{Cycle: &cycle{Cycle: &cycle{Cycle: &cycle{Cycle: &cycle{}}}}},
 			v2:   simpleCycle(),
 			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
-				return assert.EqualError(t, err, "*goalesce.cycle: cycle detected")
+				return assert.EqualError(t, err, "at Cycle.Cycle: *goalesce.cycle: cycle detected")
 			},
 			opts: []Option{WithErrorOnCycle()},
 		},
+		{
+			name: "shared pointers",
+			v1:   sharedPointers("a"),
+			v2:   sharedPointers("b"),
+			want: sharedPointers("b"),
+		},
 		{
 			name:    "generic error",
 			v1:      intPtr(1),
@@ -248,6 +254,11 @@ func Test_coalescer_deepCopyPointer(t *testing.T) {
 			},
 			opts: []Option{WithErrorOnCycle()},
 		},
+		{
+			name: "shared pointers",
+			v:    sharedPointers("a"),
+			want: sharedPointers("a"),
+		},
 		{
 			name:    "generic error",
 			v:       intPtr(1),
@@ -274,6 +285,36 @@ func Test_coalescer_deepCopyPointer(t *testing.T) {
 	}
 }
 
+func Test_coalescer_checkCycle(t *testing.T) {
+	type wrapper struct {
+		Ptr *int
+	}
+	c := newCoalescer()
+	w := reflect.ValueOf(&wrapper{}).Elem()
+	// the struct and its first field share the same address
+	assert.False(t, c.checkCycle(w))
+	assert.False(t, c.checkCycle(w.Field(0)))
+	assert.True(t, c.checkCycle(w.Field(0)))
+	c.unsee(w.Field(0))
+	assert.False(t, c.checkCycle(w.Field(0)))
+}
+
+type dagNode struct {
+	Name string
+	Next *dagNode
+}
+
+type dag struct {
+	Left, Right *dagNode
+}
+
+// sharedPointers returns a directed acyclic graph where the same node is reachable through two
+// different paths.
+func sharedPointers(name string) *dag {
+	shared := &dagNode{Name: name, Next: &dagNode{Name: name}}
+	return &dag{Left: shared, Right: shared}
+}
+
 func intPtr(i int) *int {
 	return &i
 }
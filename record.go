@@ -0,0 +1,248 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MergeRecord is a recorded merge session, see WithRecorder. Values are encoded in JSON.
+type MergeRecord struct {
+	// Type is the name of the type of the merged values.
+	Type string `json:"type"`
+	// Fingerprint is the fingerprint of the options the merge was performed with.
+	Fingerprint string `json:"fingerprint"`
+	// V1 is the first value.
+	V1 json.RawMessage `json:"v1"`
+	// V2 is the second value.
+	V2 json.RawMessage `json:"v2"`
+	// Result is the merged value, if the merge succeeded.
+	Result json.RawMessage `json:"result,omitempty"`
+	// Error is the error message, if the merge failed.
+	Error string `json:"error,omitempty"`
+}
+
+// RedactFunc is a function that redacts sensitive data from a value before it is recorded by
+// WithRecorder. It must not modify the value passed to it, but return a redacted copy instead,
+// e.g. obtained with DeepCopy.
+type RedactFunc func(value interface{}) (interface{}, error)
+
+type recorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	redact RedactFunc
+}
+
+func (r *recorder) record(typeName, fingerprint string, v1, v2, result reflect.Value, mergeErr error) error {
+	rec := MergeRecord{Type: typeName, Fingerprint: fingerprint}
+	var err error
+	if rec.V1, err = r.encode(v1); err != nil {
+		return err
+	}
+	if rec.V2, err = r.encode(v2); err != nil {
+		return err
+	}
+	if mergeErr != nil {
+		rec.Error = mergeErr.Error()
+	} else if rec.Result, err = r.encode(result); err != nil {
+		return err
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err = r.w.Write(append(line, '\n'))
+	return err
+}
+
+func (r *recorder) encode(v reflect.Value) (json.RawMessage, error) {
+	var value interface{}
+	if v.IsValid() {
+		value = v.Interface()
+	}
+	if r.redact != nil {
+		var err error
+		if value, err = r.redact(value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(value)
+}
+
+// ReadMergeRecords reads all the merge records from the given reader, as written by WithRecorder.
+func ReadMergeRecords(r io.Reader) ([]*MergeRecord, error) {
+	var records []*MergeRecord
+	decoder := json.NewDecoder(r)
+	for {
+		var rec MergeRecord
+		if err := decoder.Decode(&rec); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return nil, err
+		}
+		records = append(records, &rec)
+	}
+}
+
+// ReplayMerge re-executes the given recorded merge with the given options, and returns the merged
+// value, that can then be compared with the recorded result. The options must be equivalent to the
+// options the merge was recorded with, i.e. they must have the same fingerprint; otherwise, an
+// error is returned. Note that the fingerprint only captures which options were used, and for which
+// types and fields, but not the behavior of custom functions.
+//
+// Values are decoded from JSON: therefore, values held in interfaces are decoded as generic JSON
+// values, and unexported fields are left zero.
+func ReplayMerge[T any](rec *MergeRecord, opts ...Option) (T, error) {
+	coalescer := newCoalescer(opts...)
+	if fingerprint := coalescer.fingerprint(); fingerprint != rec.Fingerprint {
+		return zero[T](), fmt.Errorf("options fingerprint mismatch: recorded %s, got %s", rec.Fingerprint, fingerprint)
+	}
+	var v1, v2 T
+	if err := json.Unmarshal(rec.V1, &v1); err != nil {
+		return zero[T](), fmt.Errorf("cannot decode first value: %w", err)
+	}
+	if err := json.Unmarshal(rec.V2, &v2); err != nil {
+		return zero[T](), fmt.Errorf("cannot decode second value: %w", err)
+	}
+	coalescer.recorder = nil // don't record replays
+	return deepMerge(coalescer, v1, v2)
+}
+
+// fingerprint returns a fingerprint of the options the coalescer was created with. It is computed
+// from the types and fields custom behaviors are registered for, and from the flags that are set.
+func (c *coalescer) fingerprint() string {
+	var entries []string
+	for t := range c.typeCopiers {
+		entries = append(entries, "typeCopier:"+t.String())
+	}
+	for t := range c.typeMergers {
+		entries = append(entries, "typeMerger:"+t.String())
+	}
+	for origin := range c.genericTypeCopiers {
+		entries = append(entries, "genericTypeCopier:"+origin)
+	}
+	for origin := range c.genericTypeMergers {
+		entries = append(entries, "genericTypeMerger:"+origin)
+	}
+	for kind := range c.kindMergers {
+		entries = append(entries, "kindMerger:"+kind.String())
+	}
+	for _, ic := range c.interfaceCopiers {
+		entries = append(entries, "interfaceCopier:"+ic.ifaceType.String())
+	}
+	for _, im := range c.interfaceMergers {
+		entries = append(entries, "interfaceMerger:"+im.ifaceType.String())
+	}
+	for t := range c.sliceMergers {
+		entries = append(entries, "sliceMerger:"+t.String())
+	}
+	if c.listAppendDedup {
+		entries = append(entries, "listAppendDedup")
+	}
+	for t := range c.listAppendDedupKeys {
+		entries = append(entries, "listAppendDedupKey:"+t.String())
+	}
+	for t := range c.sliceSorts {
+		entries = append(entries, "sliceSort:"+t.String())
+	}
+	for t := range c.sliceDifferences {
+		entries = append(entries, "sliceDifference:"+t.String())
+	}
+	for t := range c.sliceDeleteMarkers {
+		entries = append(entries, "sliceDeleteMarker:"+t.String())
+	}
+	for t := range c.sliceElemMergers {
+		entries = append(entries, "sliceElemMerger:"+t.String())
+	}
+	for t := range c.sliceKeyOrders {
+		entries = append(entries, "sliceKeyOrder:"+t.String())
+	}
+	for t := range c.arrayMergers {
+		entries = append(entries, "arrayMerger:"+t.String())
+	}
+	for t := range c.mapAtomicValues {
+		entries = append(entries, "mapAtomicValues:"+t.String())
+	}
+	for t, keys := range c.mapKeyMergers {
+		for k := range keys {
+			entries = append(entries, fmt.Sprintf("mapKeyMerger:%s[%v]", t.String(), k))
+		}
+	}
+	for t, fields := range c.fieldMergers {
+		for field := range fields {
+			entries = append(entries, "fieldMerger:"+t.String()+"."+field)
+		}
+	}
+	for t, fields := range c.fieldDefaults {
+		for field := range fields {
+			entries = append(entries, "fieldDefault:"+t.String()+"."+field)
+		}
+	}
+	for t, paths := range c.fieldPathMergers {
+		for path := range paths {
+			entries = append(entries, "fieldPathMerger:"+t.String()+"."+path)
+		}
+	}
+	for _, pm := range c.pathMergers {
+		entries = append(entries, "pathMerger:"+pm.expr)
+	}
+	if c.errorAccumulation {
+		entries = append(entries, "errorAccumulation")
+	}
+	if c.dryRun != nil {
+		entries = append(entries, "dryRun")
+	}
+	if c.maxElements > 0 {
+		entries = append(entries, fmt.Sprintf("maxElements:%d", c.maxElements))
+	}
+	if c.mapNullDeletion {
+		entries = append(entries, "mapNullDeletion")
+	}
+	for t := range c.mapNullDeletions {
+		entries = append(entries, "mapNullDeletions:"+t.String())
+	}
+	if c.firstValuePriority {
+		entries = append(entries, "firstValuePriority")
+	}
+	if c.memoizer != nil {
+		entries = append(entries, "memoization")
+	}
+	if c.deepCopyMethods {
+		entries = append(entries, "deepCopyMethods")
+	}
+	if c.unexportedFieldPolicy != UnexportedFieldsSkip {
+		entries = append(entries, fmt.Sprintf("unexportedFieldPolicy:%d", c.unexportedFieldPolicy))
+	}
+	for t, fields := range c.fieldAllowlists {
+		for field := range fields {
+			entries = append(entries, "fieldAllowlist:"+t.String()+"."+field)
+		}
+	}
+	sort.Strings(entries)
+	entries = append(entries, fmt.Sprintf("sliceMerger=%t sliceKeyOrder=%t strictIndexMerge=%t mapNoNewKeys=%t mapAddOnly=%t fieldNameMatching=%d arrayMerger=%t zeroEmptySlice=%t identityShortCircuit=%t subtreeHashing=%t errorOnCycle=%t lenientTags=%t patchDirectives=%t validator=%t fieldPermission=%t errorOnFieldPermission=%t",
+		c.sliceMerger != nil, c.defaultSliceKeyOrder != nil, c.strictIndexMerge, c.mapNoNewKeys != nil, c.mapAddOnly, c.fieldNameMatching, c.arrayMerger != nil, c.zeroEmptySlice, c.identityShortCircuit, c.subtreeHasher != nil, c.errorOnCycle, c.lenientTags, c.patchDirectives, c.validator != nil, c.fieldPermission != nil, c.errorOnFieldPermission))
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:8])
+}
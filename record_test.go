@@ -0,0 +1,111 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedUser struct {
+	Name     string
+	Password string
+	Tags     []string
+}
+
+func TestReplayMerge(t *testing.T) {
+	var buf bytes.Buffer
+	redact := func(value interface{}) (interface{}, error) {
+		if user, ok := value.(*recordedUser); ok && user != nil {
+			redacted := *user
+			redacted.Password = "***"
+			return &redacted, nil
+		}
+		return value, nil
+	}
+	opts := []Option{WithRecorder(&buf, redact), WithSliceListAppendMerge(reflect.TypeOf([]string{}))}
+	v1 := &recordedUser{Name: "Alice", Password: "secret", Tags: []string{"a"}}
+	v2 := &recordedUser{Password: "secret2", Tags: []string{"b"}}
+	merged, err := DeepMerge(v1, v2, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", v1.Password, "inputs must not be modified")
+	_, err = DeepMerge(v1, v2, append(opts, withMockDeepMergeError)...)
+	require.Error(t, err)
+
+	records, err := ReadMergeRecords(&buf)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	rec := records[0]
+	assert.Equal(t, "*goalesce.recordedUser", rec.Type)
+	assert.JSONEq(t, `{"Name":"Alice","Password":"***","Tags":["a"]}`, string(rec.V1))
+	assert.JSONEq(t, `{"Name":"","Password":"***","Tags":["b"]}`, string(rec.V2))
+	assert.JSONEq(t, `{"Name":"Alice","Password":"***","Tags":["a","b"]}`, string(rec.Result))
+	assert.Empty(t, rec.Error)
+	assert.Equal(t, "secret2", merged.Password)
+
+	replayed, err := ReplayMerge[*recordedUser](rec, opts...)
+	require.NoError(t, err)
+	assert.Equal(t, &recordedUser{Name: "Alice", Password: "***", Tags: []string{"a", "b"}}, replayed)
+	assert.Zero(t, buf.Len(), "replays must not be recorded")
+
+	_, err = ReplayMerge[*recordedUser](rec)
+	assert.ErrorContains(t, err, "options fingerprint mismatch")
+
+	rec = records[1]
+	assert.Equal(t, "mock DeepMerge error", rec.Error)
+	assert.Empty(t, rec.Result)
+
+	_, err = ReadMergeRecords(bytes.NewBufferString("{"))
+	assert.Error(t, err)
+}
+
+func Test_recorder_errors(t *testing.T) {
+	t.Run("redact error", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := DeepMerge("a", "b", WithRecorder(&buf, func(interface{}) (interface{}, error) {
+			return nil, errors.New("cannot redact")
+		}))
+		assert.EqualError(t, err, "cannot record merge: cannot redact")
+	})
+	t.Run("encoding error", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := DeepMerge(map[string]interface{}{"a": func() {}}, nil, WithRecorder(&buf, nil))
+		assert.ErrorContains(t, err, "cannot record merge: json: unsupported type: func()")
+	})
+	t.Run("decoding error", func(t *testing.T) {
+		rec := &MergeRecord{Fingerprint: newCoalescer().fingerprint(), V1: json.RawMessage(`"a"`), V2: json.RawMessage(`"b"`)}
+		_, err := ReplayMerge[int](rec)
+		assert.ErrorContains(t, err, "cannot decode first value")
+	})
+}
+
+func Test_coalescer_fingerprint(t *testing.T) {
+	userType := reflect.TypeOf(recordedUser{})
+	assert.Equal(t, newCoalescer().fingerprint(), newCoalescer(WithRecorder(io.Discard, nil)).fingerprint())
+	assert.Equal(t,
+		newCoalescer(WithFieldListAppendMerge(userType, "Tags"), WithErrorOnCycle()).fingerprint(),
+		newCoalescer(WithErrorOnCycle(), WithFieldListAppendMerge(userType, "Tags")).fingerprint(),
+	)
+	assert.NotEqual(t, newCoalescer().fingerprint(), newCoalescer(WithErrorOnCycle()).fingerprint())
+	assert.NotEqual(t, newCoalescer().fingerprint(), newCoalescer(WithAtomicMerge(userType)).fingerprint())
+	assert.NotEqual(t, newCoalescer().fingerprint(), newCoalescer(WithFieldDefault(userType, "Name", "x")).fingerprint())
+}
@@ -0,0 +1,197 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// CopyInto deep-copies src into dst, recycling the object graph already referenced by dst whenever
+// possible, instead of allocating new objects: pointer targets are overwritten in place, slices are
+// resliced if their capacity is sufficient, and maps are cleared and refilled. This is useful for
+// high-frequency copy workloads, typically in combination with a CopyPool.
+//
+// After the call, dst is deeply equal to what DeepCopy(src) would have returned, and shares no
+// references with src. However, any value previously referenced by dst may have been modified;
+// therefore, dst's object graph must not be shared with other live objects.
+//
+// Types with a custom copier registered through WithTypeCopier or WithAtomicCopy are copied with
+// that copier, without recycling.
+func CopyInto[T any](dst *T, src T, opts ...Option) error {
+	if dst == nil {
+		return fmt.Errorf("cannot copy into nil %T", dst)
+	}
+	coalescer := newCoalescer(opts...)
+	return coalescer.copyInto(reflect.ValueOf(dst).Elem(), reflect.ValueOf(&src).Elem())
+}
+
+// copyInto copies src into dst, which must be settable and of the same type as src.
+func (c *coalescer) copyInto(dst, src reflect.Value) error {
+	if _, found := c.typeCopier(src.Type()); found || src.IsZero() || isSyncAtomicType(src.Type()) || src.Type() == syncMapType ||
+		c.unexportedFieldPolicy != UnexportedFieldsSkip && src.Kind() == reflect.Struct && hasUnexportedFields(src.Type()) {
+		copied, err := c.deepCopy(src)
+		if err != nil {
+			return err
+		}
+		dst.Set(copied)
+		return nil
+	}
+	switch src.Kind() {
+	case reflect.Ptr:
+		if c.checkCycle(src) {
+			if c.errorOnCycle {
+				return &CycleError{Type: src.Type()}
+			}
+			dst.Set(reflect.Zero(src.Type()))
+			return nil
+		}
+		defer c.unsee(src)
+		if dst.IsNil() {
+			dst.Set(reflect.New(src.Type().Elem()))
+		}
+		return c.copyInto(dst.Elem(), src.Elem())
+	case reflect.Struct:
+		if hasUnexportedFields(src.Type()) {
+			// unexported fields cannot be set individually: zero the whole struct, but keep the
+			// exported fields' previous values around for recycling
+			previous := reflect.New(src.Type()).Elem()
+			previous.Set(dst)
+			dst.Set(reflect.Zero(src.Type()))
+			for i := 0; i < src.NumField(); i++ {
+				if src.Type().Field(i).IsExported() {
+					dst.Field(i).Set(previous.Field(i))
+				}
+			}
+		}
+		for i := 0; i < src.NumField(); i++ {
+			if excludedField(src.Type(), i) {
+				if src.Type().Field(i).IsExported() {
+					dst.Field(i).Set(reflect.Zero(src.Type().Field(i).Type))
+				}
+			} else if !src.Type().Field(i).IsExported() {
+				continue
+			} else if fieldCopyStrategy(src.Type(), i) != "" {
+				copied, err := c.copyField(src, i)
+				if err != nil {
+					return err
+				} else if !copied.IsValid() {
+					copied = reflect.Zero(src.Type().Field(i).Type)
+				}
+				dst.Field(i).Set(copied)
+			} else if err := c.copyInto(dst.Field(i), src.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice:
+		if err := c.visitElements(src.Len()); err != nil {
+			return err
+		}
+		if dst.IsNil() || dst.Cap() < src.Len() {
+			dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		} else {
+			// zero out the elements beyond the new length, to release references
+			for i := src.Len(); i < dst.Len(); i++ {
+				dst.Index(i).Set(reflect.Zero(src.Type().Elem()))
+			}
+			dst.SetLen(src.Len())
+		}
+		if c.isPlainType(src.Type().Elem()) {
+			reflect.Copy(dst, src)
+			return nil
+		}
+		fallthrough
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			if err := c.copyInto(dst.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if err := c.visitElements(src.Len()); err != nil {
+			return err
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		} else {
+			dst.Clear()
+		}
+		for _, k := range sortedMapKeys(src) {
+			copiedKey, err := c.deepCopy(k)
+			if err != nil {
+				return err
+			}
+			copiedValue, err := c.deepCopy(src.MapIndex(k))
+			if err != nil {
+				return err
+			}
+			dst.SetMapIndex(copiedKey, copiedValue)
+		}
+		return nil
+	}
+	copied, err := c.deepCopy(src)
+	if err != nil {
+		return err
+	}
+	dst.Set(copied)
+	return nil
+}
+
+func hasUnexportedFields(structType reflect.Type) bool {
+	for i := 0; i < structType.NumField(); i++ {
+		if !structType.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+// CopyPool is a pool of recycled objects of type T, to be used as destinations of deep copies.
+// Objects obtained with Copy must be returned to the pool with Release when they are not used
+// anymore; their object graph will then be recycled by subsequent copies. A CopyPool is safe for
+// concurrent use.
+type CopyPool[T any] struct {
+	pool sync.Pool
+	opts []Option
+}
+
+// NewCopyPool creates a new CopyPool. The given options are used for every copy.
+func NewCopyPool[T any](opts ...Option) *CopyPool[T] {
+	return &CopyPool[T]{
+		pool: sync.Pool{New: func() interface{} { return new(T) }},
+		opts: opts,
+	}
+}
+
+// Copy deep-copies src into a recycled object obtained from the pool, and returns it. See
+// CopyInto.
+func (p *CopyPool[T]) Copy(src T) (*T, error) {
+	dst := p.pool.Get().(*T)
+	if err := CopyInto(dst, src, p.opts...); err != nil {
+		p.Release(dst)
+		return nil, err
+	}
+	return dst, nil
+}
+
+// Release returns the given object to the pool. The object must not be used after this call.
+func (p *CopyPool[T]) Release(dst *T) {
+	if dst != nil {
+		p.pool.Put(dst)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyInto(t *testing.T) {
+	type inner struct {
+		Name string
+	}
+	type outer struct {
+		ID       int
+		Inner    *inner
+		Tags     []string
+		Labels   map[string]string
+		Array    [2]*inner
+		Any      interface{}
+		internal int
+	}
+	t.Run("nil destination", func(t *testing.T) {
+		err := CopyInto[outer](nil, outer{})
+		assert.EqualError(t, err, "cannot copy into nil *goalesce.outer")
+	})
+	t.Run("zero destination", func(t *testing.T) {
+		src := outer{ID: 1, Inner: &inner{Name: "foo"}, Tags: []string{"a"}, Labels: map[string]string{"k": "v"}, Any: "x"}
+		var dst outer
+		err := CopyInto(&dst, src)
+		require.NoError(t, err)
+		assert.Equal(t, src, dst)
+		assertNotSame(t, src, dst)
+	})
+	t.Run("recycled destination", func(t *testing.T) {
+		previousInner := &inner{Name: "old"}
+		previousTags := make([]string, 3, 10)
+		previousLabels := map[string]string{"old": "old"}
+		dst := outer{ID: 2, Inner: previousInner, Tags: previousTags, Labels: previousLabels, internal: 42}
+		src := outer{ID: 1, Inner: &inner{Name: "foo"}, Tags: []string{"a", "b"}, Labels: map[string]string{"k": "v"}}
+		err := CopyInto(&dst, src)
+		require.NoError(t, err)
+		assert.Equal(t, src, dst)
+		assert.Same(t, previousInner, dst.Inner)
+		assert.Equal(t, reflect.ValueOf(previousTags).Pointer(), reflect.ValueOf(dst.Tags).Pointer())
+		assert.Equal(t, "", previousTags[:3][2])
+		assert.Equal(t, reflect.ValueOf(previousLabels).Pointer(), reflect.ValueOf(dst.Labels).Pointer())
+		assert.NotSame(t, src.Inner, dst.Inner)
+	})
+	t.Run("slice too small", func(t *testing.T) {
+		dst := outer{Tags: make([]string, 1)}
+		err := CopyInto(&dst, outer{Tags: []string{"a", "b"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, dst.Tags)
+	})
+	t.Run("zero source", func(t *testing.T) {
+		dst := outer{ID: 1, Inner: &inner{Name: "foo"}, Tags: []string{"a"}}
+		err := CopyInto(&dst, outer{})
+		require.NoError(t, err)
+		assert.Equal(t, outer{}, dst)
+	})
+	t.Run("type copier", func(t *testing.T) {
+		dst := outer{Inner: &inner{Name: "old"}}
+		src := outer{Inner: &inner{Name: "foo"}}
+		err := CopyInto(&dst, src, WithAtomicCopy(reflect.TypeOf(&inner{})))
+		require.NoError(t, err)
+		assert.Same(t, src.Inner, dst.Inner)
+	})
+	t.Run("copier error", func(t *testing.T) {
+		var dst outer
+		err := CopyInto(&dst, outer{Inner: &inner{}}, WithTypeCopier(reflect.TypeOf(&inner{}), func(v reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, errors.New("fake")
+		}))
+		assert.EqualError(t, err, "fake")
+	})
+	t.Run("cycle", func(t *testing.T) {
+		type node struct {
+			Next *node
+		}
+		src := &node{}
+		src.Next = src
+		var dst *node
+		err := CopyInto(&dst, src)
+		require.NoError(t, err)
+		assert.Nil(t, dst.Next.Next)
+		err = CopyInto(&dst, src, WithErrorOnCycle())
+		assert.EqualError(t, err, "*goalesce.node: cycle detected")
+	})
+}
+
+func TestCopyPool(t *testing.T) {
+	type foo struct {
+		Tags []string
+	}
+	pool := NewCopyPool[foo]()
+	copied, err := pool.Copy(foo{Tags: []string{"a", "b"}})
+	require.NoError(t, err)
+	assert.Equal(t, &foo{Tags: []string{"a", "b"}}, copied)
+	pool.Release(copied)
+	pool.Release(nil)
+	copied, err = pool.Copy(foo{Tags: []string{"c"}})
+	require.NoError(t, err)
+	assert.Equal(t, &foo{Tags: []string{"c"}}, copied)
+	t.Run("error", func(t *testing.T) {
+		pool := NewCopyPool[foo](WithTypeCopier(reflect.TypeOf([]string{}), func(v reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, errors.New("fake")
+		}))
+		copied, err := pool.Copy(foo{Tags: []string{"a"}})
+		assert.Nil(t, copied)
+		assert.EqualError(t, err, "fake")
+	})
+}
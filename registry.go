@@ -0,0 +1,85 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var registeredStrategies sync.Map // map[string]DeepMergeFuncProvider
+
+// builtinStrategies are the names of the built-in merge strategies, which cannot be registered.
+var builtinStrategies = map[string]bool{
+	MergeStrategyAtomic:     true,
+	MergeStrategyAppend:     true,
+	MergeStrategyUnion:      true,
+	MergeStrategyIndex:      true,
+	MergeStrategyID:         true,
+	MergeStrategyDifference: true,
+	MergeStrategyLatest:     true,
+	MergeStrategyEarliest:   true,
+	MergeStrategySemverMax:  true,
+	MergeStrategyBitwiseOr:  true,
+	MergeStrategySum:        true,
+	MergeStrategyMin:        true,
+	MergeStrategyMax:        true,
+	MergeStrategyOneOf:      true,
+	MergeStrategyDefault:    true,
+	MergeStrategyImmutable:  true,
+	MergeStrategyKeepFirst:  true,
+	MergeStrategyKeep:       true,
+	MergeStrategyMustMatch:  true,
+	MergeStrategyKey:        true,
+	MergeStrategyExclude:    true,
+}
+
+// RegisterMergeStrategy registers a custom merge strategy under the given name, so that it can be
+// referenced from MergeStrategyTag struct tags, e.g. `goalesce:"envlist"`, or from
+// StrategiesDeclarer implementations. The provider is invoked with the main DeepMergeFunc and
+// DeepCopyFunc instances for each field declaring the strategy, and returns the merger of the field;
+// as with other custom mergers, the merger can return an invalid value to fall back to the default
+// merge.
+//
+// Registering a strategy under a name already registered replaces it. This function is safe for
+// concurrent use, but is typically called from an init function.
+//
+// This function panics if the name is empty, contains a colon, a comma or an equal sign, which
+// would clash with ElemStrategyPrefix, or is the name of a built-in strategy.
+func RegisterMergeStrategy(name string, provider DeepMergeFuncProvider) {
+	if name == "" || strings.ContainsAny(name, ":,=") {
+		panic(fmt.Sprintf("goalesce: invalid merge strategy name: %q", name))
+	}
+	if builtinStrategies[name] {
+		panic(fmt.Sprintf("goalesce: cannot register built-in merge strategy: %s", name))
+	}
+	registeredStrategies.Store(name, provider)
+}
+
+// UnregisterMergeStrategy removes the merge strategy registered under the given name, if any.
+func UnregisterMergeStrategy(name string) {
+	registeredStrategies.Delete(name)
+}
+
+// registeredStrategyMerger returns the merger of the custom strategy registered under the given
+// name, if any.
+func (c *coalescer) registeredStrategyMerger(name string) (DeepMergeFunc, bool) {
+	provider, found := registeredStrategies.Load(name)
+	if !found {
+		return nil, false
+	}
+	return c.customFieldMerger(provider.(DeepMergeFuncProvider)(c.deepMerge, c.deepCopy)), true
+}
@@ -0,0 +1,87 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterMergeStrategy(t *testing.T) {
+	// envlist merges comma-separated lists of environment variable names
+	RegisterMergeStrategy("envlist", func(_ DeepMergeFunc, _ DeepCopyFunc) DeepMergeFunc {
+		return func(v1, v2 reflect.Value) (reflect.Value, error) {
+			if v1.String() == "" || v2.String() == "" {
+				return reflect.Value{}, nil
+			}
+			return reflect.ValueOf(v1.String() + "," + v2.String()), nil
+		}
+	})
+	defer UnregisterMergeStrategy("envlist")
+	type config struct {
+		Env  string `goalesce:"envlist"`
+		Name string
+	}
+	t.Run("merge", func(t *testing.T) {
+		got, err := DeepMerge(config{Env: "A,B", Name: "a"}, config{Env: "C"})
+		require.NoError(t, err)
+		assert.Equal(t, config{Env: "A,B,C", Name: "a"}, got)
+	})
+	t.Run("fallback to default merge", func(t *testing.T) {
+		got, err := DeepMerge(config{Env: "A"}, config{Name: "b"})
+		require.NoError(t, err)
+		assert.Equal(t, config{Env: "A", Name: "b"}, got)
+	})
+	t.Run("describe", func(t *testing.T) {
+		plan, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(config{})})
+		require.NoError(t, err)
+		assert.Equal(t, "envlist", plan[0].Strategy)
+	})
+	t.Run("global functions", func(t *testing.T) {
+		RegisterMergeStrategy("upper", func(globalMerger DeepMergeFunc, _ DeepCopyFunc) DeepMergeFunc {
+			return func(v1, v2 reflect.Value) (reflect.Value, error) {
+				merged, err := globalMerger(v1, v2)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				return reflect.ValueOf(strings.ToUpper(merged.String())), nil
+			}
+		})
+		defer UnregisterMergeStrategy("upper")
+		type upper struct {
+			Name string `goalesce:"upper"`
+		}
+		got, err := DeepMerge(upper{"a"}, upper{"b"})
+		require.NoError(t, err)
+		assert.Equal(t, upper{"B"}, got)
+	})
+	t.Run("unregistered", func(t *testing.T) {
+		UnregisterMergeStrategy("envlist")
+		_, err := DeepMerge(config{Env: "A"}, config{Env: "B"})
+		assert.ErrorIs(t, err, ErrUnknownStrategy)
+	})
+	t.Run("invalid names", func(t *testing.T) {
+		provider := func(DeepMergeFunc, DeepCopyFunc) DeepMergeFunc { return nil }
+		assert.PanicsWithValue(t, `goalesce: invalid merge strategy name: ""`, func() { RegisterMergeStrategy("", provider) })
+		assert.PanicsWithValue(t, `goalesce: invalid merge strategy name: "a:b"`, func() { RegisterMergeStrategy("a:b", provider) })
+		assert.PanicsWithValue(t, `goalesce: invalid merge strategy name: "a,b"`, func() { RegisterMergeStrategy("a,b", provider) })
+		assert.PanicsWithValue(t, `goalesce: invalid merge strategy name: "elem=x"`, func() { RegisterMergeStrategy("elem=x", provider) })
+		assert.PanicsWithValue(t, "goalesce: cannot register built-in merge strategy: union", func() { RegisterMergeStrategy(MergeStrategyUnion, provider) })
+	})
+}
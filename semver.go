@@ -0,0 +1,164 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// semver is a parsed semantic version, see https://semver.org.
+type semver struct {
+	major, minor, patch uint64
+	prerelease          []string
+}
+
+// parseSemver parses the given semantic version. A leading "v" is accepted; build metadata is
+// ignored, as it does not participate in version precedence.
+func parseSemver(s string) (semver, bool) {
+	var v semver
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(s, '+'); i != -1 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i != -1 {
+		v.prerelease = strings.Split(s[i+1:], ".")
+		for _, identifier := range v.prerelease {
+			if identifier == "" {
+				return semver{}, false
+			}
+		}
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	numbers := make([]uint64, 3)
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil || (len(part) > 1 && part[0] == '0') {
+			return semver{}, false
+		}
+		numbers[i] = n
+	}
+	v.major, v.minor, v.patch = numbers[0], numbers[1], numbers[2]
+	return v, true
+}
+
+// compare returns -1, 0 or 1 if v has lower, equal or higher precedence than other.
+func (v semver) compare(other semver) int {
+	for _, pair := range [][2]uint64{{v.major, other.major}, {v.minor, other.minor}, {v.patch, other.patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	// a version without pre-release has higher precedence than a version with pre-release
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		if c := comparePrereleaseIdentifiers(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(v.prerelease) < len(other.prerelease):
+		return -1
+	case len(v.prerelease) > len(other.prerelease):
+		return 1
+	}
+	return 0
+}
+
+// comparePrereleaseIdentifiers compares 2 pre-release identifiers: numeric identifiers are compared
+// numerically and have lower precedence than alphanumeric identifiers, which are compared
+// lexically.
+func comparePrereleaseIdentifiers(id1, id2 string) int {
+	n1, err1 := strconv.ParseUint(id1, 10, 64)
+	n2, err2 := strconv.ParseUint(id2, 10, 64)
+	switch {
+	case err1 == nil && err2 == nil:
+		if n1 < n2 {
+			return -1
+		} else if n1 > n2 {
+			return 1
+		}
+		return 0
+	case err1 == nil:
+		return -1
+	case err2 == nil:
+		return 1
+	}
+	return strings.Compare(id1, id2)
+}
+
+// deepMergeSemverMax merges 2 strings, or pointers thereto, holding semantic versions, by selecting
+// the highest version. Empty strings and nil pointers are ignored. It returns an error if a
+// version cannot be parsed.
+func (c *coalescer) deepMergeSemverMax(v1, v2 reflect.Value) (reflect.Value, error) {
+	if indirect(v1.Type()).Kind() != reflect.String {
+		return reflect.Value{}, fmt.Errorf("expecting string or pointer thereto, got: %s", v1.Type().String())
+	}
+	s1 := safeIndirect(v1).String()
+	s2 := safeIndirect(v2).String()
+	ver1, err := parseSemverIfNotEmpty(s1)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	ver2, err := parseSemverIfNotEmpty(s2)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	switch {
+	case s1 == "" && s2 == "" && v2.IsZero():
+		return c.deepCopy(v1)
+	case s1 == "":
+		return c.deepCopy(v2)
+	case s2 == "":
+		return c.deepCopy(v1)
+	case ver1.compare(ver2) < 0:
+		return c.deepCopy(v2)
+	}
+	return c.deepCopy(v1)
+}
+
+func parseSemverIfNotEmpty(s string) (semver, error) {
+	if s == "" {
+		return semver{}, nil
+	}
+	v, ok := parseSemver(s)
+	if !ok {
+		return semver{}, fmt.Errorf("invalid semantic version: %q", s)
+	}
+	return v, nil
+}
+
+func (c *coalescer) semverFieldMerger(field reflect.StructField) (DeepMergeFunc, error) {
+	if indirect(field.Type).Kind() != reflect.String {
+		return nil, fmt.Errorf("%s strategy is only supported for strings and pointers thereto", MergeStrategySemverMax)
+	}
+	return c.deepMergeSemverMax, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseSemver(t *testing.T) {
+	tests := []struct {
+		s      string
+		want   semver
+		wantOk bool
+	}{
+		{"1.2.3", semver{major: 1, minor: 2, patch: 3}, true},
+		{"v1.2.3", semver{major: 1, minor: 2, patch: 3}, true},
+		{"1.2.3-rc.1", semver{major: 1, minor: 2, patch: 3, prerelease: []string{"rc", "1"}}, true},
+		{"1.2.3+build.5", semver{major: 1, minor: 2, patch: 3}, true},
+		{"1.2.3-beta+build", semver{major: 1, minor: 2, patch: 3, prerelease: []string{"beta"}}, true},
+		{"1.2", semver{}, false},
+		{"1.2.3.4", semver{}, false},
+		{"1.02.3", semver{}, false},
+		{"1.x.3", semver{}, false},
+		{"1.2.3-", semver{}, false},
+		{"1.2.3-rc..1", semver{}, false},
+		{"latest", semver{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, ok := parseSemver(tt.s)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantOk, ok)
+		})
+	}
+}
+
+func Test_semver_compare(t *testing.T) {
+	// versions in increasing order of precedence, taken from the semver specification
+	versions := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+		"1.0.1",
+		"1.1.0",
+		"2.0.0",
+	}
+	for i, s1 := range versions {
+		for j, s2 := range versions {
+			v1, _ := parseSemver(s1)
+			v2, _ := parseSemver(s2)
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			assert.Equal(t, want, v1.compare(v2), "%s <=> %s", s1, s2)
+		}
+	}
+}
+
+func Test_coalescer_deepMergeSemverMax(t *testing.T) {
+	type component struct {
+		Version    string  `goalesce:"semverMax"`
+		MinVersion *string `goalesce:"semverMax"`
+	}
+	tests := []struct {
+		name    string
+		v1      component
+		v2      component
+		want    component
+		wantErr string
+	}{
+		{
+			name: "zero",
+			v1:   component{},
+			v2:   component{},
+			want: component{},
+		},
+		{
+			name: "v1 only",
+			v1:   component{Version: "1.0.0", MinVersion: stringPtr("1.0.0")},
+			v2:   component{},
+			want: component{Version: "1.0.0", MinVersion: stringPtr("1.0.0")},
+		},
+		{
+			name: "v2 only",
+			v1:   component{},
+			v2:   component{Version: "1.0.0", MinVersion: stringPtr("1.0.0")},
+			want: component{Version: "1.0.0", MinVersion: stringPtr("1.0.0")},
+		},
+		{
+			name: "v1 higher",
+			v1:   component{Version: "1.10.0", MinVersion: stringPtr("v2.0.0")},
+			v2:   component{Version: "1.9.0", MinVersion: stringPtr("v2.0.0-rc.1")},
+			want: component{Version: "1.10.0", MinVersion: stringPtr("v2.0.0")},
+		},
+		{
+			name: "v2 higher",
+			v1:   component{Version: "1.9.0", MinVersion: stringPtr("v2.0.0-rc.1")},
+			v2:   component{Version: "1.10.0", MinVersion: stringPtr("v2.0.0")},
+			want: component{Version: "1.10.0", MinVersion: stringPtr("v2.0.0")},
+		},
+		{
+			name: "pointer to empty",
+			v1:   component{MinVersion: nil},
+			v2:   component{MinVersion: stringPtr("")},
+			want: component{MinVersion: stringPtr("")},
+		},
+		{
+			name:    "invalid v1",
+			v1:      component{Version: "latest"},
+			v2:      component{Version: "1.0.0"},
+			wantErr: `at Version: invalid semantic version: "latest"`,
+		},
+		{
+			name:    "invalid v2",
+			v1:      component{},
+			v2:      component{Version: "1.0"},
+			wantErr: `at Version: invalid semantic version: "1.0"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+	t.Run("wrong type", func(t *testing.T) {
+		c := newCoalescer()
+		_, err := c.deepMergeSemverMax(reflect.ValueOf(1), reflect.ValueOf(2))
+		assert.EqualError(t, err, "expecting string or pointer thereto, got: int")
+	})
+}
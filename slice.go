@@ -17,6 +17,8 @@ package goalesce
 import (
 	"fmt"
 	"reflect"
+	"slices"
+	"sort"
 )
 
 // SliceMergeKeyFunc is a function that extracts a merge key from a slice element's index and value. The passed element
@@ -39,11 +41,63 @@ var SliceIndex SliceMergeKeyFunc = func(index int, element reflect.Value) (key r
 	return reflect.ValueOf(index), nil
 }
 
+// SliceMergeByField returns a merge key func that returns the value of the given struct field as
+// key, thus achieving merge-by-id semantics, as with WithSliceMergeByID. It works on slices of
+// structs and slices of pointers to structs.
+func SliceMergeByField(field string) SliceMergeKeyFunc {
+	return newMergeByField(field)
+}
+
+// SliceMergeByMapKey returns a merge key func that returns the value of the map entry with the
+// given key as key, thus achieving merge-by-id semantics for slices of maps, e.g.
+// []map[string]interface{} decoded from untyped JSON or YAML lists. It works on slices of maps,
+// slices of pointers to maps and slices of interfaces holding maps, e.g. []interface{}. Elements
+// lacking the entry get a zero key, and therefore match each other.
+func SliceMergeByMapKey(key interface{}) SliceMergeKeyFunc {
+	return func(_ int, elem reflect.Value) (reflect.Value, error) {
+		for elem.Kind() == reflect.Interface && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+		if elem.Kind() == reflect.Interface {
+			// nil interface
+			return elem, nil
+		}
+		m := safeIndirect(elem)
+		if m.Kind() != reflect.Map {
+			return reflect.Value{}, fmt.Errorf("expecting map or pointer thereto, got: %s", elem.Type().String())
+		}
+		k := reflect.ValueOf(key)
+		if k.Type() != m.Type().Key() {
+			if !k.CanConvert(m.Type().Key()) {
+				return reflect.Value{}, fmt.Errorf("map key %v of type %T is not convertible to %s", key, key, m.Type().Key().String())
+			}
+			k = k.Convert(m.Type().Key())
+		}
+		entry := m.MapIndex(k)
+		if !entry.IsValid() {
+			return reflect.Zero(m.Type().Elem()), nil
+		} else if entry.Kind() == reflect.Interface && !entry.IsNil() {
+			entry = entry.Elem()
+		}
+		return entry, nil
+	}
+}
+
 // deepMergeSlice is the default slice merger. It first checks if there is a custom slice merger
 // registered for the slice type. If there is, it uses it. Otherwise, it uses the default slice
 // merge strategy, which is atomic.
 func (c *coalescer) deepMergeSlice(v1, v2 reflect.Value) (reflect.Value, error) {
-	if value, done := checkZero(v1, v2); done {
+	if mergeKeyFunc, found := c.sliceDifferences[v1.Type()]; found {
+		// the zero shortcut does not apply: a zero v1 minus v2 is still zero
+		c.trace("using slice difference for %s", v1.Type().String())
+		return c.deepMergeSliceDifference(v1, v2, mergeKeyFunc)
+	}
+	// with a delete marker, a zero v1 can't be replaced with v2, since marked elements must be removed;
+	// with a sorted result, the non-zero value must be sorted
+	// with field permissions, a zero v1 can't be replaced with v2 either, since the fields of its
+	// elements must be checked
+	if value, done := checkZero(v1, v2); done && c.sliceSorts[v1.Type()] == nil && (v2.IsZero() || c.sliceDeleteMarker(v1.Type()) == nil) && !c.mustCheckPermissions(v2) {
+		c.traceZeroShortcut(v1)
 		return c.deepCopy(value)
 	}
 	if v1.Len() == 0 && v2.Len() == 0 {
@@ -56,24 +110,63 @@ func (c *coalescer) deepMergeSlice(v1, v2 reflect.Value) (reflect.Value, error)
 		if v2.Len() == 0 {
 			v2 = reflect.Zero(v2.Type())
 		}
-		if value, done := checkZero(v1, v2); done {
+		if value, done := checkZero(v1, v2); done && !c.mustCheckPermissions(v2) {
 			return c.deepCopy(value)
 		}
 	}
+	if elemMerger, found := c.sliceElemMergers[v1.Type()]; found {
+		previous := c.elemMerger
+		c.elemMerger = elemMerger
+		defer func() { c.elemMerger = previous }()
+	}
+	if sliceMerger, found := c.sliceMergerOverrides[v1.Type()]; found {
+		c.trace("using slice merger override for %s", v1.Type().String())
+		return sliceMerger(v1, v2)
+	}
 	if sliceMerger, found := c.sliceMergers[v1.Type()]; found {
+		c.trace("using slice merger for %s", v1.Type().String())
 		return sliceMerger(v1, v2)
 	}
+	if key, found := elemMergeKey(v1.Type()); found {
+		c.trace("using merge key %s declared by %s", key, v1.Type().Elem().String())
+		return c.deepMergeSliceWithMergeKey(v1, v2, newMergeByField(key))
+	}
+	if isByteSlice(v1.Type()) {
+		// binary blobs are opaque: the default slice merger, if any, does not apply to them
+		return c.deepMergeAtomic(v1, v2)
+	}
 	if c.sliceMerger != nil {
+		c.trace("using default slice merger")
 		return c.sliceMerger(v1, v2)
 	}
+	if c.mustCheckPermissions(v2) && mayHoldStructs(v1.Type().Elem()) {
+		return c.deepMergeCheckedElements(v1, v2)
+	}
 	return c.deepMergeAtomic(v1, v2)
 }
 
+// isByteSlice returns true if the given slice type is []byte, or a named type thereof, such as
+// json.RawMessage.
+func isByteSlice(sliceType reflect.Type) bool {
+	return sliceType.Elem().Kind() == reflect.Uint8
+}
+
 // deepMergeSliceWithListAppend is an alternate slice merger that appends the elements of the second
 // slice to the first slice. It is not the default merge strategy for slices; it is only activated
 // if a slice merger has been registered through one of the options:
 // WithDefaultSliceListAppendMerge, WithSliceListAppendMerge or WithFieldListAppendMerge.
 func (c *coalescer) deepMergeSliceWithListAppend(v1, v2 reflect.Value) (reflect.Value, error) {
+	merged, err := c.appendSlices(v1, v2)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if mergeKeyFunc := c.listAppendDedupKey(v1.Type()); mergeKeyFunc != nil {
+		return dedupSlice(merged, mergeKeyFunc)
+	}
+	return merged, nil
+}
+
+func (c *coalescer) appendSlices(v1, v2 reflect.Value) (reflect.Value, error) {
 	if value, done := checkZero(v1, v2); done {
 		return c.deepCopy(value)
 	}
@@ -81,6 +174,9 @@ func (c *coalescer) deepMergeSliceWithListAppend(v1, v2 reflect.Value) (reflect.
 		return c.deepCopy(v2)
 	}
 	l := v1.Len() + v2.Len()
+	if err := c.visitElements(l); err != nil {
+		return reflect.Value{}, err
+	}
 	merged := reflect.MakeSlice(v1.Type(), l, l)
 	for i := 0; i < v1.Len(); i++ {
 		elem, err := c.deepCopy(v1.Index(i))
@@ -99,8 +195,100 @@ func (c *coalescer) deepMergeSliceWithListAppend(v1, v2 reflect.Value) (reflect.
 	return merged, nil
 }
 
+// deepMergeSliceDifference merges 2 slices with "set-difference" semantics: the merged slice
+// contains the elements of v1 whose merge keys do not appear in v2, in the order of v1. Elements of
+// v2 are never included in the merged slice.
+func (c *coalescer) deepMergeSliceDifference(v1, v2 reflect.Value, mergeKeyFunc SliceMergeKeyFunc) (reflect.Value, error) {
+	if v1.IsZero() || v2.IsZero() {
+		return c.deepCopy(v1)
+	}
+	if err := c.visitElements(v1.Len() + v2.Len()); err != nil {
+		return reflect.Value{}, err
+	}
+	removed := make(map[interface{}]bool, v2.Len())
+	for i := 0; i < v2.Len(); i++ {
+		k, err := mergeKey(mergeKeyFunc, i, v2.Index(i))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		removed[k.Interface()] = true
+	}
+	merged := reflect.MakeSlice(v1.Type(), 0, v1.Len())
+	for i := 0; i < v1.Len(); i++ {
+		k, err := mergeKey(mergeKeyFunc, i, v1.Index(i))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if removed[k.Interface()] {
+			continue
+		}
+		elem, err := c.deepCopy(v1.Index(i))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		merged = reflect.Append(merged, elem)
+	}
+	return merged, nil
+}
+
+// listAppendDedupKey returns the SliceMergeKeyFunc used to remove duplicates from slices of the
+// given type after a list-append, or nil if duplicates are retained. See WithListAppendDedup.
+func (c *coalescer) listAppendDedupKey(sliceType reflect.Type) SliceMergeKeyFunc {
+	if mergeKeyFunc, found := c.listAppendDedupKeys[sliceType]; found {
+		return mergeKeyFunc
+	} else if c.listAppendDedup {
+		return SliceUnion
+	}
+	return nil
+}
+
+// dedupSlice removes the elements of the given slice whose merge keys appear again later in the
+// slice, thus keeping the last occurrence of each merge key. The slice is modified in place.
+func dedupSlice(v reflect.Value, mergeKeyFunc SliceMergeKeyFunc) (reflect.Value, error) {
+	if v.Len() < 2 {
+		return v, nil
+	}
+	keys := make([]interface{}, v.Len())
+	last := make(map[interface{}]int, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		k, err := mergeKey(mergeKeyFunc, i, v.Index(i))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		keys[i] = k.Interface()
+		last[keys[i]] = i
+	}
+	n := 0
+	for i := 0; i < v.Len(); i++ {
+		if last[keys[i]] == i {
+			v.Index(n).Set(v.Index(i))
+			n++
+		}
+	}
+	for i := n; i < v.Len(); i++ {
+		v.Index(i).Set(reflect.Zero(v.Type().Elem())) // release references
+	}
+	return v.Slice(0, n), nil
+}
+
 var typeOfInterface = reflect.TypeOf((*interface{})(nil)).Elem()
 
+// deepMergeSliceByIndex merges slices with merge-by-index semantics. If WithStrictIndexMerge was
+// used, it returns an error when both slices are non-nil and of different lengths; empty slices
+// are considered nil if WithZeroEmptySliceMerge was used.
+func (c *coalescer) deepMergeSliceByIndex(v1, v2 reflect.Value) (reflect.Value, error) {
+	present := func(v reflect.Value) bool {
+		return !v.IsNil() && (v.Len() > 0 || !c.zeroEmptySlice)
+	}
+	if c.strictIndexMerge && present(v1) && present(v2) && v1.Len() != v2.Len() {
+		return reflect.Value{}, fmt.Errorf("cannot merge slices of different lengths by index: %d != %d", v1.Len(), v2.Len())
+	}
+	if present(v1) && present(v2) && v1.Len() != v2.Len() {
+		c.warn("slices of different lengths merged by index: %d != %d", v1.Len(), v2.Len())
+	}
+	return c.deepMergeSliceWithMergeKey(v1, v2, SliceIndex)
+}
+
 // deepMergeSliceWithMergeKey is an alternate slice merger that merges the elements of the two
 // slices using a merge key function. It is not the default merge strategy for slices; it is only
 // activated if a slice merger has been registered through one of the options:
@@ -108,78 +296,311 @@ var typeOfInterface = reflect.TypeOf((*interface{})(nil)).Elem()
 // WithSliceMergeByIndex, WithSliceMergeByID, WithSliceMergeByKeyFunc, WithFieldMergeByIndex,
 // WithFieldMergeByID, WithFieldMergeByKeyFunc.
 func (c *coalescer) deepMergeSliceWithMergeKey(v1, v2 reflect.Value, mergeKeyFunc SliceMergeKeyFunc) (reflect.Value, error) {
-	if value, done := checkZero(v1, v2); done {
+	merged, err := c.mergeSliceByKey(v1, v2, mergeKeyFunc)
+	if less, found := c.sliceSorts[v1.Type()]; found && err == nil && merged.Len() > 1 {
+		sort.SliceStable(merged.Interface(), func(i, j int) bool {
+			return less(merged.Index(i), merged.Index(j))
+		})
+	}
+	return merged, err
+}
+
+func (c *coalescer) mergeSliceByKey(v1, v2 reflect.Value, mergeKeyFunc SliceMergeKeyFunc) (reflect.Value, error) {
+	// the element merger only applies to the elements of this slice, not to nested slices
+	elemMerger := c.deepMerge
+	if c.elemMerger != nil {
+		elemMerger = c.elemMerger
+		c.elemMerger = nil
+		defer func(previous DeepMergeFunc) { c.elemMerger = previous }(elemMerger)
+	}
+	deleteMarker := c.sliceDeleteMarker(v1.Type())
+	// with a delete marker, a zero v1 can't be replaced with v2, since marked elements must be removed
+	if value, done := checkZero(v1, v2); done && (deleteMarker == nil || v2.IsZero()) {
 		return c.deepCopy(value)
 	}
 	if v1.Len() == 0 && v2.Len() == 0 {
 		return c.deepCopy(v2)
 	}
+	if err := c.visitElements(v1.Len() + v2.Len()); err != nil {
+		return reflect.Value{}, err
+	}
 	// The "keys" slice allows to keep a deterministic element order in the resulting slice.
 	keys := reflect.MakeSlice(reflect.SliceOf(typeOfInterface), 0, 0)
+	var keys1, keys2 []interface{}
 	m1 := reflect.MakeMap(reflect.MapOf(typeOfInterface, v1.Type().Elem()))
 	for i := 0; i < v1.Len(); i++ {
 		v := v1.Index(i)
-		k, err := mergeKeyFunc(i, v)
+		k, err := mergeKey(mergeKeyFunc, i, v)
 		if err != nil {
 			return reflect.Value{}, err
-		} else if err := checkMergeKey(k); err != nil {
-			return reflect.Value{}, err
 		}
 		if !m1.MapIndex(k).IsValid() {
 			keys = reflect.Append(keys, k)
+			keys1 = append(keys1, k.Interface())
 		}
 		m1.SetMapIndex(k, v)
 	}
 	m2 := reflect.MakeMap(reflect.MapOf(typeOfInterface, v2.Type().Elem()))
+	deleted := make(map[interface{}]bool)
 	for i := 0; i < v2.Len(); i++ {
 		v := v2.Index(i)
-		k, err := mergeKeyFunc(i, v)
+		k, err := mergeKey(mergeKeyFunc, i, v)
 		if err != nil {
 			return reflect.Value{}, err
-		} else if err := checkMergeKey(k); err != nil {
-			return reflect.Value{}, err
 		}
-		if !m1.MapIndex(k).IsValid() && !m2.MapIndex(k).IsValid() {
-			keys = reflect.Append(keys, k)
+		if deleteMarker != nil {
+			if marked, err := deleteMarker(v); err != nil {
+				return reflect.Value{}, err
+			} else if marked {
+				deleted[k.Interface()] = true
+				continue
+			}
+		}
+		if !m2.MapIndex(k).IsValid() {
+			keys2 = append(keys2, k.Interface())
+			if !m1.MapIndex(k).IsValid() {
+				keys = reflect.Append(keys, k)
+			}
 		}
 		m2.SetMapIndex(k, v)
 	}
+	if order := c.sliceKeyOrder(v1.Type()); order != nil {
+		keys = reorderKeys(keys, order(keys1, keys2))
+	}
 	// Note: we can't call deepMergeMap here because it is important to NOT copy the merge keys
-	m := reflect.MakeMap(m1.Type())
-	for _, k := range m1.MapKeys() {
-		if !m2.MapIndex(k).IsValid() {
-			copiedValue, err := c.deepCopy(m1.MapIndex(k))
-			if err != nil {
-				return reflect.Value{}, err
-			}
-			m.SetMapIndex(k, copiedValue)
+	merged := reflect.MakeSlice(v1.Type(), 0, keys.Len())
+	parent := c.path
+	defer func() { c.path = parent }()
+	for i := 0; i < keys.Len(); i++ {
+		k := keys.Index(i)
+		if deleted[k.Interface()] {
+			continue
+		}
+		c.path = indexPath(parent, merged.Len())
+		var elem reflect.Value
+		var err error
+		if elem1, elem2 := m1.MapIndex(k), m2.MapIndex(k); elem1.IsValid() && elem2.IsValid() {
+			elem, err = c.mergeAt(elemMerger, elem1, elem2)
+		} else if elem1.IsValid() {
+			elem, err = c.deepCopy(elem1)
+		} else {
+			elem, err = c.deepCopy(elem2)
+		}
+		if err != nil {
+			return reflect.Value{}, err
 		}
+		merged = reflect.Append(merged, elem)
 	}
-	for _, k := range m2.MapKeys() {
-		if m1.MapIndex(k).IsValid() {
-			mergedValue, err := c.deepMerge(m1.MapIndex(k), m2.MapIndex(k))
+	return merged, nil
+}
+
+// SliceKeyOrder is a function that determines the order of the elements of slices merged by key.
+// It receives the distinct merge keys of the first and second slices, in the order they appear in
+// each slice, and returns the merge keys in the desired order. Returned keys that do not exist in
+// any slice are ignored, and keys that are not returned are appended at the end, in the default
+// order. See WithSliceKeyOrder.
+type SliceKeyOrder func(keys1, keys2 []interface{}) []interface{}
+
+// SliceKeyOrderFirst is the default SliceKeyOrder: elements appear in the order of the first slice,
+// followed by the elements that exist only in the second slice, in the order of the second slice.
+var SliceKeyOrderFirst SliceKeyOrder = func(keys1, keys2 []interface{}) []interface{} {
+	return append(append([]interface{}{}, keys1...), keys2...)
+}
+
+// SliceKeyOrderSecond is a SliceKeyOrder where elements appear in the order of the second slice,
+// followed by the elements that exist only in the first slice, in the order of the first slice.
+var SliceKeyOrderSecond SliceKeyOrder = func(keys1, keys2 []interface{}) []interface{} {
+	return append(append([]interface{}{}, keys2...), keys1...)
+}
+
+// SliceKeyOrderInterleave is a SliceKeyOrder where elements appear in the order of the first slice,
+// and elements that exist only in the second slice are interleaved at their positions in the second
+// slice, that is, right after the element that precedes them in the second slice, or first if they
+// are first in the second slice.
+var SliceKeyOrderInterleave SliceKeyOrder = func(keys1, keys2 []interface{}) []interface{} {
+	keys := append([]interface{}{}, keys1...)
+	positions := make(map[interface{}]bool, len(keys1))
+	for _, k := range keys1 {
+		positions[k] = true
+	}
+	insertAt := 0
+	for _, k := range keys2 {
+		if positions[k] {
+			insertAt = slices.Index(keys, k) + 1
+			continue
+		}
+		keys = slices.Insert(keys, insertAt, k)
+		insertAt++
+	}
+	return keys
+}
+
+// SliceKeyOrderExplicit returns a SliceKeyOrder where elements whose merge keys are among the given
+// keys appear first, in the order of the given keys, followed by the other elements, in the default
+// order (see SliceKeyOrderFirst).
+func SliceKeyOrderExplicit(keys ...interface{}) SliceKeyOrder {
+	return func([]interface{}, []interface{}) []interface{} {
+		return keys
+	}
+}
+
+// SliceKeyOrderSorted is a SliceKeyOrder where elements are sorted by merge key: keys of basic types
+// are sorted by value, e.g. numerically for integers, and composite keys element by element. Note
+// that key orders only apply when both slices are non-zero; see WithSortedSliceResult to also sort
+// slices that are merged with a zero slice.
+var SliceKeyOrderSorted SliceKeyOrder = func(keys1, keys2 []interface{}) []interface{} {
+	keys := append(append([]interface{}{}, keys1...), keys2...)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compareKeys(reflect.ValueOf(&keys[i]).Elem(), reflect.ValueOf(&keys[j]).Elem()) < 0
+	})
+	return keys
+}
+
+func (c *coalescer) sliceKeyOrder(sliceType reflect.Type) SliceKeyOrder {
+	if order, found := c.sliceKeyOrders[sliceType]; found {
+		return order
+	}
+	return c.defaultSliceKeyOrder
+}
+
+// SliceDeleteMarker is a function that determines whether an element of the second slice of a
+// merge-by-key is a deletion marker: if so, the element with the same merge key is removed from the
+// merged slice, and the marker itself does not appear in it. See WithSliceDeleteMarker.
+type SliceDeleteMarker func(element reflect.Value) (bool, error)
+
+// SliceDeleteMarkerField returns a SliceDeleteMarker that marks elements whose bool field with the
+// given name is true. Elements must be structs, or pointers thereto; nil pointers are not marked.
+func SliceDeleteMarkerField(field string) SliceDeleteMarker {
+	return func(element reflect.Value) (bool, error) {
+		deref := safeIndirect(element)
+		if deref.Kind() != reflect.Struct {
+			return false, fmt.Errorf("expecting struct or pointer thereto, got: %s", element.Type().String())
+		}
+		marker := fieldByName(deref, field)
+		if !marker.IsValid() {
+			return false, fmt.Errorf("struct type %s has no field named %s", deref.Type().String(), field)
+		} else if marker.Kind() != reflect.Bool {
+			return false, fmt.Errorf("expecting bool delete marker field %s.%s, got: %s", deref.Type().String(), field, marker.Type().String())
+		}
+		return marker.Bool(), nil
+	}
+}
+
+// sliceDeleteMarker returns the SliceDeleteMarker of the given slice type, or nil if there is none.
+// With WithPatchDirectives, elements holding a `$patch: delete` directive are also deletion
+// markers.
+func (c *coalescer) sliceDeleteMarker(sliceType reflect.Type) SliceDeleteMarker {
+	marker := c.sliceDeleteMarkers[sliceType]
+	if !c.patchDirectives {
+		return marker
+	}
+	return func(element reflect.Value) (bool, error) {
+		if deleted, err := c.isDeleteDirective(element); err != nil || deleted {
+			return deleted, err
+		}
+		if marker == nil {
+			return false, nil
+		}
+		return marker(element)
+	}
+}
+
+// reorderKeys reorders the given keys according to the desired order: keys that do not exist are
+// ignored, and keys that are missing from the desired order are appended at the end.
+func reorderKeys(keys reflect.Value, order []interface{}) reflect.Value {
+	remaining := make(map[interface{}]bool, keys.Len())
+	for i := 0; i < keys.Len(); i++ {
+		remaining[keys.Index(i).Interface()] = true
+	}
+	reordered := reflect.MakeSlice(keys.Type(), 0, keys.Len())
+	for _, k := range order {
+		if remaining[k] {
+			delete(remaining, k)
+			reordered = reflect.Append(reordered, reflect.ValueOf(&k).Elem())
+		}
+	}
+	for i := 0; i < keys.Len(); i++ {
+		if remaining[keys.Index(i).Interface()] {
+			reordered = reflect.Append(reordered, keys.Index(i))
+		}
+	}
+	return reordered
+}
+
+// SliceVersionFunc is a function that extracts the version stamp of a slice element, and tells
+// whether the element is a tombstone, that is, a marker that the element has been removed. The
+// passed element may be the zero-value for the slice element type, but it will never be an invalid
+// value. See WithSliceORSetMerge.
+type SliceVersionFunc func(element reflect.Value) (version int64, removed bool, err error)
+
+// deepMergeSliceWithORSet is an alternate slice merger that merges the elements of the two slices
+// with observed-remove set semantics. It is not the default merge strategy for slices; it is only
+// activated if a slice merger has been registered through the option WithSliceORSetMerge.
+//
+// Elements having the same merge key are not merged: the element with the highest version stamp
+// wins; if both versions are equal, live elements win over tombstones. Tombstones are retained in
+// the merged slice, so that removals propagate to subsequent merges. The merged slice is sorted by
+// merge key, so that the result does not depend on the order in which the slices are merged.
+func (c *coalescer) deepMergeSliceWithORSet(v1, v2 reflect.Value, mergeKeyFunc SliceMergeKeyFunc, versionFunc SliceVersionFunc) (reflect.Value, error) {
+	if value, done := checkZero(v1, v2); done {
+		return c.deepCopy(value)
+	}
+	if err := c.visitElements(v1.Len() + v2.Len()); err != nil {
+		return reflect.Value{}, err
+	}
+	type entry struct {
+		elem    reflect.Value
+		version int64
+		removed bool
+	}
+	entries := make(map[interface{}]entry)
+	var keys []reflect.Value
+	for _, v := range []reflect.Value{v1, v2} {
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			k, err := mergeKey(mergeKeyFunc, i, elem)
 			if err != nil {
 				return reflect.Value{}, err
 			}
-			m.SetMapIndex(k, mergedValue)
-		} else {
-			copiedValue, err := c.deepCopy(m2.MapIndex(k))
+			version, removed, err := versionFunc(elem)
 			if err != nil {
 				return reflect.Value{}, err
 			}
-			m.SetMapIndex(k, copiedValue)
+			key := k.Interface()
+			if existing, found := entries[key]; !found {
+				keys = append(keys, k)
+				entries[key] = entry{elem, version, removed}
+			} else if version > existing.version || (version == existing.version && existing.removed && !removed) {
+				entries[key] = entry{elem, version, removed}
+			}
 		}
 	}
-	merged := reflect.MakeSlice(v1.Type(), 0, 0)
-	for i := 0; i < keys.Len(); i++ {
-		k := keys.Index(i)
-		if m.MapIndex(k).IsValid() {
-			merged = reflect.Append(merged, m.MapIndex(k))
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compareKeys(keys[i], keys[j]) < 0
+	})
+	merged := reflect.MakeSlice(v1.Type(), len(keys), len(keys))
+	for i, key := range keys {
+		elem, err := c.deepCopy(entries[key.Interface()].elem)
+		if err != nil {
+			return reflect.Value{}, err
 		}
+		merged.Index(i).Set(elem)
 	}
 	return merged, nil
 }
 
+// mergeKey extracts the merge key of the given slice element with the given merge key func, and
+// checks that it is a valid merge key.
+func mergeKey(mergeKeyFunc SliceMergeKeyFunc, index int, elem reflect.Value) (reflect.Value, error) {
+	k, err := mergeKeyFunc(index, elem)
+	if err != nil {
+		return reflect.Value{}, err
+	} else if err := checkMergeKey(k); err != nil {
+		return reflect.Value{}, err
+	}
+	return k, nil
+}
+
 func checkMergeKey(k reflect.Value) error {
 	if !k.IsValid() {
 		return fmt.Errorf("slice merge key func returned nil")
@@ -193,7 +614,15 @@ func (c *coalescer) deepCopySlice(v reflect.Value) (reflect.Value, error) {
 	if v.IsZero() {
 		return reflect.Zero(v.Type()), nil
 	}
+	if err := c.visitElements(v.Len()); err != nil {
+		return reflect.Value{}, err
+	}
 	copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	if c.isPlainType(v.Type().Elem()) {
+		// e.g. []byte: a single memory copy is enough
+		reflect.Copy(copied, v)
+		return copied, nil
+	}
 	for i := 0; i < v.Len(); i++ {
 		elem, err := c.deepCopy(v.Index(i))
 		if err != nil {
@@ -15,6 +15,7 @@
 package goalesce
 
 import (
+	"encoding/json"
 	"errors"
 	"reflect"
 	"testing"
@@ -1669,6 +1670,44 @@ func Test_coalescer_deepMergeSliceWithMergeKey(t *testing.T) {
 			v2:   reflect.ValueOf([]int{3, 4, 5}),
 			want: reflect.ValueOf([]int{1, 2, 3, 4, 5}),
 		},
+		{
+			name: "key order first",
+			v1:   reflect.ValueOf([]int{1, 2, 3}),
+			v2:   reflect.ValueOf([]int{5, 4, 3}),
+			want: reflect.ValueOf([]int{1, 2, 3, 5, 4}),
+			opts: []Option{WithDefaultSliceKeyOrder(SliceKeyOrderFirst)},
+		},
+		{
+			name: "key order second",
+			v1:   reflect.ValueOf([]int{1, 2, 3}),
+			v2:   reflect.ValueOf([]int{5, 4, 3}),
+			want: reflect.ValueOf([]int{5, 4, 3, 1, 2}),
+			opts: []Option{WithDefaultSliceKeyOrder(SliceKeyOrderSecond)},
+		},
+		{
+			name: "key order interleave",
+			v1:   reflect.ValueOf([]int{1, 2, 3}),
+			v2:   reflect.ValueOf([]int{0, 2, 4, 5, 1, 6}),
+			want: reflect.ValueOf([]int{0, 1, 6, 2, 4, 5, 3}),
+			opts: []Option{WithDefaultSliceKeyOrder(SliceKeyOrderInterleave)},
+		},
+		{
+			name: "key order explicit",
+			v1:   reflect.ValueOf([]int{1, 2, 3}),
+			v2:   reflect.ValueOf([]int{5, 4, 3}),
+			want: reflect.ValueOf([]int{4, 2, 1, 3, 5}),
+			opts: []Option{WithDefaultSliceKeyOrder(SliceKeyOrderExplicit(4, 2, 6, 4))},
+		},
+		{
+			name: "key order per type",
+			v1:   reflect.ValueOf([]int{1, 2, 3}),
+			v2:   reflect.ValueOf([]int{5, 4, 3}),
+			want: reflect.ValueOf([]int{5, 4, 3, 1, 2}),
+			opts: []Option{
+				WithDefaultSliceKeyOrder(SliceKeyOrderExplicit(3)),
+				WithSliceKeyOrder(reflect.TypeOf([]int{}), SliceKeyOrderSecond),
+			},
+		},
 		{
 			name:    "error copy v1",
 			v1:      reflect.ValueOf([]int{1, 2, 3}),
@@ -1709,6 +1748,441 @@ func Test_coalescer_deepMergeSliceWithMergeKey(t *testing.T) {
 			}
 		})
 	}
+	t.Run("sorted result", func(t *testing.T) {
+		type user struct {
+			ID   int
+			Name string
+		}
+		byName := func(e1, e2 reflect.Value) bool {
+			return e1.Interface().(user).Name < e2.Interface().(user).Name
+		}
+		c := newCoalescer(WithSliceMergeByID(reflect.TypeOf([]user{}), "ID"), WithSortedSliceResult(reflect.TypeOf([]user{}), byName))
+		v1 := []user{{ID: 1, Name: "carol"}, {ID: 2, Name: "alice"}}
+		v2 := []user{{ID: 3, Name: "bob"}, {ID: 1, Name: "dave"}}
+		got, err := c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, []user{{ID: 2, Name: "alice"}, {ID: 3, Name: "bob"}, {ID: 1, Name: "dave"}}, got.Interface())
+		got, err = c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf([]user(nil)))
+		assert.NoError(t, err)
+		assert.Equal(t, []user{{ID: 2, Name: "alice"}, {ID: 1, Name: "carol"}}, got.Interface())
+		assert.Equal(t, []user{{ID: 1, Name: "carol"}, {ID: 2, Name: "alice"}}, v1)
+		got, err = c.deepMerge(reflect.ValueOf([]user(nil)), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, []user{{ID: 3, Name: "bob"}, {ID: 1, Name: "dave"}}, got.Interface())
+	})
+	t.Run("key order sorted", func(t *testing.T) {
+		c := newCoalescer(WithDefaultSliceKeyOrder(SliceKeyOrderSorted))
+		got, err := c.deepMergeSliceWithMergeKey(reflect.ValueOf([]int{10, 2, 3}), reflect.ValueOf([]int{5, 1, 3}), SliceUnion)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 5, 10}, got.Interface())
+		got, err = c.deepMergeSliceWithMergeKey(reflect.ValueOf([]string{"b", "c"}), reflect.ValueOf([]string{"a"}), SliceUnion)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, got.Interface())
+	})
+	t.Run("delete marker", func(t *testing.T) {
+		type user struct {
+			ID      int
+			Name    string
+			Deleted bool
+		}
+		marker := WithSliceDeleteMarker(reflect.TypeOf([]*user{}), SliceDeleteMarkerField("Deleted"))
+		c := newCoalescer(WithSliceMergeByID(reflect.TypeOf([]*user{}), "ID"), marker)
+		v1 := []*user{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}, {ID: 3, Name: "carol"}}
+		v2 := []*user{{ID: 2, Deleted: true}, {ID: 3, Name: "caroline"}, {ID: 4, Deleted: true}}
+		got, err := c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, []*user{{ID: 1, Name: "alice"}, {ID: 3, Name: "caroline"}}, got.Interface())
+		got, err = c.deepMerge(reflect.ValueOf([]*user(nil)), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, []*user{{ID: 3, Name: "caroline"}}, got.Interface())
+	})
+	t.Run("delete marker errors", func(t *testing.T) {
+		type user struct {
+			ID      int
+			Deleted string
+		}
+		for _, tt := range []struct {
+			field   string
+			wantErr string
+		}{
+			{"Deleted", "expecting bool delete marker field goalesce.user.Deleted, got: string"},
+			{"Removed", "struct type goalesce.user has no field named Removed"},
+		} {
+			c := newCoalescer(WithSliceDeleteMarker(reflect.TypeOf([]user{}), SliceDeleteMarkerField(tt.field)))
+			_, err := c.deepMergeSliceWithMergeKey(reflect.ValueOf([]user{{ID: 1}}), reflect.ValueOf([]user{{ID: 1}}), SliceMergeByField("ID"))
+			assert.EqualError(t, err, tt.wantErr)
+		}
+		_, err := SliceDeleteMarkerField("Deleted")(reflect.ValueOf(1))
+		assert.EqualError(t, err, "expecting struct or pointer thereto, got: int")
+	})
+	t.Run("delete directive", func(t *testing.T) {
+		type container map[string]interface{}
+		c := newCoalescer(WithPatchDirectives(), WithSliceMergeByKeyFunc(reflect.TypeOf([]container{}), func(_ int, elem reflect.Value) (reflect.Value, error) {
+			return elem.MapIndex(reflect.ValueOf("name")).Elem(), nil
+		}))
+		v1 := []container{{"name": "a", "image": "x"}, {"name": "b", "image": "y"}}
+		v2 := []container{{"name": "a", "$patch": "delete"}}
+		got, err := c.deepMerge(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, []container{{"name": "b", "image": "y"}}, got.Interface())
+	})
+}
+
+func Test_coalescer_deepMergeSliceWithListAppend_dedup(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	tests := []struct {
+		name string
+		v1   interface{}
+		v2   interface{}
+		want interface{}
+		opts []Option
+	}{
+		{
+			name: "disabled",
+			v1:   []int{1, 2, 3},
+			v2:   []int{2, 4},
+			want: []int{1, 2, 3, 2, 4},
+		},
+		{
+			name: "keep last occurrence",
+			v1:   []int{1, 2, 3},
+			v2:   []int{2, 4, 1},
+			want: []int{3, 2, 4, 1},
+			opts: []Option{WithListAppendDedup()},
+		},
+		{
+			name: "v1 nil",
+			v1:   []int(nil),
+			v2:   []int{1, 2, 1},
+			want: []int{2, 1},
+			opts: []Option{WithListAppendDedup()},
+		},
+		{
+			name: "both nil",
+			v1:   []int(nil),
+			v2:   []int(nil),
+			want: []int(nil),
+			opts: []Option{WithListAppendDedup()},
+		},
+		{
+			name: "by key",
+			v1:   []*user{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}},
+			v2:   []*user{{ID: 1, Name: "alicia"}, {ID: 3, Name: "carol"}},
+			want: []*user{{ID: 2, Name: "bob"}, {ID: 1, Name: "alicia"}, {ID: 3, Name: "carol"}},
+			opts: []Option{WithListAppendDedupByKeyFunc(reflect.TypeOf([]*user{}), SliceMergeByField("ID"))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(tt.opts...)
+			got, err := c.deepMergeSliceWithListAppend(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Interface())
+			assertNotSame(t, tt.v1, got.Interface())
+			assertNotSame(t, tt.v2, got.Interface())
+		})
+	}
+	t.Run("merge key error", func(t *testing.T) {
+		c := newCoalescer(WithListAppendDedupByKeyFunc(reflect.TypeOf([]int{}), func(int, reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, assert.AnError
+		}))
+		_, err := c.deepMergeSliceWithListAppend(reflect.ValueOf([]int{1}), reflect.ValueOf([]int{2}))
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func Test_coalescer_deepMergeSliceDifference(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	tests := []struct {
+		name string
+		v1   interface{}
+		v2   interface{}
+		want interface{}
+		opts []Option
+	}{
+		{
+			name: "v1 nil",
+			v1:   []int(nil),
+			v2:   []int{1},
+			want: []int(nil),
+			opts: []Option{WithSliceSetDifferenceMerge(reflect.TypeOf([]int{}))},
+		},
+		{
+			name: "v2 nil",
+			v1:   []int{1, 2},
+			v2:   []int(nil),
+			want: []int{1, 2},
+			opts: []Option{WithSliceSetDifferenceMerge(reflect.TypeOf([]int{}))},
+		},
+		{
+			name: "set difference",
+			v1:   []int{1, 2, 3, 2},
+			v2:   []int{2, 4},
+			want: []int{1, 3},
+			opts: []Option{WithSliceSetDifferenceMerge(reflect.TypeOf([]int{}))},
+		},
+		{
+			name: "pointers",
+			v1:   []*int{intPtr(1), intPtr(2)},
+			v2:   []*int{intPtr(2)},
+			want: []*int{intPtr(1)},
+			opts: []Option{WithSliceSetDifferenceMerge(reflect.TypeOf([]*int{}))},
+		},
+		{
+			name: "by key",
+			v1:   []user{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}},
+			v2:   []user{{ID: 1}},
+			want: []user{{ID: 2, Name: "bob"}},
+			opts: []Option{WithSliceDifferenceByKeyFunc(reflect.TypeOf([]user{}), SliceMergeByField("ID"))},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(tt.opts...)
+			got, err := c.deepMerge(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Interface())
+			assertNotSame(t, tt.v1, got.Interface())
+		})
+	}
+	t.Run("struct tags", func(t *testing.T) {
+		type overlay struct {
+			Tags  []string `goalesce:"difference"`
+			Users []*user  `goalesce:"difference:ID"`
+		}
+		v1 := overlay{Tags: []string{"a", "b"}, Users: []*user{{ID: 1, Name: "alice"}, {ID: 2, Name: "bob"}}}
+		v2 := overlay{Tags: []string{"b"}, Users: []*user{{ID: 2}}}
+		got, err := DeepMerge(v1, v2)
+		assert.NoError(t, err)
+		assert.Equal(t, overlay{Tags: []string{"a"}, Users: []*user{{ID: 1, Name: "alice"}}}, got)
+		got, err = DeepMerge(overlay{}, v2)
+		assert.NoError(t, err)
+		assert.Equal(t, overlay{}, got)
+	})
+	t.Run("invalid tags", func(t *testing.T) {
+		type notSlice struct {
+			Field int `goalesce:"difference"`
+		}
+		type missingKey struct {
+			Field []user `goalesce:"difference:"`
+		}
+		type unknownKey struct {
+			Field []user `goalesce:"difference:Email"`
+		}
+		type notStruct struct {
+			Field []int `goalesce:"difference:ID"`
+		}
+		_, err := DeepMerge(notSlice{Field: 1}, notSlice{Field: 2})
+		assert.EqualError(t, err, "at Field: field goalesce.notSlice.Field: difference strategy is only supported for slices")
+		_, err = DeepMerge(missingKey{Field: []user{{}}}, missingKey{Field: []user{{}}})
+		assert.EqualError(t, err, "at Field: field goalesce.missingKey.Field: difference strategy must be followed by a colon and the merge key")
+		_, err = DeepMerge(unknownKey{Field: []user{{}}}, unknownKey{Field: []user{{}}})
+		assert.EqualError(t, err, "at Field: field goalesce.unknownKey.Field: slice element type goalesce.user has no field named Email")
+		_, err = DeepMerge(notStruct{Field: []int{1}}, notStruct{Field: []int{1}})
+		assert.EqualError(t, err, "at Field: field goalesce.notStruct.Field: expecting slice of struct or pointer thereto, got: []int")
+	})
+}
+
+func Test_coalescer_deepMergeSliceByIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		v1      reflect.Value
+		v2      reflect.Value
+		want    reflect.Value
+		wantErr string
+		opts    []Option
+	}{
+		{
+			name: "lenient",
+			v1:   reflect.ValueOf([]int{1, 2, 3}),
+			v2:   reflect.ValueOf([]int{4}),
+			want: reflect.ValueOf([]int{4, 2, 3}),
+		},
+		{
+			name: "strict same lengths",
+			v1:   reflect.ValueOf([]int{1, 2, 3}),
+			v2:   reflect.ValueOf([]int{4, 5, 6}),
+			want: reflect.ValueOf([]int{4, 5, 6}),
+			opts: []Option{WithStrictIndexMerge()},
+		},
+		{
+			name:    "strict different lengths",
+			v1:      reflect.ValueOf([]int{1, 2, 3}),
+			v2:      reflect.ValueOf([]int{4}),
+			wantErr: "cannot merge slices of different lengths by index: 3 != 1",
+			opts:    []Option{WithStrictIndexMerge()},
+		},
+		{
+			name: "strict nil",
+			v1:   reflect.ValueOf([]int{1, 2, 3}),
+			v2:   reflect.ValueOf([]int(nil)),
+			want: reflect.ValueOf([]int{1, 2, 3}),
+			opts: []Option{WithStrictIndexMerge()},
+		},
+		{
+			name:    "strict empty",
+			v1:      reflect.ValueOf([]int{}),
+			v2:      reflect.ValueOf([]int{4}),
+			wantErr: "cannot merge slices of different lengths by index: 0 != 1",
+			opts:    []Option{WithStrictIndexMerge()},
+		},
+		{
+			name: "strict empty zero",
+			v1:   reflect.ValueOf([]int{}),
+			v2:   reflect.ValueOf([]int{4}),
+			want: reflect.ValueOf([]int{4}),
+			opts: []Option{WithStrictIndexMerge(), WithZeroEmptySliceMerge()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(tt.opts...)
+			got, err := c.deepMergeSliceByIndex(tt.v1, tt.v2)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.False(t, got.IsValid())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want.Interface(), got.Interface())
+			}
+		})
+	}
+}
+
+func Test_coalescer_deepMergeSliceWithORSet(t *testing.T) {
+	type item struct {
+		ID      string
+		Value   int
+		Version int64
+		Removed bool
+	}
+	keyFunc := newMergeByField("ID")
+	versionFunc := func(elem reflect.Value) (int64, bool, error) {
+		return elem.FieldByName("Version").Int(), elem.FieldByName("Removed").Bool(), nil
+	}
+	tests := []struct {
+		name        string
+		v1          []item
+		v2          []item
+		want        []item
+		keyFunc     SliceMergeKeyFunc
+		versionFunc SliceVersionFunc
+		wantErr     string
+		opts        []Option
+	}{
+		{
+			name: "v1 nil",
+			v1:   nil,
+			v2:   []item{{ID: "a", Version: 1}},
+			want: []item{{ID: "a", Version: 1}},
+		},
+		{
+			name: "v2 nil",
+			v1:   []item{{ID: "a", Version: 1}},
+			v2:   nil,
+			want: []item{{ID: "a", Version: 1}},
+		},
+		{
+			name: "union sorted by key",
+			v1:   []item{{ID: "c", Version: 1}, {ID: "a", Version: 1}},
+			v2:   []item{{ID: "b", Version: 1}},
+			want: []item{{ID: "a", Version: 1}, {ID: "b", Version: 1}, {ID: "c", Version: 1}},
+		},
+		{
+			name: "numeric keys sorted numerically",
+			v1:   []item{{Value: 10, Version: 1}, {Value: 2, Version: 1}},
+			v2:   []item{{Value: 9, Version: 1}, {Value: -1, Version: 1}},
+			want: []item{{Value: -1, Version: 1}, {Value: 2, Version: 1}, {Value: 9, Version: 1}, {Value: 10, Version: 1}},
+			keyFunc: func(_ int, elem reflect.Value) (reflect.Value, error) {
+				return elem.FieldByName("Value"), nil
+			},
+		},
+		{
+			name: "highest version wins",
+			v1:   []item{{ID: "a", Value: 1, Version: 2}, {ID: "b", Value: 1, Version: 1}},
+			v2:   []item{{ID: "a", Value: 2, Version: 1}, {ID: "b", Value: 2, Version: 2}},
+			want: []item{{ID: "a", Value: 1, Version: 2}, {ID: "b", Value: 2, Version: 2}},
+		},
+		{
+			name: "tombstone wins over older version",
+			v1:   []item{{ID: "a", Value: 1, Version: 1}},
+			v2:   []item{{ID: "a", Version: 2, Removed: true}},
+			want: []item{{ID: "a", Version: 2, Removed: true}},
+		},
+		{
+			name: "add wins over concurrent tombstone",
+			v1:   []item{{ID: "a", Value: 1, Version: 2}},
+			v2:   []item{{ID: "a", Version: 2, Removed: true}},
+			want: []item{{ID: "a", Value: 1, Version: 2}},
+		},
+		{
+			name: "add wins over concurrent tombstone reversed",
+			v1:   []item{{ID: "a", Version: 2, Removed: true}},
+			v2:   []item{{ID: "a", Value: 1, Version: 2}},
+			want: []item{{ID: "a", Value: 1, Version: 2}},
+		},
+		{
+			name:    "key func error",
+			v1:      []item{{ID: "a"}},
+			v2:      []item{{ID: "b"}},
+			keyFunc: newMergeByField("Unknown"),
+			wantErr: "struct type goalesce.item has no field named Unknown",
+		},
+		{
+			name: "key func nil",
+			v1:   []item{{ID: "a"}},
+			v2:   []item{{ID: "b"}},
+			keyFunc: func(int, reflect.Value) (reflect.Value, error) {
+				return reflect.Value{}, nil
+			},
+			wantErr: "slice merge key func returned nil",
+		},
+		{
+			name: "version func error",
+			v1:   []item{{ID: "a"}},
+			v2:   []item{{ID: "b"}},
+			versionFunc: func(reflect.Value) (int64, bool, error) {
+				return 0, false, errors.New("version error")
+			},
+			wantErr: "version error",
+		},
+		{
+			name:    "copy error",
+			v1:      []item{{ID: "a"}},
+			v2:      []item{{ID: "b"}},
+			wantErr: "mock DeepCopy error",
+			opts:    []Option{withMockDeepCopyError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.keyFunc == nil {
+				tt.keyFunc = keyFunc
+			}
+			if tt.versionFunc == nil {
+				tt.versionFunc = versionFunc
+			}
+			c := newCoalescer(tt.opts...)
+			got, err := c.deepMergeSliceWithORSet(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2), tt.keyFunc, tt.versionFunc)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.False(t, got.IsValid())
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got.Interface())
+				// merges must be commutative
+				reversed, err := c.deepMergeSliceWithORSet(reflect.ValueOf(tt.v2), reflect.ValueOf(tt.v1), tt.keyFunc, tt.versionFunc)
+				assert.NoError(t, err)
+				assert.Equal(t, got.Interface(), reversed.Interface())
+			}
+		})
+	}
 }
 
 func Test_coalescer_deepCopySlice(t *testing.T) {
@@ -1734,9 +2208,27 @@ func Test_coalescer_deepCopySlice(t *testing.T) {
 			v:    reflect.ValueOf([]int{1, 2, 3}),
 			want: reflect.ValueOf([]int{1, 2, 3}),
 		},
+		{
+			name: "bytes",
+			v:    reflect.ValueOf([]byte("abc")),
+			want: reflect.ValueOf([]byte("abc")),
+		},
+		{
+			name: "non plain elements",
+			v:    reflect.ValueOf([]*int{intPtr(1)}),
+			want: reflect.ValueOf([]*int{intPtr(1)}),
+		},
+		{
+			name: "plain elements with type copier",
+			v:    reflect.ValueOf([]int{1, 2, 3}),
+			want: reflect.ValueOf([]int{-1, -2, -3}),
+			opts: []Option{WithTypeCopier(reflect.TypeOf(0), func(v reflect.Value) (reflect.Value, error) {
+				return reflect.ValueOf(-v.Interface().(int)), nil
+			})},
+		},
 		{
 			name:    "error",
-			v:       reflect.ValueOf([]int{1, 2, 3}),
+			v:       reflect.ValueOf([]*int{intPtr(1)}),
 			wantErr: assert.Error,
 			opts:    []Option{withMockDeepCopyError},
 		},
@@ -1759,3 +2251,59 @@ func Test_coalescer_deepCopySlice(t *testing.T) {
 		})
 	}
 }
+
+func TestDeepMerge_byteSlices(t *testing.T) {
+	type blob struct {
+		Data json.RawMessage
+		Tags []string
+	}
+	v1 := blob{Data: json.RawMessage(`{"a":1}`), Tags: []string{"a"}}
+	v2 := blob{Data: json.RawMessage(`{"b":2}`), Tags: []string{"b"}}
+	got, err := DeepMerge(v1, v2, WithDefaultSliceListAppendMerge())
+	assert.NoError(t, err)
+	assert.Equal(t, blob{Data: v2.Data, Tags: []string{"a", "b"}}, got)
+	got, err = DeepMerge(v1, v2, WithSliceListAppendMerge(reflect.TypeOf(json.RawMessage{})))
+	assert.NoError(t, err)
+	assert.Equal(t, blob{Data: json.RawMessage(`{"a":1}{"b":2}`), Tags: []string{"b"}}, got)
+}
+
+func TestSliceMergeByMapKey(t *testing.T) {
+	type labels map[string]string
+	type name string
+	tests := []struct {
+		name    string
+		key     interface{}
+		elem    interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{"map", "name", map[string]interface{}{"name": "a", "value": 1}, "a", ""},
+		{"pointer to map", "name", &map[string]string{"name": "a"}, "a", ""},
+		{"nil pointer to map", "name", (*map[string]string)(nil), "", ""},
+		{"missing entry", "name", map[string]int{"value": 1}, 0, ""},
+		{"convertible key", "name", map[name]string{"name": "a"}, "a", ""},
+		{"named map", "name", labels{"name": "a"}, "a", ""},
+		{"not a map", "name", 123, nil, "expecting map or pointer thereto, got: int"},
+		{"inconvertible key", "name", map[int]string{1: "a"}, nil, "map key name of type string is not convertible to int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SliceMergeByMapKey(tt.key)(0, reflect.ValueOf(tt.elem))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Interface())
+		})
+	}
+	t.Run("interface elements", func(t *testing.T) {
+		elems := []interface{}{map[string]interface{}{"name": "a"}, nil}
+		got, err := SliceMergeByMapKey("name")(0, reflect.ValueOf(elems).Index(0))
+		assert.NoError(t, err)
+		assert.Equal(t, "a", got.Interface())
+		got, err = SliceMergeByMapKey("name")(1, reflect.ValueOf(elems).Index(1))
+		assert.NoError(t, err)
+		assert.Nil(t, got.Interface())
+	})
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"bytes"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// stdlibImmutableTypes are the well-known standard library types that are immutable, and whose
+// internals are unexported: they cannot be deep-copied through reflection, but can safely be
+// shared.
+var stdlibImmutableTypes = []reflect.Type{
+	timeType,
+	reflect.TypeOf(time.Duration(0)),
+	reflect.TypeOf(&time.Location{}),
+	reflect.TypeOf(netip.Addr{}),
+	reflect.TypeOf(netip.AddrPort{}),
+	reflect.TypeOf(netip.Prefix{}),
+	reflect.TypeOf(&url.Userinfo{}),
+	reflect.TypeOf(regexp.Regexp{}),
+	reflect.TypeOf(&regexp.Regexp{}),
+}
+
+var (
+	ipType  = reflect.TypeOf(net.IP{})
+	urlType = reflect.TypeOf(url.URL{})
+)
+
+// registerStdlibTypes installs the default copiers and mergers for well-known standard library
+// types. It must be called before options are applied, so that options can override them.
+func (c *coalescer) registerStdlibTypes() {
+	for _, t := range stdlibImmutableTypes {
+		c.registerImmutableType(t)
+	}
+	// net.IP is a mutable byte slice: it is merged atomically, but its bytes must be copied
+	c.typeCopiers[ipType] = deepCopyIP
+	c.typeMergers[ipType] = c.deepMergeAtomic
+	// url.URL only holds immutable values, including its *url.Userinfo field: a shallow copy is
+	// a deep copy
+	c.typeCopiers[urlType] = c.deepCopyAtomic
+	c.typeMergers[urlType] = c.deepMergeAtomic
+}
+
+func deepCopyIP(v reflect.Value) (reflect.Value, error) {
+	if v.IsNil() {
+		return v, nil
+	}
+	return reflect.ValueOf(net.IP(bytes.Clone(v.Bytes()))), nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// StrategiesDeclarer is an optional interface that struct types can implement to declare the merge
+// strategies of their fields in code, rather than with struct tags. This is useful when struct tags
+// cannot be added, e.g. to generated types, which can then be wrapped in local type definitions
+// declaring the method.
+//
+// GoalesceStrategies returns a map of field names to merge strategies, expressed as in
+// MergeStrategyTag struct tags, e.g. {"Ports": "id:Name", "Replicas": "default:1"}. The method is
+// called once per type, on a zero value, or on a pointer to a zero value if it has a pointer
+// receiver. Struct tags, when present, take precedence.
+type StrategiesDeclarer interface {
+	GoalesceStrategies() map[string]string
+}
+
+var strategiesDeclarerType = reflect.TypeOf((*StrategiesDeclarer)(nil)).Elem()
+
+// declaredStrategies caches the strategies declared by struct types implementing StrategiesDeclarer.
+var declaredStrategies sync.Map // map[reflect.Type]map[string]string
+
+// fieldStrategies holds the merge strategies of the fields of a struct type, indexed by field
+// index, as returned by fieldStrategy.
+type fieldStrategies struct {
+	strategies []string
+	declared   []bool
+	// any is true if a strategy is declared for at least one exported field.
+	any bool
+	// excludes is true if at least one field is excluded with MergeStrategyExclude.
+	excludes bool
+	// copies holds the copy strategies of the fields, without their CopyStrategyPrefix, or empty
+	// strings for fields without copy strategy, as returned by fieldCopyStrategy.
+	copies []string
+	// anyCopy is true if a copy strategy is declared for at least one exported field.
+	anyCopy bool
+	// keys holds the names of the exported fields declared with MergeStrategyKey, in field order.
+	keys []string
+}
+
+// parsedStrategies caches the merge strategies of struct types, so that repeated merges of the
+// same types, even with different coalescers, don't pay the cost of parsing struct tags again.
+var parsedStrategies sync.Map // map[reflect.Type]*fieldStrategies
+
+// fieldStrategy returns the merge strategy of the given field, as declared in its struct tag, or
+// by its struct type through StrategiesDeclarer.
+func fieldStrategy(structType reflect.Type, field reflect.StructField) (string, bool) {
+	if len(field.Index) != 1 {
+		// promoted fields are not fields of the struct type itself
+		return lookupFieldStrategy(structType, field)
+	}
+	strategies := strategiesFor(structType)
+	i := field.Index[0]
+	return strategies.strategies[i], strategies.declared[i]
+}
+
+// strategiesFor returns the merge strategies of the fields of the given struct type.
+func strategiesFor(structType reflect.Type) *fieldStrategies {
+	if strategies, found := parsedStrategies.Load(structType); found {
+		return strategies.(*fieldStrategies)
+	}
+	strategies := &fieldStrategies{
+		strategies: make([]string, structType.NumField()),
+		declared:   make([]bool, structType.NumField()),
+		copies:     make([]string, structType.NumField()),
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		strategies.strategies[i], strategies.declared[i] = lookupFieldStrategy(structType, field)
+		if strategies.copies[i] = lookupFieldCopyStrategy(structType, field); strategies.copies[i] != "" && field.IsExported() {
+			strategies.anyCopy = true
+		}
+		if strategies.declared[i] && field.IsExported() {
+			strategies.any = true
+		}
+		if strategies.declared[i] && strategies.strategies[i] == MergeStrategyExclude {
+			strategies.excludes = true
+		}
+		if strategies.declared[i] && strategies.strategies[i] == MergeStrategyKey && field.IsExported() {
+			strategies.keys = append(strategies.keys, field.Name)
+		}
+	}
+	parsedStrategies.Store(structType, strategies)
+	return strategies
+}
+
+// excludedField returns true if the i-th field of the given struct type is excluded with
+// MergeStrategyExclude.
+func excludedField(structType reflect.Type, i int) bool {
+	strategies := strategiesFor(structType)
+	return strategies.declared[i] && strategies.strategies[i] == MergeStrategyExclude
+}
+
+// elemMergeKey returns the merge key declared with MergeStrategyKey by the element type of the given
+// slice type, if that type is a struct type, or a pointer thereto. Composite keys are joined with
+// MergeKeySeparator.
+func elemMergeKey(sliceType reflect.Type) (string, bool) {
+	elemType := indirect(sliceType.Elem())
+	if elemType.Kind() != reflect.Struct {
+		return "", false
+	}
+	keys := strategiesFor(elemType).keys
+	return strings.Join(keys, MergeKeySeparator), len(keys) > 0
+}
+
+// fieldCopyStrategy returns the copy strategy of the i-th field of the given struct type, without
+// its CopyStrategyPrefix, or an empty string if the field has no copy strategy.
+func fieldCopyStrategy(structType reflect.Type, i int) string {
+	return strategiesFor(structType).copies[i]
+}
+
+func lookupFieldStrategy(structType reflect.Type, field reflect.StructField) (string, bool) {
+	tag, found := lookupFieldTag(structType, field)
+	if !found {
+		return "", false
+	}
+	strategy, _ := splitCopyStrategy(tag)
+	return strategy, strategy != ""
+}
+
+func lookupFieldCopyStrategy(structType reflect.Type, field reflect.StructField) string {
+	tag, _ := lookupFieldTag(structType, field)
+	_, copyStrategy := splitCopyStrategy(tag)
+	return copyStrategy
+}
+
+func lookupFieldTag(structType reflect.Type, field reflect.StructField) (string, bool) {
+	if tag, found := field.Tag.Lookup(MergeStrategyTag); found {
+		return tag, true
+	}
+	tag, found := strategiesOf(structType)[field.Name]
+	return tag, found
+}
+
+// splitCopyStrategy splits the given tag into its merge strategy and its copy strategy, e.g.
+// "union,copy:atomic" into "union" and "atomic". The copy strategy must come last; merge
+// strategies may contain commas themselves, e.g. "default:a,b".
+func splitCopyStrategy(tag string) (mergeStrategy, copyStrategy string) {
+	if strings.HasPrefix(tag, CopyStrategyPrefix) {
+		return "", strings.TrimPrefix(tag, CopyStrategyPrefix)
+	}
+	if i := strings.LastIndex(tag, ","+CopyStrategyPrefix); i >= 0 {
+		return tag[:i], tag[i+len(CopyStrategyPrefix)+1:]
+	}
+	return tag, ""
+}
+
+func strategiesOf(structType reflect.Type) map[string]string {
+	if strategies, found := declaredStrategies.Load(structType); found {
+		return strategies.(map[string]string)
+	}
+	var strategies map[string]string
+	if structType.Implements(strategiesDeclarerType) {
+		strategies = reflect.Zero(structType).Interface().(StrategiesDeclarer).GoalesceStrategies()
+	} else if reflect.PointerTo(structType).Implements(strategiesDeclarerType) {
+		strategies = reflect.New(structType).Interface().(StrategiesDeclarer).GoalesceStrategies()
+	}
+	declaredStrategies.Store(structType, strategies)
+	return strategies
+}
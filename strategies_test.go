@@ -0,0 +1,146 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type declaredPort struct {
+	Name string
+	Port int
+}
+
+type declaredService struct {
+	Ports    []declaredPort
+	Tags     []string
+	Replicas int
+	Owner    string `goalesce:"keepfirst"`
+}
+
+func (declaredService) GoalesceStrategies() map[string]string {
+	return map[string]string{
+		"Ports":    "id:Name",
+		"Tags":     "union",
+		"Replicas": "default:1",
+		"Owner":    "atomic", // overridden by the struct tag
+	}
+}
+
+type declaredPointerService struct {
+	Tags []string
+}
+
+func (*declaredPointerService) GoalesceStrategies() map[string]string {
+	return map[string]string{"Tags": "append"}
+}
+
+func Test_fieldStrategy(t *testing.T) {
+	t.Run("value receiver", func(t *testing.T) {
+		got, err := DeepMerge(
+			declaredService{Ports: []declaredPort{{"http", 80}}, Tags: []string{"a", "b"}, Owner: "alice"},
+			declaredService{Ports: []declaredPort{{"http", 8080}, {"https", 443}}, Tags: []string{"b", "c"}, Owner: "bob"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, declaredService{
+			Ports:    []declaredPort{{"http", 8080}, {"https", 443}},
+			Tags:     []string{"a", "b", "c"},
+			Replicas: 1,
+			Owner:    "alice",
+		}, got)
+	})
+	t.Run("pointer receiver", func(t *testing.T) {
+		got, err := DeepMerge(declaredPointerService{Tags: []string{"a"}}, declaredPointerService{Tags: []string{"a"}})
+		require.NoError(t, err)
+		assert.Equal(t, declaredPointerService{Tags: []string{"a", "a"}}, got)
+	})
+	t.Run("no declarer", func(t *testing.T) {
+		strategy, found := fieldStrategy(reflect.TypeOf(declaredPort{}), reflect.TypeOf(declaredPort{}).Field(0))
+		assert.False(t, found)
+		assert.Empty(t, strategy)
+	})
+	t.Run("describe", func(t *testing.T) {
+		plan, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(declaredService{})})
+		require.NoError(t, err)
+		assert.Equal(t, FieldStrategy{Struct: "goalesce.declaredService", Field: "Ports", Type: "[]goalesce.declaredPort", Strategy: MergeStrategyID, MergeKey: "Name", Tag: "id:Name"}, plan[0])
+		assert.Equal(t, FieldStrategy{Struct: "goalesce.declaredService", Field: "Replicas", Type: "int", Strategy: MergeStrategyAtomic, Default: "1", Tag: "default:1"}, plan[2])
+		assert.Equal(t, "keepfirst", plan[3].Tag)
+	})
+}
+
+func Test_strategiesFor(t *testing.T) {
+	type tagged struct {
+		Plain  int
+		Tagged []int `goalesce:"append"`
+	}
+	type untagged struct {
+		Plain int
+		unexp []int `goalesce:"append"`
+	}
+	strategies := strategiesFor(reflect.TypeOf(tagged{}))
+	assert.Same(t, strategies, strategiesFor(reflect.TypeOf(tagged{})))
+	assert.Equal(t, &fieldStrategies{strategies: []string{"", "append"}, declared: []bool{false, true}, any: true, copies: []string{"", ""}}, strategies)
+	type copyTagged struct {
+		Shared  []int `goalesce:"union,copy:atomic"`
+		Skipped int   `goalesce:"copy:skip"`
+		Default []int `goalesce:"default:1,2"`
+	}
+	assert.Equal(t, &fieldStrategies{
+		strategies: []string{"union", "", "default:1,2"},
+		declared:   []bool{true, false, true},
+		any:        true,
+		copies:     []string{"atomic", "skip", ""},
+		anyCopy:    true,
+	}, strategiesFor(reflect.TypeOf(copyTagged{})))
+	assert.False(t, strategiesFor(reflect.TypeOf(untagged{})).any)
+	assert.True(t, strategiesFor(reflect.TypeOf(declaredService{})).any)
+	assert.Equal(t, "id:Name", strategiesFor(reflect.TypeOf(declaredService{})).strategies[0])
+	type keyed struct {
+		Namespace string `goalesce:"key"`
+		Name      string `goalesce:"key"`
+		unexp     string `goalesce:"key"`
+	}
+	assert.Equal(t, []string{"Namespace", "Name"}, strategiesFor(reflect.TypeOf(keyed{})).keys)
+}
+
+func Test_elemMergeKey(t *testing.T) {
+	type keyed struct {
+		ID   int `goalesce:"key"`
+		Name string
+	}
+	type compositeKeyed struct {
+		Namespace string `goalesce:"key"`
+		Name      string `goalesce:"key"`
+	}
+	for _, tt := range []struct {
+		sliceType reflect.Type
+		want      string
+		found     bool
+	}{
+		{reflect.TypeOf([]keyed{}), "ID", true},
+		{reflect.TypeOf([]*keyed{}), "ID", true},
+		{reflect.TypeOf([]compositeKeyed{}), "Namespace+Name", true},
+		{reflect.TypeOf([]int{}), "", false},
+		{reflect.TypeOf([]struct{ ID int }{}), "", false},
+	} {
+		got, found := elemMergeKey(tt.sliceType)
+		assert.Equal(t, tt.want, got, tt.sliceType.String())
+		assert.Equal(t, tt.found, found, tt.sliceType.String())
+	}
+}
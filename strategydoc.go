@@ -0,0 +1,302 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"reflect"
+	"strings"
+)
+
+const (
+	// StrategyFormatMarkdown renders merge strategies as Markdown tables.
+	StrategyFormatMarkdown = "markdown"
+	// StrategyFormatHTML renders merge strategies as HTML tables.
+	StrategyFormatHTML = "html"
+)
+
+// Strategies that are not declared in struct tags, as reported by DescribeMergeStrategies.
+const (
+	// MergeStrategyDeep is the default strategy of structs, maps, pointers and interfaces: values are
+	// merged recursively.
+	MergeStrategyDeep = "deep"
+	// MergeStrategyCustom is reported for fields merged by custom mergers.
+	MergeStrategyCustom = "custom"
+)
+
+// FieldStrategy describes the effective merge strategy of a struct field.
+type FieldStrategy struct {
+	// Struct is the name of the struct type declaring the field.
+	Struct string `json:"struct"`
+	// Field is the name of the field.
+	Field string `json:"field"`
+	// Type is the name of the field type.
+	Type string `json:"type"`
+	// Strategy is the name of the effective merge strategy, e.g. MergeStrategyAppend.
+	Strategy string `json:"strategy"`
+	// MergeKey is the merge key of the MergeStrategyID strategy, if any.
+	MergeKey string `json:"mergeKey,omitempty"`
+	// Default is the default value of the field, if any.
+	Default string `json:"default,omitempty"`
+	// Tag is the raw merge strategy of the field, as declared in its MergeStrategyTag struct tag, or
+	// by its struct type through StrategiesDeclarer, if any.
+	Tag string `json:"tag,omitempty"`
+}
+
+// MergePlan is a snapshot of the merge strategies of some types, as returned by
+// DescribeMergeStrategies. It can be serialized, e.g. to JSON, and compared with another snapshot
+// with Diff. This allows to detect unintended changes of merge semantics, e.g. in tests.
+type MergePlan []FieldStrategy
+
+// Diff returns the differences between this plan and the other plan. Each change is reported at
+// the path of the field, e.g. "api.Deployment.Replicas", with the field's FieldStrategy as old and
+// new values. Changes are reported in the order of this plan, followed by the fields added in the
+// other plan.
+func (p MergePlan) Diff(other MergePlan) Diff {
+	path := func(s FieldStrategy) string {
+		return s.Struct + "." + s.Field
+	}
+	index := make(map[string]FieldStrategy, len(other))
+	for _, s := range other {
+		index[path(s)] = s
+	}
+	var diff Diff
+	seen := make(map[string]bool, len(p))
+	for _, s := range p {
+		seen[path(s)] = true
+		if o, found := index[path(s)]; !found {
+			diff = append(diff, Change{Path: path(s), Kind: ChangeRemoved, From: s})
+		} else if o != s {
+			diff = append(diff, Change{Path: path(s), Kind: ChangeModified, From: s, To: o})
+		}
+	}
+	for _, o := range other {
+		if !seen[path(o)] {
+			diff = append(diff, Change{Path: path(o), Kind: ChangeAdded, To: o})
+		}
+	}
+	return diff
+}
+
+// ParseMergeStrategyTag splits the value of a MergeStrategyTag struct tag into a strategy name and
+// its argument, if any: for example, "id:Name" is split into MergeStrategyID and "Name".
+func ParseMergeStrategyTag(tag string) (strategy, argument string) {
+	strategy, argument, _ = strings.Cut(tag, ":")
+	return strategy, argument
+}
+
+// DescribeMergeStrategies describes the effective merge strategy of every exported field of the
+// given struct types, and of the struct types reachable from them through fields, pointers, slices,
+// arrays and maps. The given options are taken into account, e.g. fields with a merger registered
+// through WithFieldMerger are reported with MergeStrategyCustom. Fields are returned grouped by
+// struct type, in order of discovery. An error is returned if a struct tag is invalid.
+func DescribeMergeStrategies(roots []reflect.Type, opts ...Option) (MergePlan, error) {
+	c := newCoalescer(opts...)
+	var strategies MergePlan
+	visited := make(map[reflect.Type]bool)
+	var visit func(t reflect.Type) error
+	visit = func(t reflect.Type) error {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			return visit(t.Elem())
+		case reflect.Struct:
+		default:
+			return nil
+		}
+		if visited[t] || t == timeType || t == syncMapType || isSyncAtomicType(t) {
+			return nil
+		}
+		visited[t] = true
+		if err := c.checkFieldNames(t); err != nil {
+			return err
+		}
+		var nested []reflect.Type
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			strategy, err := c.describeField(t, field)
+			if err != nil {
+				return err
+			}
+			strategies = append(strategies, strategy)
+			nested = append(nested, field.Type)
+		}
+		for _, n := range nested {
+			if err := visit(n); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, root := range roots {
+		if err := visit(root); err != nil {
+			return nil, err
+		}
+	}
+	return strategies, nil
+}
+
+func (c *coalescer) describeField(structType reflect.Type, field reflect.StructField) (FieldStrategy, error) {
+	tagMerger, err := c.fieldMergerFromTag(structType, field)
+	if err != nil {
+		return FieldStrategy{}, err
+	}
+	strategy := FieldStrategy{
+		Struct: structType.String(),
+		Field:  field.Name,
+		Type:   field.Type.String(),
+	}
+	strategy.Tag, _ = fieldStrategy(structType, field)
+	if defaultValue, found := c.fieldDefaults[structType][field.Name]; found {
+		strategy.Default = fmt.Sprint(defaultValue.Interface())
+	} else if defaultValue, found := defaultFromTag(structType, field); found {
+		strategy.Default = defaultValue
+	}
+	// tagMerger is nil when there is no tag, or when the tag is unknown and ignored (WithLenientTags)
+	sliceStrategy, _, _ := cutElemStrategy(strategy.Tag)
+	if name, argument := ParseMergeStrategyTag(sliceStrategy); tagMerger != nil && name != MergeStrategyDefault && name != MergeStrategyKey {
+		strategy.Strategy = name
+		if name == MergeStrategyID {
+			strategy.MergeKey = argument
+		}
+		return strategy, nil
+	}
+	strategy.Strategy = c.typeStrategy(structType, field)
+	if strategy.Strategy == MergeStrategyID {
+		strategy.MergeKey, _ = elemMergeKey(field.Type)
+	}
+	return strategy, nil
+}
+
+// typeStrategy returns the name of the strategy used to merge a field without a strategy tag.
+func (c *coalescer) typeStrategy(structType reflect.Type, field reflect.StructField) string {
+	if _, found := c.fieldMergers[structType][field.Name]; found {
+		return MergeStrategyCustom
+	} else if merger, found := c.typeMerger(field.Type); found {
+		// types merged atomically by default, e.g. time.Time, are registered with deepMergeAtomic
+		if reflect.ValueOf(merger).Pointer() == reflect.ValueOf(c.deepMergeAtomic).Pointer() {
+			return MergeStrategyAtomic
+		}
+		return MergeStrategyCustom
+	} else if _, found := c.kindMergers[field.Type.Kind()]; found {
+		return MergeStrategyCustom
+	}
+	switch field.Type.Kind() {
+	case reflect.Slice:
+		if _, found := c.sliceMergers[field.Type]; found {
+			return MergeStrategyCustom
+		} else if _, found := elemMergeKey(field.Type); found {
+			return MergeStrategyID
+		} else if c.sliceMerger != nil {
+			return MergeStrategyCustom
+		}
+		return MergeStrategyAtomic
+	case reflect.Array:
+		if _, found := c.arrayMergers[field.Type]; found || c.arrayMerger != nil {
+			return MergeStrategyCustom
+		}
+		return MergeStrategyAtomic
+	case reflect.Struct:
+		if field.Type == timeType || isSyncAtomicType(field.Type) {
+			return MergeStrategyAtomic
+		}
+		return MergeStrategyDeep
+	case reflect.Map, reflect.Ptr, reflect.Interface:
+		return MergeStrategyDeep
+	}
+	return MergeStrategyAtomic
+}
+
+// RenderMergeStrategies renders the given field strategies, as returned by
+// DescribeMergeStrategies, as a reference document in the given format: StrategyFormatMarkdown or
+// StrategyFormatHTML. The document contains one table per struct type.
+func RenderMergeStrategies(w io.Writer, strategies []FieldStrategy, format string) error {
+	var render func(w io.Writer, structType string, fields []FieldStrategy) error
+	switch format {
+	case StrategyFormatMarkdown:
+		render = renderStrategiesMarkdown
+	case StrategyFormatHTML:
+		render = renderStrategiesHTML
+	default:
+		return fmt.Errorf("unknown format: %s", format)
+	}
+	for start := 0; start < len(strategies); {
+		end := start + 1
+		for end < len(strategies) && strategies[end].Struct == strategies[start].Struct {
+			end++
+		}
+		if err := render(w, strategies[start].Struct, strategies[start:end]); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+var strategyColumns = []string{"Field", "Type", "Strategy", "Merge key", "Default", "Tag"}
+
+func strategyCells(s FieldStrategy) []string {
+	return []string{s.Field, s.Type, s.Strategy, s.MergeKey, s.Default, s.Tag}
+}
+
+func renderStrategiesMarkdown(w io.Writer, structType string, fields []FieldStrategy) error {
+	escape := func(s string) string {
+		if s == "" {
+			return ""
+		}
+		return "`" + strings.ReplaceAll(s, "|", `\|`) + "`"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## %s\n\n", structType)
+	fmt.Fprintf(&sb, "| %s |\n", strings.Join(strategyColumns, " | "))
+	fmt.Fprintf(&sb, "|%s\n", strings.Repeat(" --- |", len(strategyColumns)))
+	for _, field := range fields {
+		cells := strategyCells(field)
+		for i := range cells {
+			cells[i] = escape(cells[i])
+		}
+		fmt.Fprintf(&sb, "| %s |\n", strings.Join(cells, " | "))
+	}
+	sb.WriteString("\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func renderStrategiesHTML(w io.Writer, structType string, fields []FieldStrategy) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<h2>%s</h2>\n<table>\n<tr>", html.EscapeString(structType))
+	for _, column := range strategyColumns {
+		fmt.Fprintf(&sb, "<th>%s</th>", column)
+	}
+	sb.WriteString("</tr>\n")
+	for _, field := range fields {
+		sb.WriteString("<tr>")
+		for _, cell := range strategyCells(field) {
+			if cell == "" {
+				sb.WriteString("<td></td>")
+			} else {
+				fmt.Fprintf(&sb, "<td><code>%s</code></td>", html.EscapeString(cell))
+			}
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</table>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type docContainer struct {
+	Name  string
+	Ports []int `goalesce:"union"`
+}
+
+type docDeployment struct {
+	Name       string         `goalesce:"immutable"`
+	Replicas   *int           `goalesce:"default:1"`
+	Containers []docContainer `goalesce:"id:Name"`
+	Labels     map[string]string
+	Created    time.Time
+	Tags       []string
+	Owner      string
+	Sidecars   map[string]*docContainer
+	internal   string
+}
+
+func TestParseMergeStrategyTag(t *testing.T) {
+	for tag, want := range map[string][2]string{
+		"":             {"", ""},
+		"append":       {"append", ""},
+		"id:Name":      {"id", "Name"},
+		"default:a:b":  {"default", "a:b"},
+		"default:":     {"default", ""},
+		"unknown:what": {"unknown", "what"},
+	} {
+		strategy, argument := ParseMergeStrategyTag(tag)
+		assert.Equal(t, want, [2]string{strategy, argument}, tag)
+	}
+}
+
+func TestDescribeMergeStrategies(t *testing.T) {
+	t.Run("default options", func(t *testing.T) {
+		got, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(&docDeployment{})})
+		require.NoError(t, err)
+		assert.Equal(t, MergePlan{
+			{Struct: "goalesce.docDeployment", Field: "Name", Type: "string", Strategy: "immutable", Tag: "immutable"},
+			{Struct: "goalesce.docDeployment", Field: "Replicas", Type: "*int", Strategy: "deep", Default: "1", Tag: "default:1"},
+			{Struct: "goalesce.docDeployment", Field: "Containers", Type: "[]goalesce.docContainer", Strategy: "id", MergeKey: "Name", Tag: "id:Name"},
+			{Struct: "goalesce.docDeployment", Field: "Labels", Type: "map[string]string", Strategy: "deep"},
+			{Struct: "goalesce.docDeployment", Field: "Created", Type: "time.Time", Strategy: "atomic"},
+			{Struct: "goalesce.docDeployment", Field: "Tags", Type: "[]string", Strategy: "atomic"},
+			{Struct: "goalesce.docDeployment", Field: "Owner", Type: "string", Strategy: "atomic"},
+			{Struct: "goalesce.docDeployment", Field: "Sidecars", Type: "map[string]*goalesce.docContainer", Strategy: "deep"},
+			{Struct: "goalesce.docContainer", Field: "Name", Type: "string", Strategy: "atomic"},
+			{Struct: "goalesce.docContainer", Field: "Ports", Type: "[]int", Strategy: "union", Tag: "union"},
+		}, got)
+	})
+	t.Run("with options", func(t *testing.T) {
+		deploymentType := reflect.TypeOf(docDeployment{})
+		got, err := DescribeMergeStrategies([]reflect.Type{deploymentType},
+			WithFieldMerger(deploymentType, "Owner", func(v1, v2 reflect.Value) (reflect.Value, error) { return v1, nil }),
+			WithDefaultSliceListAppendMerge(),
+			WithFieldDefault(deploymentType, "Replicas", intPtr(3)),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "custom", got[5].Strategy) // Tags
+		assert.Equal(t, "custom", got[6].Strategy) // Owner
+		assert.Equal(t, "deep", got[1].Strategy)   // Replicas
+		assert.NotEmpty(t, got[1].Default)
+	})
+	t.Run("invalid tag", func(t *testing.T) {
+		type invalid struct {
+			Name string `goalesce:"append"`
+		}
+		_, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(invalid{})})
+		assert.EqualError(t, err, "field goalesce.invalid.Name: append strategy is only supported for slices")
+	})
+	t.Run("lenient tags", func(t *testing.T) {
+		type lenient struct {
+			Name string `goalesce:"unknown"`
+		}
+		got, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(lenient{})}, WithLenientTags(nil))
+		require.NoError(t, err)
+		assert.Equal(t, MergePlan{{Struct: "goalesce.lenient", Field: "Name", Type: "string", Strategy: "atomic", Tag: "unknown"}}, got)
+	})
+}
+
+func TestRenderMergeStrategies(t *testing.T) {
+	strategies := []FieldStrategy{
+		{Struct: "api.A", Field: "Items", Type: "[]api.B", Strategy: "id", MergeKey: "Name", Tag: "id:Name"},
+		{Struct: "api.A", Field: "Or", Type: "string", Strategy: "atomic", Default: "a|b"},
+		{Struct: "api.B", Field: "Name", Type: "map[string]<T>", Strategy: "deep"},
+	}
+	t.Run("markdown", func(t *testing.T) {
+		var sb strings.Builder
+		require.NoError(t, RenderMergeStrategies(&sb, strategies, StrategyFormatMarkdown))
+		assert.Equal(t, "## api.A\n\n"+
+			"| Field | Type | Strategy | Merge key | Default | Tag |\n"+
+			"| --- | --- | --- | --- | --- | --- |\n"+
+			"| `Items` | `[]api.B` | `id` | `Name` |  | `id:Name` |\n"+
+			"| `Or` | `string` | `atomic` |  | `a\\|b` |  |\n"+
+			"\n"+
+			"## api.B\n\n"+
+			"| Field | Type | Strategy | Merge key | Default | Tag |\n"+
+			"| --- | --- | --- | --- | --- | --- |\n"+
+			"| `Name` | `map[string]<T>` | `deep` |  |  |  |\n"+
+			"\n", sb.String())
+	})
+	t.Run("html", func(t *testing.T) {
+		var sb strings.Builder
+		require.NoError(t, RenderMergeStrategies(&sb, strategies[2:], StrategyFormatHTML))
+		assert.Equal(t, "<h2>api.B</h2>\n<table>\n"+
+			"<tr><th>Field</th><th>Type</th><th>Strategy</th><th>Merge key</th><th>Default</th><th>Tag</th></tr>\n"+
+			"<tr><td><code>Name</code></td><td><code>map[string]&lt;T&gt;</code></td><td><code>deep</code></td><td></td><td></td><td></td></tr>\n"+
+			"</table>\n", sb.String())
+	})
+	t.Run("unknown format", func(t *testing.T) {
+		assert.EqualError(t, RenderMergeStrategies(&strings.Builder{}, strategies, "pdf"), "unknown format: pdf")
+	})
+}
+
+func TestMergePlan_Diff(t *testing.T) {
+	type deployment struct {
+		Name  string
+		Tags  []string `goalesce:"union"`
+		Ports []int
+	}
+	type refactored struct {
+		Name     string
+		Tags     []string
+		Replicas int `goalesce:"default:1"`
+	}
+	before, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(deployment{})})
+	require.NoError(t, err)
+	after, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(refactored{})})
+	require.NoError(t, err)
+	// simulate a refactoring that keeps the type name
+	for i := range after {
+		after[i].Struct = before[0].Struct
+	}
+	t.Run("round trip", func(t *testing.T) {
+		data, err := json.Marshal(before)
+		require.NoError(t, err)
+		var decoded MergePlan
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Empty(t, before.Diff(decoded))
+	})
+	t.Run("changes", func(t *testing.T) {
+		assert.Equal(t, Diff{
+			{
+				Path: "goalesce.deployment.Tags",
+				Kind: ChangeModified,
+				From: FieldStrategy{Struct: "goalesce.deployment", Field: "Tags", Type: "[]string", Strategy: "union", Tag: "union"},
+				To:   FieldStrategy{Struct: "goalesce.deployment", Field: "Tags", Type: "[]string", Strategy: "atomic"},
+			},
+			{
+				Path: "goalesce.deployment.Ports",
+				Kind: ChangeRemoved,
+				From: FieldStrategy{Struct: "goalesce.deployment", Field: "Ports", Type: "[]int", Strategy: "atomic"},
+			},
+			{
+				Path: "goalesce.deployment.Replicas",
+				Kind: ChangeAdded,
+				To:   FieldStrategy{Struct: "goalesce.deployment", Field: "Replicas", Type: "int", Strategy: "atomic", Default: "1", Tag: "default:1"},
+			},
+		}, before.Diff(after))
+	})
+}
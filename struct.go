@@ -15,6 +15,7 @@
 package goalesce
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -34,172 +35,533 @@ const (
 	MergeStrategyIndex = "index"
 	// MergeStrategyID applies "merge-by-id" semantics.
 	MergeStrategyID = "id"
+	// MergeStrategyDifference applies "set-difference" semantics: elements of the second slice are
+	// removed from the first slice. It can be followed by a colon and the name of a merge key field,
+	// e.g. "difference:ID"; otherwise, elements are compared as with MergeStrategyUnion.
+	MergeStrategyDifference = "difference"
+	// MergeStrategyLatest selects the later of two timestamps. When followed by a colon and the name
+	// of a timestamp field, e.g. "latest:UpdatedAt", it selects the struct with the later timestamp
+	// instead, atomically.
+	MergeStrategyLatest = "latest"
+	// MergeStrategyEarliest selects the earlier of two timestamps. When followed by a colon and the
+	// name of a timestamp field, it selects the struct with the earlier timestamp instead, atomically.
+	MergeStrategyEarliest = "earliest"
+	// MergeStrategySemverMax selects the highest of two semantic versions.
+	MergeStrategySemverMax = "semverMax"
+	// MergeStrategyBitwiseOr combines two integer bit flags with a bitwise OR.
+	MergeStrategyBitwiseOr = "bitor"
+	// MergeStrategySum adds two numbers.
+	MergeStrategySum = "sum"
+	// MergeStrategyMin selects the smaller of two numbers.
+	MergeStrategyMin = "min"
+	// MergeStrategyMax selects the larger of two numbers.
+	MergeStrategyMax = "max"
+	// MergeStrategyOneOf applies default merge semantics, and checks that the merged value is one of
+	// the allowed values. It must be followed by a colon and the allowed values, separated by pipes.
+	MergeStrategyOneOf = "oneof"
+	// MergeStrategyDefault applies default merge semantics, and declares a default value to use when
+	// the merged value is zero. It must be followed by a colon and the default value.
+	MergeStrategyDefault = "default"
+	// MergeStrategyImmutable forbids modifying a non-zero value.
+	MergeStrategyImmutable = "immutable"
+	// MergeStrategyKeepFirst applies "keep-first" semantics.
+	MergeStrategyKeepFirst = "keepfirst"
+	// MergeStrategyKeep is a shorter alias for MergeStrategyKeepFirst.
+	MergeStrategyKeep = "keep"
+	// MergeStrategyMustMatch fails the merge with a *ConflictError when two non-zero values are not
+	// deeply equal.
+	MergeStrategyMustMatch = "mustmatch"
+	// MergeStrategyKey declares the field as the merge key of its struct type: slices of that struct
+	// type, or of pointers thereto, are then merged by key, without per-slice options or parent
+	// struct tags. If several fields are declared as keys, the merge key is a composite key made of
+	// all of them. The field itself is merged with default merge semantics.
+	MergeStrategyKey = "key"
+	// MergeStrategyExclude excludes the field from both merges and copies: it is always zero in the
+	// result.
+	MergeStrategyExclude = "-"
+)
+
+// CopyStrategyPrefix introduces the copy strategy of a field in its MergeStrategyTag struct tag,
+// after its merge strategy, if any, e.g. `goalesce:"copy:atomic"` or `goalesce:"union,copy:atomic"`.
+// Copy strategies are independent of the field's merge strategy: they apply when the struct is
+// copied as a whole, e.g. by DeepCopy, and also when a merge copies the field's value instead of
+// merging it, e.g. when one of the values is zero, or when building a set-union.
+const CopyStrategyPrefix = "copy:"
+
+// ElemStrategyPrefix introduces the merge strategy of the elements of a slice field in its
+// MergeStrategyTag struct tag, after the merge strategy of the slice itself, which must be
+// MergeStrategyUnion, MergeStrategyIndex or MergeStrategyID, e.g. `goalesce:"id:Name,elem=atomic"`.
+// Matching elements are then merged with the element strategy, instead of the default merge. Element
+// strategies can be nested, e.g. `goalesce:"index,elem=index,elem=atomic"` for a [][][]int field.
+const ElemStrategyPrefix = "elem="
+
+// MergeKeySeparator separates the fields of a composite merge key in a MergeStrategyID struct tag,
+// e.g. `goalesce:"id:Namespace+Name"`: slice elements are then matched when all these fields are
+// equal.
+const MergeKeySeparator = "+"
+
+const (
+	// CopyStrategyAtomic copies the field with atomic semantics: the value is shared, not deep-copied.
+	CopyStrategyAtomic = "atomic"
+	// CopyStrategySkip skips the field when copying: it is zero in the copy.
+	CopyStrategySkip = "skip"
 )
 
 func (c *coalescer) deepMergeStruct(v1, v2 reflect.Value) (reflect.Value, error) {
-	// don't fallback to deepCopy if we have custom field mergers
-	if value, done := checkZero(v1, v2); done && !c.hasFieldMergers(v1.Type()) {
+	if isSyncAtomicType(v1.Type()) {
+		return c.deepMergeSyncAtomic(v1, v2)
+	} else if v1.Type() == syncMapType {
+		return c.deepMergeSyncMap(v1, v2)
+	} else if c.sharesUnexportedFields(v1.Type()) {
+		return c.deepMergeAtomic(v1, v2)
+	}
+	plan := c.structPlan(v1.Type())
+	if plan.err != nil {
+		return reflect.Value{}, plan.err
+	}
+	// don't fallback to deepCopy if we have custom field mergers, field defaults, field path
+	// mergers, path mergers, field hooks or a field allowlist, or if field permissions must be
+	// checked
+	exitScope := c.enterFieldPathScope(v1.Type())
+	defer exitScope()
+	if value, done := checkZero(v1, v2); done && plan.shortcut && len(c.fieldHooks) == 0 && !c.mustCheckPermissions(v2) && !c.hasFieldPathMergersUnder() && !c.hasPathMergersUnder() {
+		c.traceZeroShortcut(v1)
 		return c.deepCopy(value)
 	}
 	merged := reflect.New(v1.Type()).Elem()
-	for i := 0; i < v1.NumField(); i++ {
-		field := v1.Type().Field(i)
-		if field.IsExported() {
-			if fieldMerger, err := c.fieldMerger(v1.Type(), field); err != nil {
-				return reflect.Value{}, err
-			} else if mergedField, err := fieldMerger(v1.Field(i), v2.Field(i)); err != nil {
-				return reflect.Value{}, err
+	parent := c.path
+	defer func() { c.path = parent }()
+	for i := range plan.fields {
+		fp := &plan.fields[i]
+		field := fp.field
+		if fp.excluded {
+			continue
+		} else if field.IsExported() {
+			c.path = fieldPath(parent, field.Name)
+			if plan.allowlist != nil && !plan.allowlist[field.Name] {
+				// fields not in the allowlist are retained from v1
+				copiedField, err := c.copyField(v1, i)
+				if err != nil {
+					return reflect.Value{}, err
+				} else if copiedField.IsValid() {
+					merged.Field(i).Set(copiedField)
+				}
+			} else if mergedField, err := c.mergeField(v1.Type(), fp, v1.Field(i), v2.Field(i)); err != nil {
+				if err = c.accumulate(wrapPath(c.path, err)); err != nil {
+					return reflect.Value{}, err
+				}
+				// with WithErrorAccumulation, the field keeps the value of v1
+				copiedField, err := c.copyField(v1, i)
+				if err != nil {
+					return reflect.Value{}, err
+				} else if copiedField.IsValid() {
+					merged.Field(i).Set(copiedField)
+				}
 			} else {
+				c.runFieldHooks(v1.Field(i), v2.Field(i), mergedField)
 				merged.Field(i).Set(mergedField)
 			}
+		} else if !v1.Field(i).IsZero() || !v2.Field(i).IsZero() {
+			if err := c.unexportedFieldLost(v1.Type(), fieldPath(parent, field.Name), field.Name, "unexported field not merged"); err != nil {
+				return reflect.Value{}, err
+			}
 		}
 	}
 	return merged, nil
 }
 
+// mergeField merges the given field of 2 struct values, as compiled in the given field plan.
+func (c *coalescer) mergeField(structType reflect.Type, fp *fieldPlan, v1, v2 reflect.Value) (reflect.Value, error) {
+	fieldMerger, err := c.plannedFieldMerger(fp)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if copyStrategy := fieldCopyStrategy(structType, fp.index); copyStrategy != "" {
+		if fieldMerger, err = c.copyStrategyMerger(structType, fp.field, copyStrategy, fieldMerger); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+	merged, err := c.checkFieldPermission(fp.field, fieldMerger, v1, v2)
+	if err != nil || !fp.hasDefault {
+		return merged, err
+	}
+	return c.applyFieldDefault(structType, fp.field, merged)
+}
+
+// accumulate collects the given error and returns nil if WithErrorAccumulation is in effect, and
+// returns the error otherwise.
+func (c *coalescer) accumulate(err error) error {
+	if !c.errorAccumulation {
+		return err
+	}
+	c.errs = append(c.errs, err)
+	return nil
+}
+
 func (c *coalescer) deepCopyStruct(v reflect.Value) (reflect.Value, error) {
 	if v.IsZero() {
 		return reflect.Zero(v.Type()), nil
 	}
+	if isSyncAtomicType(v.Type()) {
+		return c.deepCopySyncAtomic(v)
+	} else if v.Type() == syncMapType {
+		return c.deepCopySyncMap(v)
+	} else if c.sharesUnexportedFields(v.Type()) {
+		return copyPlain(v), nil
+	}
 	copied := reflect.New(v.Type()).Elem()
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Type().Field(i)
-		if field.IsExported() {
-			copiedField, err := c.deepCopy(v.Field(i))
+		if excludedField(v.Type(), i) {
+			continue
+		} else if field.IsExported() {
+			copiedField, err := c.copyField(v, i)
 			if err != nil {
 				return reflect.Value{}, err
+			} else if copiedField.IsValid() {
+				copied.Field(i).Set(copiedField)
 			}
-			copied.Field(i).Set(copiedField)
+		} else if v.Field(i).IsZero() {
+			continue
+		} else if c.unexportedFieldPolicy == UnexportedFieldsCopy {
+			if err := c.copyUnexportedField(copied, v, i); err != nil {
+				return reflect.Value{}, err
+			}
+		} else if err := c.unexportedFieldLost(v.Type(), fieldPath(c.path, field.Name), field.Name, "unexported field not copied"); err != nil {
+			return reflect.Value{}, err
 		}
 	}
 	return copied, nil
 }
 
-func (c *coalescer) hasFieldMergers(structType reflect.Type) bool {
-	for i := 0; i < structType.NumField(); i++ {
-		field := structType.Field(i)
-		if field.IsExported() {
-			if _, foundTag := field.Tag.Lookup(MergeStrategyTag); foundTag {
-				return true
-			} else if fieldMergers, foundStruct := c.fieldMergers[structType]; foundStruct {
-				if _, foundField := fieldMergers[field.Name]; foundField {
-					return true
-				}
+// copyField copies the i-th field of the given struct value, according to its copy strategy, if
+// any. It returns an invalid value if the field must be skipped.
+func (c *coalescer) copyField(v reflect.Value, i int) (reflect.Value, error) {
+	switch copyStrategy := fieldCopyStrategy(v.Type(), i); copyStrategy {
+	case "":
+		return c.deepCopy(v.Field(i))
+	case CopyStrategyAtomic:
+		return v.Field(i), nil
+	case CopyStrategySkip:
+		return reflect.Value{}, nil
+	default:
+		return reflect.Value{}, unknownCopyStrategy(v.Type(), v.Type().Field(i), copyStrategy)
+	}
+}
+
+// copyStrategyMerger wraps the given merger of a field declaring the given copy strategy, so that
+// the merge honors it wherever it copies a value instead of merging it: with CopyStrategyAtomic,
+// values copied by the merge, e.g. when one of the values is zero, or by set-union, are shared;
+// with CopyStrategySkip, the field is zero when one of the values is zero, since the merge would
+// then copy the other one.
+func (c *coalescer) copyStrategyMerger(structType reflect.Type, field reflect.StructField, copyStrategy string, fieldMerger DeepMergeFunc) (DeepMergeFunc, error) {
+	switch copyStrategy {
+	case CopyStrategyAtomic:
+		return func(v1, v2 reflect.Value) (reflect.Value, error) {
+			previous := c.deepCopy
+			c.deepCopy = func(v reflect.Value) (reflect.Value, error) { return v, nil }
+			defer func() { c.deepCopy = previous }()
+			return fieldMerger(v1, v2)
+		}, nil
+	case CopyStrategySkip:
+		return func(v1, v2 reflect.Value) (reflect.Value, error) {
+			if v1.IsZero() || v2.IsZero() {
+				return reflect.Zero(v1.Type()), nil
 			}
-		}
+			return fieldMerger(v1, v2)
+		}, nil
+	default:
+		return nil, unknownCopyStrategy(structType, field, copyStrategy)
 	}
-	return false
 }
 
-func (c *coalescer) fieldMerger(structType reflect.Type, field reflect.StructField) (DeepMergeFunc, error) {
-	fieldMerger, err := c.fieldMergerFromTag(structType, field)
-	if err != nil {
-		return nil, err
+// unknownCopyStrategy returns a *TagError wrapping ErrUnknownStrategy for the given copy strategy.
+func unknownCopyStrategy(structType reflect.Type, field reflect.StructField, copyStrategy string) error {
+	return &TagError{
+		Struct:   structType,
+		Field:    field.Name,
+		Strategy: CopyStrategyPrefix + copyStrategy,
+		Err:      fmt.Errorf("%w: %s", ErrUnknownStrategy, CopyStrategyPrefix+copyStrategy),
 	}
-	if fieldMerger == nil {
-		if fieldMergers, foundStruct := c.fieldMergers[structType]; foundStruct {
-			if customFieldMerger, foundField := fieldMergers[field.Name]; foundField {
-				fieldMerger = func(v1, v2 reflect.Value) (reflect.Value, error) {
-					merged, err := customFieldMerger(v1, v2)
-					if done, merged, err := checkCustomResult(merged, err, v1.Type()); done {
-						return merged, err
-					}
-					return c.deepMerge(v1, v2)
-				}
-			}
+}
+
+func (c *coalescer) hasFieldMergers(structType reflect.Type) bool {
+	if strategiesFor(structType).any {
+		return true
+	}
+	for name := range c.fieldMergers[structType] {
+		if field, found := structType.FieldByName(name); found && field.IsExported() && len(field.Index) == 1 {
+			return true
 		}
 	}
-	if fieldMerger == nil {
-		fieldMerger = c.deepMerge
+	return false
+}
+
+// customFieldMerger wraps the given custom field merger, falling back to the default merge if the
+// custom merger returns an invalid value.
+func (c *coalescer) customFieldMerger(customFieldMerger DeepMergeFunc) DeepMergeFunc {
+	return func(v1, v2 reflect.Value) (reflect.Value, error) {
+		merged, err := customFieldMerger(v1, v2)
+		if done, merged, err := checkCustomResult(merged, err, v1.Type()); done {
+			return merged, err
+		}
+		return c.deepMerge(v1, v2)
 	}
-	return fieldMerger, nil
 }
 
+// fieldMergerFromTag returns the merger for the merge strategy declared for the given field, or nil
+// if no strategy is declared, or if the strategy is unknown and WithLenientTags is in effect.
 func (c *coalescer) fieldMergerFromTag(structType reflect.Type, field reflect.StructField) (DeepMergeFunc, error) {
-	mergeStrategy, found := field.Tag.Lookup(MergeStrategyTag)
+	fieldMerger, err := c.strictFieldMergerFromTag(structType, field)
+	if unknown, ok := unknownStrategy(err); ok && c.lenientTags {
+		c.ignoreUnknownStrategy(unknown)
+		return nil, nil
+	}
+	return fieldMerger, err
+}
+
+// strictFieldMergerFromTag is like fieldMergerFromTag, but returns a *TagError wrapping
+// ErrUnknownStrategy for unknown strategies, regardless of WithLenientTags.
+func (c *coalescer) strictFieldMergerFromTag(structType reflect.Type, field reflect.StructField) (DeepMergeFunc, error) {
+	mergeStrategy, found := fieldStrategy(structType, field)
 	if !found {
 		return nil, nil
 	}
+	fieldMerger, err := c.strategyMerger(field, mergeStrategy)
+	if err != nil {
+		return nil, &TagError{Struct: structType, Field: field.Name, Strategy: mergeStrategy, Err: err}
+	}
+	return fieldMerger, nil
+}
+
+// strategyMerger returns the merger for the given merge strategy, declared for the given field.
+func (c *coalescer) strategyMerger(field reflect.StructField, mergeStrategy string) (DeepMergeFunc, error) {
+	if sliceStrategy, elemStrategy, found := cutElemStrategy(mergeStrategy); found {
+		return c.elemStrategyMerger(field, sliceStrategy, elemStrategy)
+	}
 	switch {
 	case mergeStrategy == MergeStrategyAtomic:
 		return c.deepMergeAtomic, nil
 	case mergeStrategy == MergeStrategyAppend:
-		return c.appendFieldMerger(structType, field)
+		return c.appendFieldMerger(field)
 	case mergeStrategy == MergeStrategyUnion:
-		return c.unionFieldMerger(structType, field)
+		return c.unionFieldMerger(field)
 	case mergeStrategy == MergeStrategyIndex:
-		return c.indexFieldMerger(structType, field)
+		return c.indexFieldMerger(field)
+	case mergeStrategy == MergeStrategyDifference || strings.HasPrefix(mergeStrategy, MergeStrategyDifference+":"):
+		return c.differenceFieldMerger(field, mergeStrategy)
+	case mergeStrategy == MergeStrategyLatest || mergeStrategy == MergeStrategyEarliest:
+		return c.timeFieldMerger(field, mergeStrategy)
+	case strings.HasPrefix(mergeStrategy, MergeStrategyLatest+":") || strings.HasPrefix(mergeStrategy, MergeStrategyEarliest+":"):
+		return c.recordTimeFieldMerger(field, mergeStrategy)
+	case mergeStrategy == MergeStrategySemverMax:
+		return c.semverFieldMerger(field)
+	case mergeStrategy == MergeStrategyBitwiseOr:
+		return c.bitwiseOrFieldMerger(field)
+	case mergeStrategy == MergeStrategySum:
+		return c.numericFieldMerger(field, NumericSum)
+	case mergeStrategy == MergeStrategyMin:
+		return c.numericFieldMerger(field, NumericMin)
+	case mergeStrategy == MergeStrategyMax:
+		return c.numericFieldMerger(field, NumericMax)
+	case mergeStrategy == MergeStrategyKeepFirst || mergeStrategy == MergeStrategyKeep:
+		return c.deepMergeKeepFirst, nil
+	case mergeStrategy == MergeStrategyImmutable:
+		return c.deepMergeImmutable, nil
+	case mergeStrategy == MergeStrategyMustMatch:
+		return c.deepMergeMustMatch, nil
+	case mergeStrategy == MergeStrategyKey:
+		return c.deepMerge, nil
+	case mergeStrategy == MergeStrategyExclude:
+		return deepMergeExcluded, nil
+	case strings.HasPrefix(mergeStrategy, MergeStrategyDefault+":"):
+		return c.deepMerge, nil
+	case strings.HasPrefix(mergeStrategy, MergeStrategyOneOf+":"):
+		return c.oneOfFieldMerger(field, mergeStrategy)
 	case strings.HasPrefix(mergeStrategy, MergeStrategyID):
-		return c.idFieldMerger(structType, field, mergeStrategy)
+		return c.idFieldMerger(field, mergeStrategy)
+	}
+	if merger, found := c.registeredStrategyMerger(mergeStrategy); found {
+		return merger, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, mergeStrategy)
+}
+
+// cutElemStrategy splits the given merge strategy around its first ElemStrategyPrefix, e.g.
+// "id:Name,elem=atomic" into "id:Name" and "atomic".
+func cutElemStrategy(mergeStrategy string) (sliceStrategy, elemStrategy string, found bool) {
+	return strings.Cut(mergeStrategy, ","+ElemStrategyPrefix)
+}
+
+// elemStrategyMerger returns the merger of the given slice field for the given slice strategy,
+// merging matching elements with the given element strategy.
+func (c *coalescer) elemStrategyMerger(field reflect.StructField, sliceStrategy, elemStrategy string) (DeepMergeFunc, error) {
+	switch name, _ := ParseMergeStrategyTag(sliceStrategy); name {
+	case MergeStrategyUnion, MergeStrategyIndex, MergeStrategyID:
+	default:
+		return nil, fmt.Errorf("element strategies are only supported with %s, %s and %s strategies", MergeStrategyUnion, MergeStrategyIndex, MergeStrategyID)
+	}
+	sliceMerger, err := c.strategyMerger(field, sliceStrategy)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("field %s.%s: unknown merge strategy: %s", structType.String(), field.Name, mergeStrategy)
+	elemField := field
+	elemField.Type = field.Type.Elem()
+	elemMerger, err := c.strategyMerger(elemField, elemStrategy)
+	if err != nil {
+		return nil, fmt.Errorf("element strategy %s: %w", elemStrategy, err)
+	}
+	return func(v1, v2 reflect.Value) (reflect.Value, error) {
+		previous := c.elemMerger
+		c.elemMerger = elemMerger
+		defer func() { c.elemMerger = previous }()
+		return sliceMerger(v1, v2)
+	}, nil
 }
 
-func (c *coalescer) appendFieldMerger(structType reflect.Type, field reflect.StructField) (DeepMergeFunc, error) {
+// deepMergeExcluded implements MergeStrategyExclude: it returns the zero-value.
+func deepMergeExcluded(v1, _ reflect.Value) (reflect.Value, error) {
+	return reflect.Zero(v1.Type()), nil
+}
+
+// unknownStrategy returns the *TagError of the given error, if it reports an unknown strategy.
+func unknownStrategy(err error) (*TagError, bool) {
+	var tagErr *TagError
+	if errors.As(err, &tagErr) && errors.Is(tagErr, ErrUnknownStrategy) {
+		return tagErr, true
+	}
+	return nil, false
+}
+
+// ignoreUnknownStrategy reports an unknown merge strategy ignored because of WithLenientTags.
+func (c *coalescer) ignoreUnknownStrategy(err *TagError) {
+	if c.lenientTagsWarn != nil {
+		c.lenientTagsWarn(err)
+	}
+	c.warn("ignoring unknown merge strategy: %s", err.Strategy)
+}
+
+func (c *coalescer) appendFieldMerger(field reflect.StructField) (DeepMergeFunc, error) {
 	if field.Type.Kind() != reflect.Slice {
-		return nil, fmt.Errorf("field %s.%s: %s strategy is only supported for slices", structType.String(), field.Name, MergeStrategyAppend)
+		return nil, fmt.Errorf("%s strategy is only supported for slices", MergeStrategyAppend)
 	}
 	return c.deepMergeSliceWithListAppend, nil
 }
 
-func (c *coalescer) unionFieldMerger(structType reflect.Type, field reflect.StructField) (DeepMergeFunc, error) {
+func (c *coalescer) unionFieldMerger(field reflect.StructField) (DeepMergeFunc, error) {
 	if field.Type.Kind() != reflect.Slice {
-		return nil, fmt.Errorf("field %s.%s: %s strategy is only supported for slices", structType.String(), field.Name, MergeStrategyUnion)
+		return nil, fmt.Errorf("%s strategy is only supported for slices", MergeStrategyUnion)
 	}
 	return func(v1, v2 reflect.Value) (reflect.Value, error) {
 		return c.deepMergeSliceWithMergeKey(v1, v2, SliceUnion)
 	}, nil
 }
 
-func (c *coalescer) indexFieldMerger(structType reflect.Type, field reflect.StructField) (DeepMergeFunc, error) {
+func (c *coalescer) differenceFieldMerger(field reflect.StructField, strategy string) (DeepMergeFunc, error) {
+	if field.Type.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%s strategy is only supported for slices", MergeStrategyDifference)
+	}
+	mergeKeyFunc := SliceUnion
+	if _, key, found := strings.Cut(strategy, ":"); found {
+		elemType := indirect(field.Type.Elem())
+		if key == "" {
+			return nil, fmt.Errorf("%s strategy must be followed by a colon and the merge key", MergeStrategyDifference)
+		} else if elemType.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("expecting slice of struct or pointer thereto, got: %s", field.Type.String())
+		} else if err := checkMergeKeyFields(elemType, key); err != nil {
+			return nil, err
+		}
+		mergeKeyFunc = newMergeByField(key)
+	}
+	return func(v1, v2 reflect.Value) (reflect.Value, error) {
+		return c.deepMergeSliceDifference(v1, v2, mergeKeyFunc)
+	}, nil
+}
+
+func (c *coalescer) indexFieldMerger(field reflect.StructField) (DeepMergeFunc, error) {
 	switch field.Type.Kind() {
 	case reflect.Slice:
-		return func(v1, v2 reflect.Value) (reflect.Value, error) {
-			return c.deepMergeSliceWithMergeKey(v1, v2, SliceIndex)
-		}, nil
+		return c.deepMergeSliceByIndex, nil
 	case reflect.Array:
 		return func(v1, v2 reflect.Value) (reflect.Value, error) {
 			return c.deepMergeArrayByIndex(v1, v2)
 		}, nil
 	default:
-		return nil, fmt.Errorf("field %s.%s: %s strategy is only supported for slices and arrays", structType.String(), field.Name, MergeStrategyIndex)
+		return nil, fmt.Errorf("%s strategy is only supported for slices and arrays", MergeStrategyIndex)
 	}
 }
 
-func (c *coalescer) idFieldMerger(structType reflect.Type, field reflect.StructField, strategy string) (DeepMergeFunc, error) {
+func (c *coalescer) idFieldMerger(field reflect.StructField, strategy string) (DeepMergeFunc, error) {
 	if field.Type.Kind() != reflect.Slice {
-		return nil, fmt.Errorf("field %s.%s: %s strategy is only supported for slices", structType.String(), field.Name, MergeStrategyID)
+		return nil, fmt.Errorf("%s strategy is only supported for slices", MergeStrategyID)
 	}
 	var key string
 	if i := strings.IndexRune(strategy, ':'); i != -1 {
 		key = strategy[i+1:]
 	}
 	if key == "" {
-		return nil, fmt.Errorf("field %s.%s: %s strategy must be followed by a colon and the merge key", structType.String(), field.Name, MergeStrategyID)
+		return nil, fmt.Errorf("%s strategy must be followed by a colon and the merge key", MergeStrategyID)
 	}
 	elemType := indirect(field.Type.Elem())
 	if elemType.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("field %s.%s: expecting slice of struct or pointer thereto, got: %s", structType.String(), field.Name, field.Type.String())
-	} else if _, found := elemType.FieldByName(key); !found {
-		return nil, fmt.Errorf("field %s.%s: slice element type %s has no field named %s", structType.String(), field.Name, elemType.String(), key)
+		return nil, fmt.Errorf("expecting slice of struct or pointer thereto, got: %s", field.Type.String())
+	} else if err := checkMergeKeyFields(elemType, key); err != nil {
+		return nil, err
 	}
 	return func(v1, v2 reflect.Value) (reflect.Value, error) {
 		return c.deepMergeSliceWithMergeKey(v1, v2, newMergeByField(key))
 	}, nil
 }
 
+// deepMergeImmutable merges 2 values of an immutable field: a zero v1 can be set to any value, but
+// a non-zero v1 cannot be modified, i.e. v2 must be either zero or deeply equal to v1.
+func (c *coalescer) deepMergeImmutable(v1, v2 reflect.Value) (reflect.Value, error) {
+	if v1.IsZero() {
+		return c.deepCopy(v2)
+	}
+	if !v2.IsZero() && !reflect.DeepEqual(v1.Interface(), v2.Interface()) {
+		return reflect.Value{}, errors.New("immutable field cannot be modified")
+	}
+	return c.deepCopy(v1)
+}
+
+func (c *coalescer) deepMergeMustMatch(v1, v2 reflect.Value) (reflect.Value, error) {
+	if v1.IsZero() {
+		return c.deepCopy(v2)
+	}
+	if !v2.IsZero() && !reflect.DeepEqual(v1.Interface(), v2.Interface()) {
+		return reflect.Value{}, &ConflictError{First: v1.Interface(), Second: v2.Interface()}
+	}
+	return c.deepCopy(v1)
+}
+
+// checkMergeKeyFields checks that the given struct type has all the fields of the given merge key, which
+// may be a composite key, e.g. "Namespace+Name".
+func checkMergeKeyFields(structType reflect.Type, key string) error {
+	for _, field := range strings.Split(key, MergeKeySeparator) {
+		if _, found := structType.FieldByName(field); !found {
+			return fmt.Errorf("slice element type %s has no field named %s", structType.String(), field)
+		}
+	}
+	return nil
+}
+
 // newMergeByField returns a SliceMergeKeyFunc that returns the value of the given struct field for each slice element.
 // This function is designed to work on slices of structs, and slices of pointers to structs. When this function
 // encounters a pointer while extracting the merge key, it dereferences the pointer; if the pointer was nil, a zero
 // value will be used instead, but beware that this may result in nondeterministic merge results.
+// If the given key is a composite key, e.g. "Namespace+Name", see newMergeByFields.
 func newMergeByField(key string) SliceMergeKeyFunc {
+	if fields := strings.Split(key, MergeKeySeparator); len(fields) > 1 {
+		return newMergeByFields(fields)
+	}
 	return func(_ int, elem reflect.Value) (reflect.Value, error) {
 		// the slice element itself may be a pointer; we want to dereference it and return a zero-value if it's nil.
 		deref := safeIndirect(elem)
 		if deref.Type().Kind() != reflect.Struct {
 			return reflect.Value{}, fmt.Errorf("expecting struct or pointer thereto, got: %s", elem.Type().String())
 		}
-		field := deref.FieldByName(key)
+		field := fieldByName(deref, key)
 		if !field.IsValid() {
 			return reflect.Value{}, fmt.Errorf("struct type %s has no field named %s", deref.Type().String(), key)
 		}
@@ -208,3 +570,25 @@ func newMergeByField(key string) SliceMergeKeyFunc {
 		return safeIndirect(field), nil
 	}
 }
+
+// newMergeByFields returns a SliceMergeKeyFunc that returns a composite merge key for each slice
+// element: an array holding the values of the given struct fields, in order. Two elements
+// therefore have the same merge key if all these fields are equal.
+func newMergeByFields(fields []string) SliceMergeKeyFunc {
+	keyFuncs := make([]SliceMergeKeyFunc, len(fields))
+	for i, field := range fields {
+		keyFuncs[i] = newMergeByField(field)
+	}
+	keyType := reflect.ArrayOf(len(fields), typeOfInterface)
+	return func(index int, elem reflect.Value) (reflect.Value, error) {
+		key := reflect.New(keyType).Elem()
+		for i, keyFunc := range keyFuncs {
+			value, err := keyFunc(index, elem)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			key.Index(i).Set(value)
+		}
+		return key, nil
+	}
+}
@@ -414,6 +414,24 @@ func Test_coalescer_deepMergeStruct(t *testing.T) {
 		type invalidMerge struct {
 			FieldInt int `goalesce:"id"`
 		}
+		type invalidLatest struct {
+			FieldInt int `goalesce:"latest"`
+		}
+		type invalidSemver struct {
+			FieldInt int `goalesce:"semverMax"`
+		}
+		type invalidBitwiseOr struct {
+			FieldString string `goalesce:"bitor"`
+		}
+		type invalidSum struct {
+			FieldString string `goalesce:"sum"`
+		}
+		type missingOneOf struct {
+			FieldString string `goalesce:"oneof:"`
+		}
+		type invalidOneOf struct {
+			FieldInt int `goalesce:"oneof:1|two"`
+		}
 		type missingKey struct {
 			FieldInts []int `goalesce:"id"`
 		}
@@ -430,6 +448,9 @@ func Test_coalescer_deepMergeStruct(t *testing.T) {
 			FieldFoos    []foo  `goalesce:"id:unknown"`
 			FieldFooPtrs []*foo `goalesce:"id:unknown"`
 		}
+		type unknownCompositeField struct {
+			FieldFoos []foo `goalesce:"id:FieldInt+unknown"`
+		}
 		tests := []struct {
 			name string
 			v1   interface{}
@@ -440,67 +461,109 @@ func Test_coalescer_deepMergeStruct(t *testing.T) {
 				"unknown strategy",
 				unknownStrategy{FieldInts: []int{1, 2}},
 				unknownStrategy{FieldInts: []int{2, 3}},
-				"field goalesce.unknownStrategy.FieldInts: unknown merge strategy: unknown",
+				"at FieldInts: field goalesce.unknownStrategy.FieldInts: unknown merge strategy: unknown",
 			},
 			{
 				"invalid append",
 				invalidAppend{FieldInt: 1},
 				invalidAppend{FieldInt: 2},
-				"field goalesce.invalidAppend.FieldInt: append strategy is only supported for slices",
+				"at FieldInt: field goalesce.invalidAppend.FieldInt: append strategy is only supported for slices",
 			},
 			{
 				"invalid union",
 				invalidUnion{FieldInt: 1},
 				invalidUnion{FieldInt: 2},
-				"field goalesce.invalidUnion.FieldInt: union strategy is only supported for slices",
+				"at FieldInt: field goalesce.invalidUnion.FieldInt: union strategy is only supported for slices",
 			},
 			{
 				"invalid index",
 				invalidIndex{FieldInt: 1},
 				invalidIndex{FieldInt: 2},
-				"field goalesce.invalidIndex.FieldInt: index strategy is only supported for slices and arrays",
+				"at FieldInt: field goalesce.invalidIndex.FieldInt: index strategy is only supported for slices and arrays",
 			},
 			{
 				"invalid merge",
 				invalidMerge{FieldInt: 1},
 				invalidMerge{FieldInt: 2},
-				"field goalesce.invalidMerge.FieldInt: id strategy is only supported for slices",
+				"at FieldInt: field goalesce.invalidMerge.FieldInt: id strategy is only supported for slices",
+			},
+			{
+				"invalid latest",
+				invalidLatest{FieldInt: 1},
+				invalidLatest{FieldInt: 2},
+				"at FieldInt: field goalesce.invalidLatest.FieldInt: latest strategy is only supported for time.Time and pointers thereto",
+			},
+			{
+				"invalid semverMax",
+				invalidSemver{FieldInt: 1},
+				invalidSemver{FieldInt: 2},
+				"at FieldInt: field goalesce.invalidSemver.FieldInt: semverMax strategy is only supported for strings and pointers thereto",
+			},
+			{
+				"invalid bitor",
+				invalidBitwiseOr{FieldString: "a"},
+				invalidBitwiseOr{FieldString: "b"},
+				"at FieldString: field goalesce.invalidBitwiseOr.FieldString: bitor strategy is only supported for integers and pointers thereto",
+			},
+			{
+				"invalid sum",
+				invalidSum{FieldString: "a"},
+				invalidSum{FieldString: "b"},
+				"at FieldString: field goalesce.invalidSum.FieldString: sum strategy is only supported for numbers and pointers thereto",
+			},
+			{
+				"missing oneof values",
+				missingOneOf{FieldString: "a"},
+				missingOneOf{FieldString: "b"},
+				"at FieldString: field goalesce.missingOneOf.FieldString: oneof strategy must be followed by a colon and the allowed values",
+			},
+			{
+				"invalid oneof value",
+				invalidOneOf{FieldInt: 1},
+				invalidOneOf{FieldInt: 2},
+				`at FieldInt: field goalesce.invalidOneOf.FieldInt: invalid allowed value "two": strconv.ParseInt: parsing "two": invalid syntax`,
 			},
 			{
 				"missing merge key",
 				missingKey{FieldInts: []int{1}},
 				missingKey{FieldInts: []int{2}},
-				"field goalesce.missingKey.FieldInts: id strategy must be followed by a colon and the merge key",
+				"at FieldInts: field goalesce.missingKey.FieldInts: id strategy must be followed by a colon and the merge key",
 			},
 			{
 				"missing merge key 2",
 				missingKey2{FieldInts: []int{1}},
 				missingKey2{FieldInts: []int{2}},
-				"field goalesce.missingKey2.FieldInts: id strategy must be followed by a colon and the merge key",
+				"at FieldInts: field goalesce.missingKey2.FieldInts: id strategy must be followed by a colon and the merge key",
 			},
 			{
 				"missing merge key 3",
 				missingKey3{FieldInts: []int{1}},
 				missingKey3{FieldInts: []int{2}},
-				"field goalesce.missingKey3.FieldInts: id strategy must be followed by a colon and the merge key",
+				"at FieldInts: field goalesce.missingKey3.FieldInts: id strategy must be followed by a colon and the merge key",
 			},
 			{
 				"wrong element type",
 				wrongElemType{FieldInts: []int{1}},
 				wrongElemType{FieldInts: []int{2}},
-				"field goalesce.wrongElemType.FieldInts: expecting slice of struct or pointer thereto, got: []int",
+				"at FieldInts: field goalesce.wrongElemType.FieldInts: expecting slice of struct or pointer thereto, got: []int",
 			},
 			{
 				"unknown field",
 				unknownField{FieldFoos: []foo{{FieldInt: 1}}},
 				unknownField{FieldFoos: []foo{{FieldInt: 2}}},
-				"field goalesce.unknownField.FieldFoos: slice element type goalesce.foo has no field named unknown",
+				"at FieldFoos: field goalesce.unknownField.FieldFoos: slice element type goalesce.foo has no field named unknown",
 			},
 			{
 				"unknown field ptr",
 				unknownField{FieldFooPtrs: []*foo{{FieldInt: 1}}},
 				unknownField{FieldFooPtrs: []*foo{{FieldInt: 2}}},
-				"field goalesce.unknownField.FieldFoos: slice element type goalesce.foo has no field named unknown",
+				"at FieldFoos: field goalesce.unknownField.FieldFoos: slice element type goalesce.foo has no field named unknown",
+			},
+			{
+				"unknown composite field",
+				unknownCompositeField{FieldFoos: []foo{{FieldInt: 1}}},
+				unknownCompositeField{FieldFoos: []foo{{FieldInt: 2}}},
+				"at FieldFoos: field goalesce.unknownCompositeField.FieldFoos: slice element type goalesce.foo has no field named unknown",
 			},
 		}
 		for _, tt := range tests {
@@ -511,6 +574,125 @@ func Test_coalescer_deepMergeStruct(t *testing.T) {
 			})
 		}
 	})
+	t.Run("keepfirst", func(t *testing.T) {
+		type resource struct {
+			Owner  string            `goalesce:"keepfirst"`
+			Labels map[string]string `goalesce:"keepfirst"`
+			Name   string
+		}
+		c := newCoalescer()
+		v1 := resource{Owner: "alice", Labels: map[string]string{"a": "b"}, Name: "foo"}
+		v2 := resource{Owner: "bob", Labels: map[string]string{"c": "d"}, Name: "bar"}
+		got, err := c.deepMergeStruct(reflect.ValueOf(v1), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, resource{Owner: "alice", Labels: map[string]string{"a": "b"}, Name: "bar"}, got.Interface())
+		assertNotSame(t, v1, got.Interface())
+		got, err = c.deepMergeStruct(reflect.ValueOf(resource{Name: "foo"}), reflect.ValueOf(v2))
+		assert.NoError(t, err)
+		assert.Equal(t, v2, got.Interface())
+		assertNotSame(t, v2, got.Interface())
+	})
+	t.Run("keep", func(t *testing.T) {
+		type resource struct {
+			Owner string `goalesce:"keep"`
+			Name  string
+		}
+		got, err := newCoalescer().deepMergeStruct(reflect.ValueOf(resource{Owner: "alice", Name: "foo"}), reflect.ValueOf(resource{Owner: "bob", Name: "bar"}))
+		assert.NoError(t, err)
+		assert.Equal(t, resource{Owner: "alice", Name: "bar"}, got.Interface())
+	})
+	t.Run("excluded", func(t *testing.T) {
+		type cached struct {
+			Name   string
+			Hash   string         `goalesce:"-"`
+			Cache  map[string]int `goalesce:"-"`
+			hidden string         `goalesce:"-"`
+		}
+		var warnings []Warning
+		c := newCoalescer(WithWarnings(&warnings))
+		got, err := c.deepMergeStruct(
+			reflect.ValueOf(cached{Name: "a", Hash: "h1", Cache: map[string]int{"a": 1}, hidden: "x"}),
+			reflect.ValueOf(cached{Hash: "h2", Cache: map[string]int{"b": 2}}),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, cached{Name: "a"}, got.Interface())
+		assert.Empty(t, warnings)
+		got, err = c.deepMergeStruct(reflect.ValueOf(cached{}), reflect.ValueOf(cached{Name: "b", Hash: "h2"}))
+		require.NoError(t, err)
+		assert.Equal(t, cached{Name: "b"}, got.Interface())
+	})
+	t.Run("immutable", func(t *testing.T) {
+		type account struct {
+			ID      string            `goalesce:"immutable"`
+			Labels  map[string]string `goalesce:"immutable"`
+			Created *int              `goalesce:"immutable"`
+			Name    string
+		}
+		tests := []struct {
+			name    string
+			v1      account
+			v2      account
+			want    account
+			wantErr string
+		}{
+			{
+				"zero v1",
+				account{Name: "foo"},
+				account{ID: "1", Labels: map[string]string{"a": "b"}, Created: intPtr(1)},
+				account{ID: "1", Labels: map[string]string{"a": "b"}, Created: intPtr(1), Name: "foo"},
+				"",
+			},
+			{
+				"zero v2",
+				account{ID: "1", Labels: map[string]string{"a": "b"}, Created: intPtr(1)},
+				account{Name: "foo"},
+				account{ID: "1", Labels: map[string]string{"a": "b"}, Created: intPtr(1), Name: "foo"},
+				"",
+			},
+			{
+				"equal",
+				account{ID: "1", Labels: map[string]string{"a": "b"}, Created: intPtr(1)},
+				account{ID: "1", Labels: map[string]string{"a": "b"}, Created: intPtr(1), Name: "foo"},
+				account{ID: "1", Labels: map[string]string{"a": "b"}, Created: intPtr(1), Name: "foo"},
+				"",
+			},
+			{
+				"modified scalar",
+				account{ID: "1"},
+				account{ID: "2"},
+				account{},
+				"at ID: immutable field cannot be modified",
+			},
+			{
+				"modified map",
+				account{Labels: map[string]string{"a": "b"}},
+				account{Labels: map[string]string{"a": "c"}},
+				account{},
+				"at Labels: immutable field cannot be modified",
+			},
+			{
+				"modified pointer",
+				account{Created: intPtr(1)},
+				account{Created: intPtr(2)},
+				account{},
+				"at Created: immutable field cannot be modified",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				c := newCoalescer()
+				got, err := c.deepMergeStruct(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2))
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+				} else {
+					assert.NoError(t, err)
+					assert.Equal(t, tt.want, got.Interface())
+					assertNotSame(t, tt.v1, got.Interface())
+					assertNotSame(t, tt.v2, got.Interface())
+				}
+			})
+		}
+	})
 	t.Run("interface field", func(t *testing.T) {
 		type foo struct {
 			Bird Bird
@@ -520,13 +702,54 @@ func Test_coalescer_deepMergeStruct(t *testing.T) {
 		assert.Equal(t, foo{Bird: &Goose{"Scrooge"}}, merged.Interface())
 		assert.NoError(t, err)
 	})
+	t.Run("field allowlist", func(t *testing.T) {
+		type spec struct {
+			Name     string
+			Labels   map[string]string
+			Replicas *int
+			Image    string
+		}
+		c := newCoalescer(WithFieldAllowlist(reflect.TypeOf(spec{}), "Name", "Labels"), WithFieldAllowlist(reflect.TypeOf(spec{}), "Replicas"))
+		tests := []struct {
+			name string
+			v1   spec
+			v2   spec
+			want spec
+		}{
+			{
+				"allowed fields merged",
+				spec{Name: "a", Labels: map[string]string{"x": "1"}, Replicas: intPtr(1), Image: "img1"},
+				spec{Name: "b", Labels: map[string]string{"y": "2"}, Replicas: intPtr(2), Image: "img2"},
+				spec{Name: "b", Labels: map[string]string{"x": "1", "y": "2"}, Replicas: intPtr(2), Image: "img1"},
+			},
+			{
+				"other fields retained",
+				spec{Name: "a"},
+				spec{Image: "img2"},
+				spec{Name: "a"},
+			},
+			{
+				"zero v1",
+				spec{},
+				spec{Name: "b", Image: "img2"},
+				spec{Name: "b"},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := c.deepMergeStruct(reflect.ValueOf(tt.v1), reflect.ValueOf(tt.v2))
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got.Interface())
+			})
+		}
+	})
 	t.Run("generic error", func(t *testing.T) {
 		type foo struct {
 			FieldInt int
 		}
 		c := newCoalescer(withMockDeepMergeError)
 		_, err := c.deepMergeStruct(reflect.ValueOf(foo{FieldInt: 1}), reflect.ValueOf(foo{FieldInt: 2}))
-		assert.EqualError(t, err, "mock DeepMerge error")
+		assert.EqualError(t, err, "at FieldInt: mock DeepMerge error")
 	})
 }
 
@@ -584,6 +807,18 @@ func Test_newMergeByField(t *testing.T) {
 		assert.False(t, mergeKey.IsValid())
 		assert.ErrorContains(t, err, "struct type goalesce.User has no field named NonExistent")
 	})
+	t.Run("composite key", func(t *testing.T) {
+		mergeKeyFunc := newMergeByField("ID+Name")
+		mergeKey, err := mergeKeyFunc(-1, reflect.ValueOf(&u))
+		assert.Equal(t, [2]interface{}{1, "Alice"}, mergeKey.Interface())
+		assert.NoError(t, err)
+	})
+	t.Run("composite key, invalid field", func(t *testing.T) {
+		mergeKeyFunc := newMergeByField("ID+NonExistent")
+		mergeKey, err := mergeKeyFunc(-1, reflect.ValueOf(u))
+		assert.False(t, mergeKey.IsValid())
+		assert.ErrorContains(t, err, "struct type goalesce.User has no field named NonExistent")
+	})
 }
 
 func Test_coalescer_deepCopyStruct(t *testing.T) {
@@ -642,3 +877,220 @@ func Test_coalescer_deepCopyStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestDeepCopy_excludedFields(t *testing.T) {
+	type point struct {
+		X, Y int
+		Norm int `goalesce:"-"`
+	}
+	type shape struct {
+		Points []point
+		Bounds *point `goalesce:"-"`
+	}
+	v := shape{Points: []point{{1, 2, 3}}, Bounds: &point{1, 2, 3}}
+	got, err := DeepCopy(v)
+	require.NoError(t, err)
+	assert.Equal(t, shape{Points: []point{{1, 2, 0}}}, got)
+	dst := shape{Bounds: &point{4, 5, 6}}
+	err = CopyInto(&dst, v)
+	require.NoError(t, err)
+	assert.Equal(t, shape{Points: []point{{1, 2, 0}}}, dst)
+}
+
+func TestDeepCopy_copyStrategies(t *testing.T) {
+	type conn struct {
+		Addr string
+	}
+	type client struct {
+		Name  string
+		Conns []*conn `goalesce:"union,copy:atomic"`
+		Stats *int    `goalesce:"copy:skip"`
+	}
+	c1 := &conn{"a"}
+	v := client{Name: "a", Conns: []*conn{c1}, Stats: intPtr(1)}
+	t.Run("copy", func(t *testing.T) {
+		got, err := DeepCopy(v)
+		require.NoError(t, err)
+		assert.Equal(t, client{Name: "a", Conns: []*conn{c1}}, got)
+		assert.Same(t, c1, got.Conns[0])
+	})
+	t.Run("merge", func(t *testing.T) {
+		c2 := &conn{"b"}
+		got, err := DeepMerge(v, client{Conns: []*conn{c2}, Stats: intPtr(2)})
+		require.NoError(t, err)
+		assert.Equal(t, []*conn{c1, c2}, got.Conns)
+		assert.Same(t, c1, got.Conns[0])
+		assert.Same(t, c2, got.Conns[1])
+		assert.Equal(t, intPtr(2), got.Stats)
+	})
+	t.Run("merge with zero-value", func(t *testing.T) {
+		got, err := DeepMerge(v, client{})
+		require.NoError(t, err)
+		assert.Equal(t, client{Name: "a", Conns: []*conn{c1}}, got)
+		assert.Same(t, c1, got.Conns[0])
+		got, err = DeepMerge(client{}, v)
+		require.NoError(t, err)
+		assert.Equal(t, client{Name: "a", Conns: []*conn{c1}}, got)
+		assert.Same(t, c1, got.Conns[0])
+	})
+	t.Run("merge atomic field", func(t *testing.T) {
+		type pool struct {
+			Name    string `goalesce:"mustmatch"`
+			Primary *conn  `goalesce:"atomic,copy:atomic"`
+			Backup  *conn  `goalesce:"copy:atomic"`
+		}
+		c2 := &conn{"b"}
+		got, err := DeepMerge(pool{Name: "p", Primary: c1}, pool{Primary: c2, Backup: c2})
+		require.NoError(t, err)
+		assert.Same(t, c2, got.Primary)
+		assert.Same(t, c2, got.Backup)
+	})
+	t.Run("merge with allowlist", func(t *testing.T) {
+		got, err := DeepMerge(v, client{Name: "b", Conns: []*conn{{"b"}}, Stats: intPtr(2)}, WithFieldAllowlist(reflect.TypeOf(client{}), "Name"))
+		require.NoError(t, err)
+		assert.Equal(t, client{Name: "b", Conns: []*conn{c1}}, got)
+		assert.Same(t, c1, got.Conns[0])
+	})
+	t.Run("CopyInto", func(t *testing.T) {
+		dst := client{Stats: intPtr(2)}
+		require.NoError(t, CopyInto(&dst, v))
+		assert.Equal(t, client{Name: "a", Conns: []*conn{c1}}, dst)
+		assert.Same(t, c1, dst.Conns[0])
+	})
+	t.Run("unknown", func(t *testing.T) {
+		type invalid struct {
+			Name string `goalesce:"copy:unknown"`
+		}
+		_, err := DeepCopy(invalid{"a"})
+		assert.ErrorIs(t, err, ErrUnknownStrategy)
+		assert.EqualError(t, err, "field goalesce.invalid.Name: unknown merge strategy: copy:unknown")
+		_, err = DeepMerge(invalid{"a"}, invalid{"b"})
+		assert.ErrorIs(t, err, ErrUnknownStrategy)
+	})
+}
+
+func TestDeepMerge_elemStrategies(t *testing.T) {
+	type container struct {
+		Name  string
+		Image string
+		Ports []int
+	}
+	type pod struct {
+		Containers []container `goalesce:"id:Name,elem=atomic"`
+		Matrix     [][]int     `goalesce:"index,elem=index"`
+		Groups     [][]string  `goalesce:"index,elem=union"`
+	}
+	v1 := pod{
+		Containers: []container{{Name: "a", Image: "a:1", Ports: []int{80}}, {Name: "b", Image: "b:1"}},
+		Matrix:     [][]int{{1, 2}, {3, 4}},
+		Groups:     [][]string{{"x"}},
+	}
+	v2 := pod{
+		Containers: []container{{Name: "a", Image: "a:2"}},
+		Matrix:     [][]int{{0, 5}},
+		Groups:     [][]string{{"y", "x"}, {"z"}},
+	}
+	got, err := DeepMerge(v1, v2)
+	require.NoError(t, err)
+	assert.Equal(t, pod{
+		// matching elements are replaced, not merged: Ports are lost
+		Containers: []container{{Name: "a", Image: "a:2"}, {Name: "b", Image: "b:1"}},
+		Matrix:     [][]int{{1, 5}, {3, 4}},
+		Groups:     [][]string{{"x", "y"}, {"z"}},
+	}, got)
+	t.Run("nested", func(t *testing.T) {
+		type cube struct {
+			Cells [][][]int `goalesce:"index,elem=index,elem=union"`
+		}
+		got, err := DeepMerge(cube{[][][]int{{{1}, {2}}}}, cube{[][][]int{{{3}, {2}}}})
+		require.NoError(t, err)
+		assert.Equal(t, cube{[][][]int{{{1, 3}, {2}}}}, got)
+	})
+	t.Run("not leaking to nested slices", func(t *testing.T) {
+		type group struct {
+			Name    string
+			Members []string
+		}
+		type org struct {
+			Groups []group `goalesce:"id:Name,elem=keepfirst"`
+			Tags   [][]string
+		}
+		got, err := DeepMerge(org{Groups: []group{{Name: "a", Members: []string{"x"}}}, Tags: [][]string{{"a"}}}, org{Groups: []group{{Name: "a", Members: []string{"y"}}}, Tags: [][]string{{"b"}}})
+		require.NoError(t, err)
+		assert.Equal(t, org{Groups: []group{{Name: "a", Members: []string{"x"}}}, Tags: [][]string{{"b"}}}, got)
+	})
+	t.Run("describe", func(t *testing.T) {
+		plan, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(pod{})})
+		require.NoError(t, err)
+		assert.Equal(t, FieldStrategy{Struct: "goalesce.pod", Field: "Containers", Type: "[]goalesce.container", Strategy: MergeStrategyID, MergeKey: "Name", Tag: "id:Name,elem=atomic"}, plan[0])
+	})
+	t.Run("invalid", func(t *testing.T) {
+		type appended struct {
+			Items [][]int `goalesce:"append,elem=atomic"`
+		}
+		_, err := DeepMerge(appended{[][]int{{1}}}, appended{[][]int{{2}}})
+		assert.EqualError(t, err, "at Items: field goalesce.appended.Items: element strategies are only supported with union, index and id strategies")
+		type notSlice struct {
+			Items []int `goalesce:"index,elem=append"`
+		}
+		_, err = DeepMerge(notSlice{[]int{1}}, notSlice{[]int{2}})
+		assert.EqualError(t, err, "at Items: field goalesce.notSlice.Items: element strategy append: append strategy is only supported for slices")
+	})
+}
+
+func TestDeepMerge_compositeMergeKey(t *testing.T) {
+	type resource struct {
+		Namespace string
+		Name      string
+		Replicas  int
+	}
+	type manifest struct {
+		Resources []resource `goalesce:"id:Namespace+Name"`
+	}
+	v1 := manifest{Resources: []resource{{"default", "web", 1}, {"kube-system", "web", 1}}}
+	v2 := manifest{Resources: []resource{{"default", "web", 3}, {"default", "db", 1}}}
+	got, err := DeepMerge(v1, v2)
+	require.NoError(t, err)
+	assert.Equal(t, manifest{Resources: []resource{{"default", "web", 3}, {"kube-system", "web", 1}, {"default", "db", 1}}}, got)
+}
+
+func TestDeepMerge_keyTag(t *testing.T) {
+	type item struct {
+		SKU   string `goalesce:"key"`
+		Price int
+		Tags  []string
+	}
+	type cart struct {
+		Items    []item
+		Previous []*item
+	}
+	v1 := cart{Items: []item{{"a", 1, nil}, {"b", 2, nil}}, Previous: []*item{{"a", 1, nil}}}
+	v2 := cart{Items: []item{{"b", 0, []string{"sale"}}, {"c", 3, nil}}, Previous: []*item{{"a", 2, nil}}}
+	got, err := DeepMerge(v1, v2)
+	require.NoError(t, err)
+	assert.Equal(t, cart{Items: []item{{"a", 1, nil}, {"b", 2, []string{"sale"}}, {"c", 3, nil}}, Previous: []*item{{"a", 2, nil}}}, got)
+	t.Run("top-level slice", func(t *testing.T) {
+		got, err := DeepMerge([]item{{"a", 1, nil}}, []item{{"a", 2, nil}})
+		require.NoError(t, err)
+		assert.Equal(t, []item{{"a", 2, nil}}, got)
+	})
+	t.Run("overridden by field tag", func(t *testing.T) {
+		type appended struct {
+			Items []item `goalesce:"append"`
+		}
+		got, err := DeepMerge(appended{[]item{{"a", 1, nil}}}, appended{[]item{{"a", 2, nil}}})
+		require.NoError(t, err)
+		assert.Equal(t, appended{[]item{{"a", 1, nil}, {"a", 2, nil}}}, got)
+	})
+	t.Run("overridden by slice option", func(t *testing.T) {
+		got, err := DeepMerge([]item{{"a", 1, nil}}, []item{{"a", 2, nil}}, WithSliceListAppendMerge(reflect.TypeOf([]item{})))
+		require.NoError(t, err)
+		assert.Equal(t, []item{{"a", 1, nil}, {"a", 2, nil}}, got)
+	})
+	t.Run("describe", func(t *testing.T) {
+		plan, err := DescribeMergeStrategies([]reflect.Type{reflect.TypeOf(cart{})})
+		require.NoError(t, err)
+		assert.Equal(t, FieldStrategy{Struct: "goalesce.cart", Field: "Items", Type: "[]goalesce.item", Strategy: MergeStrategyID, MergeKey: "SKU"}, plan[0])
+		assert.Equal(t, FieldStrategy{Struct: "goalesce.item", Field: "SKU", Type: "string", Strategy: MergeStrategyAtomic, Tag: "key"}, plan[2])
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import "reflect"
+
+// isSyncAtomicType returns true if the given type is one of the wrapper types of the sync/atomic
+// package, e.g. atomic.Value, atomic.Pointer[T] or atomic.Int64. Such types only have unexported
+// fields and must be accessed through their Load and Store methods.
+func isSyncAtomicType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.PkgPath() != "sync/atomic" {
+		return false
+	}
+	ptr := reflect.PointerTo(t)
+	_, hasLoad := ptr.MethodByName("Load")
+	_, hasStore := ptr.MethodByName("Store")
+	return hasLoad && hasStore
+}
+
+func (c *coalescer) deepMergeSyncAtomic(v1, v2 reflect.Value) (reflect.Value, error) {
+	merged, err := c.deepMerge(loadSyncAtomic(v1), loadSyncAtomic(v2))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return storeSyncAtomic(v1.Type(), merged), nil
+}
+
+func (c *coalescer) deepCopySyncAtomic(v reflect.Value) (reflect.Value, error) {
+	copied, err := c.deepCopy(loadSyncAtomic(v))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return storeSyncAtomic(v.Type(), copied), nil
+}
+
+// loadSyncAtomic atomically loads the value held by the given sync/atomic wrapper.
+func loadSyncAtomic(v reflect.Value) reflect.Value {
+	var ptr reflect.Value
+	if v.CanAddr() {
+		ptr = v.Addr()
+	} else {
+		ptr = reflect.New(v.Type())
+		ptr.Elem().Set(v)
+	}
+	return ptr.MethodByName("Load").Call(nil)[0]
+}
+
+// storeSyncAtomic creates a new sync/atomic wrapper of the given type holding the given value.
+func storeSyncAtomic(t reflect.Type, value reflect.Value) reflect.Value {
+	ptr := reflect.New(t)
+	// atomic.Value panics when storing nil
+	if !value.IsZero() {
+		ptr.MethodByName("Store").Call([]reflect.Value{value})
+	}
+	return ptr.Elem()
+}
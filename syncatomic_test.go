@@ -0,0 +1,159 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type atomicSection struct {
+	Name  string
+	Ports []int
+}
+
+type atomicConfig struct {
+	Section *atomic.Pointer[atomicSection]
+	Value   *atomic.Value
+	Count   *atomic.Int64
+}
+
+func newAtomicConfig(section *atomicSection, value interface{}, count int64) atomicConfig {
+	config := atomicConfig{
+		Section: &atomic.Pointer[atomicSection]{},
+		Value:   &atomic.Value{},
+		Count:   &atomic.Int64{},
+	}
+	if section != nil {
+		config.Section.Store(section)
+	}
+	if value != nil {
+		config.Value.Store(value)
+	}
+	config.Count.Store(count)
+	return config
+}
+
+func Test_isSyncAtomicType(t *testing.T) {
+	assert.True(t, isSyncAtomicType(reflect.TypeOf(atomic.Value{})))
+	assert.True(t, isSyncAtomicType(reflect.TypeOf(atomic.Pointer[int]{})))
+	assert.True(t, isSyncAtomicType(reflect.TypeOf(atomic.Int64{})))
+	assert.True(t, isSyncAtomicType(reflect.TypeOf(atomic.Bool{})))
+	assert.False(t, isSyncAtomicType(reflect.TypeOf(&atomic.Value{})))
+	assert.False(t, isSyncAtomicType(reflect.TypeOf(atomicSection{})))
+}
+
+func Test_coalescer_deepCopySyncAtomic(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		got, err := DeepCopy(newAtomicConfig(nil, nil, 0))
+		require.NoError(t, err)
+		assert.Nil(t, got.Section.Load())
+		assert.Nil(t, got.Value.Load())
+		assert.Equal(t, int64(0), got.Count.Load())
+	})
+	t.Run("non empty", func(t *testing.T) {
+		section := &atomicSection{Name: "foo", Ports: []int{80}}
+		value := &atomicSection{Name: "bar"}
+		config := newAtomicConfig(section, value, 42)
+		got, err := DeepCopy(config)
+		require.NoError(t, err)
+		assert.NotSame(t, config.Section, got.Section)
+		assert.Equal(t, section, got.Section.Load())
+		assertNotSame(t, section, got.Section.Load())
+		assert.Equal(t, value, got.Value.Load())
+		assert.NotSame(t, value, got.Value.Load())
+		assert.Equal(t, int64(42), got.Count.Load())
+		// changes to the copy are not visible in the original
+		got.Section.Load().Ports[0] = 8080
+		assert.Equal(t, 80, section.Ports[0])
+	})
+	t.Run("by value", func(t *testing.T) {
+		var v atomic.Pointer[atomicSection]
+		v.Store(&atomicSection{Name: "foo"})
+		c := newCoalescer()
+		got, err := c.deepCopy(reflect.ValueOf(&v).Elem())
+		require.NoError(t, err)
+		copied := got.Addr().Interface().(*atomic.Pointer[atomicSection])
+		assert.Equal(t, &atomicSection{Name: "foo"}, copied.Load())
+	})
+	t.Run("copy into", func(t *testing.T) {
+		config := newAtomicConfig(&atomicSection{Name: "foo"}, "bar", 42)
+		dst := newAtomicConfig(&atomicSection{Name: "old"}, "old", 1)
+		err := CopyInto(&dst, config)
+		require.NoError(t, err)
+		assert.Equal(t, &atomicSection{Name: "foo"}, dst.Section.Load())
+		assert.Equal(t, "bar", dst.Value.Load())
+		assert.Equal(t, int64(42), dst.Count.Load())
+	})
+}
+
+func Test_coalescer_deepMergeSyncAtomic(t *testing.T) {
+	tests := []struct {
+		name        string
+		v1          atomicConfig
+		v2          atomicConfig
+		wantSection *atomicSection
+		wantValue   interface{}
+		wantCount   int64
+	}{
+		{
+			name:        "empty",
+			v1:          newAtomicConfig(nil, nil, 0),
+			v2:          newAtomicConfig(nil, nil, 0),
+			wantSection: nil,
+			wantValue:   nil,
+			wantCount:   0,
+		},
+		{
+			name:        "v1 only",
+			v1:          newAtomicConfig(&atomicSection{Name: "foo"}, "foo", 1),
+			v2:          newAtomicConfig(nil, nil, 0),
+			wantSection: &atomicSection{Name: "foo"},
+			wantValue:   "foo",
+			wantCount:   1,
+		},
+		{
+			name:        "v2 only",
+			v1:          newAtomicConfig(nil, nil, 0),
+			v2:          newAtomicConfig(&atomicSection{Name: "bar"}, "bar", 2),
+			wantSection: &atomicSection{Name: "bar"},
+			wantValue:   "bar",
+			wantCount:   2,
+		},
+		{
+			name:        "both",
+			v1:          newAtomicConfig(&atomicSection{Name: "foo", Ports: []int{80}}, &atomicSection{Name: "foo"}, 1),
+			v2:          newAtomicConfig(&atomicSection{Ports: []int{8080}}, &atomicSection{Ports: []int{443}}, 2),
+			wantSection: &atomicSection{Name: "foo", Ports: []int{8080}},
+			wantValue:   &atomicSection{Name: "foo", Ports: []int{443}},
+			wantCount:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSection, got.Section.Load())
+			assert.Equal(t, tt.wantValue, got.Value.Load())
+			assert.Equal(t, tt.wantCount, got.Count.Load())
+			assert.NotSame(t, tt.v1.Section, got.Section)
+			assert.NotSame(t, tt.v2.Section, got.Section)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var syncMapType = reflect.TypeOf(sync.Map{})
+
+func (c *coalescer) deepMergeSyncMap(v1, v2 reflect.Value) (reflect.Value, error) {
+	m1 := syncMapOf(v1)
+	m2 := syncMapOf(v2)
+	merged := reflect.New(syncMapType)
+	mergedMap := merged.Interface().(*sync.Map)
+	for _, k := range sortedSyncMapKeys(m1) {
+		mergedKey, err := c.deepCopy(interfaceValue(k))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		e1, _ := m1.Load(k)
+		var mergedValue reflect.Value
+		if e2, found := m2.Load(k); found {
+			mergedValue, err = c.deepMerge(interfaceValue(e1), interfaceValue(e2))
+		} else {
+			mergedValue, err = c.deepCopy(interfaceValue(e1))
+		}
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		mergedMap.Store(mergedKey.Interface(), mergedValue.Interface())
+	}
+	for _, k := range sortedSyncMapKeys(m2) {
+		if _, found := m1.Load(k); found {
+			continue
+		}
+		copiedKey, err := c.deepCopy(interfaceValue(k))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		e2, _ := m2.Load(k)
+		copiedValue, err := c.deepCopy(interfaceValue(e2))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		mergedMap.Store(copiedKey.Interface(), copiedValue.Interface())
+	}
+	return merged.Elem(), nil
+}
+
+func (c *coalescer) deepCopySyncMap(v reflect.Value) (reflect.Value, error) {
+	copied := reflect.New(syncMapType)
+	copiedMap := copied.Interface().(*sync.Map)
+	m := syncMapOf(v)
+	for _, k := range sortedSyncMapKeys(m) {
+		copiedKey, err := c.deepCopy(interfaceValue(k))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		e, _ := m.Load(k)
+		copiedValue, err := c.deepCopy(interfaceValue(e))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		copiedMap.Store(copiedKey.Interface(), copiedValue.Interface())
+	}
+	return copied.Elem(), nil
+}
+
+// sortedSyncMapKeys returns the keys of the given sync.Map, in a deterministic order (see
+// compareKeys).
+func sortedSyncMapKeys(m *sync.Map) []interface{} {
+	var keys []interface{}
+	m.Range(func(k, _ interface{}) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compareKeys(interfaceValue(keys[i]), interfaceValue(keys[j])) < 0
+	})
+	return keys
+}
+
+// syncMapOf returns a pointer to the sync.Map held by the given value. If the value is not
+// addressable, a pointer to a shallow copy of it is returned.
+func syncMapOf(v reflect.Value) *sync.Map {
+	if v.CanAddr() {
+		return v.Addr().Interface().(*sync.Map)
+	}
+	ptr := reflect.New(syncMapType)
+	ptr.Elem().Set(v)
+	return ptr.Interface().(*sync.Map)
+}
+
+// interfaceValue returns a reflect.Value of kind Interface holding the given value, as if it had
+// been obtained from a map[interface{}]interface{}.
+func interfaceValue(i interface{}) reflect.Value {
+	return reflect.ValueOf(&i).Elem()
+}
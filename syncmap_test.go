@@ -0,0 +1,156 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type syncMapUser struct {
+	ID   int
+	Name string
+	Age  int
+}
+
+type syncMapHolder struct {
+	Cache *sync.Map
+}
+
+func newSyncMap(entries map[interface{}]interface{}) *sync.Map {
+	m := &sync.Map{}
+	for k, v := range entries {
+		m.Store(k, v)
+	}
+	return m
+}
+
+func syncMapEntries(m *sync.Map) map[interface{}]interface{} {
+	entries := make(map[interface{}]interface{})
+	m.Range(func(k, v interface{}) bool {
+		entries[k] = v
+		return true
+	})
+	return entries
+}
+
+func Test_coalescer_deepCopySyncMap(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		got, err := DeepCopy(syncMapHolder{Cache: &sync.Map{}})
+		require.NoError(t, err)
+		assert.Empty(t, syncMapEntries(got.Cache))
+	})
+	t.Run("non empty", func(t *testing.T) {
+		user := &syncMapUser{ID: 1, Name: "Alice"}
+		m := newSyncMap(map[interface{}]interface{}{"a": 1, "user": user, "nil": nil})
+		got, err := DeepCopy(syncMapHolder{Cache: m})
+		require.NoError(t, err)
+		assert.NotSame(t, m, got.Cache)
+		assert.Equal(t, map[interface{}]interface{}{"a": 1, "user": user, "nil": nil}, syncMapEntries(got.Cache))
+		copiedUser, _ := got.Cache.Load("user")
+		assert.NotSame(t, user, copiedUser)
+		// changes to the copy are not visible in the original
+		got.Cache.Store("b", 2)
+		_, found := m.Load("b")
+		assert.False(t, found)
+	})
+	t.Run("by value", func(t *testing.T) {
+		m := newSyncMap(map[interface{}]interface{}{"a": 1})
+		got, err := newCoalescer().deepCopy(reflect.ValueOf(m).Elem())
+		require.NoError(t, err)
+		assert.Equal(t, map[interface{}]interface{}{"a": 1}, syncMapEntries(got.Addr().Interface().(*sync.Map)))
+	})
+	t.Run("error", func(t *testing.T) {
+		m := newSyncMap(map[interface{}]interface{}{"a": 1})
+		_, err := DeepCopy(syncMapHolder{Cache: m}, WithTypeCopier(reflect.TypeOf(0), func(v reflect.Value) (reflect.Value, error) {
+			return reflect.Value{}, errors.New("fake")
+		}))
+		assert.EqualError(t, err, "fake")
+	})
+}
+
+func Test_coalescer_deepMergeSyncMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		v1      map[interface{}]interface{}
+		v2      map[interface{}]interface{}
+		want    map[interface{}]interface{}
+		wantErr string
+		opts    []Option
+	}{
+		{
+			name: "empty",
+			v1:   map[interface{}]interface{}{},
+			v2:   map[interface{}]interface{}{},
+			want: map[interface{}]interface{}{},
+		},
+		{
+			name: "disjoint",
+			v1:   map[interface{}]interface{}{"a": 1},
+			v2:   map[interface{}]interface{}{"b": 2},
+			want: map[interface{}]interface{}{"a": 1, "b": 2},
+		},
+		{
+			name: "overlapping",
+			v1:   map[interface{}]interface{}{"a": 1, "b": &syncMapUser{ID: 1, Name: "Alice"}},
+			v2:   map[interface{}]interface{}{"a": 2, "b": &syncMapUser{Age: 20}},
+			want: map[interface{}]interface{}{"a": 2, "b": &syncMapUser{ID: 1, Name: "Alice", Age: 20}},
+		},
+		{
+			name: "different types",
+			v1:   map[interface{}]interface{}{"a": 1},
+			v2:   map[interface{}]interface{}{"a": "abc"},
+			want: map[interface{}]interface{}{"a": "abc"},
+		},
+		{
+			name:    "merge error",
+			v1:      map[interface{}]interface{}{"a": 1},
+			v2:      map[interface{}]interface{}{"a": 2},
+			wantErr: "at Cache: fake",
+			opts: []Option{WithTypeMerger(reflect.TypeOf(0), func(v1, v2 reflect.Value) (reflect.Value, error) {
+				return reflect.Value{}, errors.New("fake")
+			})},
+		},
+		{
+			name:    "copy error",
+			v1:      map[interface{}]interface{}{"a": 1},
+			v2:      map[interface{}]interface{}{"b": 2},
+			wantErr: "at Cache: fake",
+			opts: []Option{WithTypeCopier(reflect.TypeOf(0), func(v reflect.Value) (reflect.Value, error) {
+				return reflect.Value{}, errors.New("fake")
+			})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v1 := syncMapHolder{Cache: newSyncMap(tt.v1)}
+			v2 := syncMapHolder{Cache: newSyncMap(tt.v2)}
+			got, err := DeepMerge(v1, v2, tt.opts...)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, syncMapEntries(got.Cache))
+				assert.NotSame(t, v1.Cache, got.Cache)
+				assert.NotSame(t, v2.Cache, got.Cache)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// deepMergeTimeLatest merges 2 time.Time values, or pointers thereto, by selecting the later of
+// the two timestamps. Zero timestamps and nil pointers are ignored.
+func (c *coalescer) deepMergeTimeLatest(v1, v2 reflect.Value) (reflect.Value, error) {
+	return c.deepMergeTime(v1, v2, func(t1, t2 time.Time) bool { return t2.After(t1) })
+}
+
+// deepMergeTimeEarliest merges 2 time.Time values, or pointers thereto, by selecting the earlier
+// of the two timestamps. Zero timestamps and nil pointers are ignored.
+func (c *coalescer) deepMergeTimeEarliest(v1, v2 reflect.Value) (reflect.Value, error) {
+	return c.deepMergeTime(v1, v2, func(t1, t2 time.Time) bool { return t2.Before(t1) })
+}
+
+func (c *coalescer) deepMergeTime(v1, v2 reflect.Value, favorSecond func(t1, t2 time.Time) bool) (reflect.Value, error) {
+	if indirect(v1.Type()) != timeType {
+		return reflect.Value{}, fmt.Errorf("expecting time.Time or pointer thereto, got: %s", v1.Type().String())
+	}
+	t1 := safeIndirect(v1)
+	t2 := safeIndirect(v2)
+	chosen := t1
+	if t1.IsZero() || (!t2.IsZero() && favorSecond(t1.Interface().(time.Time), t2.Interface().(time.Time))) {
+		chosen = t2
+	}
+	if v1.Kind() != reflect.Ptr {
+		return chosen, nil
+	}
+	if chosen.IsZero() {
+		// preserve the distinction between nil and a pointer to a zero timestamp
+		if v2.IsNil() {
+			return c.deepCopy(v1)
+		}
+		return c.deepCopy(v2)
+	}
+	merged := reflect.New(timeType)
+	merged.Elem().Set(chosen)
+	return merged, nil
+}
+
+func (c *coalescer) timeFieldMerger(field reflect.StructField, strategy string) (DeepMergeFunc, error) {
+	if indirect(field.Type) != timeType {
+		return nil, fmt.Errorf("%s strategy is only supported for time.Time and pointers thereto", strategy)
+	}
+	if strategy == MergeStrategyLatest {
+		return c.deepMergeTimeLatest, nil
+	}
+	return c.deepMergeTimeEarliest, nil
+}
+
+// newRecordTimeMerger returns a DeepMergeFunc that merges 2 structs, or pointers thereto, atomically,
+// by keeping the struct whose timestamp field with the given name is the later of the two (or the
+// earlier, if latest is false). Zero structs and nil pointers are ignored; when both timestamps are
+// equal, the second struct is kept.
+func (c *coalescer) newRecordTimeMerger(timestampField string, latest bool) DeepMergeFunc {
+	return func(v1, v2 reflect.Value) (reflect.Value, error) {
+		if value, done := checkZero(v1, v2); done {
+			return c.deepCopy(value)
+		}
+		t1, err := recordTimestamp(v1, timestampField)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		t2, err := recordTimestamp(v2, timestampField)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if latest && t1.After(t2) || !latest && t1.Before(t2) {
+			return c.deepCopy(v1)
+		}
+		return c.deepCopy(v2)
+	}
+}
+
+// recordTimestamp returns the value of the given timestamp field of the given struct, or pointer
+// thereto. A nil *time.Time field is returned as a zero timestamp.
+func recordTimestamp(record reflect.Value, timestampField string) (time.Time, error) {
+	deref := safeIndirect(record)
+	if deref.Kind() != reflect.Struct {
+		return time.Time{}, fmt.Errorf("expecting struct or pointer thereto, got: %s", record.Type().String())
+	}
+	field := fieldByName(deref, timestampField)
+	if !field.IsValid() {
+		return time.Time{}, fmt.Errorf("struct type %s has no field named %s", deref.Type().String(), timestampField)
+	}
+	timestamp := safeIndirect(field)
+	if timestamp.Type() != timeType {
+		return time.Time{}, fmt.Errorf("expecting time.Time or pointer thereto, got: %s", field.Type().String())
+	}
+	return timestamp.Interface().(time.Time), nil
+}
+
+func (c *coalescer) recordTimeFieldMerger(field reflect.StructField, strategy string) (DeepMergeFunc, error) {
+	name, timestampField := ParseMergeStrategyTag(strategy)
+	if timestampField == "" || strings.ContainsRune(timestampField, ':') {
+		return nil, fmt.Errorf("%s strategy must be followed by a colon and the name of a timestamp field", name)
+	}
+	recordType := indirect(field.Type)
+	if recordType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s:%s strategy is only supported for structs and pointers thereto", name, timestampField)
+	}
+	timestamp, found := recordType.FieldByName(timestampField)
+	if !found {
+		return nil, fmt.Errorf("struct type %s has no field named %s", recordType.String(), timestampField)
+	} else if indirect(timestamp.Type) != timeType {
+		return nil, fmt.Errorf("field %s.%s is not a time.Time or pointer thereto", recordType.String(), timestampField)
+	}
+	return c.newRecordTimeMerger(timestampField, name == MergeStrategyLatest), nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func Test_coalescer_deepMergeTime(t *testing.T) {
+	early := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	type session struct {
+		LastSeen  time.Time  `goalesce:"latest"`
+		ExpiresAt *time.Time `goalesce:"earliest"`
+		Created   time.Time  `goalesce:"earliest"`
+	}
+	tests := []struct {
+		name string
+		v1   session
+		v2   session
+		want session
+	}{
+		{
+			name: "zero",
+			v1:   session{},
+			v2:   session{},
+			want: session{},
+		},
+		{
+			name: "v1 only",
+			v1:   session{LastSeen: late, ExpiresAt: timePtr(late), Created: late},
+			v2:   session{},
+			want: session{LastSeen: late, ExpiresAt: timePtr(late), Created: late},
+		},
+		{
+			name: "v2 only",
+			v1:   session{},
+			v2:   session{LastSeen: early, ExpiresAt: timePtr(early), Created: early},
+			want: session{LastSeen: early, ExpiresAt: timePtr(early), Created: early},
+		},
+		{
+			name: "v1 wins",
+			v1:   session{LastSeen: late, ExpiresAt: timePtr(early), Created: early},
+			v2:   session{LastSeen: early, ExpiresAt: timePtr(late), Created: late},
+			want: session{LastSeen: late, ExpiresAt: timePtr(early), Created: early},
+		},
+		{
+			name: "v2 wins",
+			v1:   session{LastSeen: early, ExpiresAt: timePtr(late), Created: late},
+			v2:   session{LastSeen: late, ExpiresAt: timePtr(early), Created: early},
+			want: session{LastSeen: late, ExpiresAt: timePtr(early), Created: early},
+		},
+		{
+			name: "pointer to zero",
+			v1:   session{ExpiresAt: nil},
+			v2:   session{ExpiresAt: timePtr(time.Time{})},
+			want: session{ExpiresAt: timePtr(time.Time{})},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			if got.ExpiresAt != nil {
+				assert.NotSame(t, tt.v1.ExpiresAt, got.ExpiresAt)
+				assert.NotSame(t, tt.v2.ExpiresAt, got.ExpiresAt)
+			}
+		})
+	}
+	t.Run("wrong type", func(t *testing.T) {
+		c := newCoalescer()
+		_, err := c.deepMergeTimeLatest(reflect.ValueOf(1), reflect.ValueOf(2))
+		assert.EqualError(t, err, "expecting time.Time or pointer thereto, got: int")
+	})
+}
+
+func Test_coalescer_newRecordTimeMerger(t *testing.T) {
+	early := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	type item struct {
+		SKU       string
+		Count     int
+		Labels    map[string]string
+		UpdatedAt time.Time
+		CreatedAt *time.Time
+	}
+	type inventory struct {
+		Latest   item    `goalesce:"latest:UpdatedAt"`
+		Earliest *item   `goalesce:"earliest:CreatedAt"`
+		Items    []*item `goalesce:"id:SKU,elem=latest:UpdatedAt"`
+	}
+	tests := []struct {
+		name string
+		v1   inventory
+		v2   inventory
+		want inventory
+	}{
+		{
+			name: "zero",
+			v1:   inventory{},
+			v2:   inventory{},
+			want: inventory{},
+		},
+		{
+			name: "v1 later",
+			v1:   inventory{Latest: item{Count: 1, Labels: map[string]string{"a": "b"}, UpdatedAt: late}},
+			v2:   inventory{Latest: item{SKU: "a", Labels: map[string]string{"c": "d"}, UpdatedAt: early}},
+			want: inventory{Latest: item{Count: 1, Labels: map[string]string{"a": "b"}, UpdatedAt: late}},
+		},
+		{
+			name: "v2 later",
+			v1:   inventory{Latest: item{Count: 1, UpdatedAt: early}},
+			v2:   inventory{Latest: item{SKU: "a", UpdatedAt: late}},
+			want: inventory{Latest: item{SKU: "a", UpdatedAt: late}},
+		},
+		{
+			name: "equal timestamps",
+			v1:   inventory{Latest: item{Count: 1, UpdatedAt: late}},
+			v2:   inventory{Latest: item{Count: 2, UpdatedAt: late}},
+			want: inventory{Latest: item{Count: 2, UpdatedAt: late}},
+		},
+		{
+			name: "pointer earliest",
+			v1:   inventory{Earliest: &item{Count: 1, CreatedAt: &early}},
+			v2:   inventory{Earliest: &item{Count: 2, CreatedAt: &late}},
+			want: inventory{Earliest: &item{Count: 1, CreatedAt: &early}},
+		},
+		{
+			name: "nil pointer",
+			v1:   inventory{Earliest: &item{Count: 1}},
+			v2:   inventory{},
+			want: inventory{Earliest: &item{Count: 1}},
+		},
+		{
+			name: "slice elements",
+			v1:   inventory{Items: []*item{{SKU: "a", Count: 1, UpdatedAt: late}, {SKU: "b", Count: 1, UpdatedAt: early}}},
+			v2:   inventory{Items: []*item{{SKU: "b", Count: 2, UpdatedAt: late}, {SKU: "a", Count: 2, UpdatedAt: early}}},
+			want: inventory{Items: []*item{{SKU: "a", Count: 1, UpdatedAt: late}, {SKU: "b", Count: 2, UpdatedAt: late}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeepMerge(tt.v1, tt.v2)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assertNotSame(t, tt.v1, got)
+			assertNotSame(t, tt.v2, got)
+		})
+	}
+	t.Run("invalid tags", func(t *testing.T) {
+		type notStruct struct {
+			Field int `goalesce:"latest:UpdatedAt"`
+		}
+		type missingField struct {
+			Field item `goalesce:"latest:ModifiedAt"`
+		}
+		type notTimestamp struct {
+			Field item `goalesce:"latest:Count"`
+		}
+		type missingName struct {
+			Field item `goalesce:"earliest:"`
+		}
+		_, err := DeepMerge(notStruct{Field: 1}, notStruct{Field: 2})
+		assert.EqualError(t, err, "at Field: field goalesce.notStruct.Field: latest:UpdatedAt strategy is only supported for structs and pointers thereto")
+		_, err = DeepMerge(missingField{Field: item{Count: 1}}, missingField{Field: item{Count: 2}})
+		assert.EqualError(t, err, "at Field: field goalesce.missingField.Field: struct type goalesce.item has no field named ModifiedAt")
+		_, err = DeepMerge(notTimestamp{Field: item{Count: 1}}, notTimestamp{Field: item{Count: 2}})
+		assert.EqualError(t, err, "at Field: field goalesce.notTimestamp.Field: field goalesce.item.Count is not a time.Time or pointer thereto")
+		_, err = DeepMerge(missingName{Field: item{Count: 1}}, missingName{Field: item{Count: 2}})
+		assert.EqualError(t, err, "at Field: field goalesce.missingName.Field: earliest strategy must be followed by a colon and the name of a timestamp field")
+	})
+	t.Run("wrong type", func(t *testing.T) {
+		c := newCoalescer()
+		_, err := c.newRecordTimeMerger("UpdatedAt", true)(reflect.ValueOf(1), reflect.ValueOf(2))
+		assert.EqualError(t, err, "expecting struct or pointer thereto, got: int")
+	})
+}
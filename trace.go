@@ -0,0 +1,55 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// tracer writes merge traces to a writer, possibly from several goroutines, see MergeMany.
+type tracer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// trace writes a merge decision about the value at the current path, if WithTrace is in effect.
+// Lines are indented according to the depth of the value being merged.
+func (c *coalescer) trace(format string, args ...interface{}) {
+	if c.tracer == nil {
+		return
+	}
+	path := c.path
+	if path == "" {
+		path = "<root>"
+	}
+	line := strings.Repeat("  ", c.traceDepth) + path + ": " + fmt.Sprintf(format, args...) + "\n"
+	c.tracer.mu.Lock()
+	defer c.tracer.mu.Unlock()
+	// errors are ignored: tracing is a debugging aid, and must not fail the merge
+	_, _ = io.WriteString(c.tracer.w, line)
+}
+
+// traceZeroShortcut traces a merge short-circuited because one of the values is zero.
+func (c *coalescer) traceZeroShortcut(v1 reflect.Value) {
+	if v1.IsZero() {
+		c.trace("first value is zero, copying second value")
+	} else {
+		c.trace("second value is zero, copying first value")
+	}
+}
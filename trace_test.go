@@ -0,0 +1,57 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeepMerge_trace(t *testing.T) {
+	type inner struct {
+		Tags  []string `goalesce:"union"`
+		Other []string
+	}
+	type outer struct {
+		Name  string
+		Inner inner
+		Ptr   *inner
+		Count int
+		Ports []int
+	}
+	v1 := outer{Name: "a", Inner: inner{Tags: []string{"x"}, Other: []string{"o"}}, Count: 1, Ports: []int{80}}
+	v2 := outer{Inner: inner{Tags: []string{"y"}, Other: []string{"p"}}, Ptr: &inner{}, Count: 2, Ports: []int{443}}
+	var buf bytes.Buffer
+	_, err := DeepMerge(v1, v2, WithTrace(&buf), WithSliceListAppendMerge(reflect.TypeOf([]int{})))
+	require.NoError(t, err)
+	assert.Equal(t, `<root>: merging goalesce.outer
+  Name: merging string
+    Name: atomic merge, keeping first value
+  Inner: merging goalesce.inner
+    Inner.Tags: using merge strategy from tag: union
+    Inner.Other: merging []string
+      Inner.Other: atomic merge, using second value
+  Ptr: merging *goalesce.inner
+    Ptr: first value is zero, copying second value
+  Count: merging int
+    Count: atomic merge, using second value
+  Ports: merging []int
+    Ports: using slice merger for []int
+`, buf.String())
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// UnexportedFieldPolicy determines what happens to the unexported fields of structs when they are
+// copied or merged, see WithUnexportedFieldPolicy.
+type UnexportedFieldPolicy int
+
+const (
+	// UnexportedFieldsSkip leaves unexported fields zero in copied and merged structs. This is the
+	// default. Lost fields are reported as warnings, see WithWarnings.
+	UnexportedFieldsSkip UnexportedFieldPolicy = iota
+	// UnexportedFieldsError fails the operation with an *UnexportedFieldError when a struct with a
+	// non-zero unexported field is copied or merged.
+	UnexportedFieldsError
+	// UnexportedFieldsShare copies structs with unexported fields with a plain assignment, that is,
+	// shallowly: the copy shares all the references held by the original struct. Such structs are
+	// merged atomically.
+	UnexportedFieldsShare
+	// UnexportedFieldsCopy deep-copies unexported fields using unsafe reflection, see
+	// WithUnexportedFieldCopy.
+	UnexportedFieldsCopy
+)
+
+// unexportedFieldLost applies the unexported field policy to a non-zero unexported field that
+// cannot be copied or merged: it returns an error with UnexportedFieldsError, and reports a
+// warning otherwise.
+func (c *coalescer) unexportedFieldLost(structType reflect.Type, path, field, msg string) error {
+	if c.unexportedFieldPolicy == UnexportedFieldsError {
+		return &UnexportedFieldError{Path: path, Struct: structType, Field: field}
+	}
+	c.warnAt(path, msg)
+	return nil
+}
+
+// sharesUnexportedFields returns true if the given struct type must be shared as a whole, according
+// to the UnexportedFieldsShare policy.
+func (c *coalescer) sharesUnexportedFields(structType reflect.Type) bool {
+	return c.unexportedFieldPolicy == UnexportedFieldsShare && hasUnexportedFields(structType)
+}
+
+// copyUnexportedField deep-copies the i-th field of src, which must be unexported, into the same
+// field of dst, which must be addressable. See WithUnexportedFieldCopy.
+func (c *coalescer) copyUnexportedField(dst, src reflect.Value, i int) error {
+	if !src.CanAddr() {
+		// unexported fields can only be accessed through their address
+		addressable := reflect.New(src.Type()).Elem()
+		addressable.Set(src)
+		src = addressable
+	}
+	copied, err := c.deepCopy(unexportedField(src, i))
+	if err != nil {
+		return err
+	}
+	unexportedField(dst, i).Set(copied)
+	return nil
+}
+
+// unexportedField returns the i-th field of the given addressable struct value, circumventing the
+// restrictions that reflection imposes on unexported fields.
+func unexportedField(v reflect.Value, i int) reflect.Value {
+	f := v.Field(i)
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+}
@@ -0,0 +1,112 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type opaque struct {
+	Name    string
+	secret  string
+	counter *int
+	tags    []string
+	nested  *opaque
+}
+
+func TestDeepCopy_unexportedFields(t *testing.T) {
+	v := opaque{
+		Name:    "a",
+		secret:  "s",
+		counter: intPtr(1),
+		tags:    []string{"x"},
+		nested:  &opaque{secret: "nested"},
+	}
+	t.Run("default", func(t *testing.T) {
+		got, err := DeepCopy(v)
+		require.NoError(t, err)
+		assert.Equal(t, opaque{Name: "a"}, got)
+	})
+	t.Run("WithUnexportedFieldCopy", func(t *testing.T) {
+		got, err := DeepCopy(v, WithUnexportedFieldCopy())
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+		assert.NotSame(t, v.counter, got.counter)
+		assert.NotSame(t, v.nested, got.nested)
+		got.tags[0] = "y"
+		assert.Equal(t, "x", v.tags[0])
+	})
+	t.Run("pointer", func(t *testing.T) {
+		got, err := DeepCopy(&v, WithUnexportedFieldCopy())
+		require.NoError(t, err)
+		assert.Equal(t, &v, got)
+	})
+	t.Run("CopyInto", func(t *testing.T) {
+		var dst opaque
+		err := CopyInto(&dst, v, WithUnexportedFieldCopy())
+		require.NoError(t, err)
+		assert.Equal(t, v, dst)
+	})
+	t.Run("merge", func(t *testing.T) {
+		got, err := DeepMerge(opaque{}, v, WithUnexportedFieldCopy())
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	})
+}
+
+func TestUnexportedFieldPolicy(t *testing.T) {
+	v := opaque{Name: "a", secret: "s", counter: intPtr(1)}
+	t.Run("skip", func(t *testing.T) {
+		var warnings []Warning
+		got, err := DeepCopy(v, WithWarnings(&warnings))
+		require.NoError(t, err)
+		assert.Equal(t, opaque{Name: "a"}, got)
+		assert.Len(t, warnings, 2)
+	})
+	t.Run("error", func(t *testing.T) {
+		_, err := DeepCopy(v, WithUnexportedFieldPolicy(UnexportedFieldsError))
+		var unexportedErr *UnexportedFieldError
+		require.ErrorAs(t, err, &unexportedErr)
+		assert.Equal(t, "secret", unexportedErr.Path)
+		assert.EqualError(t, err, "unexported field goalesce.opaque.secret would be lost")
+		_, err = DeepMerge(v, opaque{Name: "b"}, WithUnexportedFieldPolicy(UnexportedFieldsError))
+		require.ErrorAs(t, err, &unexportedErr)
+		got, err := DeepCopy(opaque{Name: "a"}, WithUnexportedFieldPolicy(UnexportedFieldsError))
+		require.NoError(t, err)
+		assert.Equal(t, opaque{Name: "a"}, got)
+	})
+	t.Run("share", func(t *testing.T) {
+		got, err := DeepCopy(v, WithUnexportedFieldPolicy(UnexportedFieldsShare))
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+		assert.Same(t, v.counter, got.counter)
+		v2 := opaque{Name: "b", counter: intPtr(2)}
+		got, err = DeepMerge(v, v2, WithUnexportedFieldPolicy(UnexportedFieldsShare))
+		require.NoError(t, err)
+		assert.Equal(t, v2, got)
+		got, err = DeepMerge(v, opaque{}, WithUnexportedFieldPolicy(UnexportedFieldsShare))
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+	})
+	t.Run("copy", func(t *testing.T) {
+		got, err := DeepCopy(v, WithUnexportedFieldPolicy(UnexportedFieldsCopy))
+		require.NoError(t, err)
+		assert.Equal(t, v, got)
+		assert.NotSame(t, v.counter, got.counter)
+	})
+}
@@ -15,8 +15,10 @@
 package goalesce
 
 import (
+	"cmp"
 	"fmt"
 	"reflect"
+	"sort"
 )
 
 // zero returns the zero-value of type T.
@@ -64,7 +66,7 @@ func checkZero(v1, v2 reflect.Value) (reflect.Value, bool) {
 
 func checkTypesMatch(v1, v2 reflect.Type) error {
 	if v1 != v2 {
-		return fmt.Errorf("types do not match: %s != %s", v1.String(), v2.String())
+		return &TypeMismatchError{Type1: v1, Type2: v2}
 	}
 	return nil
 }
@@ -80,3 +82,94 @@ func checkCustomResult(result reflect.Value, err error, expectedType reflect.Typ
 	}
 	return false, reflect.Value{}, nil
 }
+
+// fieldPath returns the path of the given struct field, relative to the given parent path.
+func fieldPath(parent, field string) string {
+	if parent == "" {
+		return field
+	}
+	return parent + "." + field
+}
+
+// indexPath returns the path of the given slice or array index, relative to the given parent path.
+func indexPath(parent string, index int) string {
+	return fmt.Sprintf("%s[%d]", parent, index)
+}
+
+// keyPath returns the path of the given map key, relative to the given parent path. String keys are
+// quoted, so that paths can be parsed back unambiguously.
+func keyPath(parent string, key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return fmt.Sprintf("%s[%q]", parent, key.String())
+	}
+	return fmt.Sprintf("%s[%v]", parent, key.Interface())
+}
+
+// sortedMapKeys returns the keys of the given map, in a deterministic order (see compareKeys). Maps
+// are iterated in this order whenever the iteration order is observable, e.g. in errors or diffs.
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compareKeys(keys[i], keys[j]) < 0
+	})
+	return keys
+}
+
+// compareKeys compares two map keys. Keys of basic types are compared by value, e.g. numerically
+// for integers; composite keys are compared element by element; keys of different types (in maps
+// with interface keys) are compared by type name first.
+func compareKeys(k1, k2 reflect.Value) int {
+	if k1.Kind() == reflect.Interface {
+		if k1.IsNil() || k2.IsNil() {
+			return cmp.Compare(btoi(!k1.IsNil()), btoi(!k2.IsNil()))
+		}
+		return compareKeys(k1.Elem(), k2.Elem())
+	}
+	if k1.Type() != k2.Type() {
+		if c := cmp.Compare(k1.Type().String(), k2.Type().String()); c != 0 {
+			return c
+		}
+		return cmp.Compare(fmt.Sprint(k1), fmt.Sprint(k2))
+	}
+	switch k1.Kind() {
+	case reflect.Bool:
+		return cmp.Compare(btoi(k1.Bool()), btoi(k2.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(k1.Int(), k2.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(k1.Uint(), k2.Uint())
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(k1.Float(), k2.Float())
+	case reflect.Complex64, reflect.Complex128:
+		if c := cmp.Compare(real(k1.Complex()), real(k2.Complex())); c != 0 {
+			return c
+		}
+		return cmp.Compare(imag(k1.Complex()), imag(k2.Complex()))
+	case reflect.String:
+		return cmp.Compare(k1.String(), k2.String())
+	case reflect.Array:
+		for i := 0; i < k1.Len(); i++ {
+			if c := compareKeys(k1.Index(i), k2.Index(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Struct:
+		for i := 0; i < k1.NumField(); i++ {
+			if c := compareKeys(k1.Field(i), k2.Field(i)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		return cmp.Compare(k1.Pointer(), k2.Pointer())
+	}
+	return cmp.Compare(fmt.Sprint(k1), fmt.Sprint(k2))
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
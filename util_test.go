@@ -225,3 +225,24 @@ func Test_checkCustomResult(t *testing.T) {
 		})
 	}
 }
+
+func Test_sortedMapKeys(t *testing.T) {
+	type point struct {
+		X, Y int
+	}
+	keysOf := func(m interface{}) []interface{} {
+		var keys []interface{}
+		for _, k := range sortedMapKeys(reflect.ValueOf(m)) {
+			keys = append(keys, k.Interface())
+		}
+		return keys
+	}
+	assert.Equal(t, []interface{}{"a", "b", "c"}, keysOf(map[string]int{"c": 1, "a": 2, "b": 3}))
+	assert.Equal(t, []interface{}{-1, 2, 10}, keysOf(map[int]int{10: 1, 2: 2, -1: 3}))
+	assert.Equal(t, []interface{}{uint(2), uint(10)}, keysOf(map[uint]int{10: 1, 2: 2}))
+	assert.Equal(t, []interface{}{-1.5, 2.5}, keysOf(map[float64]int{2.5: 1, -1.5: 2}))
+	assert.Equal(t, []interface{}{false, true}, keysOf(map[bool]int{true: 1, false: 2}))
+	assert.Equal(t, []interface{}{[2]int{1, 2}, [2]int{1, 3}}, keysOf(map[[2]int]int{{1, 3}: 1, {1, 2}: 2}))
+	assert.Equal(t, []interface{}{point{1, 2}, point{2, 1}}, keysOf(map[point]int{{2, 1}: 1, {1, 2}: 2}))
+	assert.Equal(t, []interface{}{nil, 2, 10, "a"}, keysOf(map[interface{}]int{"a": 1, 10: 2, 2: 3, nil: 4}))
+}
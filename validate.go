@@ -0,0 +1,109 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidateFunc is a function that validates the result of a merge. It is typically a thin wrapper
+// around a validation library, e.g. go-playground/validator's Validate.Struct method.
+type ValidateFunc func(merged interface{}) error
+
+// ValidationError is the error returned by DeepMerge when the merged value fails validation. See
+// WithValidator.
+type ValidationError struct {
+	// Path is the location of the invalid value, e.g. "Spec.Containers[2].Image". It is empty when
+	// the violation applies to the merged value as a whole, or when it could not be determined.
+	Path string
+	// Err is the error returned by the validator.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("validation failed: %v", e.Err)
+	}
+	return fmt.Sprintf("validation failed at %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the error returned by the validator.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// namespacedError is the interface implemented by go-playground/validator's FieldError. It is
+// declared here so that violations can be mapped back to field paths without depending on that
+// library.
+type namespacedError interface {
+	error
+	StructNamespace() string
+}
+
+// validate validates the merged value with the configured validator, if any.
+func (c *coalescer) validate(merged reflect.Value) error {
+	if c.validator == nil || !merged.IsValid() {
+		return nil
+	}
+	err := c.validator(merged.Interface())
+	if err == nil {
+		return nil
+	}
+	violations := validationErrors(err)
+	if len(violations) == 0 {
+		return &ValidationError{Err: err}
+	}
+	errs := make([]error, len(violations))
+	for i, violation := range violations {
+		errs[i] = &ValidationError{Path: namespaceToPath(violation.StructNamespace()), Err: violation}
+	}
+	return errors.Join(errs...)
+}
+
+// validationErrors extracts individual field violations from the given error. It recognizes
+// errors implementing namespacedError, and slices thereof, like go-playground/validator's
+// ValidationErrors.
+func validationErrors(err error) []namespacedError {
+	var violation namespacedError
+	if errors.As(err, &violation) {
+		return []namespacedError{violation}
+	}
+	v := reflect.ValueOf(err)
+	if v.Kind() != reflect.Slice {
+		return nil
+	}
+	violations := make([]namespacedError, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		if violation, ok := v.Index(i).Interface().(namespacedError); ok {
+			violations = append(violations, violation)
+		} else {
+			return nil
+		}
+	}
+	return violations
+}
+
+// namespaceToPath converts a struct namespace, e.g. "Config.Spec.Name", into a field path relative
+// to the root value, e.g. "Spec.Name".
+func namespaceToPath(namespace string) string {
+	if i := strings.IndexAny(namespace, ".["); i != -1 {
+		return strings.TrimPrefix(namespace[i:], ".")
+	}
+	return ""
+}
@@ -0,0 +1,128 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockFieldError mimics go-playground/validator's FieldError.
+type mockFieldError struct {
+	namespace string
+	tag       string
+}
+
+func (e mockFieldError) Error() string {
+	return "Key: '" + e.namespace + "' Error:Field validation failed on the '" + e.tag + "' tag"
+}
+
+func (e mockFieldError) StructNamespace() string {
+	return e.namespace
+}
+
+// mockValidationErrors mimics go-playground/validator's ValidationErrors.
+type mockValidationErrors []mockFieldError
+
+func (e mockValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func Test_coalescer_validate(t *testing.T) {
+	type config struct {
+		Name string
+	}
+	tests := []struct {
+		name      string
+		validator ValidateFunc
+		want      []*ValidationError
+	}{
+		{
+			name:      "no validator",
+			validator: nil,
+		},
+		{
+			name:      "valid",
+			validator: func(interface{}) error { return nil },
+		},
+		{
+			name:      "generic error",
+			validator: func(interface{}) error { return errors.New("invalid") },
+			want:      []*ValidationError{{Err: errors.New("invalid")}},
+		},
+		{
+			name: "field error",
+			validator: func(interface{}) error {
+				return mockFieldError{namespace: "config.Spec.Name", tag: "required"}
+			},
+			want: []*ValidationError{
+				{Path: "Spec.Name", Err: mockFieldError{namespace: "config.Spec.Name", tag: "required"}},
+			},
+		},
+		{
+			name: "validation errors",
+			validator: func(interface{}) error {
+				return mockValidationErrors{
+					{namespace: "config.Name", tag: "required"},
+					{namespace: "config[0].Name", tag: "min"},
+					{namespace: "config", tag: "custom"},
+				}
+			},
+			want: []*ValidationError{
+				{Path: "Name", Err: mockFieldError{namespace: "config.Name", tag: "required"}},
+				{Path: "[0].Name", Err: mockFieldError{namespace: "config[0].Name", tag: "min"}},
+				{Path: "", Err: mockFieldError{namespace: "config", tag: "custom"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newCoalescer(WithValidator(tt.validator))
+			err := c.validate(reflect.ValueOf(config{}))
+			if tt.want == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var got []*ValidationError
+			if joined, ok := err.(interface{ Unwrap() []error }); ok {
+				for _, e := range joined.Unwrap() {
+					got = append(got, e.(*ValidationError))
+				}
+			} else {
+				got = append(got, err.(*ValidationError))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	t.Run("invalid value", func(t *testing.T) {
+		c := newCoalescer(WithValidator(func(interface{}) error { return errors.New("invalid") }))
+		assert.NoError(t, c.validate(reflect.Value{}))
+	})
+}
+
+func TestValidationError(t *testing.T) {
+	cause := errors.New("cause")
+	assert.EqualError(t, &ValidationError{Err: cause}, "validation failed: cause")
+	assert.EqualError(t, &ValidationError{Path: "Spec.Name", Err: cause}, "validation failed at Spec.Name: cause")
+	assert.ErrorIs(t, &ValidationError{Err: cause}, cause)
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Warning is a recoverable issue encountered during a copy or a merge, see WithWarnings.
+type Warning struct {
+	// Path is the location of the issue, e.g. "Spec.Containers[2].Ports".
+	Path string
+	// Message describes the issue.
+	Message string
+}
+
+// String returns a human-readable representation of the warning.
+func (w Warning) String() string {
+	if w.Path == "" {
+		return w.Message
+	}
+	return w.Path + ": " + w.Message
+}
+
+// warnings collects warnings into a slice, possibly from several goroutines, see MergeMany.
+type warnings struct {
+	mu  sync.Mutex
+	dst *[]Warning
+}
+
+// warn reports a warning at the current path, if WithWarnings is in effect.
+func (c *coalescer) warn(format string, args ...interface{}) {
+	c.warnAt(c.path, format, args...)
+}
+
+// warnAt reports a warning at the given path, if WithWarnings is in effect.
+func (c *coalescer) warnAt(path, format string, args ...interface{}) {
+	if c.warnings == nil {
+		return
+	}
+	c.warnings.mu.Lock()
+	defer c.warnings.mu.Unlock()
+	*c.warnings.dst = append(*c.warnings.dst, Warning{Path: path, Message: fmt.Sprintf(format, args...)})
+}
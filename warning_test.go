@@ -0,0 +1,41 @@
+// Copyright 2022 Alexandre Dutra
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalesce
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarning_String(t *testing.T) {
+	assert.Equal(t, "Spec.Ports: new map key ignored", Warning{Path: "Spec.Ports", Message: "new map key ignored"}.String())
+	assert.Equal(t, "new map key ignored", Warning{Message: "new map key ignored"}.String())
+}
+
+func Test_coalescer_warn(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		c := newCoalescer()
+		assert.NotPanics(t, func() { c.warn("ignored") })
+	})
+	t.Run("enabled", func(t *testing.T) {
+		var warnings []Warning
+		c := newCoalescer(WithWarnings(&warnings))
+		c.path = "Foo"
+		c.warn("message %d", 1)
+		c.warnAt("Bar", "message %d", 2)
+		assert.Equal(t, []Warning{{Path: "Foo", Message: "message 1"}, {Path: "Bar", Message: "message 2"}}, warnings)
+	})
+}